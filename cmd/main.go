@@ -7,6 +7,7 @@ import (
 	"github.com/WhyDias/Marketplace/internal/controllers"
 	"github.com/WhyDias/Marketplace/internal/db"
 	"github.com/WhyDias/Marketplace/internal/middlewares"
+	"github.com/WhyDias/Marketplace/internal/models"
 	"github.com/WhyDias/Marketplace/internal/services"
 	"github.com/WhyDias/Marketplace/pkg/jwt"
 	"log"
@@ -53,14 +54,45 @@ func main() {
 	productService := services.NewProductService()
 	categoryService := services.NewCategoryService()
 	attributeService := services.NewAttributeService()
+	imageService := services.NewImageService()
+	moderationService := services.NewModerationService()
+	autoModerationService := services.NewAutoModerationService()
+	rejectionReasonService := services.NewRejectionReasonService()
+	translationService := services.NewTranslationService()
+	taxonomyService := services.NewTaxonomyService()
+	colorService := services.NewColorService()
+	sitemapService := services.NewSitemapService()
+	marketService := services.NewMarketService()
 
 	// Инициализация контроллеров
 	attributeController := controllers.NewAttributeController(attributeService)
-	productController := controllers.NewProductController(productService, supplierService)
+	productController := controllers.NewProductController(productService, supplierService, translationService)
 	userController := controllers.NewUserController(userService, supplierService, jwtService)
-	supplierController := controllers.NewSupplierController(supplierService)
+	supplierController := controllers.NewSupplierController(supplierService, translationService)
 	verificationController := controllers.NewVerificationController(supplierService, userService)
-	categoryController := controllers.NewCategoryController(categoryService)
+	categoryController := controllers.NewCategoryController(categoryService, translationService)
+	imageController := controllers.NewImageController(imageService)
+	moderationController := controllers.NewModerationController(moderationService, autoModerationService)
+	rejectionReasonController := controllers.NewRejectionReasonController(rejectionReasonService)
+	translationController := controllers.NewTranslationController(translationService)
+	taxonomyController := controllers.NewTaxonomyController(taxonomyService)
+	colorController := controllers.NewColorController(colorService)
+	sitemapController := controllers.NewSitemapController(sitemapService)
+	marketController := controllers.NewMarketController(marketService)
+
+	// Slug продуктов, созданных до появления slug
+	if err := productService.BackfillProductSlugs(); err != nil {
+		log.Printf("Не удалось заполнить slug продуктов: %v", err)
+	}
+
+	// Задания смены типа атрибутов, прерванные остановкой сервера
+	services.FailInterruptedConversions()
+
+	// Перенос событий продуктов в дневную статистику для кабинета поставщика
+	services.StartProductStatsAggregator(services.ProductStatsAggregationInterval)
+
+	// Начало и конец отпусков поставщиков
+	services.StartSupplierVacationScheduler(services.SupplierVacationSyncInterval)
 
 	// Создание роутера Gin
 	router := gin.Default()
@@ -74,6 +106,7 @@ func main() {
 	// Публичные маршруты (доступны без токена)
 	router.POST("/register", verificationController.SendVerificationCode)
 	router.POST("/verify", verificationController.VerifyCode)
+	router.POST("/api/supplier/team/invites/accept", userController.AcceptSupplierInvite)
 	router.POST("/set_password", userController.SetPassword)
 	router.POST("/api/users/login", userController.LoginUser)
 	router.POST("/api/users/register", userController.RegisterUser)
@@ -92,10 +125,29 @@ func main() {
 	//router.POST("/api/categories/attributes", categoryController.AddCategoryAttributes)
 	//router.GET("/api/categories/:id/attributes", categoryController.GetCategoryAttributesByCategoryID)
 	router.GET("/api/categories/:id", categoryController.GetCategoryByID)
+	router.GET("/api/categories/by-slug/*slug", categoryController.GetCategoryBySlug)
 	//router.POST("/api/products", productController.AddProduct)
 	router.Static("/uploads", "./uploads")
 	router.GET("/api/categories/root", categoryController.GetRootCategories)
 	router.GET("/attributes", categoryController.GetAttributesByCategoryAndIsLinked)
+	router.GET("/api/products/:id/images", imageController.GetProductImages)
+	router.GET("/api/products/:id", productController.GetProduct)
+	router.POST("/api/products/:id/contact-click",
+		middlewares.RateLimitByClient(services.ContactClickRateInterval, services.ContactClickRateBurst),
+		productController.RecordContactClick)
+	router.GET("/api/products/by-slug/:slug", productController.GetProductBySlug)
+	router.GET("/sitemap.xml", sitemapController.GetSitemap)
+	router.GET("/api/products/:id/attributes", productController.GetProductAttributeValues)
+	router.GET("/api/attributes/units", attributeController.GetUnits)
+	router.GET("/api/variations/:id/images", imageController.GetVariationImages)
+	router.GET("/api/colors", colorController.GetColors)
+	router.GET("/api/colors/families", colorController.GetColorFamilies)
+	router.GET("/api/catalog/colors", colorController.GetColorFacets)
+	router.GET("/api/catalog/products", productController.GetCatalogProducts)
+	router.GET("/api/suppliers/:id", supplierController.GetStorefront)
+	router.GET("/api/markets/nearby", marketController.GetNearbyMarkets)
+	router.GET("/api/markets/:id", marketController.GetMarket)
+	router.GET("/api/market-rows/:id/suppliers", marketController.GetRowSuppliers)
 
 	// Защищенные маршруты
 	authorized := router.Group("/")
@@ -108,12 +160,124 @@ func main() {
 		authorized.POST("/api/products", productController.AddProduct)
 		authorized.POST("/api/categories/attributes", categoryController.AddCategoryAttributes)
 		authorized.GET("/api/categories/:id/attributes", categoryController.GetCategoryAttributesByCategoryID)
+		authorized.GET("/api/attributes/:id/versions", attributeController.GetAttributeVersions)
 		authorized.GET("/api/supplier/categories", supplierController.GetSupplierCategoriesHandler)
+		authorized.GET("/api/supplier/profile", supplierController.GetProfile)
+		authorized.PUT("/api/supplier/profile", supplierController.UpdateProfile)
+		authorized.GET("/api/supplier/verification", supplierController.GetVerification)
+		authorized.POST("/api/supplier/verification/documents", supplierController.UploadDocument)
+		authorized.DELETE("/api/supplier/verification/documents/:id", supplierController.DeleteDocument)
+		authorized.POST("/api/supplier/verification/submit", supplierController.SubmitVerification)
+		authorized.GET("/api/supplier/team", supplierController.GetTeam)
+		authorized.POST("/api/supplier/team/invites", supplierController.InviteMember)
+		authorized.DELETE("/api/supplier/team/invites/:id", supplierController.RevokeInvite)
+		authorized.PUT("/api/supplier/team/members/:user_id", supplierController.UpdateMemberRole)
+		authorized.DELETE("/api/supplier/team/members/:user_id", supplierController.RemoveMember)
+		authorized.GET("/api/supplier/dashboard", supplierController.GetDashboard)
+		authorized.GET("/api/supplier/schedule", supplierController.GetSchedule)
+		authorized.PUT("/api/supplier/schedule/working-hours", supplierController.UpdateWorkingHours)
+		authorized.PUT("/api/supplier/schedule/exceptions/:date", supplierController.SetScheduleException)
+		authorized.DELETE("/api/supplier/schedule/exceptions/:date", supplierController.DeleteScheduleException)
+		authorized.PUT("/api/supplier/vacation", supplierController.SetVacation)
+		authorized.DELETE("/api/supplier/vacation", supplierController.CancelVacation)
+		authorized.GET("/api/favorites", productController.GetFavorites)
+		authorized.PUT("/api/products/:id/favorite", productController.AddFavorite)
+		authorized.DELETE("/api/products/:id/favorite", productController.RemoveFavorite)
 		authorized.DELETE("/categories/:category_id/attributes", categoryController.DeleteCategoryAttributes)
 		authorized.GET("/categories/:path/attributes", categoryController.GetCategoryAttributesByPath)
 		authorized.PUT("/api/products/:id", productController.UpdateProduct)
-		authorized.POST("/products/:id/approve", productController.ApproveProduct)
-		authorized.POST("/products/:id/reject", productController.RejectProduct)
+
+		// Изображения продуктов и вариаций
+		authorized.POST("/api/products/:id/images", imageController.AddProductImages)
+		authorized.PUT("/api/products/:id/images", imageController.ReorderProductImages)
+		authorized.DELETE("/api/products/:id/images/:image_id", imageController.DeleteProductImage)
+		authorized.PUT("/api/products/:id/images/:image_id/primary", imageController.SetPrimaryProductImage)
+		authorized.POST("/api/variations/:id/images", imageController.AddVariationImages)
+		authorized.PUT("/api/variations/:id/images", imageController.ReorderVariationImages)
+		authorized.DELETE("/api/variations/:id/images/:image_id", imageController.DeleteVariationImage)
+		authorized.PUT("/api/variations/:id/images/:image_id/primary", imageController.SetPrimaryVariationImage)
+
+		// Модерация
+		authorized.GET("/api/products/:id/moderation-history", moderationController.GetProductHistory)
+		authorized.GET("/api/moderation/rejection-reasons", rejectionReasonController.GetRejectionReasons)
+	}
+
+	// Модерация, доступная модераторам и администраторам
+	moderation := router.Group("/")
+	moderation.Use(middlewares.AuthMiddleware(jwtService), middlewares.RequireRoles(models.RoleModerator, models.RoleAdmin))
+	{
+		moderation.GET("/api/moderation/queue", moderationController.GetQueue)
+		moderation.POST("/api/moderation/queue/:id/claim", moderationController.ClaimProduct)
+		moderation.DELETE("/api/moderation/queue/:id/claim", moderationController.ReleaseClaim)
+		moderation.POST("/products/:id/approve", productController.ApproveProduct)
+		moderation.POST("/products/:id/reject", productController.RejectProduct)
+		moderation.GET("/api/moderation/products/:id/duplicates", moderationController.GetDuplicateImages)
+		moderation.POST("/api/moderation/products/:id/auto-moderation", moderationController.RunAutoModeration)
+		moderation.POST("/api/moderation/bulk", moderationController.BulkModerate)
+	}
+
+	// Администрирование
+	admin := router.Group("/")
+	admin.Use(middlewares.AuthMiddleware(jwtService), middlewares.RequireRoles(models.RoleAdmin))
+	{
+		admin.GET("/api/moderation/rules", moderationController.GetAutoModerationRules)
+		admin.PUT("/api/moderation/rules/:code", moderationController.UpdateAutoModerationRule)
+		admin.POST("/api/moderation/rejection-reasons", rejectionReasonController.CreateRejectionReason)
+		admin.PUT("/api/moderation/rejection-reasons/:id", rejectionReasonController.UpdateRejectionReason)
+		admin.GET("/api/moderation/reports/rejection-reasons", rejectionReasonController.GetRejectionReasonStats)
+
+		// Дерево категорий
+		admin.POST("/api/admin/categories", categoryController.CreateCategory)
+		admin.PUT("/api/admin/categories/:id", categoryController.RenameCategory)
+		admin.POST("/api/admin/categories/:id/move", categoryController.MoveCategory)
+		admin.DELETE("/api/admin/categories/:id", categoryController.DeleteCategory)
+		admin.POST("/api/admin/categories/:id/hidden-attributes", categoryController.HideInheritedAttribute)
+		admin.DELETE("/api/admin/categories/:id/hidden-attributes/:name", categoryController.UnhideInheritedAttribute)
+
+		// Флаги и смена типа атрибутов
+		admin.PUT("/api/admin/attributes/:id/flags", attributeController.UpdateAttributeFlags)
+		admin.POST("/api/admin/attributes/:id/conversions", attributeController.StartAttributeConversion)
+		admin.GET("/api/admin/attribute-conversions/:id", attributeController.GetAttributeConversion)
+
+		// Справочник значений атрибутов
+		admin.GET("/api/admin/attributes/:id/values", attributeController.GetAttributeValues)
+		admin.GET("/api/admin/attributes/:id/values/duplicates", attributeController.GetDuplicateAttributeValues)
+		admin.POST("/api/admin/attributes/:id/values", attributeController.CreateAttributeValue)
+		admin.POST("/api/admin/attributes/:id/values/merge", attributeController.MergeAttributeValues)
+		admin.PUT("/api/admin/attributes/:id/values/:value_id", attributeController.UpdateAttributeValue)
+		admin.DELETE("/api/admin/attributes/:id/values/:value_id", attributeController.DeleteAttributeValue)
+
+		// Палитра цветов
+		admin.POST("/api/admin/colors", colorController.CreateColor)
+		admin.PUT("/api/admin/colors/:id", colorController.UpdateColor)
+		admin.DELETE("/api/admin/colors/:id", colorController.DeleteColor)
+
+		// Справочник рынков
+		admin.POST("/api/admin/markets", marketController.CreateMarket)
+		admin.PUT("/api/admin/markets/:id", marketController.UpdateMarket)
+		admin.DELETE("/api/admin/markets/:id", marketController.DeleteMarket)
+		admin.POST("/api/admin/markets/:id/rows", marketController.CreateMarketRow)
+		admin.PUT("/api/admin/market-rows/:id", marketController.UpdateMarketRow)
+		admin.DELETE("/api/admin/market-rows/:id", marketController.DeleteMarketRow)
+		admin.POST("/api/admin/market-rows/:id/stalls", marketController.AddMarketStalls)
+		admin.PUT("/api/admin/market-stalls/:id", marketController.RenameMarketStall)
+		admin.DELETE("/api/admin/market-stalls/:id", marketController.DeleteMarketStall)
+
+		// Проверка поставщиков по документам
+		admin.GET("/api/admin/supplier-verifications", supplierController.GetVerificationQueue)
+		admin.GET("/api/admin/suppliers/:id/verification", supplierController.GetSupplierVerification)
+		admin.POST("/api/admin/suppliers/:id/verification/approve", supplierController.ApproveVerification)
+		admin.POST("/api/admin/suppliers/:id/verification/reject", supplierController.RejectVerification)
+
+		// Переводы каталога
+		admin.GET("/api/admin/translations/missing", translationController.GetMissingTranslations)
+		admin.GET("/api/admin/translations/:entity/:id", translationController.GetTranslations)
+		admin.PUT("/api/admin/translations/:entity/:id/:lang", translationController.SaveTranslation)
+		admin.DELETE("/api/admin/translations/:entity/:id/:lang", translationController.DeleteTranslation)
+
+		// Перенос таксономии между окружениями
+		admin.GET("/api/admin/taxonomy", taxonomyController.ExportTaxonomy)
+		admin.POST("/api/admin/taxonomy/import", taxonomyController.ImportTaxonomy)
 	}
 
 	// Маршруты для получения рынков и категорий
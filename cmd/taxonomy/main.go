@@ -0,0 +1,140 @@
+// cmd/taxonomy/main.go
+
+// Команда taxonomy выгружает и загружает дерево категорий с атрибутами:
+//
+//	taxonomy [-config путь] export [-format yaml|json] [-o файл]
+//	taxonomy [-config путь] import -f файл [-format yaml|json] [-dry-run] [-prune]
+//
+// Формат файла при импорте по умолчанию определяется по расширению.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/WhyDias/Marketplace/internal/db"
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/WhyDias/Marketplace/internal/services"
+)
+
+func main() {
+	configPath := flag.String("config", "/app/configs/config.yaml", "Путь к конфигурации")
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	if err := db.InitDB(*configPath); err != nil {
+		log.Fatalf("Не удалось подключиться к базе данных: %v", err)
+	}
+
+	service := services.NewTaxonomyService()
+	var err error
+	switch flag.Arg(0) {
+	case "export":
+		err = runExport(service, flag.Args()[1:])
+	case "import":
+		err = runImport(service, flag.Args()[1:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Использование:")
+	fmt.Fprintln(os.Stderr, "  taxonomy [-config путь] export [-format yaml|json] [-o файл]")
+	fmt.Fprintln(os.Stderr, "  taxonomy [-config путь] import -f файл [-format yaml|json] [-dry-run] [-prune]")
+}
+
+func runExport(service *services.TaxonomyService, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", models.TaxonomyFormatYAML, "Формат: yaml или json")
+	output := fs.String("o", "", "Файл для выгрузки, по умолчанию stdout")
+	fs.Parse(args)
+
+	doc, err := service.Export()
+	if err != nil {
+		return err
+	}
+	data, err := services.MarshalTaxonomy(doc, *format)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(*output, data, 0o644); err != nil {
+		return err
+	}
+	log.Printf("Выгружено категорий: %d в %s", len(doc.Categories), *output)
+	return nil
+}
+
+func runImport(service *services.TaxonomyService, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	file := fs.String("f", "", "Файл таксономии")
+	format := fs.String("format", "", "Формат: yaml или json, по умолчанию по расширению файла")
+	dryRun := fs.Bool("dry-run", false, "Только показать разницу")
+	prune := fs.Bool("prune", false, "Удалять то, чего нет в документе")
+	fs.Parse(args)
+
+	if *file == "" {
+		return errors.New("не указан файл таксономии (-f)")
+	}
+	if *format == "" {
+		*format = models.TaxonomyFormatYAML
+		if strings.EqualFold(filepath.Ext(*file), ".json") {
+			*format = models.TaxonomyFormatJSON
+		}
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+	doc, err := services.ParseTaxonomy(data, *format)
+	if err != nil {
+		return err
+	}
+
+	// Изменения из командной строки не привязываются к пользователю
+	diff, err := service.Import(0, doc, *dryRun, *prune)
+	if diff != nil {
+		printDiff(diff)
+	}
+	return err
+}
+
+func printDiff(diff *models.TaxonomyDiff) {
+	for _, change := range diff.Changes {
+		line := fmt.Sprintf("%-6s %-16s %s", change.Action, change.Kind, change.Path)
+		if change.Name != "" {
+			line += " / " + change.Name
+		}
+		if change.Details != "" {
+			line += ": " + change.Details
+		}
+		fmt.Println(line)
+	}
+	for _, conflict := range diff.Conflicts {
+		fmt.Println("конфликт:", conflict)
+	}
+
+	state := "не применено"
+	if diff.Applied {
+		state = "применено"
+	}
+	fmt.Printf("Создано: %d, изменено: %d, удалено: %d (%s)\n", diff.Created, diff.Updated, diff.Deleted, state)
+}
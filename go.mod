@@ -14,6 +14,7 @@ require (
 	github.com/swaggo/gin-swagger v1.6.0
 	github.com/swaggo/swag v1.16.3
 	golang.org/x/crypto v0.27.0
+	golang.org/x/image v0.20.0
 	golang.org/x/time v0.6.0
 	gopkg.in/yaml.v2 v2.4.0
 )
@@ -3,11 +3,14 @@
 package controllers
 
 import (
+	"errors"
+	"log"
 	"net/http"
 	"strconv"
 
 	"github.com/WhyDias/Marketplace/internal/models"
 	"github.com/WhyDias/Marketplace/internal/services"
+	"github.com/WhyDias/Marketplace/internal/utils"
 	"github.com/gin-gonic/gin"
 )
 
@@ -66,3 +69,169 @@ func (ac *AttributeController) GetAttributeValueImage(c *gin.Context) {
 
 	c.JSON(http.StatusOK, image)
 }
+
+// writeAttributeError переводит ошибку сервиса атрибутов в HTTP-ответ
+func writeAttributeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrAttributeNotFound), errors.Is(err, services.ErrConversionJobNotFound),
+		errors.Is(err, services.ErrAttributeValueNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrConversionInProgress), errors.Is(err, services.ErrAttributeValueExists),
+		errors.Is(err, services.ErrAttributeValueInUse):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrInvalidAttributeConversion), errors.Is(err, services.ErrInvalidAttributeValue),
+		errors.Is(err, services.ErrInvalidAttributeUnit):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+}
+
+// UpdateAttributeFlags меняет флаги атрибута
+// @Summary Флаги атрибута
+// @Description is_required — атрибут обязателен для продуктов категории (linked — для каждой вариации), sort_order — порядок вывода, is_filterable — атрибут участвует в фильтрах каталога. Не указанные поля не меняются.
+// @Tags Атрибуты
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID атрибута"
+// @Param flags body models.UpdateAttributeFlagsRequest true "Флаги"
+// @Success 200 {object} models.CategoryAttribute
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/attributes/{id}/flags [put]
+func (ac *AttributeController) UpdateAttributeFlags(c *gin.Context) {
+	attributeID, ok := getIntParam(c, "id", "Некорректный ID атрибута")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateAttributeFlagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Некорректный формат данных"})
+		return
+	}
+
+	attribute, err := ac.AttributeService.UpdateAttributeFlags(attributeID, req)
+	if err != nil {
+		log.Printf("UpdateAttributeFlags: ошибка при обновлении флагов атрибута %d: %v", attributeID, err)
+		writeAttributeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, attribute)
+}
+
+// GetAttributeVersions возвращает историю определения атрибута
+// @Summary Версии атрибута
+// @Description Все версии определения атрибута (тип, опции, описание), начиная с последней, с описанием изменений
+// @Tags Атрибуты
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID атрибута"
+// @Success 200 {array} models.AttributeVersion
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/attributes/{id}/versions [get]
+func (ac *AttributeController) GetAttributeVersions(c *gin.Context) {
+	attributeID, ok := getIntParam(c, "id", "Некорректный ID атрибута")
+	if !ok {
+		return
+	}
+
+	versions, err := ac.AttributeService.GetAttributeVersions(attributeID)
+	if err != nil {
+		log.Printf("GetAttributeVersions: ошибка при получении версий атрибута %d: %v", attributeID, err)
+		writeAttributeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, versions)
+}
+
+// StartAttributeConversion запускает смену типа атрибута с конвертацией значений
+// @Summary Конвертация типа атрибута
+// @Description Создаёт задание, которое в фоне конвертирует все сохранённые значения атрибута в новый тип и меняет тип. mapping заменяет значения до конвертации. Если хотя бы одно значение не конвертируется, задание завершается ошибкой и ничего не меняет.
+// @Tags Атрибуты
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID атрибута"
+// @Param conversion body models.AttributeConversionRequest true "Новый тип"
+// @Success 202 {object} models.AttributeConversionJob
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Конвертация уже выполняется"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/attributes/{id}/conversions [post]
+func (ac *AttributeController) StartAttributeConversion(c *gin.Context) {
+	attributeID, ok := getIntParam(c, "id", "Некорректный ID атрибута")
+	if !ok {
+		return
+	}
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.AttributeConversionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Некорректный формат данных"})
+		return
+	}
+
+	job, err := ac.AttributeService.StartConversion(attributeID, userID, req)
+	if err != nil {
+		log.Printf("StartAttributeConversion: ошибка при запуске конвертации атрибута %d: %v", attributeID, err)
+		writeAttributeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetAttributeConversion возвращает состояние задания конвертации
+// @Summary Состояние конвертации атрибута
+// @Tags Атрибуты
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID задания"
+// @Success 200 {object} models.AttributeConversionJob
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/attribute-conversions/{id} [get]
+func (ac *AttributeController) GetAttributeConversion(c *gin.Context) {
+	jobID, ok := getIntParam(c, "id", "Некорректный ID задания")
+	if !ok {
+		return
+	}
+
+	job, err := ac.AttributeService.GetConversionJob(jobID)
+	if err != nil {
+		writeAttributeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// GetUnits возвращает поддерживаемые единицы измерения числовых атрибутов
+// @Summary Единицы измерения
+// @Description Величины и их единицы, которые можно указать в dimension и unit атрибута numeric или range
+// @Tags Атрибуты
+// @Produce json
+// @Success 200 {object} map[string][]utils.Unit
+// @Router /api/attributes/units [get]
+func (ac *AttributeController) GetUnits(c *gin.Context) {
+	c.JSON(http.StatusOK, utils.UnitsByDimension())
+}
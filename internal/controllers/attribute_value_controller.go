@@ -0,0 +1,227 @@
+// internal/controllers/attribute_value_controller.go
+
+package controllers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// GetAttributeValues возвращает справочник значений атрибута
+// @Summary Значения атрибута
+// @Description Все значения атрибута в порядке вывода (sort_order, затем ID) с изображениями, образцом цвета и числом продуктов, у которых значение выбрано
+// @Tags Справочник значений
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID атрибута"
+// @Success 200 {array} models.AttributeValueEntry
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/attributes/{id}/values [get]
+func (ac *AttributeController) GetAttributeValues(c *gin.Context) {
+	attributeID, ok := getIntParam(c, "id", "Некорректный ID атрибута")
+	if !ok {
+		return
+	}
+
+	values, err := ac.AttributeService.GetAttributeValues(attributeID)
+	if err != nil {
+		log.Printf("GetAttributeValues: ошибка при получении значений атрибута %d: %v", attributeID, err)
+		writeAttributeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, values)
+}
+
+// GetDuplicateAttributeValues возвращает повторяющиеся значения атрибута
+// @Summary Дубликаты значений атрибута
+// @Description Группы значений, совпадающих без учёта регистра и лишних пробелов ("Красный", "красный ", "КРАСНЫЙ"). Группы объединяются через merge.
+// @Tags Справочник значений
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID атрибута"
+// @Success 200 {array} models.AttributeValueDuplicates
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/attributes/{id}/values/duplicates [get]
+func (ac *AttributeController) GetDuplicateAttributeValues(c *gin.Context) {
+	attributeID, ok := getIntParam(c, "id", "Некорректный ID атрибута")
+	if !ok {
+		return
+	}
+
+	duplicates, err := ac.AttributeService.GetDuplicateAttributeValues(attributeID)
+	if err != nil {
+		log.Printf("GetDuplicateAttributeValues: ошибка при поиске дубликатов атрибута %d: %v", attributeID, err)
+		writeAttributeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, duplicates)
+}
+
+// CreateAttributeValue добавляет значение в справочник атрибута
+// @Summary Создание значения атрибута
+// @Description Значение проверяется по типу атрибута: строка для dropdown и text, число для numeric и range (с unit приводится к единице атрибута), true/false для switcher. swatch_hex принимается в виде #rrggbb или #rgb.
+// @Tags Справочник значений
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID атрибута"
+// @Param value body models.AttributeValueEntryRequest true "Значение"
+// @Success 201 {object} models.AttributeValueEntry
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Такое значение уже есть"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/attributes/{id}/values [post]
+func (ac *AttributeController) CreateAttributeValue(c *gin.Context) {
+	attributeID, ok := getIntParam(c, "id", "Некорректный ID атрибута")
+	if !ok {
+		return
+	}
+
+	var req models.AttributeValueEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Value == nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Некорректный формат данных"})
+		return
+	}
+
+	value, err := ac.AttributeService.CreateAttributeValue(attributeID, req)
+	if err != nil {
+		log.Printf("CreateAttributeValue: ошибка при создании значения атрибута %d: %v", attributeID, err)
+		writeAttributeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, value)
+}
+
+// UpdateAttributeValue меняет значение справочника
+// @Summary Изменение значения атрибута
+// @Description Не указанные поля не меняются. Продукты и вариации остаются связаны со значением, переводы значения переносятся на новый текст. Пустой swatch_hex убирает образец цвета, пустой image_urls — изображения.
+// @Tags Справочник значений
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID атрибута"
+// @Param value_id path int true "ID значения"
+// @Param value body models.AttributeValueEntryRequest true "Изменения"
+// @Success 200 {object} models.AttributeValueEntry
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Такое значение уже есть"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/attributes/{id}/values/{value_id} [put]
+func (ac *AttributeController) UpdateAttributeValue(c *gin.Context) {
+	attributeID, ok := getIntParam(c, "id", "Некорректный ID атрибута")
+	if !ok {
+		return
+	}
+	valueID, ok := getIntParam(c, "value_id", "Некорректный ID значения атрибута")
+	if !ok {
+		return
+	}
+
+	var req models.AttributeValueEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Некорректный формат данных"})
+		return
+	}
+
+	value, err := ac.AttributeService.UpdateAttributeValue(attributeID, valueID, req)
+	if err != nil {
+		log.Printf("UpdateAttributeValue: ошибка при изменении значения %d атрибута %d: %v", valueID, attributeID, err)
+		writeAttributeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, value)
+}
+
+// DeleteAttributeValue удаляет значение справочника
+// @Summary Удаление значения атрибута
+// @Description Удаляет значение вместе с изображениями. Значение, выбранное у продуктов, удалить нельзя — его нужно объединить с другим.
+// @Tags Справочник значений
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID атрибута"
+// @Param value_id path int true "ID значения"
+// @Success 200 {object} GoodResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Значение выбрано у продуктов"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/attributes/{id}/values/{value_id} [delete]
+func (ac *AttributeController) DeleteAttributeValue(c *gin.Context) {
+	attributeID, ok := getIntParam(c, "id", "Некорректный ID атрибута")
+	if !ok {
+		return
+	}
+	valueID, ok := getIntParam(c, "value_id", "Некорректный ID значения атрибута")
+	if !ok {
+		return
+	}
+
+	if err := ac.AttributeService.DeleteAttributeValue(attributeID, valueID); err != nil {
+		log.Printf("DeleteAttributeValue: ошибка при удалении значения %d атрибута %d: %v", valueID, attributeID, err)
+		writeAttributeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Значение атрибута удалено"})
+}
+
+// MergeAttributeValues объединяет значения атрибута
+// @Summary Объединение значений атрибута
+// @Description Все продукты и вариации со значениями source_ids переходят к значению target_id, значения source_ids удаляются. Изображения source_ids переносятся, если у target_id их нет. Выполняется в одной транзакции.
+// @Tags Справочник значений
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID атрибута"
+// @Param merge body models.MergeAttributeValuesRequest true "Объединяемые значения"
+// @Success 200 {object} models.AttributeValueEntry
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/attributes/{id}/values/merge [post]
+func (ac *AttributeController) MergeAttributeValues(c *gin.Context) {
+	attributeID, ok := getIntParam(c, "id", "Некорректный ID атрибута")
+	if !ok {
+		return
+	}
+
+	var req models.MergeAttributeValuesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Некорректный формат данных"})
+		return
+	}
+
+	value, err := ac.AttributeService.MergeAttributeValues(attributeID, req)
+	if err != nil {
+		log.Printf("MergeAttributeValues: ошибка при объединении значений атрибута %d: %v", attributeID, err)
+		writeAttributeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, value)
+}
@@ -4,6 +4,7 @@ package controllers
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/WhyDias/Marketplace/internal/models"
 	"github.com/WhyDias/Marketplace/internal/services"
 	"github.com/WhyDias/Marketplace/internal/utils"
@@ -14,12 +15,14 @@ import (
 )
 
 type CategoryController struct {
-	Service *services.CategoryService
+	Service      *services.CategoryService
+	Translations *services.TranslationService
 }
 
-func NewCategoryController(service *services.CategoryService) *CategoryController {
+func NewCategoryController(service *services.CategoryService, translations *services.TranslationService) *CategoryController {
 	return &CategoryController{
-		Service: service,
+		Service:      service,
+		Translations: translations,
 	}
 }
 
@@ -38,6 +41,7 @@ type Category struct {
 // @Accept json
 // @Produce json
 // @Param path query string true "Category path"
+// @Param lang query string false "Язык: ru, ky или en, по умолчанию из Accept-Language"
 // @Success 200 {array} Category
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
@@ -54,6 +58,7 @@ func (cc *CategoryController) GetImmediateSubcategories(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Не удалось получить подкатегории"})
 		return
 	}
+	cc.Translations.LocalizeCategories(requestLanguage(c), categories)
 
 	c.JSON(http.StatusOK, categories)
 }
@@ -72,6 +77,7 @@ type CategoryNode struct {
 // @Tags Categories
 // @Accept json
 // @Produce json
+// @Param lang query string false "Язык: ru, ky или en, по умолчанию из Accept-Language"
 // @Success 200 {array} CategoryNode
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /api/categories [get]
@@ -81,6 +87,7 @@ func (cc *CategoryController) GetAllCategories(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{Error: "Не удалось получить категории"})
 		return
 	}
+	cc.Translations.LocalizeCategoryTree(requestLanguage(c), categories)
 
 	c.JSON(http.StatusOK, categories)
 }
@@ -144,13 +151,14 @@ func (cc *CategoryController) DeleteCategoryAttributes(c *gin.Context) {
 // AddCategoryAttributes добавляет или обновляет атрибуты для категории
 // @Summary Добавление или обновление атрибутов категории
 // @Tags Category
-// @Description Добавляет или обновляет атрибуты для указанной категории, проверяет уникальность по имени
+// @Description Добавляет или обновляет атрибуты для указанной категории, проверяет уникальность по имени. Изменение атрибута сохраняется как новая версия. Переименование или удаление опции dropdown, выбранной у продуктов, требует value_migrations ("старая опция" -> "новая опция"), иначе возвращается 409 со списком затронутых продуктов. Смена типа атрибута, значения которого сохранены у продуктов, выполняется только заданием конвертации. Атрибуты numeric и range могут объявить величину (dimension) и каноническую единицу (unit); при смене единицы той же величины сохранённые значения пересчитываются.
 // @Accept json
 // @Produce json
 // @Param AddCategoryAttributesRequest body models.AddCategoryAttributesRequest true "Запрос на добавление атрибутов категории"
 // @Success 201 {object} utils.ErrorResponse "Атрибуты успешно добавлены"
 // @Failure 400 {object} utils.ErrorResponse "Неверный формат данных"
 // @Failure 401 {object} utils.ErrorResponse "Необходима авторизация"
+// @Failure 409 {object} utils.ErrorResponse "Опции или значения атрибута используются продуктами"
 // @Failure 500 {object} utils.ErrorResponse "Ошибка сервера"
 // @Router /category/attributes [post]
 // @Security ApiKeyAuth
@@ -177,7 +185,17 @@ func (cc *CategoryController) AddCategoryAttributes(c *gin.Context) {
 
 	err := cc.Service.AddCategoryAttributes(userID, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{Error: "Не удалось добавить атрибуты: " + err.Error()})
+		var inUse *services.AttributeOptionsInUseError
+		switch {
+		case errors.As(err, &inUse):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "attribute": inUse.Attribute, "options": inUse.Usage})
+		case errors.Is(err, services.ErrAttributeTypeChangeInUse), errors.Is(err, services.ErrAttributeUnitChangeInUse):
+			c.JSON(http.StatusConflict, utils.ErrorResponse{Error: err.Error()})
+		case errors.Is(err, services.ErrInvalidValueMigration), errors.Is(err, services.ErrInvalidAttributeUnit):
+			c.JSON(http.StatusBadRequest, utils.ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, utils.ErrorResponse{Error: "Не удалось добавить атрибуты: " + err.Error()})
+		}
 		return
 	}
 
@@ -191,11 +209,12 @@ type CategoryAttribute struct {
 
 // GetCategoryAttributes
 // @Summary Get category attributes
-// @Description Получает список атрибутов, связанных с категорией по её ID
+// @Description Получает список атрибутов категории по её ID, включая унаследованные от категорий-предков. Унаследованные атрибуты отмечены inherited и source_category_id.
 // @Tags Categories
 // @Accept json
 // @Produce json
 // @Param id path int true "Category ID"
+// @Param lang query string false "Язык: ru, ky или en, по умолчанию из Accept-Language"
 // @Success 200 {array} CategoryAttribute
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
@@ -213,6 +232,7 @@ func (cc *CategoryController) GetCategoryAttributes(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{Error: "Не удалось получить атрибуты категории"})
 		return
 	}
+	cc.Translations.LocalizeCategoryAttributes(requestLanguage(c), attributes)
 
 	c.JSON(http.StatusOK, attributes)
 }
@@ -224,6 +244,7 @@ func (cc *CategoryController) GetCategoryAttributes(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "Category ID"
+// @Param lang query string false "Язык: ru, ky или en, по умолчанию из Accept-Language"
 // @Success 200 {object} Category
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
@@ -241,6 +262,11 @@ func (cc *CategoryController) GetCategoryByID(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{Error: "Не удалось получить категорию"})
 		return
 	}
+	if category != nil {
+		localized := []models.Category{*category}
+		cc.Translations.LocalizeCategories(requestLanguage(c), localized)
+		category = &localized[0]
+	}
 
 	c.JSON(http.StatusOK, category)
 }
@@ -252,6 +278,7 @@ func (cc *CategoryController) GetCategoryByID(c *gin.Context) {
 // @Accept  json
 // @Produce  json
 // @Param id path int true "ID категории"
+// @Param lang query string false "Язык: ru, ky или en, по умолчанию из Accept-Language"
 // @Success 200 {array} models.CategoryAttributeResponse
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
@@ -286,6 +313,7 @@ func (cc *CategoryController) GetCategoryAttributesByCategoryID(c *gin.Context)
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{Error: "Не удалось получить атрибуты: " + err.Error()})
 		return
 	}
+	cc.Translations.LocalizeAttributeResponses(requestLanguage(c), attributes)
 
 	c.JSON(http.StatusOK, attributes)
 }
@@ -296,6 +324,7 @@ func (cc *CategoryController) GetCategoryAttributesByCategoryID(c *gin.Context)
 // @Tags Categories
 // @Accept json
 // @Produce json
+// @Param lang query string false "Язык: ru, ky или en, по умолчанию из Accept-Language"
 // @Success 200 {array} Category
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /api/categories/root [get]
@@ -305,6 +334,7 @@ func (ctrl *CategoryController) GetRootCategories(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	ctrl.Translations.LocalizeCategories(requestLanguage(c), categories)
 	c.JSON(http.StatusOK, categories)
 }
 
@@ -315,6 +345,7 @@ func (ctrl *CategoryController) GetRootCategories(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param path path string true "Путь категории (например, 'root/electronics/phones')"
+// @Param lang query string false "Язык: ru, ky или en, по умолчанию из Accept-Language"
 // @Success 200 {object} []models.CategoryAttributeResponse
 // @Failure 400 {object} map[string]string "error"
 // @Failure 404 {object} map[string]string "error"
@@ -338,15 +369,17 @@ func (cc *CategoryController) GetCategoryAttributesByPath(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить атрибуты категории"})
 		return
 	}
+	cc.Translations.LocalizeCategoryAttributes(requestLanguage(c), attributes)
 	c.JSON(http.StatusOK, attributes)
 }
 
 // GetAttributesByCategoryAndIsLinked Получить атрибуты по категории и is_linked
 // @Summary Получить атрибуты по категории и is_linked
-// @Description Возвращает список атрибутов для указанной категории с учетом is_linked
+// @Description Возвращает список атрибутов для указанной категории с учетом is_linked, включая унаследованные от категорий-предков
 // @Tags Атрибуты
 // @Param category_id query int true "ID категории"
 // @Param is_linked query bool true "Флаг is_linked"
+// @Param lang query string false "Язык: ru, ky или en, по умолчанию из Accept-Language"
 // @Success 200 {array} models.Attribute
 // @Failure 400 {object} utils.ErrorResponse "Некорректные параметры"
 // @Failure 500 {object} utils.ErrorResponse "Ошибка сервера"
@@ -371,6 +404,271 @@ func (cc *CategoryController) GetAttributesByCategoryAndIsLinked(c *gin.Context)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить атрибуты"})
 		return
 	}
+	cc.Translations.LocalizeAttributes(requestLanguage(c), attributes)
 	// Отправляем результат
 	c.JSON(http.StatusOK, attributes)
 }
+
+// writeCategoryError переводит ошибку управления деревом категорий в HTTP-ответ
+func writeCategoryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrCategoryNotFound), errors.Is(err, services.ErrAttributeNotHidden):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrCategoryPathExists):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrInvalidCategoryLabel), errors.Is(err, services.ErrInvalidCategoryMove),
+		errors.Is(err, services.ErrInvalidCategoryTarget), errors.Is(err, services.ErrCategoryTargetNotFound),
+		errors.Is(err, services.ErrAttributeNotInherited):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+}
+
+// CreateCategory создаёт категорию в дереве
+// @Summary Создание категории
+// @Description Создаёт категорию под указанным родителем (или корневую). Path строится из path родителя и метки, метка по умолчанию — транслитерация названия. parent_id заполняется автоматически.
+// @Tags Categories
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param category body models.CreateCategoryRequest true "Категория"
+// @Success 201 {object} models.Category
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse "Родитель не найден"
+// @Failure 409 {object} ErrorResponse "Path уже занят"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/categories [post]
+func (cc *CategoryController) CreateCategory(c *gin.Context) {
+	var req models.CreateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Некорректный формат данных"})
+		return
+	}
+
+	category, err := cc.Service.CreateCategory(req)
+	if err != nil {
+		log.Printf("CreateCategory: ошибка при создании категории %s: %v", req.Name, err)
+		writeCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, category)
+}
+
+// RenameCategory меняет название категории
+// @Summary Переименование категории
+// @Description Меняет название категории. Path и подкатегории не меняются, для смены метки используйте перенос.
+// @Tags Categories
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID категории"
+// @Param category body models.RenameCategoryRequest true "Новое название"
+// @Success 200 {object} models.Category
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/categories/{id} [put]
+func (cc *CategoryController) RenameCategory(c *gin.Context) {
+	categoryID, ok := getIntParam(c, "id", "Некорректный ID категории")
+	if !ok {
+		return
+	}
+
+	var req models.RenameCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Некорректный формат данных"})
+		return
+	}
+
+	category, err := cc.Service.RenameCategory(categoryID, req.Name)
+	if err != nil {
+		log.Printf("RenameCategory: ошибка при переименовании категории %d: %v", categoryID, err)
+		writeCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// MoveCategory переносит категорию с подкатегориями
+// @Summary Перенос категории
+// @Description Переносит категорию со всеми подкатегориями под другого родителя (или в корень) и при необходимости меняет её метку. Path всех подкатегорий переписывается в одной транзакции.
+// @Tags Categories
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID категории"
+// @Param move body models.MoveCategoryRequest true "Новый родитель"
+// @Success 200 {object} models.Category
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Path уже занят"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/categories/{id}/move [post]
+func (cc *CategoryController) MoveCategory(c *gin.Context) {
+	categoryID, ok := getIntParam(c, "id", "Некорректный ID категории")
+	if !ok {
+		return
+	}
+
+	var req models.MoveCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Некорректный формат данных"})
+		return
+	}
+
+	category, err := cc.Service.MoveCategory(categoryID, req)
+	if err != nil {
+		log.Printf("MoveCategory: ошибка при переносе категории %d: %v", categoryID, err)
+		writeCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// DeleteCategory удаляет категорию с подкатегориями
+// @Summary Удаление категории
+// @Description Удаляет категорию со всеми подкатегориями. Продукты и поставщики переносятся в целевую категорию, туда же переносятся атрибуты (одноимённые атрибуты объединяются).
+// @Tags Categories
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID категории"
+// @Param target_id query int true "ID категории, в которую переносятся продукты и поставщики"
+// @Success 200 {object} models.CategoryDeleteResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/categories/{id} [delete]
+func (cc *CategoryController) DeleteCategory(c *gin.Context) {
+	categoryID, ok := getIntParam(c, "id", "Некорректный ID категории")
+	if !ok {
+		return
+	}
+
+	targetID, ok := getIntQuery(c, "target_id", "Некорректный target_id")
+	if !ok {
+		return
+	}
+	if targetID == 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Параметр target_id обязателен"})
+		return
+	}
+
+	result, err := cc.Service.DeleteCategory(categoryID, targetID)
+	if err != nil {
+		log.Printf("DeleteCategory: ошибка при удалении категории %d: %v", categoryID, err)
+		writeCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// HideInheritedAttribute скрывает унаследованный атрибут
+// @Summary Скрытие унаследованного атрибута
+// @Description Убирает атрибут, унаследованный от категории-предка, у категории и всех её подкатегорий. Подкатегория может вернуть атрибут, объявив свой с тем же названием.
+// @Tags Categories
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID категории"
+// @Param attribute body models.HiddenAttributeRequest true "Название атрибута"
+// @Success 200 {object} GoodResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/categories/{id}/hidden-attributes [post]
+func (cc *CategoryController) HideInheritedAttribute(c *gin.Context) {
+	categoryID, ok := getIntParam(c, "id", "Некорректный ID категории")
+	if !ok {
+		return
+	}
+
+	var req models.HiddenAttributeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Некорректный формат данных"})
+		return
+	}
+
+	if err := cc.Service.HideInheritedAttribute(categoryID, req.Name); err != nil {
+		log.Printf("HideInheritedAttribute: ошибка при скрытии атрибута %s категории %d: %v", req.Name, categoryID, err)
+		writeCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Атрибут скрыт"})
+}
+
+// UnhideInheritedAttribute возвращает скрытый унаследованный атрибут
+// @Summary Отмена скрытия атрибута
+// @Tags Categories
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID категории"
+// @Param name path string true "Название атрибута"
+// @Success 200 {object} GoodResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/categories/{id}/hidden-attributes/{name} [delete]
+func (cc *CategoryController) UnhideInheritedAttribute(c *gin.Context) {
+	categoryID, ok := getIntParam(c, "id", "Некорректный ID категории")
+	if !ok {
+		return
+	}
+
+	if err := cc.Service.UnhideInheritedAttribute(categoryID, c.Param("name")); err != nil {
+		writeCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Атрибут снова наследуется"})
+}
+
+// GetCategoryBySlug возвращает категорию по URL
+// @Summary Категория по slug
+// @Description URL категории строится из path: метки через "/", "_" заменяется на "-" (odezhda/muzhskaya-odezhda). Если URL принадлежал категории до переноса или удаления, отвечает 301 на текущий URL.
+// @Tags Categories
+// @Produce json
+// @Param slug path string true "URL категории"
+// @Param lang query string false "Язык: ru, ky или en, по умолчанию из Accept-Language"
+// @Success 200 {object} models.Category
+// @Success 301 "Переадресация на текущий URL"
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/categories/by-slug/{slug} [get]
+func (cc *CategoryController) GetCategoryBySlug(c *gin.Context) {
+	category, redirect, err := cc.Service.GetCategoryBySlug(c.Param("slug"))
+	if errors.Is(err, services.ErrCategoryNotFound) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		return
+	} else if err != nil {
+		log.Printf("GetCategoryBySlug: ошибка при получении категории %s: %v", c.Param("slug"), err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Не удалось получить категорию"})
+		return
+	}
+	if redirect != "" {
+		redirectPermanently(c, "/api/categories/by-slug/"+redirect)
+		return
+	}
+
+	localized := []models.Category{*category}
+	cc.Translations.LocalizeCategories(requestLanguage(c), localized)
+
+	c.JSON(http.StatusOK, localized[0])
+}
@@ -0,0 +1,202 @@
+// internal/controllers/color_controller.go
+
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/WhyDias/Marketplace/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// ColorController палитра цветов вариаций и фасет цветов каталога
+type ColorController struct {
+	Service *services.ColorService
+}
+
+func NewColorController(service *services.ColorService) *ColorController {
+	return &ColorController{Service: service}
+}
+
+// writeColorError переводит ошибку палитры в HTTP-ответ
+func writeColorError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrColorNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrColorExists), errors.Is(err, services.ErrColorInUse):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrInvalidColor):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+}
+
+// GetColors возвращает палитру цветов
+// @Summary Палитра цветов
+// @Description Цвета, из которых поставщики выбирают цвета вариаций, с кодом #rrggbb и семейством
+// @Tags Цвета
+// @Produce json
+// @Param family query string false "Код семейства цветов"
+// @Success 200 {array} models.PaletteColor
+// @Failure 500 {object} ErrorResponse
+// @Router /api/colors [get]
+func (cc *ColorController) GetColors(c *gin.Context) {
+	colors, err := cc.Service.GetColors(c.Query("family"))
+	if err != nil {
+		log.Printf("GetColors: ошибка при получении палитры: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Не удалось получить палитру цветов"})
+		return
+	}
+
+	c.JSON(http.StatusOK, colors)
+}
+
+// GetColorFamilies возвращает семейства цветов
+// @Summary Семейства цветов
+// @Description Семейства цветов для фильтров каталога с образцом цвета
+// @Tags Цвета
+// @Produce json
+// @Success 200 {array} models.ColorFamily
+// @Failure 500 {object} ErrorResponse
+// @Router /api/colors/families [get]
+func (cc *ColorController) GetColorFamilies(c *gin.Context) {
+	families, err := cc.Service.GetColorFamilies()
+	if err != nil {
+		log.Printf("GetColorFamilies: ошибка при получении семейств цветов: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Не удалось получить семейства цветов"})
+		return
+	}
+
+	c.JSON(http.StatusOK, families)
+}
+
+// GetColorFacets возвращает фасет цветов каталога
+// @Summary Фасет цветов
+// @Description Семейства цветов и цвета одобренных продуктов с числом продуктов. С category_id учитываются категория и её подкатегории.
+// @Tags Цвета
+// @Produce json
+// @Param category_id query int false "ID категории"
+// @Success 200 {array} models.ColorFacet
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/catalog/colors [get]
+func (cc *ColorController) GetColorFacets(c *gin.Context) {
+	categoryID, ok := getIntQuery(c, "category_id", "Некорректный ID категории")
+	if !ok {
+		return
+	}
+
+	facets, err := cc.Service.GetColorFacets(categoryID)
+	if err != nil {
+		log.Printf("GetColorFacets: ошибка при получении фасета цветов категории %d: %v", categoryID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Не удалось получить фасет цветов"})
+		return
+	}
+
+	c.JSON(http.StatusOK, facets)
+}
+
+// CreateColor добавляет цвет в палитру
+// @Summary Создание цвета
+// @Description Название обязательно и уникально без учёта регистра. Код принимается в виде #rrggbb или #rgb, семейство — код из /api/colors/families.
+// @Tags Цвета
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param color body models.PaletteColorRequest true "Цвет"
+// @Success 201 {object} models.PaletteColor
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Цвет с таким названием уже есть"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/colors [post]
+func (cc *ColorController) CreateColor(c *gin.Context) {
+	var req models.PaletteColorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Некорректный формат данных"})
+		return
+	}
+
+	color, err := cc.Service.CreateColor(req)
+	if err != nil {
+		log.Printf("CreateColor: ошибка при создании цвета: %v", err)
+		writeColorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, color)
+}
+
+// UpdateColor меняет цвет палитры
+// @Summary Изменение цвета
+// @Description Не указанные поля не меняются. Пустой code или family убирает код или семейство. Вариации остаются связаны с цветом.
+// @Tags Цвета
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID цвета"
+// @Param color body models.PaletteColorRequest true "Изменения"
+// @Success 200 {object} models.PaletteColor
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Цвет с таким названием уже есть"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/colors/{id} [put]
+func (cc *ColorController) UpdateColor(c *gin.Context) {
+	colorID, ok := getIntParam(c, "id", "Некорректный ID цвета")
+	if !ok {
+		return
+	}
+
+	var req models.PaletteColorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Некорректный формат данных"})
+		return
+	}
+
+	color, err := cc.Service.UpdateColor(colorID, req)
+	if err != nil {
+		log.Printf("UpdateColor: ошибка при изменении цвета %d: %v", colorID, err)
+		writeColorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, color)
+}
+
+// DeleteColor удаляет цвет палитры
+// @Summary Удаление цвета
+// @Description Цвет, выбранный у вариаций, удалить нельзя
+// @Tags Цвета
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID цвета"
+// @Success 200 {object} GoodResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Цвет выбран у вариаций"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/colors/{id} [delete]
+func (cc *ColorController) DeleteColor(c *gin.Context) {
+	colorID, ok := getIntParam(c, "id", "Некорректный ID цвета")
+	if !ok {
+		return
+	}
+
+	if err := cc.Service.DeleteColor(colorID); err != nil {
+		log.Printf("DeleteColor: ошибка при удалении цвета %d: %v", colorID, err)
+		writeColorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Цвет удалён"})
+}
@@ -0,0 +1,364 @@
+// internal/controllers/image_controller.go
+
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/WhyDias/Marketplace/internal/services"
+	"github.com/WhyDias/Marketplace/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ImageController управляет изображениями продуктов и вариаций
+type ImageController struct {
+	Service *services.ImageService
+}
+
+func NewImageController(service *services.ImageService) *ImageController {
+	return &ImageController{
+		Service: service,
+	}
+}
+
+// getUserID извлекает user_id, установленный AuthMiddleware
+func getUserID(c *gin.Context) (int, bool) {
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Пользователь не авторизован"})
+		return 0, false
+	}
+	userID, ok := userIDInterface.(int)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Некорректный идентификатор пользователя"})
+		return 0, false
+	}
+	return userID, true
+}
+
+// getIntParam разбирает числовой параметр пути
+func getIntParam(c *gin.Context, name string, errorMessage string) (int, bool) {
+	value, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: errorMessage})
+		return 0, false
+	}
+	return value, true
+}
+
+// writeImageError переводит ошибку сервиса в HTTP-ответ
+func writeImageError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrImageOwnerNotFound), errors.Is(err, services.ErrImageNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrImageForbidden):
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+	case isImageValidationError(err), errors.Is(err, services.ErrInvalidImageOrder):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+}
+
+// isImageValidationError сообщает, что файл отклонён при проверке изображения
+func isImageValidationError(err error) bool {
+	return errors.Is(err, utils.ErrImageTooLarge) ||
+		errors.Is(err, utils.ErrImageTypeNotAllowed) ||
+		errors.Is(err, utils.ErrImageDimensions)
+}
+
+func (ic *ImageController) getImages(c *gin.Context, owner models.ImageOwner) {
+	ownerID, ok := getIntParam(c, "id", "Некорректный ID")
+	if !ok {
+		return
+	}
+
+	images, err := ic.Service.GetImages(owner, ownerID)
+	if err != nil {
+		log.Printf("getImages: ошибка при получении изображений %s %d: %v", owner, ownerID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Не удалось получить изображения"})
+		return
+	}
+
+	c.JSON(http.StatusOK, images)
+}
+
+func (ic *ImageController) addImages(c *gin.Context, owner models.ImageOwner) {
+	ownerID, ok := getIntParam(c, "id", "Некорректный ID")
+	if !ok {
+		return
+	}
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Не удалось получить данные формы"})
+		return
+	}
+	files := form.File["images"]
+	if len(files) == 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Необходимо предоставить хотя бы одно изображение"})
+		return
+	}
+
+	images, err := ic.Service.AddImages(owner, ownerID, userID, files, c.PostForm("alt_text"))
+	if err != nil {
+		log.Printf("addImages: ошибка при добавлении изображений %s %d: %v", owner, ownerID, err)
+		writeImageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, images)
+}
+
+func (ic *ImageController) deleteImage(c *gin.Context, owner models.ImageOwner) {
+	ownerID, ok := getIntParam(c, "id", "Некорректный ID")
+	if !ok {
+		return
+	}
+	imageID, ok := getIntParam(c, "image_id", "Некорректный ID изображения")
+	if !ok {
+		return
+	}
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := ic.Service.DeleteImage(owner, ownerID, userID, imageID); err != nil {
+		log.Printf("deleteImage: ошибка при удалении изображения %d у %s %d: %v", imageID, owner, ownerID, err)
+		writeImageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Изображение удалено"})
+}
+
+func (ic *ImageController) reorderImages(c *gin.Context, owner models.ImageOwner) {
+	ownerID, ok := getIntParam(c, "id", "Некорректный ID")
+	if !ok {
+		return
+	}
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ReorderImagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Некорректный формат данных: " + err.Error()})
+		return
+	}
+
+	if err := ic.Service.ReorderImages(owner, ownerID, userID, req.ImageIDs); err != nil {
+		log.Printf("reorderImages: ошибка при изменении порядка изображений %s %d: %v", owner, ownerID, err)
+		writeImageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Порядок изображений обновлен"})
+}
+
+func (ic *ImageController) setPrimaryImage(c *gin.Context, owner models.ImageOwner) {
+	ownerID, ok := getIntParam(c, "id", "Некорректный ID")
+	if !ok {
+		return
+	}
+	imageID, ok := getIntParam(c, "image_id", "Некорректный ID изображения")
+	if !ok {
+		return
+	}
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := ic.Service.SetPrimaryImage(owner, ownerID, userID, imageID); err != nil {
+		log.Printf("setPrimaryImage: ошибка при назначении главного изображения %d у %s %d: %v", imageID, owner, ownerID, err)
+		writeImageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Главное изображение обновлено"})
+}
+
+// GetProductImages возвращает изображения продукта
+// @Summary Изображения продукта
+// @Description Возвращает изображения продукта в порядке отображения
+// @Tags Изображения
+// @Produce json
+// @Param id path int true "ID продукта"
+// @Success 200 {array} models.Image
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/products/{id}/images [get]
+func (ic *ImageController) GetProductImages(c *gin.Context) {
+	ic.getImages(c, models.ImageOwnerProduct)
+}
+
+// AddProductImages добавляет изображения продукта
+// @Summary Добавить изображения продукта
+// @Description Загружает изображения и добавляет их в конец списка. Первое изображение становится главным.
+// @Description Допустимы JPEG, PNG и WebP до 10 МБ; EXIF удаляется, сохраняются миниатюры 200, 600 и 1200 px.
+// @Tags Изображения
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "ID продукта"
+// @Param images formData file true "Изображения" multiple=true
+// @Param alt_text formData string false "Альтернативный текст"
+// @Success 201 {array} models.Image
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/products/{id}/images [post]
+func (ic *ImageController) AddProductImages(c *gin.Context) {
+	ic.addImages(c, models.ImageOwnerProduct)
+}
+
+// ReorderProductImages задаёт порядок изображений продукта
+// @Summary Изменить порядок изображений продукта
+// @Tags Изображения
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID продукта"
+// @Param input body models.ReorderImagesRequest true "Все ID изображений в новом порядке"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/products/{id}/images [put]
+func (ic *ImageController) ReorderProductImages(c *gin.Context) {
+	ic.reorderImages(c, models.ImageOwnerProduct)
+}
+
+// DeleteProductImage удаляет одно изображение продукта
+// @Summary Удалить изображение продукта
+// @Tags Изображения
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID продукта"
+// @Param image_id path int true "ID изображения"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/products/{id}/images/{image_id} [delete]
+func (ic *ImageController) DeleteProductImage(c *gin.Context) {
+	ic.deleteImage(c, models.ImageOwnerProduct)
+}
+
+// SetPrimaryProductImage назначает главное изображение продукта
+// @Summary Назначить главное изображение продукта
+// @Tags Изображения
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID продукта"
+// @Param image_id path int true "ID изображения"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/products/{id}/images/{image_id}/primary [put]
+func (ic *ImageController) SetPrimaryProductImage(c *gin.Context) {
+	ic.setPrimaryImage(c, models.ImageOwnerProduct)
+}
+
+// GetVariationImages возвращает изображения вариации
+// @Summary Изображения вариации
+// @Description Возвращает изображения вариации в порядке отображения
+// @Tags Изображения
+// @Produce json
+// @Param id path int true "ID вариации"
+// @Success 200 {array} models.Image
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/variations/{id}/images [get]
+func (ic *ImageController) GetVariationImages(c *gin.Context) {
+	ic.getImages(c, models.ImageOwnerVariation)
+}
+
+// AddVariationImages добавляет изображения вариации
+// @Summary Добавить изображения вариации
+// @Description Загружает изображения и добавляет их в конец списка. Первое изображение становится главным.
+// @Description Допустимы JPEG, PNG и WebP до 10 МБ; EXIF удаляется, сохраняются миниатюры 200, 600 и 1200 px.
+// @Tags Изображения
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "ID вариации"
+// @Param images formData file true "Изображения" multiple=true
+// @Param alt_text formData string false "Альтернативный текст"
+// @Success 201 {array} models.Image
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/variations/{id}/images [post]
+func (ic *ImageController) AddVariationImages(c *gin.Context) {
+	ic.addImages(c, models.ImageOwnerVariation)
+}
+
+// ReorderVariationImages задаёт порядок изображений вариации
+// @Summary Изменить порядок изображений вариации
+// @Tags Изображения
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID вариации"
+// @Param input body models.ReorderImagesRequest true "Все ID изображений в новом порядке"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/variations/{id}/images [put]
+func (ic *ImageController) ReorderVariationImages(c *gin.Context) {
+	ic.reorderImages(c, models.ImageOwnerVariation)
+}
+
+// DeleteVariationImage удаляет одно изображение вариации
+// @Summary Удалить изображение вариации
+// @Tags Изображения
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID вариации"
+// @Param image_id path int true "ID изображения"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/variations/{id}/images/{image_id} [delete]
+func (ic *ImageController) DeleteVariationImage(c *gin.Context) {
+	ic.deleteImage(c, models.ImageOwnerVariation)
+}
+
+// SetPrimaryVariationImage назначает главное изображение вариации
+// @Summary Назначить главное изображение вариации
+// @Tags Изображения
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID вариации"
+// @Param image_id path int true "ID изображения"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/variations/{id}/images/{image_id}/primary [put]
+func (ic *ImageController) SetPrimaryVariationImage(c *gin.Context) {
+	ic.setPrimaryImage(c, models.ImageOwnerVariation)
+}
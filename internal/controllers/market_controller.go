@@ -0,0 +1,469 @@
+// internal/controllers/market_controller.go
+
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/WhyDias/Marketplace/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// MarketController справочник рынков с рядами и местами
+type MarketController struct {
+	Service *services.MarketService
+}
+
+func NewMarketController(service *services.MarketService) *MarketController {
+	return &MarketController{Service: service}
+}
+
+// writeMarketError переводит ошибку справочника рынков в HTTP-ответ
+func writeMarketError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrMarketNotFound), errors.Is(err, services.ErrMarketRowNotFound),
+		errors.Is(err, services.ErrStallNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrMarketExists), errors.Is(err, services.ErrMarketInUse),
+		errors.Is(err, services.ErrMarketRowExists), errors.Is(err, services.ErrMarketRowInUse),
+		errors.Is(err, services.ErrStallExists), errors.Is(err, services.ErrStallTaken):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrInvalidMarket), errors.Is(err, services.ErrInvalidLocation):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+}
+
+// getFloatQuery разбирает дробный параметр запроса, 0 если он не указан
+func getFloatQuery(c *gin.Context, name string, errorMessage string) (float64, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return 0, true
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: errorMessage})
+		return 0, false
+	}
+	return parsed, true
+}
+
+// GetNearbyMarkets возвращает рынки рядом с покупателем
+// @Summary Рынки рядом
+// @Description Рынки в радиусе от точки, ближайшие первыми, с расстоянием в километрах. С category_id остаются рынки, где есть одобренные продукты категории и её подкатегорий.
+// @Tags Рынки
+// @Produce json
+// @Param lat query number true "Широта"
+// @Param lng query number true "Долгота"
+// @Param radius query number false "Радиус в км (по умолчанию 10, максимум 200)"
+// @Param category_id query int false "ID категории"
+// @Success 200 {array} models.NearbyMarket
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/markets/nearby [get]
+func (mc *MarketController) GetNearbyMarkets(c *gin.Context) {
+	if c.Query("lat") == "" || c.Query("lng") == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Укажите lat и lng"})
+		return
+	}
+	var near models.GeoFilter
+	var ok bool
+	if near.Lat, ok = getFloatQuery(c, "lat", "Некорректная широта"); !ok {
+		return
+	}
+	if near.Lng, ok = getFloatQuery(c, "lng", "Некорректная долгота"); !ok {
+		return
+	}
+	if near.RadiusKm, ok = getFloatQuery(c, "radius", "Некорректный радиус"); !ok {
+		return
+	}
+	categoryID, ok := getIntQuery(c, "category_id", "Некорректный ID категории")
+	if !ok {
+		return
+	}
+
+	markets, err := mc.Service.GetNearbyMarkets(near, categoryID)
+	if err != nil {
+		log.Printf("GetNearbyMarkets: ошибка при поиске рынков рядом с %g,%g: %v", near.Lat, near.Lng, err)
+		writeMarketError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, markets)
+}
+
+// GetMarket возвращает рынок с рядами и местами
+// @Summary Рынок с рядами и местами
+// @Description Адрес, координаты, часы работы, выходные дни и ряды рынка. У занятого места указан поставщик.
+// @Tags Рынки
+// @Produce json
+// @Param id path int true "ID рынка"
+// @Success 200 {object} models.MarketDetails
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/markets/{id} [get]
+func (mc *MarketController) GetMarket(c *gin.Context) {
+	marketID, ok := getIntParam(c, "id", "Некорректный ID рынка")
+	if !ok {
+		return
+	}
+
+	market, err := mc.Service.GetMarket(marketID)
+	if err != nil {
+		log.Printf("GetMarket: ошибка при получении рынка %d: %v", marketID, err)
+		writeMarketError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, market)
+}
+
+// GetRowSuppliers возвращает поставщиков ряда
+// @Summary Поставщики ряда
+// @Description Поставщики, занявшие места в ряду рынка, по названию места
+// @Tags Рынки
+// @Produce json
+// @Param id path int true "ID ряда"
+// @Success 200 {array} models.RowSupplier
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/market-rows/{id}/suppliers [get]
+func (mc *MarketController) GetRowSuppliers(c *gin.Context) {
+	rowID, ok := getIntParam(c, "id", "Некорректный ID ряда")
+	if !ok {
+		return
+	}
+
+	suppliers, err := mc.Service.GetRowSuppliers(rowID)
+	if err != nil {
+		log.Printf("GetRowSuppliers: ошибка при получении поставщиков ряда %d: %v", rowID, err)
+		writeMarketError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, suppliers)
+}
+
+// CreateMarket добавляет рынок
+// @Summary Создание рынка
+// @Description Название обязательно и уникально без учёта регистра. Время работы в виде ЧЧ:ММ, выходные — дни недели ISO от 1 (понедельник) до 7.
+// @Tags Рынки
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param market body models.MarketRequest true "Рынок"
+// @Success 201 {object} models.Market
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Рынок с таким названием уже есть"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/markets [post]
+func (mc *MarketController) CreateMarket(c *gin.Context) {
+	var req models.MarketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Некорректный формат данных"})
+		return
+	}
+
+	market, err := mc.Service.CreateMarket(req)
+	if err != nil {
+		log.Printf("CreateMarket: ошибка при создании рынка: %v", err)
+		writeMarketError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, market)
+}
+
+// UpdateMarket меняет рынок
+// @Summary Изменение рынка
+// @Description Не указанные поля не меняются. Пустой address, opens_at или closes_at убирает значение, clear_location убирает координаты.
+// @Tags Рынки
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID рынка"
+// @Param market body models.MarketRequest true "Изменения"
+// @Success 200 {object} models.Market
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Рынок с таким названием уже есть"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/markets/{id} [put]
+func (mc *MarketController) UpdateMarket(c *gin.Context) {
+	marketID, ok := getIntParam(c, "id", "Некорректный ID рынка")
+	if !ok {
+		return
+	}
+
+	var req models.MarketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Некорректный формат данных"})
+		return
+	}
+
+	market, err := mc.Service.UpdateMarket(marketID, req)
+	if err != nil {
+		log.Printf("UpdateMarket: ошибка при изменении рынка %d: %v", marketID, err)
+		writeMarketError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, market)
+}
+
+// DeleteMarket удаляет рынок
+// @Summary Удаление рынка
+// @Description Рынок удаляется вместе с рядами и местами. Рынок с поставщиками или продуктами удалить нельзя.
+// @Tags Рынки
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID рынка"
+// @Success 200 {object} GoodResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "На рынке есть поставщики или продукты"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/markets/{id} [delete]
+func (mc *MarketController) DeleteMarket(c *gin.Context) {
+	marketID, ok := getIntParam(c, "id", "Некорректный ID рынка")
+	if !ok {
+		return
+	}
+
+	if err := mc.Service.DeleteMarket(marketID); err != nil {
+		log.Printf("DeleteMarket: ошибка при удалении рынка %d: %v", marketID, err)
+		writeMarketError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Рынок удалён"})
+}
+
+// CreateMarketRow добавляет ряд на рынок
+// @Summary Создание ряда
+// @Description Название ряда уникально в пределах рынка
+// @Tags Рынки
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID рынка"
+// @Param row body models.MarketRowRequest true "Ряд"
+// @Success 201 {object} models.MarketRow
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Ряд с таким названием уже есть"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/markets/{id}/rows [post]
+func (mc *MarketController) CreateMarketRow(c *gin.Context) {
+	marketID, ok := getIntParam(c, "id", "Некорректный ID рынка")
+	if !ok {
+		return
+	}
+
+	var req models.MarketRowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Некорректный формат данных"})
+		return
+	}
+
+	row, err := mc.Service.CreateRow(marketID, req)
+	if err != nil {
+		log.Printf("CreateMarketRow: ошибка при создании ряда рынка %d: %v", marketID, err)
+		writeMarketError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, row)
+}
+
+// UpdateMarketRow меняет ряд
+// @Summary Изменение ряда
+// @Description Новое название ряда копируется поставщикам, занявшим места в ряду
+// @Tags Рынки
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID ряда"
+// @Param row body models.MarketRowRequest true "Ряд"
+// @Success 200 {object} models.MarketRow
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Ряд с таким названием уже есть"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/market-rows/{id} [put]
+func (mc *MarketController) UpdateMarketRow(c *gin.Context) {
+	rowID, ok := getIntParam(c, "id", "Некорректный ID ряда")
+	if !ok {
+		return
+	}
+
+	var req models.MarketRowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Некорректный формат данных"})
+		return
+	}
+
+	row, err := mc.Service.UpdateRow(rowID, req)
+	if err != nil {
+		log.Printf("UpdateMarketRow: ошибка при изменении ряда %d: %v", rowID, err)
+		writeMarketError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, row)
+}
+
+// DeleteMarketRow удаляет ряд
+// @Summary Удаление ряда
+// @Description Ряд удаляется вместе с местами. Ряд с занятыми местами удалить нельзя.
+// @Tags Рынки
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID ряда"
+// @Success 200 {object} GoodResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "В ряду есть занятые места"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/market-rows/{id} [delete]
+func (mc *MarketController) DeleteMarketRow(c *gin.Context) {
+	rowID, ok := getIntParam(c, "id", "Некорректный ID ряда")
+	if !ok {
+		return
+	}
+
+	if err := mc.Service.DeleteRow(rowID); err != nil {
+		log.Printf("DeleteMarketRow: ошибка при удалении ряда %d: %v", rowID, err)
+		writeMarketError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Ряд удалён"})
+}
+
+// AddMarketStalls добавляет места в ряд
+// @Summary Добавление мест
+// @Description Названия мест уникальны в пределах ряда. Если хотя бы одно название занято, места не добавляются.
+// @Tags Рынки
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID ряда"
+// @Param stalls body models.MarketStallsRequest true "Названия мест"
+// @Success 201 {array} models.MarketStall
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Место с таким названием уже есть"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/market-rows/{id}/stalls [post]
+func (mc *MarketController) AddMarketStalls(c *gin.Context) {
+	rowID, ok := getIntParam(c, "id", "Некорректный ID ряда")
+	if !ok {
+		return
+	}
+
+	var req models.MarketStallsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Некорректный формат данных"})
+		return
+	}
+
+	stalls, err := mc.Service.AddStalls(rowID, req)
+	if err != nil {
+		log.Printf("AddMarketStalls: ошибка при добавлении мест в ряд %d: %v", rowID, err)
+		writeMarketError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, stalls)
+}
+
+// RenameMarketStall переименовывает место
+// @Summary Переименование места
+// @Description Новое название места копируется поставщику, занявшему его
+// @Tags Рынки
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID места"
+// @Param stall body models.MarketStallRequest true "Место"
+// @Success 200 {object} models.MarketStall
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Место с таким названием уже есть"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/market-stalls/{id} [put]
+func (mc *MarketController) RenameMarketStall(c *gin.Context) {
+	stallID, ok := getIntParam(c, "id", "Некорректный ID места")
+	if !ok {
+		return
+	}
+
+	var req models.MarketStallRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Некорректный формат данных"})
+		return
+	}
+
+	stall, err := mc.Service.RenameStall(stallID, req)
+	if err != nil {
+		log.Printf("RenameMarketStall: ошибка при переименовании места %d: %v", stallID, err)
+		writeMarketError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stall)
+}
+
+// DeleteMarketStall удаляет место
+// @Summary Удаление места
+// @Description Занятое поставщиком место удалить нельзя
+// @Tags Рынки
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID места"
+// @Success 200 {object} GoodResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Место занято поставщиком"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/market-stalls/{id} [delete]
+func (mc *MarketController) DeleteMarketStall(c *gin.Context) {
+	stallID, ok := getIntParam(c, "id", "Некорректный ID места")
+	if !ok {
+		return
+	}
+
+	if err := mc.Service.DeleteStall(stallID); err != nil {
+		log.Printf("DeleteMarketStall: ошибка при удалении места %d: %v", stallID, err)
+		writeMarketError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Место удалено"})
+}
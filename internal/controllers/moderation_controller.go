@@ -0,0 +1,372 @@
+// internal/controllers/moderation_controller.go
+
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/WhyDias/Marketplace/internal/middlewares"
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/WhyDias/Marketplace/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// ModerationController обрабатывает запросы модераторов
+type ModerationController struct {
+	Service               *services.ModerationService
+	AutoModerationService *services.AutoModerationService
+}
+
+func NewModerationController(service *services.ModerationService, autoModerationService *services.AutoModerationService) *ModerationController {
+	return &ModerationController{
+		Service:               service,
+		AutoModerationService: autoModerationService,
+	}
+}
+
+// getIntQuery разбирает необязательный числовой параметр запроса, 0 если он не указан
+func getIntQuery(c *gin.Context, name string, errorMessage string) (int, bool) {
+	valueStr := c.Query(name)
+	if valueStr == "" {
+		return 0, true
+	}
+	value, err := strconv.Atoi(valueStr)
+	if err != nil || value < 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: errorMessage})
+		return 0, false
+	}
+	return value, true
+}
+
+// writeModerationError переводит ошибку сервиса модерации в HTTP-ответ
+func writeModerationError(c *gin.Context, err error) {
+	c.JSON(moderationErrorStatus(err), ErrorResponse{Error: err.Error()})
+}
+
+// moderationErrorStatus возвращает HTTP-код для ошибки сервиса модерации
+func moderationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrModerationProductNotFound), errors.Is(err, services.ErrModerationClaimNotFound),
+		errors.Is(err, services.ErrModerationRuleNotFound), errors.Is(err, services.ErrRejectionReasonNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrProductClaimed), errors.Is(err, services.ErrProductNotPending),
+		errors.Is(err, services.ErrRejectionReasonCodeExists), errors.Is(err, services.ErrSupplierNotVerified):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrInvalidModerationRule), errors.Is(err, services.ErrInvalidRejectionReason),
+		errors.Is(err, services.ErrBulkModerationTooLarge), errors.Is(err, services.ErrRejectionCommentRequired):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrModerationForbidden):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GetQueue возвращает очередь модерации по всем поставщикам
+// @Summary Очередь модерации
+// @Description Продукты всех поставщиков, ожидающие модерации (status_id = 2), начиная с самых старых. Для каждого продукта указано время в очереди, нарушение SLA и захват модератором.
+// @Tags Модерация
+// @Security BearerAuth
+// @Produce json
+// @Param market_id query int false "ID рынка"
+// @Param category_id query int false "ID категории (включая подкатегории)"
+// @Param limit query int false "Количество записей (по умолчанию 50, максимум 200)"
+// @Param offset query int false "Смещение"
+// @Success 200 {object} models.ModerationQueue
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/moderation/queue [get]
+func (mc *ModerationController) GetQueue(c *gin.Context) {
+	var filter models.ModerationQueueFilter
+	var ok bool
+	if filter.MarketID, ok = getIntQuery(c, "market_id", "Некорректный market_id"); !ok {
+		return
+	}
+	if filter.CategoryID, ok = getIntQuery(c, "category_id", "Некорректный category_id"); !ok {
+		return
+	}
+	if filter.Limit, ok = getIntQuery(c, "limit", "Некорректный limit"); !ok {
+		return
+	}
+	if filter.Offset, ok = getIntQuery(c, "offset", "Некорректный offset"); !ok {
+		return
+	}
+
+	queue, err := mc.Service.GetQueue(filter)
+	if err != nil {
+		log.Printf("GetQueue: ошибка при получении очереди модерации: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Не удалось получить очередь модерации"})
+		return
+	}
+
+	c.JSON(http.StatusOK, queue)
+}
+
+// ClaimProduct захватывает продукт для проверки
+// @Summary Захват продукта модератором
+// @Description Закрепляет продукт за модератором на 30 минут, чтобы его не проверяли одновременно. Повторный вызов продлевает захват.
+// @Tags Модерация
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID продукта"
+// @Success 200 {object} models.ModerationClaim
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Продукт уже проверяет другой модератор"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/moderation/queue/{id}/claim [post]
+func (mc *ModerationController) ClaimProduct(c *gin.Context) {
+	productID, ok := getIntParam(c, "id", "Некорректный ID продукта")
+	if !ok {
+		return
+	}
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	claim, err := mc.Service.ClaimProduct(productID, userID)
+	if err != nil {
+		log.Printf("ClaimProduct: ошибка при захвате продукта %d пользователем %d: %v", productID, userID, err)
+		writeModerationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, claim)
+}
+
+// ReleaseClaim снимает захват продукта
+// @Summary Снятие захвата продукта
+// @Description Возвращает продукт в общую очередь до истечения срока захвата
+// @Tags Модерация
+// @Security BearerAuth
+// @Param id path int true "ID продукта"
+// @Success 200 {object} GoodResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/moderation/queue/{id}/claim [delete]
+func (mc *ModerationController) ReleaseClaim(c *gin.Context) {
+	productID, ok := getIntParam(c, "id", "Некорректный ID продукта")
+	if !ok {
+		return
+	}
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := mc.Service.ReleaseClaim(productID, userID); err != nil {
+		log.Printf("ReleaseClaim: ошибка при снятии захвата продукта %d пользователем %d: %v", productID, userID, err)
+		writeModerationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Захват снят"})
+}
+
+// GetProductHistory возвращает хронологию модерации продукта
+// @Summary История модерации продукта
+// @Description Смены статусов и комментарии модераторов в порядке времени. Поставщику доступны только его продукты, модераторы в его истории показываются без ID и имени пользователя.
+// @Tags Модерация
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID продукта"
+// @Success 200 {array} models.ModerationHistoryEntry
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/products/{id}/moderation-history [get]
+func (mc *ModerationController) GetProductHistory(c *gin.Context) {
+	productID, ok := getIntParam(c, "id", "Некорректный ID продукта")
+	if !ok {
+		return
+	}
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	isModerator := middlewares.HasRole(c, models.RoleModerator, models.RoleAdmin)
+	history, err := mc.Service.GetProductHistory(productID, userID, isModerator)
+	if err != nil {
+		log.Printf("GetProductHistory: ошибка при получении истории продукта %d: %v", productID, err)
+		writeModerationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// GetDuplicateImages возвращает совпадения изображений продукта с продуктами других поставщиков
+// @Summary Дубликаты изображений продукта
+// @Description Изображения продукта, перцептивный хэш которых совпадает с изображениями продуктов других поставщиков, со ссылками на найденные продукты
+// @Tags Модерация
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID продукта"
+// @Success 200 {array} models.DuplicateImageMatch
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/moderation/products/{id}/duplicates [get]
+func (mc *ModerationController) GetDuplicateImages(c *gin.Context) {
+	productID, ok := getIntParam(c, "id", "Некорректный ID продукта")
+	if !ok {
+		return
+	}
+
+	matches, err := mc.Service.GetDuplicateImages(productID)
+	if err != nil {
+		log.Printf("GetDuplicateImages: ошибка при получении дубликатов для product_id %d: %v", productID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Не удалось получить дубликаты изображений"})
+		return
+	}
+
+	c.JSON(http.StatusOK, matches)
+}
+
+// GetAutoModerationRules возвращает правила автомодерации
+// @Summary Правила автомодерации
+// @Description Настраиваемые правила, которыми проверяется продукт при поступлении на модерацию. Правило auto_approve включает автоматическое подтверждение продуктов без нарушений.
+// @Tags Модерация
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.ModerationRule
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/moderation/rules [get]
+func (mc *ModerationController) GetAutoModerationRules(c *gin.Context) {
+	rules, err := mc.AutoModerationService.GetRules()
+	if err != nil {
+		log.Printf("GetAutoModerationRules: ошибка при получении правил: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Не удалось получить правила автомодерации"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// UpdateAutoModerationRule изменяет правило автомодерации
+// @Summary Изменение правила автомодерации
+// @Description Включает или выключает правило, меняет действие (reject или review), вес и параметры. Незаданные поля не меняются.
+// @Tags Модерация
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param code path string true "Код правила"
+// @Param rule body models.UpdateModerationRuleRequest true "Новые настройки"
+// @Success 200 {object} models.ModerationRule
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/moderation/rules/{code} [put]
+func (mc *ModerationController) UpdateAutoModerationRule(c *gin.Context) {
+	var req models.UpdateModerationRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Некорректный формат данных"})
+		return
+	}
+
+	rule, err := mc.AutoModerationService.UpdateRule(c.Param("code"), req)
+	if err != nil {
+		log.Printf("UpdateAutoModerationRule: ошибка при обновлении правила %s: %v", c.Param("code"), err)
+		writeModerationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// RunAutoModeration повторно проверяет продукт правилами автомодерации
+// @Summary Повторная автомодерация продукта
+// @Description Проверяет продукт, ожидающий модерации, текущими правилами и применяет решение
+// @Tags Модерация
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID продукта"
+// @Success 200 {object} models.AutoModerationResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/moderation/products/{id}/auto-moderation [post]
+func (mc *ModerationController) RunAutoModeration(c *gin.Context) {
+	productID, ok := getIntParam(c, "id", "Некорректный ID продукта")
+	if !ok {
+		return
+	}
+
+	result, err := mc.AutoModerationService.RunForProduct(productID)
+	if err != nil {
+		log.Printf("RunAutoModeration: ошибка автомодерации продукта %d: %v", productID, err)
+		writeModerationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// BulkModerate подтверждает или отклоняет несколько продуктов за один запрос
+// @Summary Массовая модерация
+// @Description Подтверждает (action=approve) или отклоняет (action=reject) до 100 продуктов. Каждый продукт обрабатывается в отдельной транзакции с теми же комментариями и записями истории, что и одиночные запросы. Общие content и reasons применяются к продуктам без собственных. Для каждого продукта возвращается результат и HTTP-код, который вернул бы одиночный запрос.
+// @Tags Модерация
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.BulkModerationRequest true "Продукты и решение"
+// @Success 200 {object} models.BulkModerationResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/moderation/bulk [post]
+func (mc *ModerationController) BulkModerate(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.BulkModerationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Некорректный формат данных"})
+		return
+	}
+
+	result, err := mc.Service.BulkModerate(userID, req)
+	if err != nil {
+		writeModerationError(c, err)
+		return
+	}
+
+	for i := range result.Results {
+		item := &result.Results[i]
+		if item.Success {
+			item.Status = http.StatusOK
+			continue
+		}
+		item.Status = moderationErrorStatus(item.Err)
+		if item.Status == http.StatusInternalServerError {
+			log.Printf("BulkModerate: ошибка при модерации продукта %d: %v", item.ProductID, item.Err)
+		}
+	}
+
+	c.JSON(http.StatusOK, result)
+}
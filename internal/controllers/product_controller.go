@@ -4,8 +4,10 @@ package controllers
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/WhyDias/Marketplace/internal/models"
 	"github.com/WhyDias/Marketplace/internal/services"
+	"github.com/WhyDias/Marketplace/internal/utils"
 	"github.com/gin-gonic/gin"
 	"log"
 	"net/http"
@@ -16,18 +18,20 @@ import (
 type ProductController struct {
 	Service         *services.ProductService
 	SupplierService *services.SupplierService
+	Translations    *services.TranslationService
 }
 
-func NewProductController(productService *services.ProductService, supplierService *services.SupplierService) *ProductController {
+func NewProductController(productService *services.ProductService, supplierService *services.SupplierService, translations *services.TranslationService) *ProductController {
 	return &ProductController{
 		Service:         productService,
 		SupplierService: supplierService,
+		Translations:    translations,
 	}
 }
 
 // GetModeratedProducts возвращает список продуктов со статусом модерации (status_id = 3).
 // @Summary      Получение продуктов с модерацией
-// @Description  Возвращает список продуктов, находящихся на модерации (status_id = 3).
+// @Description  Возвращает список продуктов, находящихся на модерации (status_id = 3), с причиной последнего отклонения в rejection_reason.
 // @Tags         Продукты
 // @Security     BearerAuth
 // @Produce      json
@@ -129,25 +133,6 @@ type AddProductRequest struct {
 	Stock       int                            `json:"stock" binding:"required"`
 }
 
-type ProductVariationRequest struct {
-	SKU        string                  `json:"sku" binding:"required"`
-	Price      float64                 `json:"price" binding:"required"`
-	Stock      int                     `json:"stock" binding:"required"`
-	Images     []string                `json:"images"`
-	Attributes []AttributeValueRequest `json:"attributes"`
-	Colors     []Color                 `json:"colors"` // Добавили поле Colors
-}
-
-type Color struct {
-	Name string `json:"name"`
-	Code string `json:"code"`
-}
-
-type AttributeValueRequest struct {
-	Name   string   `json:"name" binding:"required"`
-	Values []string `json:"values" binding:"required"`
-}
-
 // AddProduct обрабатывает запрос на добавление нового продукта
 // @Summary Добавить новый продукт
 // @Description Добавляет новый продукт вместе с его вариациями и изображениями вариаций
@@ -160,7 +145,7 @@ type AttributeValueRequest struct {
 // @Param price formData number true "Цена продукта"
 // @Param category_id formData int true "ID категории"
 // @Param attributes formData string true "JSON-строка с общими атрибутами продукта"
-// @Param variations formData string true "JSON-строка с вариациями продукта"
+// @Param variations formData string true "JSON-строка с вариациями продукта; colors — цвета из палитры по id, name или code"
 // @Param variation_images_{n} formData file false "Изображения для вариации n" multiple=true
 // @Success 200 {object} map[string]string "Продукт успешно добавлен"
 // @Failure 400 {object} map[string]string "Неверный формат данных или ошибки валидации"
@@ -220,6 +205,10 @@ func (pc *ProductController) AddProduct(c *gin.Context) {
 
 	// Вызов сервиса для добавления продукта
 	if err := pc.Service.AddProduct(&req, userID, attributes, variations); err != nil {
+		if errors.Is(err, services.ErrMissingRequiredAttributes) || errors.Is(err, services.ErrInvalidColor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -229,7 +218,7 @@ func (pc *ProductController) AddProduct(c *gin.Context) {
 
 // UpdateProduct обновляет существующий продукт
 // @Summary Обновить продукт
-// @Description Обновляет информацию о продукте, его вариациях и изображениях
+// @Description Обновляет информацию о продукте, его вариациях и изображениях. Отклонённый продукт после обновления снова поступает на модерацию.
 // @Tags Products
 // @Accept multipart/form-data
 // @Produce json
@@ -240,7 +229,7 @@ func (pc *ProductController) AddProduct(c *gin.Context) {
 // @Param price formData number false "Цена продукта"
 // @Param category_id formData int false "ID категории"
 // @Param attributes formData string false "JSON-строка с общими атрибутами продукта"
-// @Param variations formData string false "JSON-строка с вариациями продукта"
+// @Param variations formData string false "JSON-строка с вариациями продукта; colors заменяют цвета вариации, без colors цвета не меняются"
 // @Param variation_images_{n} formData file false "Изображения для вариации n" multiple=true
 // @Success 200 {object} map[string]string "Продукт успешно обновлен"
 // @Failure 400 {object} map[string]string "Неверный формат данных или ошибки валидации"
@@ -311,6 +300,10 @@ func (pc *ProductController) UpdateProduct(c *gin.Context) {
 
 	// Вызов сервиса для обновления продукта
 	if err := pc.Service.UpdateProduct(productID, &req, userID, attributes, variations); err != nil {
+		if errors.Is(err, services.ErrMissingRequiredAttributes) || errors.Is(err, services.ErrInvalidColor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -324,7 +317,7 @@ type GoodResponse struct {
 
 // ApproveProduct подтверждает продукт
 // @Summary Подтверждение продукта
-// @Description Устанавливает статус продукта на "Подтвержденный" и добавляет комментарий "Подтвержден"
+// @Description Устанавливает статус продукта на "Подтвержденный" и добавляет комментарий "Подтвержден". Пока включено правило unverified_supplier, продукты поставщиков без проверки документов не подтверждаются.
 // @Tags Модерация
 // @Security BearerAuth
 // @Param id path int true "ID продукта"
@@ -332,6 +325,8 @@ type GoodResponse struct {
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Продукт проверяет другой модератор, продукт не ожидает модерации или поставщик не прошёл проверку"
 // @Failure 500 {object} ErrorResponse
 // @Router /api/moderation/products/{id}/approve [post]
 func (pc *ProductController) ApproveProduct(c *gin.Context) {
@@ -353,16 +348,16 @@ func (pc *ProductController) ApproveProduct(c *gin.Context) {
 	// Вызываем сервис для подтверждения продукта
 	err = pc.Service.ApproveProduct(productID, userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		writeModerationError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Продукт подтвержден"})
 }
 
-// RejectProduct отклоняет продукт с комментарием
+// RejectProduct отклоняет продукт с комментарием и причинами
 // @Summary Отклонение продукта
-// @Description Устанавливает статус продукта на "Отклоненный" и добавляет комментарий модератора
+// @Description Устанавливает статус продукта на "Отклоненный", добавляет комментарий модератора и причины из справочника. Причина может указывать поле, вариацию или изображение. Нужен комментарий или хотя бы одна причина.
 // @Tags Модерация
 // @Security BearerAuth
 // @Param id path int true "ID продукта"
@@ -371,6 +366,8 @@ func (pc *ProductController) ApproveProduct(c *gin.Context) {
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Продукт проверяет другой модератор или не ожидает модерации"
 // @Failure 500 {object} ErrorResponse
 // @Router /api/moderation/products/{id}/reject [post]
 func (pc *ProductController) RejectProduct(c *gin.Context) {
@@ -388,9 +385,9 @@ func (pc *ProductController) RejectProduct(c *gin.Context) {
 		return
 	}
 
-	// Проверяем, что комментарий не пустой
-	if req.Content == "" {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Комментарий не может быть пустым"})
+	// Нужен комментарий или хотя бы одна причина
+	if req.Content == "" && len(req.Reasons) == 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Укажите комментарий или причину отклонения"})
 		return
 	}
 
@@ -403,11 +400,181 @@ func (pc *ProductController) RejectProduct(c *gin.Context) {
 	userID := userIDInterface.(int)
 
 	// Вызываем сервис для отклонения продукта
-	err = pc.Service.RejectProduct(productID, userID, req.Content)
+	err = pc.Service.RejectProduct(productID, userID, req.Content, req.Reasons)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		writeModerationError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Продукт отклонен"})
 }
+
+// GetProductAttributeValues возвращает значения атрибутов продукта для покупателя
+// @Summary Атрибуты продукта
+// @Description Значения атрибутов одобренного продукта и его вариаций. Числовые значения хранятся в канонической единице атрибута и пересчитываются в единицы из units, например units=kg,cm.
+// @Tags Продукты
+// @Produce json
+// @Param id path int true "ID продукта"
+// @Param units query string false "Предпочитаемые единицы через запятую"
+// @Param lang query string false "Язык: ru, ky или en, по умолчанию из Accept-Language"
+// @Success 200 {array} models.ProductAttributeValueView
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/products/{id}/attributes [get]
+func (pc *ProductController) GetProductAttributeValues(c *gin.Context) {
+	productID, ok := getIntParam(c, "id", "Некорректный ID продукта")
+	if !ok {
+		return
+	}
+
+	preferredUnits, err := services.ParsePreferredUnits(c.Query("units"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	values, err := pc.Service.GetProductAttributeValues(productID, preferredUnits)
+	if errors.Is(err, services.ErrProductNotFound) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		return
+	} else if err != nil {
+		log.Printf("GetProductAttributeValues: ошибка при получении атрибутов продукта %d: %v", productID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Не удалось получить атрибуты продукта"})
+		return
+	}
+	pc.Translations.LocalizeProductAttributeValues(requestLanguage(c), values)
+
+	c.JSON(http.StatusOK, values)
+}
+
+// GetProduct возвращает одобренный продукт для покупателя
+// @Summary Продукт
+// @Description Одобренный продукт с названием и описанием на языке из lang или Accept-Language, по умолчанию на русском, и цветами вариаций в colors
+// @Tags Продукты
+// @Produce json
+// @Param id path int true "ID продукта"
+// @Param lang query string false "Язык: ru, ky или en"
+// @Success 200 {object} models.Product
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/products/{id} [get]
+func (pc *ProductController) GetProduct(c *gin.Context) {
+	productID, ok := getIntParam(c, "id", "Некорректный ID продукта")
+	if !ok {
+		return
+	}
+
+	product, err := pc.Service.GetPublishedProduct(productID)
+	if errors.Is(err, services.ErrProductNotFound) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		return
+	} else if err != nil {
+		log.Printf("GetProduct: ошибка при получении продукта %d: %v", productID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Не удалось получить продукт"})
+		return
+	}
+	pc.recordProductView(productID)
+	pc.Translations.LocalizeProduct(requestLanguage(c), product)
+
+	c.JSON(http.StatusOK, product)
+}
+
+// GetProductBySlug возвращает одобренный продукт по slug
+// @Summary Продукт по slug
+// @Description Одобренный продукт по slug из названия. Если slug принадлежал продукту раньше, отвечает 301 на адрес с текущим slug.
+// @Tags Продукты
+// @Produce json
+// @Param slug path string true "Slug продукта"
+// @Param lang query string false "Язык: ru, ky или en"
+// @Success 200 {object} models.Product
+// @Success 301 "Переадресация на текущий slug"
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/products/by-slug/{slug} [get]
+func (pc *ProductController) GetProductBySlug(c *gin.Context) {
+	product, redirect, err := pc.Service.GetPublishedProductBySlug(c.Param("slug"))
+	if errors.Is(err, services.ErrProductNotFound) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		return
+	} else if err != nil {
+		log.Printf("GetProductBySlug: ошибка при получении продукта %s: %v", c.Param("slug"), err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Не удалось получить продукт"})
+		return
+	}
+	if redirect != "" {
+		redirectPermanently(c, "/api/products/by-slug/"+redirect)
+		return
+	}
+	pc.recordProductView(product.ID)
+	pc.Translations.LocalizeProduct(requestLanguage(c), product)
+
+	c.JSON(http.StatusOK, product)
+}
+
+// redirectPermanently отвечает 301, сохраняя параметры запроса
+func redirectPermanently(c *gin.Context, location string) {
+	if c.Request.URL.RawQuery != "" {
+		location += "?" + c.Request.URL.RawQuery
+	}
+	c.Redirect(http.StatusMovedPermanently, location)
+}
+
+// GetCatalogProducts возвращает страницу одобренных продуктов
+// @Summary Каталог продуктов
+// @Description Одобренные продукты, новые первыми. С near остаются продукты рынков в радиусе от точки, ближайшие первыми, с расстоянием до рынка в километрах.
+// @Tags Продукты
+// @Produce json
+// @Param category_id query int false "ID категории (включая подкатегории)"
+// @Param near query string false "Координаты покупателя: широта,долгота"
+// @Param radius query number false "Радиус в км (по умолчанию 10, максимум 200)"
+// @Param attr query []string false "Отбор по числовому атрибуту: <ID атрибута>:<от>..<до>, границы можно опустить или указать с единицей (12:1,5 кг..3 кг)" collectionFormat(multi)
+// @Param sort query string false "Сортировка по числовому атрибуту: attr:<ID> по возрастанию, -attr:<ID> по убыванию"
+// @Param limit query int false "Количество продуктов (по умолчанию 20, максимум 100)"
+// @Param offset query int false "Смещение"
+// @Param lang query string false "Язык: ru, ky или en"
+// @Success 200 {object} models.ProductCardPage
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/catalog/products [get]
+func (pc *ProductController) GetCatalogProducts(c *gin.Context) {
+	var filter models.CatalogFilter
+	var ok bool
+	if filter.CategoryID, ok = getIntQuery(c, "category_id", "Некорректный ID категории"); !ok {
+		return
+	}
+	if filter.Limit, ok = getIntQuery(c, "limit", "Некорректный limit"); !ok {
+		return
+	}
+	if filter.Offset, ok = getIntQuery(c, "offset", "Некорректный offset"); !ok {
+		return
+	}
+	if near := c.Query("near"); near != "" {
+		lat, lng, err := utils.ParseLatLng(near)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		filter.Near = &models.GeoFilter{Lat: lat, Lng: lng}
+		if filter.Near.RadiusKm, ok = getFloatQuery(c, "radius", "Некорректный радиус"); !ok {
+			return
+		}
+	}
+
+	filter.AttributeRanges = c.QueryArray("attr")
+	filter.Sort = c.Query("sort")
+
+	page, err := pc.Service.GetCatalog(filter)
+	if errors.Is(err, services.ErrInvalidLocation) || errors.Is(err, services.ErrInvalidCatalogFilter) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	} else if err != nil {
+		log.Printf("GetCatalogProducts: ошибка при получении каталога: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Не удалось получить каталог"})
+		return
+	}
+	pc.Translations.LocalizeProductCards(requestLanguage(c), page.Items)
+
+	c.JSON(http.StatusOK, page)
+}
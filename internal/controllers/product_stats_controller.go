@@ -0,0 +1,142 @@
+// internal/controllers/product_stats_controller.go
+
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/WhyDias/Marketplace/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// recordProductView сохраняет просмотр продукта, не прерывая ответ при ошибке.
+// Просмотры неопубликованных продуктов не учитываются.
+func (pc *ProductController) recordProductView(productID int) {
+	if err := pc.Service.RecordProductView(productID); err != nil && !errors.Is(err, services.ErrProductNotFound) {
+		log.Printf("recordProductView: ошибка при сохранении просмотра продукта %d: %v", productID, err)
+	}
+}
+
+// RecordContactClick сохраняет нажатие на контакт поставщика
+// @Summary Нажатие на контакт поставщика
+// @Description Учитывается в статистике продукта в кабинете поставщика. Частота нажатий с одного клиента ограничена.
+// @Tags Продукты
+// @Param id path int true "ID продукта"
+// @Success 204 "Нажатие сохранено"
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse "Слишком много нажатий"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/products/{id}/contact-click [post]
+func (pc *ProductController) RecordContactClick(c *gin.Context) {
+	productID, ok := getIntParam(c, "id", "Некорректный ID продукта")
+	if !ok {
+		return
+	}
+
+	err := pc.Service.RecordContactClick(productID)
+	if errors.Is(err, services.ErrProductNotFound) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		return
+	} else if err != nil {
+		log.Printf("RecordContactClick: ошибка при сохранении нажатия для продукта %d: %v", productID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Не удалось сохранить нажатие"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AddFavorite добавляет продукт в избранное
+// @Summary Добавить в избранное
+// @Description Повторное добавление ничего не меняет
+// @Tags Избранное
+// @Security BearerAuth
+// @Param id path int true "ID продукта"
+// @Success 204 "Продукт в избранном"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/products/{id}/favorite [put]
+func (pc *ProductController) AddFavorite(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	productID, ok := getIntParam(c, "id", "Некорректный ID продукта")
+	if !ok {
+		return
+	}
+
+	err := pc.Service.AddFavorite(userID, productID)
+	if errors.Is(err, services.ErrProductNotFound) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		return
+	} else if err != nil {
+		log.Printf("AddFavorite: ошибка при добавлении продукта %d в избранное: %v", productID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Не удалось добавить продукт в избранное"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RemoveFavorite убирает продукт из избранного
+// @Summary Убрать из избранного
+// @Tags Избранное
+// @Security BearerAuth
+// @Param id path int true "ID продукта"
+// @Success 204 "Продукт убран из избранного"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/products/{id}/favorite [delete]
+func (pc *ProductController) RemoveFavorite(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	productID, ok := getIntParam(c, "id", "Некорректный ID продукта")
+	if !ok {
+		return
+	}
+
+	if err := pc.Service.RemoveFavorite(userID, productID); err != nil {
+		log.Printf("RemoveFavorite: ошибка при удалении продукта %d из избранного: %v", productID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Не удалось убрать продукт из избранного"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetFavorites возвращает избранные продукты пользователя
+// @Summary Избранное
+// @Description Одобренные продукты из избранного, последние добавленные первыми
+// @Tags Избранное
+// @Security BearerAuth
+// @Produce json
+// @Param lang query string false "Язык: ru, ky или en"
+// @Success 200 {array} models.ProductCard
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/favorites [get]
+func (pc *ProductController) GetFavorites(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	cards, err := pc.Service.GetFavorites(userID)
+	if err != nil {
+		log.Printf("GetFavorites: ошибка при получении избранного пользователя %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Не удалось получить избранное"})
+		return
+	}
+	pc.Translations.LocalizeProductCards(requestLanguage(c), cards)
+
+	c.JSON(http.StatusOK, cards)
+}
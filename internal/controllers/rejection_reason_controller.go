@@ -0,0 +1,165 @@
+// internal/controllers/rejection_reason_controller.go
+
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/WhyDias/Marketplace/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// RejectionReasonController управляет справочником причин отклонения
+type RejectionReasonController struct {
+	Service *services.RejectionReasonService
+}
+
+func NewRejectionReasonController(service *services.RejectionReasonService) *RejectionReasonController {
+	return &RejectionReasonController{Service: service}
+}
+
+// GetRejectionReasons возвращает справочник причин отклонения
+// @Summary Справочник причин отклонения
+// @Description Причины, которые модератор указывает при отклонении продукта. По умолчанию только активные.
+// @Tags Модерация
+// @Security BearerAuth
+// @Produce json
+// @Param all query bool false "Включить неактивные причины"
+// @Success 200 {array} models.RejectionReason
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/moderation/rejection-reasons [get]
+func (rc *RejectionReasonController) GetRejectionReasons(c *gin.Context) {
+	reasons, err := rc.Service.GetReasons(c.Query("all") != "true")
+	if err != nil {
+		log.Printf("GetRejectionReasons: ошибка при получении причин: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Не удалось получить причины отклонения"})
+		return
+	}
+
+	c.JSON(http.StatusOK, reasons)
+}
+
+// CreateRejectionReason добавляет причину в справочник
+// @Summary Добавление причины отклонения
+// @Tags Модерация
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param reason body models.RejectionReasonRequest true "Причина отклонения"
+// @Success 201 {object} models.RejectionReason
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Код уже используется"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/moderation/rejection-reasons [post]
+func (rc *RejectionReasonController) CreateRejectionReason(c *gin.Context) {
+	var req models.RejectionReasonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Некорректный формат данных"})
+		return
+	}
+
+	reason, err := rc.Service.CreateReason(req)
+	if err != nil {
+		log.Printf("CreateRejectionReason: ошибка при создании причины %s: %v", req.Code, err)
+		writeModerationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, reason)
+}
+
+// UpdateRejectionReason изменяет причину в справочнике
+// @Summary Изменение причины отклонения
+// @Description Меняет код, название, описание, порядок или активность причины. Неактивную причину нельзя указать при отклонении, но она сохраняется в истории.
+// @Tags Модерация
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID причины"
+// @Param reason body models.RejectionReasonRequest true "Причина отклонения"
+// @Success 200 {object} models.RejectionReason
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Код уже используется"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/moderation/rejection-reasons/{id} [put]
+func (rc *RejectionReasonController) UpdateRejectionReason(c *gin.Context) {
+	id, ok := getIntParam(c, "id", "Некорректный ID причины")
+	if !ok {
+		return
+	}
+
+	var req models.RejectionReasonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Некорректный формат данных"})
+		return
+	}
+
+	reason, err := rc.Service.UpdateReason(id, req)
+	if err != nil {
+		log.Printf("UpdateRejectionReason: ошибка при обновлении причины %d: %v", id, err)
+		writeModerationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reason)
+}
+
+// GetRejectionReasonStats возвращает самые частые причины отклонения
+// @Summary Отчёт по причинам отклонения
+// @Description Сколько раз за период указывалась каждая причина и у скольких продуктов. Период задаётся датами в формате YYYY-MM-DD, дата to включается.
+// @Tags Модерация
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "Начало периода (YYYY-MM-DD)"
+// @Param to query string false "Конец периода (YYYY-MM-DD)"
+// @Success 200 {array} models.RejectionReasonStat
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/moderation/reports/rejection-reasons [get]
+func (rc *RejectionReasonController) GetRejectionReasonStats(c *gin.Context) {
+	from, ok := getDateQuery(c, "from")
+	if !ok {
+		return
+	}
+	to, ok := getDateQuery(c, "to")
+	if !ok {
+		return
+	}
+	if !to.IsZero() {
+		to = to.AddDate(0, 0, 1)
+	}
+
+	stats, err := rc.Service.GetStats(from, to)
+	if err != nil {
+		log.Printf("GetRejectionReasonStats: ошибка при получении статистики: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Не удалось получить отчёт"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// getDateQuery разбирает необязательную дату YYYY-MM-DD из параметра запроса
+func getDateQuery(c *gin.Context, name string) (time.Time, bool) {
+	valueStr := c.Query(name)
+	if valueStr == "" {
+		return time.Time{}, true
+	}
+	value, err := time.Parse("2006-01-02", valueStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Некорректная дата " + name + ", ожидается формат YYYY-MM-DD"})
+		return time.Time{}, false
+	}
+	return value, true
+}
@@ -0,0 +1,52 @@
+// internal/controllers/sitemap_controller.go
+
+package controllers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/WhyDias/Marketplace/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// SitemapController отдаёт sitemap.xml
+type SitemapController struct {
+	Service *services.SitemapService
+}
+
+func NewSitemapController(service *services.SitemapService) *SitemapController {
+	return &SitemapController{Service: service}
+}
+
+// GetSitemap возвращает sitemap.xml
+// @Summary Sitemap
+// @Description Адреса одобренных продуктов (/products/{slug}) и категорий, в которых есть одобренные продукты (/catalog/{slug}), на хосте запроса
+// @Tags SEO
+// @Produce xml
+// @Success 200 {string} string "sitemap.xml"
+// @Failure 500 {object} ErrorResponse
+// @Router /sitemap.xml [get]
+func (sc *SitemapController) GetSitemap(c *gin.Context) {
+	data, err := sc.Service.Sitemap(requestBaseURL(c))
+	if err != nil {
+		log.Printf("GetSitemap: ошибка при построении sitemap: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Не удалось построить sitemap"})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", data)
+}
+
+// requestBaseURL адрес сайта из запроса с учётом прокси
+func requestBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	host := c.GetHeader("X-Forwarded-Host")
+	if host == "" {
+		host = c.Request.Host
+	}
+	return scheme + "://" + host
+}
@@ -3,6 +3,7 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"github.com/WhyDias/Marketplace/internal/models"
 	"github.com/WhyDias/Marketplace/internal/services"
@@ -10,6 +11,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"log"
 	"net/http"
+	"strconv"
 )
 
 // RegisterSupplierRequest структура запроса для регистрации поставщика
@@ -21,13 +23,15 @@ type RegisterSupplierResponse struct {
 
 // SupplierController структура контроллера поставщиков
 type SupplierController struct {
-	Service *services.SupplierService
+	Service      *services.SupplierService
+	Translations *services.TranslationService
 }
 
 // NewSupplierController конструктор контроллера поставщиков
-func NewSupplierController(service *services.SupplierService) *SupplierController {
+func NewSupplierController(service *services.SupplierService, translations *services.TranslationService) *SupplierController {
 	return &SupplierController{
-		Service: service,
+		Service:      service,
+		Translations: translations,
 	}
 }
 
@@ -37,15 +41,13 @@ type SupplierService struct{}
 // internal/controllers/supplier_controller.go
 
 type UpdateSupplierDetailsRequest struct {
-	MarketID   int    `json:"market_id" binding:"required"`
-	Place      string `json:"place" binding:"required"`
-	RowName    string `json:"row_name" binding:"required"`
-	Categories []int  `json:"categories" binding:"required"`
+	StallID    int   `json:"stall_id" binding:"required"` // Место из справочника рынка, GET /api/markets/{id}
+	Categories []int `json:"categories" binding:"required"`
 }
 
 // UpdateSupplierDetails обновляет данные поставщика.
 // @Summary      Обновление данных поставщика
-// @Description  Обновляет информацию о поставщике.
+// @Description  Закрепляет за поставщиком место на рынке и заменяет его категории. Рынок, ряд и место берутся из выбранного места. Доступно владельцу и менеджерам.
 // @Tags         Поставщик
 // @Security     BearerAuth
 // @Accept       json
@@ -54,6 +56,9 @@ type UpdateSupplierDetailsRequest struct {
 // @Success      200    {object}  models.UpdateSupplierDetailsResponse
 // @Failure      400    {object}  ErrorResponse
 // @Failure      401    {object}  ErrorResponse
+// @Failure      403    {object}  ErrorResponse  "Недостаточно прав в команде поставщика"
+// @Failure      404    {object}  ErrorResponse  "Место не найдено"
+// @Failure      409    {object}  ErrorResponse  "Место занято другим поставщиком"
 // @Failure      500    {object}  ErrorResponse
 // @Router       /supplier/update_details [post]
 func (sc *SupplierController) UpdateSupplierDetails(c *gin.Context) {
@@ -77,8 +82,18 @@ func (sc *SupplierController) UpdateSupplierDetails(c *gin.Context) {
 	}
 
 	// Обновляем детали поставщика
-	err := sc.Service.UpdateSupplierDetailsByUserID(userID, req.MarketID, req.Place, req.RowName, req.Categories)
-	if err != nil {
+	err := sc.Service.UpdateSupplierDetailsByUserID(userID, req.StallID, req.Categories)
+	if errors.Is(err, services.ErrStallNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	} else if errors.Is(err, services.ErrSupplierForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	} else if errors.Is(err, services.ErrStallTaken) {
+		c.JSON(http.StatusConflict, gin.H{"error": services.ErrStallTaken.Error()})
+		return
+	} else if err != nil {
+		log.Printf("UpdateSupplierDetails: ошибка при обновлении данных поставщика пользователя %d: %v", userID, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось обновить данные поставщика"})
 		return
 	}
@@ -90,7 +105,7 @@ func (sc *SupplierController) UpdateSupplierDetails(c *gin.Context) {
 
 // GetMarkets возвращает список доступных рынков.
 // @Summary      Получение списка рынков
-// @Description  Возвращает список всех доступных рынков.
+// @Description  Возвращает список всех доступных рынков с адресом, координатами, часами работы и выходными днями.
 // @Tags         Справочники
 // @Accept       json
 // @Produce      json
@@ -160,13 +175,6 @@ func (sc *SupplierController) GetCategoryByPath(c *gin.Context) {
 	c.JSON(http.StatusOK, category)
 }
 
-// AddCategoryRequest структура запроса для добавления категории
-type AddCategoryRequest struct {
-	Name     string `json:"name" binding:"required"`
-	Path     string `json:"path" binding:"required"`
-	ImageURL string `json:"image_url" binding:"required,url"`
-}
-
 // AddCategoryResponse структура ответа при добавлении категории
 type AddCategoryResponse struct {
 	ID       int    `json:"id"`
@@ -177,13 +185,13 @@ type AddCategoryResponse struct {
 
 // AddCategory добавляет новую категорию с изображением
 // @Summary Добавить новую категорию
-// @Description Добавляет новую категорию с изображением, загружая изображение в Yandex Cloud Storage
+// @Description Добавляет новую категорию с изображением, загружая изображение в Yandex Cloud Storage. Path строится из path родителя и транслитерации названия; занятая метка получает числовой суффикс.
 // @Tags Categories
 // @Accept multipart/form-data
 // @Produce json
 // @Param Authorization header string true "Bearer <token>"
 // @Param name formData string true "Название категории"
-// @Param path formData string true "Путь категории"
+// @Param parent_id formData int false "ID родительской категории, без него создаётся корневая"
 // @Param image formData file true "Изображение категории"
 // @Success 201 {object} AddCategoryResponse "Категория успешно добавлена"
 // @Failure 400 {object} ErrorResponse "Неверный формат данных или ошибки валидации"
@@ -192,7 +200,20 @@ type AddCategoryResponse struct {
 // @Router /api/categories [post]
 func (sc *SupplierController) AddCategory(c *gin.Context) {
 	name := c.PostForm("name")
-	path := c.PostForm("path")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Необходимо указать название категории"})
+		return
+	}
+
+	var parentID *int
+	if raw := c.PostForm("parent_id"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Некорректный ID родительской категории"})
+			return
+		}
+		parentID = &id
+	}
 
 	file, err := c.FormFile("image")
 	if err != nil {
@@ -202,12 +223,15 @@ func (sc *SupplierController) AddCategory(c *gin.Context) {
 	}
 
 	// Сначала создаем категорию без изображения, чтобы получить ее ID
-	category, err := sc.Service.AddCategory(name, path, "")
+	category, err := sc.Service.AddCategory(name, parentID, "")
 	if err != nil {
 		log.Printf("AddCategory: ошибка при добавлении категории: %v", err)
-		if err.Error() == "категория с path '"+path+"' уже существует" {
+		switch {
+		case errors.Is(err, services.ErrCategoryNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Родительская категория не найдена"})
+		case errors.Is(err, services.ErrInvalidCategoryLabel):
 			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
-		} else {
+		default:
 			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Не удалось добавить категорию"})
 		}
 		return
@@ -216,13 +240,18 @@ func (sc *SupplierController) AddCategory(c *gin.Context) {
 	// Теперь у нас есть category.ID, можем использовать его для создания подкаталога
 	folderPath := fmt.Sprintf("categories/%d", category.ID)
 
-	// Загружаем изображение в Yandex Cloud Storage в подкаталог категории
-	imageURL, err := utils.UploadFileToYandex(file, folderPath)
+	// Проверяем изображение и загружаем миниатюры в Yandex Cloud Storage в подкаталог категории
+	uploaded, err := utils.UploadImageToYandex(file, folderPath)
 	if err != nil {
 		log.Printf("AddCategory: ошибка при загрузке изображения в Yandex Cloud Storage: %v", err)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Не удалось загрузить изображение"})
+		if isImageValidationError(err) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Не удалось загрузить изображение"})
+		}
 		return
 	}
+	imageURL := uploaded.URL
 
 	// Обновляем URL изображения в категории
 	category.ImageURL = imageURL
@@ -235,6 +264,10 @@ func (sc *SupplierController) AddCategory(c *gin.Context) {
 		return
 	}
 
+	if err := sc.Service.SaveCategoryImageHash(category.ID, uploaded); err != nil {
+		log.Printf("AddCategory: ошибка при сохранении хэша изображения категории: %v", err)
+	}
+
 	// Формирование ответа
 	response := AddCategoryResponse{
 		ID:       category.ID,
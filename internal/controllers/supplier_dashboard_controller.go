@@ -0,0 +1,59 @@
+// internal/controllers/supplier_dashboard_controller.go
+
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/WhyDias/Marketplace/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// GetDashboard возвращает кабинет поставщика со статистикой продуктов
+// @Summary Кабинет поставщика
+// @Description Количество продуктов по статусам, вариации с малым остатком, просмотры, добавления в избранное и нажатия на контакты по продуктам за период и лучшие продукты. Статистика берётся из дневных итогов, которые обновляются раз в несколько минут (время в stats_updated_at). Доступно владельцу и менеджеру.
+// @Tags Поставщик
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "Начало периода YYYY-MM-DD (по умолчанию 29 дней до to)"
+// @Param to query string false "Конец периода YYYY-MM-DD включительно (по умолчанию сегодня)"
+// @Param low_stock query int false "Порог малого остатка (по умолчанию 5)"
+// @Success 200 {object} models.SupplierDashboard
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/supplier/dashboard [get]
+func (sc *SupplierController) GetDashboard(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var filter models.SupplierDashboardFilter
+	if filter.From, ok = getDateQuery(c, "from"); !ok {
+		return
+	}
+	if filter.To, ok = getDateQuery(c, "to"); !ok {
+		return
+	}
+	if filter.LowStockThreshold, ok = getIntQuery(c, "low_stock", "Некорректный low_stock"); !ok {
+		return
+	}
+
+	dashboard, err := sc.Service.GetDashboard(userID, filter)
+	if errors.Is(err, services.ErrInvalidDashboardPeriod) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	} else if err != nil {
+		log.Printf("GetDashboard: ошибка при получении кабинета поставщика пользователя %d: %v", userID, err)
+		writeSupplierProfileError(c, err, "Не удалось получить кабинет поставщика")
+		return
+	}
+
+	c.JSON(http.StatusOK, dashboard)
+}
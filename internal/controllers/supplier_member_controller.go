@@ -0,0 +1,209 @@
+// internal/controllers/supplier_member_controller.go
+
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/WhyDias/Marketplace/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// writeSupplierTeamError отвечает кодом, соответствующим ошибке команды поставщика
+func writeSupplierTeamError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrSupplierNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: services.ErrSupplierNotFound.Error()})
+	case errors.Is(err, services.ErrSupplierMemberNotFound), errors.Is(err, services.ErrSupplierInviteNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrSupplierForbidden):
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrAlreadySupplierMember), errors.Is(err, services.ErrSupplierOwnerRemoval):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrInvalidSupplierRole), errors.Is(err, services.ErrInvitePasswordRequired):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrInvalidInviteCode):
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: message})
+	}
+}
+
+// GetTeam возвращает команду текущего поставщика
+// @Summary Команда поставщика
+// @Description Участники команды поставщика из токена (владелец первым) и действующие приглашения. Доступно владельцу и менеджерам.
+// @Tags Поставщик
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.SupplierTeam
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/supplier/team [get]
+func (sc *SupplierController) GetTeam(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	team, err := sc.Service.GetTeam(userID)
+	if err != nil {
+		log.Printf("GetTeam: ошибка при получении команды поставщика пользователя %d: %v", userID, err)
+		writeSupplierTeamError(c, err, "Не удалось получить команду поставщика")
+		return
+	}
+
+	c.JSON(http.StatusOK, team)
+}
+
+// InviteMember приглашает участника в команду текущего поставщика
+// @Summary Приглашение в команду поставщика
+// @Description Отправляет на номер телефона код в WhatsApp. Приглашённый принимает приглашение этим кодом через /api/supplier/team/invites/accept и там же задаёт пароль, если его ещё нет. Владелец приглашает менеджеров и контент-редакторов, менеджер — только контент-редакторов. Приглашение действует 7 дней, новое приглашение того же номера заменяет прежнее.
+// @Tags Поставщик
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param invite body models.InviteSupplierMemberRequest true "Номер телефона и роль"
+// @Success 201 {object} models.SupplierInvite
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Пользователь уже состоит в команде поставщика"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/supplier/team/invites [post]
+func (sc *SupplierController) InviteMember(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.InviteSupplierMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	invite, err := sc.Service.InviteMember(userID, req)
+	if err != nil {
+		log.Printf("InviteMember: ошибка при приглашении в команду поставщика пользователем %d: %v", userID, err)
+		writeSupplierTeamError(c, err, "Не удалось отправить приглашение")
+		return
+	}
+
+	c.JSON(http.StatusCreated, invite)
+}
+
+// RevokeInvite отзывает приглашение в команду текущего поставщика
+// @Summary Отзыв приглашения
+// @Description Отзывает действующее приглашение. Менеджер может отозвать только приглашение контент-редактора.
+// @Tags Поставщик
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID приглашения"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/supplier/team/invites/{id} [delete]
+func (sc *SupplierController) RevokeInvite(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	inviteID, ok := getIntParam(c, "id", "Некорректный ID приглашения")
+	if !ok {
+		return
+	}
+
+	if err := sc.Service.RevokeInvite(userID, inviteID); err != nil {
+		log.Printf("RevokeInvite: ошибка при отзыве приглашения %d: %v", inviteID, err)
+		writeSupplierTeamError(c, err, "Не удалось отозвать приглашение")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Приглашение отозвано"})
+}
+
+// UpdateMemberRole меняет роль участника команды текущего поставщика
+// @Summary Смена роли участника команды
+// @Description Доступно только владельцу. Роль владельца не меняется.
+// @Tags Поставщик
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param user_id path int true "ID пользователя-участника"
+// @Param role body models.UpdateSupplierMemberRequest true "Новая роль"
+// @Success 200 {object} models.SupplierMember
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Роль владельца не меняется"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/supplier/team/members/{user_id} [put]
+func (sc *SupplierController) UpdateMemberRole(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	memberUserID, ok := getIntParam(c, "user_id", "Некорректный ID пользователя")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateSupplierMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	member, err := sc.Service.UpdateMemberRole(userID, memberUserID, req.Role)
+	if err != nil {
+		log.Printf("UpdateMemberRole: ошибка при смене роли пользователя %d: %v", memberUserID, err)
+		writeSupplierTeamError(c, err, "Не удалось изменить роль участника")
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// RemoveMember исключает участника из команды текущего поставщика
+// @Summary Исключение из команды поставщика
+// @Description Владелец исключает любого участника, менеджер — контент-редакторов, любой участник, кроме владельца, может выйти из команды сам.
+// @Tags Поставщик
+// @Security BearerAuth
+// @Produce json
+// @Param user_id path int true "ID пользователя-участника"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Владельца нельзя исключить"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/supplier/team/members/{user_id} [delete]
+func (sc *SupplierController) RemoveMember(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	memberUserID, ok := getIntParam(c, "user_id", "Некорректный ID пользователя")
+	if !ok {
+		return
+	}
+
+	if err := sc.Service.RemoveMember(userID, memberUserID); err != nil {
+		log.Printf("RemoveMember: ошибка при исключении пользователя %d: %v", memberUserID, err)
+		writeSupplierTeamError(c, err, "Не удалось исключить участника")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Участник исключён из команды"})
+}
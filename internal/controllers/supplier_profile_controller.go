@@ -0,0 +1,159 @@
+// internal/controllers/supplier_profile_controller.go
+
+package controllers
+
+import (
+	"errors"
+	"log"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/WhyDias/Marketplace/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// writeSupplierProfileError отвечает кодом, соответствующим ошибке витрины поставщика
+func writeSupplierProfileError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrSupplierNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: services.ErrSupplierNotFound.Error()})
+	case errors.Is(err, services.ErrSupplierForbidden):
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrInvalidSupplierProfile), isImageValidationError(err):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: message})
+	}
+}
+
+// optionalFormFile возвращает файл из multipart-формы, nil если его нет
+func optionalFormFile(c *gin.Context, name string) (*multipart.FileHeader, error) {
+	file, err := c.FormFile(name)
+	if errors.Is(err, http.ErrMissingFile) || errors.Is(err, http.ErrNotMultipart) {
+		return nil, nil
+	}
+	return file, err
+}
+
+// GetStorefront возвращает публичную витрину поставщика
+// @Summary Витрина поставщика
+// @Description Название, логотип, баннер, описание, рынок и место, категории, способы связи, расписание и страница одобренных продуктов поставщика (новые первыми)
+// @Tags Поставщик
+// @Produce json
+// @Param id path int true "ID поставщика"
+// @Param limit query int false "Количество продуктов (по умолчанию 20, максимум 100)"
+// @Param offset query int false "Смещение"
+// @Param lang query string false "Язык: ru, ky или en, по умолчанию из Accept-Language"
+// @Success 200 {object} models.SupplierStorefront
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/suppliers/{id} [get]
+func (sc *SupplierController) GetStorefront(c *gin.Context) {
+	supplierID, ok := getIntParam(c, "id", "Некорректный ID поставщика")
+	if !ok {
+		return
+	}
+	limit, ok := getIntQuery(c, "limit", "Некорректный limit")
+	if !ok {
+		return
+	}
+	offset, ok := getIntQuery(c, "offset", "Некорректный offset")
+	if !ok {
+		return
+	}
+
+	storefront, err := sc.Service.GetStorefront(supplierID, limit, offset)
+	if err != nil {
+		log.Printf("GetStorefront: ошибка при получении витрины поставщика %d: %v", supplierID, err)
+		writeSupplierProfileError(c, err, "Не удалось получить витрину поставщика")
+		return
+	}
+	lang := requestLanguage(c)
+	sc.Translations.LocalizeCategories(lang, storefront.Categories)
+	sc.Translations.LocalizeProductCards(lang, storefront.Products.Items)
+
+	c.JSON(http.StatusOK, storefront)
+}
+
+// GetProfile возвращает профиль витрины текущего поставщика
+// @Summary Профиль витрины поставщика
+// @Description Логотип, баннер, описание и способы связи витрины поставщика из токена
+// @Tags Поставщик
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.SupplierProfile
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/supplier/profile [get]
+func (sc *SupplierController) GetProfile(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	profile, err := sc.Service.GetProfile(userID)
+	if err != nil {
+		log.Printf("GetProfile: ошибка при получении профиля поставщика пользователя %d: %v", userID, err)
+		writeSupplierProfileError(c, err, "Не удалось получить профиль поставщика")
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// UpdateProfile изменяет профиль витрины текущего поставщика
+// @Summary Изменение профиля витрины
+// @Description Меняет описание, Telegram, Instagram и показ телефона; не переданные поля не меняются. Доступно владельцу и менеджерам. Новые логотип и баннер передаются файлами, remove_logo и remove_banner убирают их. Telegram и Instagram принимаются как имя пользователя, @имя или ссылка на профиль, пустое значение убирает контакт.
+// @Tags Поставщик
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param description formData string false "Описание витрины"
+// @Param telegram formData string false "Имя пользователя Telegram"
+// @Param instagram formData string false "Имя пользователя Instagram"
+// @Param show_phone formData bool false "Показывать телефон и WhatsApp на витрине"
+// @Param remove_logo formData bool false "Убрать логотип"
+// @Param remove_banner formData bool false "Убрать баннер"
+// @Param logo formData file false "Логотип"
+// @Param banner formData file false "Баннер"
+// @Success 200 {object} models.SupplierProfile
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/supplier/profile [put]
+func (sc *SupplierController) UpdateProfile(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateSupplierProfileRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	logo, err := optionalFormFile(c, "logo")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Не удалось прочитать логотип"})
+		return
+	}
+	banner, err := optionalFormFile(c, "banner")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Не удалось прочитать баннер"})
+		return
+	}
+
+	profile, err := sc.Service.UpdateProfile(userID, req, logo, banner)
+	if err != nil {
+		log.Printf("UpdateProfile: ошибка при изменении профиля поставщика пользователя %d: %v", userID, err)
+		writeSupplierProfileError(c, err, "Не удалось изменить профиль поставщика")
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
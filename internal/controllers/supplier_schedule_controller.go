@@ -0,0 +1,224 @@
+// internal/controllers/supplier_schedule_controller.go
+
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/WhyDias/Marketplace/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// writeSupplierScheduleError отвечает кодом, соответствующим ошибке расписания поставщика
+func writeSupplierScheduleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrSupplierNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: services.ErrSupplierNotFound.Error()})
+	case errors.Is(err, services.ErrScheduleExceptionNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrSupplierForbidden):
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrInvalidSchedule):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: message})
+	}
+}
+
+// GetSchedule возвращает расписание текущего поставщика
+// @Summary Расписание поставщика
+// @Description Часы работы по дням недели, исключения с сегодняшнего дня и отпуск. Время местное (Бишкек).
+// @Tags Поставщик
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.SupplierSchedule
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/supplier/schedule [get]
+func (sc *SupplierController) GetSchedule(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	schedule, err := sc.Service.GetSchedule(userID)
+	if err != nil {
+		log.Printf("GetSchedule: ошибка при получении расписания поставщика пользователя %d: %v", userID, err)
+		writeSupplierScheduleError(c, err, "Не удалось получить расписание")
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// UpdateWorkingHours заменяет часы работы текущего поставщика
+// @Summary Часы работы поставщика
+// @Description Заменяет часы работы на всю неделю, не указанные дни становятся выходными. Доступно владельцу и менеджеру.
+// @Tags Поставщик
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param hours body models.UpdateWorkingHoursRequest true "Часы работы по дням недели"
+// @Success 200 {object} models.SupplierSchedule
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/supplier/schedule/working-hours [put]
+func (sc *SupplierController) UpdateWorkingHours(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateWorkingHoursRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	schedule, err := sc.Service.UpdateWorkingHours(userID, req)
+	if err != nil {
+		log.Printf("UpdateWorkingHours: ошибка при изменении часов работы поставщика пользователем %d: %v", userID, err)
+		writeSupplierScheduleError(c, err, "Не удалось изменить часы работы")
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// SetScheduleException задаёт часы работы текущего поставщика на дату
+// @Summary Исключение расписания
+// @Description Выходной (closed) или особые часы работы на дату вместо обычных, например в праздник. Доступно владельцу и менеджеру.
+// @Tags Поставщик
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param date path string true "Дата ГГГГ-ММ-ДД"
+// @Param exception body models.ScheduleExceptionRequest true "Часы работы на дату"
+// @Success 200 {object} models.SupplierSchedule
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/supplier/schedule/exceptions/{date} [put]
+func (sc *SupplierController) SetScheduleException(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ScheduleExceptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	schedule, err := sc.Service.SetScheduleException(userID, c.Param("date"), req)
+	if err != nil {
+		log.Printf("SetScheduleException: ошибка при сохранении исключения расписания на %s: %v", c.Param("date"), err)
+		writeSupplierScheduleError(c, err, "Не удалось сохранить исключение расписания")
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// DeleteScheduleException убирает исключение расписания текущего поставщика
+// @Summary Удаление исключения расписания
+// @Description На дату снова действуют обычные часы работы. Доступно владельцу и менеджеру.
+// @Tags Поставщик
+// @Security BearerAuth
+// @Produce json
+// @Param date path string true "Дата ГГГГ-ММ-ДД"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/supplier/schedule/exceptions/{date} [delete]
+func (sc *SupplierController) DeleteScheduleException(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := sc.Service.DeleteScheduleException(userID, c.Param("date")); err != nil {
+		log.Printf("DeleteScheduleException: ошибка при удалении исключения расписания на %s: %v", c.Param("date"), err)
+		writeSupplierScheduleError(c, err, "Не удалось удалить исключение расписания")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Исключение расписания удалено"})
+}
+
+// SetVacation задаёт отпуск текущего поставщика
+// @Summary Отпуск поставщика
+// @Description На время отпуска продукты остаются в каталоге с available=false (mode unavailable) или скрываются от покупателей (mode hidden). Отпуск начинается и заканчивается автоматически по датам, по местному времени. Доступно владельцу и менеджеру.
+// @Tags Поставщик
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param vacation body models.VacationRequest true "Даты и режим отпуска"
+// @Success 200 {object} models.SupplierSchedule
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/supplier/vacation [put]
+func (sc *SupplierController) SetVacation(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.VacationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	schedule, err := sc.Service.SetVacation(userID, req)
+	if err != nil {
+		log.Printf("SetVacation: ошибка при сохранении отпуска поставщика пользователем %d: %v", userID, err)
+		writeSupplierScheduleError(c, err, "Не удалось сохранить отпуск")
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// CancelVacation отменяет отпуск текущего поставщика
+// @Summary Отмена отпуска
+// @Description Продукты сразу снова доступны покупателям. Доступно владельцу и менеджеру.
+// @Tags Поставщик
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/supplier/vacation [delete]
+func (sc *SupplierController) CancelVacation(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := sc.Service.CancelVacation(userID); err != nil {
+		log.Printf("CancelVacation: ошибка при отмене отпуска поставщика пользователем %d: %v", userID, err)
+		writeSupplierScheduleError(c, err, "Не удалось отменить отпуск")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Отпуск отменён"})
+}
@@ -0,0 +1,303 @@
+// internal/controllers/supplier_verification_controller.go
+
+package controllers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/WhyDias/Marketplace/internal/services"
+	"github.com/WhyDias/Marketplace/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// writeVerificationError отвечает кодом, соответствующим ошибке проверки поставщика
+func writeVerificationError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrSupplierNotFound), errors.Is(err, services.ErrSupplierDocumentNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrSupplierForbidden):
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrVerificationPending), errors.Is(err, services.ErrVerificationAlreadyApproved),
+		errors.Is(err, services.ErrVerificationNotPending):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrInvalidSupplierDocument), errors.Is(err, services.ErrVerificationDocumentsMissing),
+		errors.Is(err, services.ErrVerificationCommentRequired),
+		errors.Is(err, utils.ErrDocumentTooLarge), errors.Is(err, utils.ErrDocumentTypeNotAllowed):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: message})
+	}
+}
+
+// GetVerification возвращает проверку текущего поставщика
+// @Summary Проверка поставщика
+// @Description Статус проверки по документам, загруженные документы со ссылками, действующими 15 минут, и история проверки поставщика из токена. Доступно владельцу и менеджерам.
+// @Tags Поставщик
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.SupplierVerification
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/supplier/verification [get]
+func (sc *SupplierController) GetVerification(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	verification, err := sc.Service.GetVerification(userID)
+	if err != nil {
+		log.Printf("GetVerification: ошибка при получении проверки поставщика пользователя %d: %v", userID, err)
+		writeVerificationError(c, err, "Не удалось получить проверку поставщика")
+		return
+	}
+
+	c.JSON(http.StatusOK, verification)
+}
+
+// UploadDocument загружает документ текущего поставщика
+// @Summary Загрузка документа поставщика
+// @Description Загружает патент, свидетельство ИНН или договор аренды места в закрытое хранилище. Допустимы PDF, JPEG и PNG до 10 МБ. Пока документы на проверке, менять их нельзя. Доступно только владельцу.
+// @Tags Поставщик
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param doc_type formData string true "Тип документа: patent, inn_certificate или stall_lease"
+// @Param file formData file true "Файл документа"
+// @Success 201 {object} models.SupplierDocument
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Документы уже на проверке"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/supplier/verification/documents [post]
+func (sc *SupplierController) UploadDocument(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UploadSupplierDocumentRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Файл документа обязателен"})
+		return
+	}
+
+	doc, err := sc.Service.UploadDocument(userID, req.DocType, file)
+	if err != nil {
+		log.Printf("UploadDocument: ошибка при загрузке документа поставщика пользователя %d: %v", userID, err)
+		writeVerificationError(c, err, "Не удалось загрузить документ")
+		return
+	}
+
+	c.JSON(http.StatusCreated, doc)
+}
+
+// DeleteDocument удаляет документ текущего поставщика
+// @Summary Удаление документа поставщика
+// @Description Удаляет документ поставщика из токена. Пока документы на проверке, менять их нельзя. Доступно только владельцу.
+// @Tags Поставщик
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID документа"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Документы уже на проверке"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/supplier/verification/documents/{id} [delete]
+func (sc *SupplierController) DeleteDocument(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	documentID, ok := getIntParam(c, "id", "Некорректный ID документа")
+	if !ok {
+		return
+	}
+
+	if err := sc.Service.DeleteDocument(userID, documentID); err != nil {
+		log.Printf("DeleteDocument: ошибка при удалении документа %d: %v", documentID, err)
+		writeVerificationError(c, err, "Не удалось удалить документ")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Документ удалён"})
+}
+
+// SubmitVerification отправляет документы текущего поставщика на проверку
+// @Summary Отправка документов на проверку
+// @Description Ставит поставщика в очередь проверки. Нужны патент или свидетельство ИНН и договор аренды места. После отказа документы можно исправить и отправить снова. Доступно только владельцу.
+// @Tags Поставщик
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.SupplierVerification
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Документы уже на проверке или поставщик уже проверен"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/supplier/verification/submit [post]
+func (sc *SupplierController) SubmitVerification(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	verification, err := sc.Service.SubmitVerification(userID)
+	if err != nil {
+		log.Printf("SubmitVerification: ошибка при отправке документов поставщика пользователя %d: %v", userID, err)
+		writeVerificationError(c, err, "Не удалось отправить документы на проверку")
+		return
+	}
+
+	c.JSON(http.StatusOK, verification)
+}
+
+// GetVerificationQueue возвращает очередь проверки поставщиков
+// @Summary Очередь проверки поставщиков
+// @Description Поставщики, отправившие документы на проверку, начиная с самых давних заявок
+// @Tags Администрирование
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "Количество записей (по умолчанию 50, максимум 200)"
+// @Param offset query int false "Смещение"
+// @Success 200 {object} models.VerificationQueue
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/supplier-verifications [get]
+func (sc *SupplierController) GetVerificationQueue(c *gin.Context) {
+	limit, ok := getIntQuery(c, "limit", "Некорректный limit")
+	if !ok {
+		return
+	}
+	offset, ok := getIntQuery(c, "offset", "Некорректный offset")
+	if !ok {
+		return
+	}
+
+	queue, err := sc.Service.GetVerificationQueue(limit, offset)
+	if err != nil {
+		log.Printf("GetVerificationQueue: ошибка при получении очереди проверки: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Не удалось получить очередь проверки"})
+		return
+	}
+
+	c.JSON(http.StatusOK, queue)
+}
+
+// GetSupplierVerification возвращает проверку поставщика для администратора
+// @Summary Проверка поставщика (администратор)
+// @Description Статус проверки, документы со ссылками, действующими 15 минут, и история проверки поставщика
+// @Tags Администрирование
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID поставщика"
+// @Success 200 {object} models.SupplierVerification
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/suppliers/{id}/verification [get]
+func (sc *SupplierController) GetSupplierVerification(c *gin.Context) {
+	supplierID, ok := getIntParam(c, "id", "Некорректный ID поставщика")
+	if !ok {
+		return
+	}
+
+	verification, err := sc.Service.GetSupplierVerification(supplierID)
+	if err != nil {
+		log.Printf("GetSupplierVerification: ошибка при получении проверки поставщика %d: %v", supplierID, err)
+		writeVerificationError(c, err, "Не удалось получить проверку поставщика")
+		return
+	}
+
+	c.JSON(http.StatusOK, verification)
+}
+
+// ApproveVerification подтверждает проверку поставщика
+// @Summary Подтверждение проверки поставщика
+// @Description Подтверждает заявку, ожидающую проверки. Поставщик получает бейдж «проверенный продавец» на витрине и продуктах.
+// @Tags Администрирование
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID поставщика"
+// @Param decision body models.VerificationDecisionRequest false "Комментарий"
+// @Success 200 {object} models.SupplierVerification
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Поставщик не ожидает проверки"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/suppliers/{id}/verification/approve [post]
+func (sc *SupplierController) ApproveVerification(c *gin.Context) {
+	sc.decideVerification(c, sc.Service.ApproveVerification)
+}
+
+// RejectVerification отклоняет проверку поставщика
+// @Summary Отказ в проверке поставщика
+// @Description Отклоняет заявку, ожидающую проверки, или снимает отметку с проверенного поставщика. Причина обязательна и видна поставщику в истории проверки.
+// @Tags Администрирование
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID поставщика"
+// @Param decision body models.VerificationDecisionRequest true "Причина отказа"
+// @Success 200 {object} models.SupplierVerification
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Поставщик не ожидает проверки"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/suppliers/{id}/verification/reject [post]
+func (sc *SupplierController) RejectVerification(c *gin.Context) {
+	sc.decideVerification(c, sc.Service.RejectVerification)
+}
+
+func (sc *SupplierController) decideVerification(c *gin.Context, decide func(supplierID, adminID int, comment string) (*models.SupplierVerification, error)) {
+	adminID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+	supplierID, ok := getIntParam(c, "id", "Некорректный ID поставщика")
+	if !ok {
+		return
+	}
+
+	// Комментарий к подтверждению необязателен, поэтому тело запроса может быть пустым
+	var req models.VerificationDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	verification, err := decide(supplierID, adminID, req.Comment)
+	if err != nil {
+		log.Printf("decideVerification: ошибка при решении по проверке поставщика %d: %v", supplierID, err)
+		writeVerificationError(c, err, "Не удалось сохранить решение по проверке")
+		return
+	}
+
+	c.JSON(http.StatusOK, verification)
+}
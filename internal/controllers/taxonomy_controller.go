@@ -0,0 +1,151 @@
+// internal/controllers/taxonomy_controller.go
+
+package controllers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/WhyDias/Marketplace/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// TaxonomyController выгружает и загружает дерево категорий с атрибутами
+type TaxonomyController struct {
+	Service *services.TaxonomyService
+}
+
+func NewTaxonomyController(service *services.TaxonomyService) *TaxonomyController {
+	return &TaxonomyController{Service: service}
+}
+
+// taxonomyContentTypes типы содержимого документа таксономии по форматам
+var taxonomyContentTypes = map[string]string{
+	models.TaxonomyFormatYAML: "application/x-yaml; charset=utf-8",
+	models.TaxonomyFormatJSON: "application/json; charset=utf-8",
+}
+
+// ExportTaxonomy выгружает таксономию
+// @Summary Выгрузка таксономии
+// @Description Все категории (path, название, изображение), их собственные атрибуты с опциями dropdown и флагом is_linked, скрытые унаследованные атрибуты.
+// @Tags Таксономия
+// @Security BearerAuth
+// @Produce json
+// @Produce application/x-yaml
+// @Param format query string false "yaml (по умолчанию) или json"
+// @Success 200 {object} models.TaxonomyDocument
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/taxonomy [get]
+func (tc *TaxonomyController) ExportTaxonomy(c *gin.Context) {
+	format := c.DefaultQuery("format", models.TaxonomyFormatYAML)
+	contentType, ok := taxonomyContentTypes[format]
+	if !ok {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: services.ErrUnsupportedTaxonomyFormat.Error()})
+		return
+	}
+
+	doc, err := tc.Service.Export()
+	if err != nil {
+		log.Printf("ExportTaxonomy: ошибка при выгрузке таксономии: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Не удалось выгрузить таксономию"})
+		return
+	}
+	data, err := services.MarshalTaxonomy(doc, format)
+	if err != nil {
+		log.Printf("ExportTaxonomy: ошибка при кодировании таксономии: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Не удалось выгрузить таксономию"})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="taxonomy.`+format+`"`)
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// ImportTaxonomy загружает таксономию
+// @Summary Загрузка таксономии
+// @Description Сравнивает документ с текущей таксономией по path категорий и названиям атрибутов и применяет разницу. С dry_run только возвращает разницу. Категории, атрибуты и скрытия, которых нет в документе, удаляются только с prune. При конфликтах (удаление используемых категорий, атрибутов или опций, смена типа используемого атрибута) ничего не меняется.
+// @Tags Таксономия
+// @Security BearerAuth
+// @Accept json
+// @Accept application/x-yaml
+// @Produce json
+// @Param format query string false "yaml или json, по умолчанию по Content-Type"
+// @Param dry_run query bool false "Только показать разницу"
+// @Param prune query bool false "Удалять то, чего нет в документе"
+// @Param taxonomy body models.TaxonomyDocument true "Документ таксономии"
+// @Success 200 {object} models.TaxonomyDiff
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} models.TaxonomyDiff "Конфликты"
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/taxonomy/import [post]
+func (tc *TaxonomyController) ImportTaxonomy(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	dryRun, ok := getBoolQuery(c, "dry_run")
+	if !ok {
+		return
+	}
+	prune, ok := getBoolQuery(c, "prune")
+	if !ok {
+		return
+	}
+
+	format := c.Query("format")
+	if format == "" {
+		format = models.TaxonomyFormatJSON
+		if strings.Contains(c.ContentType(), "yaml") {
+			format = models.TaxonomyFormatYAML
+		}
+	}
+
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Не удалось прочитать документ"})
+		return
+	}
+	doc, err := services.ParseTaxonomy(data, format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	diff, err := tc.Service.Import(userID, doc, dryRun, prune)
+	switch {
+	case errors.Is(err, services.ErrTaxonomyConflicts):
+		c.JSON(http.StatusConflict, diff)
+	case errors.Is(err, services.ErrInvalidTaxonomy):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case err != nil:
+		log.Printf("ImportTaxonomy: ошибка при импорте таксономии: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusOK, diff)
+	}
+}
+
+// getBoolQuery разбирает необязательный логический параметр запроса
+func getBoolQuery(c *gin.Context, name string) (bool, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return false, true
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Некорректное значение параметра " + name})
+		return false, false
+	}
+	return value, true
+}
@@ -0,0 +1,167 @@
+// internal/controllers/translation_controller.go
+
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/WhyDias/Marketplace/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// TranslationController управляет переводами каталога
+type TranslationController struct {
+	Service *services.TranslationService
+}
+
+func NewTranslationController(service *services.TranslationService) *TranslationController {
+	return &TranslationController{Service: service}
+}
+
+// requestLanguage выбирает язык ответа из ?lang= или Accept-Language и сообщает его в Content-Language
+func requestLanguage(c *gin.Context) string {
+	lang := services.ResolveLanguage(c.Query("lang"), c.GetHeader("Accept-Language"))
+	c.Header("Content-Language", lang)
+	return lang
+}
+
+// writeTranslationError переводит ошибку сервиса переводов в HTTP-ответ
+func writeTranslationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrTranslationTargetNotFound), errors.Is(err, services.ErrTranslationNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrUnsupportedLanguage), errors.Is(err, services.ErrUnknownTranslationEntity),
+		errors.Is(err, services.ErrInvalidTranslation):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+}
+
+// GetTranslations возвращает переводы записи каталога
+// @Summary Переводы записи каталога
+// @Description entity: category, attribute, attribute_option (id — ID атрибута), product
+// @Tags Переводы
+// @Security BearerAuth
+// @Produce json
+// @Param entity path string true "Вид записи"
+// @Param id path int true "ID записи"
+// @Success 200 {array} models.Translation
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/translations/{entity}/{id} [get]
+func (tc *TranslationController) GetTranslations(c *gin.Context) {
+	id, ok := getIntParam(c, "id", "Некорректный ID записи")
+	if !ok {
+		return
+	}
+
+	translations, err := tc.Service.GetTranslations(c.Param("entity"), id)
+	if err != nil {
+		writeTranslationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, translations)
+}
+
+// SaveTranslation создаёт или заменяет перевод записи каталога
+// @Summary Сохранение перевода
+// @Description Категория переводится полем name, атрибут и продукт — name и description, значение атрибута (entity attribute_option) — option (исходный текст на русском) и value.
+// @Tags Переводы
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param entity path string true "Вид записи"
+// @Param id path int true "ID записи"
+// @Param lang path string true "Язык: ky или en"
+// @Param translation body models.TranslationRequest true "Перевод"
+// @Success 200 {object} GoodResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/translations/{entity}/{id}/{lang} [put]
+func (tc *TranslationController) SaveTranslation(c *gin.Context) {
+	id, ok := getIntParam(c, "id", "Некорректный ID записи")
+	if !ok {
+		return
+	}
+
+	var req models.TranslationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Некорректный формат данных"})
+		return
+	}
+
+	entity, lang := c.Param("entity"), c.Param("lang")
+	if err := tc.Service.SaveTranslation(entity, id, lang, req); err != nil {
+		log.Printf("SaveTranslation: ошибка при сохранении перевода %s %d на %s: %v", entity, id, lang, err)
+		writeTranslationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Перевод сохранён"})
+}
+
+// DeleteTranslation удаляет перевод записи каталога
+// @Summary Удаление перевода
+// @Tags Переводы
+// @Security BearerAuth
+// @Produce json
+// @Param entity path string true "Вид записи"
+// @Param id path int true "ID записи"
+// @Param lang path string true "Язык: ky или en"
+// @Param option query string false "Исходное значение для attribute_option"
+// @Success 200 {object} GoodResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/translations/{entity}/{id}/{lang} [delete]
+func (tc *TranslationController) DeleteTranslation(c *gin.Context) {
+	id, ok := getIntParam(c, "id", "Некорректный ID записи")
+	if !ok {
+		return
+	}
+
+	if err := tc.Service.DeleteTranslation(c.Param("entity"), id, c.Param("lang"), c.Query("option")); err != nil {
+		writeTranslationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Перевод удалён"})
+}
+
+// GetMissingTranslations возвращает записи каталога без перевода
+// @Summary Записи без перевода
+// @Description Категории, атрибуты, опции dropdown и одобренные продукты без перевода на язык. По каждому виду возвращается общее количество и первые записи.
+// @Tags Переводы
+// @Security BearerAuth
+// @Produce json
+// @Param lang query string true "Язык: ky или en"
+// @Param entity query string false "Вид записи"
+// @Success 200 {object} models.MissingTranslationsReport
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/admin/translations/missing [get]
+func (tc *TranslationController) GetMissingTranslations(c *gin.Context) {
+	report, err := tc.Service.GetMissingTranslations(c.Query("lang"), c.Query("entity"))
+	if err != nil {
+		log.Printf("GetMissingTranslations: ошибка при получении записей без перевода: %v", err)
+		writeTranslationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -3,6 +3,7 @@
 package controllers
 
 import (
+	"github.com/WhyDias/Marketplace/internal/models"
 	"github.com/WhyDias/Marketplace/internal/services"
 	_ "github.com/WhyDias/Marketplace/internal/utils"
 	"github.com/WhyDias/Marketplace/pkg/jwt"
@@ -173,6 +174,52 @@ func (uc *UserController) SetPassword(c *gin.Context) {
 	})
 }
 
+// AcceptSupplierInvite принимает приглашение в команду поставщика
+// @Summary      Принятие приглашения в команду поставщика
+// @Description  Проверяет код из WhatsApp, полученный с приглашением, добавляет пользователя в команду и возвращает JWT-токен. Пароль обязателен, если у приглашённого его ещё нет, иначе заменяет прежний.
+// @Tags         Авторизация
+// @Accept       json
+// @Produce      json
+// @Param        input  body      models.AcceptSupplierInviteRequest  true  "Номер, код и пароль"
+// @Success      200    {object}  SetPasswordResponse
+// @Failure      400    {object}  ErrorResponse
+// @Failure      401    {object}  ErrorResponse
+// @Failure      404    {object}  ErrorResponse
+// @Failure      409    {object}  ErrorResponse "Пользователь уже состоит в команде поставщика"
+// @Failure      500    {object}  ErrorResponse
+// @Router       /api/supplier/team/invites/accept [post]
+func (uc *UserController) AcceptSupplierInvite(c *gin.Context) {
+	var req models.AcceptSupplierInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	user, err := uc.SupplierService.AcceptInvite(req)
+	if err != nil {
+		log.Printf("AcceptSupplierInvite: не удалось принять приглашение для %s: %v", req.PhoneNumber, err)
+		writeSupplierTeamError(c, err, "Не удалось принять приглашение")
+		return
+	}
+
+	var roleNames []string
+	for _, role := range user.Roles {
+		roleNames = append(roleNames, role.Name)
+	}
+
+	token, err := uc.JWT.GenerateTokenWithRoles(user.ID, roleNames)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Не удалось создать токен"})
+		return
+	}
+
+	c.JSON(http.StatusOK, SetPasswordResponse{
+		Message:     "Приглашение принято",
+		AccessToken: token,
+		ExpiresAt:   time.Now().Add(72 * time.Hour).Format(time.RFC3339),
+	})
+}
+
 // CheckPhoneRequest структура запроса для проверки номера телефона
 type CheckPhoneRequest struct {
 	Username string `json:"username" binding:"required"` // Username используется как phone_number
@@ -3,6 +3,7 @@
 package controllers
 
 import (
+	"errors"
 	"github.com/WhyDias/Marketplace/internal/services"
 	"github.com/gin-gonic/gin"
 	"net/http"
@@ -107,6 +108,7 @@ type VerifyCodeResponse struct {
 // @Param        input  body      VerifyCodeRequest  true  "Данные для верификации"
 // @Success      200    {object}  VerifyCodeResponse
 // @Failure      400    {object}  ErrorResponse
+// @Failure      409    {object}  ErrorResponse "Номер приглашён в команду поставщика"
 // @Failure      500    {object}  ErrorResponse
 // @Router       /verify [post]
 func (vc *VerificationController) VerifyCode(c *gin.Context) {
@@ -132,7 +134,10 @@ func (vc *VerificationController) VerifyCode(c *gin.Context) {
 
 	// Отмечаем номер телефона как верифицированный и связываем с userID
 	err = vc.SupplierService.MarkPhoneNumberAsVerified(req.PhoneNumber, user.ID)
-	if err != nil {
+	if errors.Is(err, services.ErrSupplierInvitePending) {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		return
+	} else if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Не удалось обновить статус поставщика"})
 		return
 	}
@@ -0,0 +1,205 @@
+// internal/db/attribute_value.go
+
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/lib/pq"
+)
+
+// attributeValueEntryQuery значения атрибутов справочника с изображениями и числом продуктов
+const attributeValueEntryQuery = `
+    SELECT av.id, av.attribute_id, COALESCE(av.value_json::jsonb, to_jsonb(av.value), 'null'::jsonb),
+           av.sort_order, COALESCE(av.swatch_hex, ''), COALESCE(img.image_url, '{}'),
+           (SELECT COUNT(DISTINCT l.product_id) FROM (` + attributeValueProducts + `) l WHERE l.attribute_value_id = av.id)
+    FROM attribute_value av
+    LEFT JOIN attribute_value_image img ON img.attribute_value_id = av.id
+`
+
+func scanAttributeValueEntry(row interface{ Scan(...interface{}) error }) (models.AttributeValueEntry, error) {
+	var entry models.AttributeValueEntry
+	var raw []byte
+	err := row.Scan(&entry.ID, &entry.AttributeID, &raw, &entry.SortOrder, &entry.SwatchHex,
+		pq.Array(&entry.ImageURLs), &entry.Products)
+	entry.Value = raw
+	return entry, err
+}
+
+// GetAttributeValueEntries возвращает значения атрибута в порядке вывода
+func GetAttributeValueEntries(attributeID int) ([]models.AttributeValueEntry, error) {
+	rows, err := DB.Query(attributeValueEntryQuery+` WHERE av.attribute_id = $1 ORDER BY av.sort_order, av.id`, attributeID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить значения атрибута: %v", err)
+	}
+	defer rows.Close()
+
+	entries := []models.AttributeValueEntry{}
+	for rows.Next() {
+		entry, err := scanAttributeValueEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании значения атрибута: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return entries, nil
+}
+
+// GetAttributeValueEntry возвращает значение атрибута по ID, nil если его нет
+func GetAttributeValueEntry(valueID int) (*models.AttributeValueEntry, error) {
+	entry, err := scanAttributeValueEntry(DB.QueryRow(attributeValueEntryQuery+` WHERE av.id = $1`, valueID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("не удалось получить значение атрибута: %v", err)
+	}
+	return &entry, nil
+}
+
+// FindAttributeValueID возвращает ID значения атрибута с таким JSON, кроме excludeID; 0 если его нет
+func FindAttributeValueID(attributeID int, valueJSON []byte, excludeID int) (int, error) {
+	var id int
+	err := DB.QueryRow(`
+        SELECT id FROM attribute_value
+        WHERE attribute_id = $1 AND value_json = $2::jsonb AND id <> $3
+        ORDER BY id
+        LIMIT 1
+    `, attributeID, valueJSON, excludeID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("не удалось проверить значение атрибута: %v", err)
+	}
+	return id, nil
+}
+
+// CreateAttributeValueEntry добавляет значение в справочник атрибута вместе с изображениями
+func CreateAttributeValueEntry(entry *models.AttributeValueEntry) (err error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	err = tx.QueryRow(`
+        INSERT INTO attribute_value (attribute_id, value_json, value, value_numeric, sort_order, swatch_hex)
+        VALUES ($1, $2::jsonb, $2::jsonb #>> '{}', `+numericFromJSON("$2")+`, $3, NULLIF($4, ''))
+        RETURNING id
+    `, entry.AttributeID, []byte(entry.Value), entry.SortOrder, entry.SwatchHex).Scan(&entry.ID)
+	if err != nil {
+		return fmt.Errorf("не удалось создать значение атрибута: %v", err)
+	}
+
+	return setAttributeValueImagesTx(tx, entry.ID, entry.ImageURLs)
+}
+
+// UpdateAttributeValueEntry сохраняет значение справочника. Если текст значения изменился
+// с oldText, переводы значения переносятся на новый текст. Продукты сохраняют связь со значением.
+func UpdateAttributeValueEntry(entry *models.AttributeValueEntry, oldText, newText string) (err error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	_, err = tx.Exec(`
+        UPDATE attribute_value
+        SET value_json = $2::jsonb, value = $2::jsonb #>> '{}', value_numeric = `+numericFromJSON("$2")+`,
+            sort_order = $3, swatch_hex = NULLIF($4, '')
+        WHERE id = $1
+    `, entry.ID, []byte(entry.Value), entry.SortOrder, entry.SwatchHex)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить значение атрибута: %v", err)
+	}
+
+	if oldText != newText && oldText != "" && newText != "" {
+		if err = renameAttributeOptionTranslationsTx(tx, entry.AttributeID, oldText, newText); err != nil {
+			return err
+		}
+	}
+
+	return setAttributeValueImagesTx(tx, entry.ID, entry.ImageURLs)
+}
+
+// setAttributeValueImagesTx заменяет изображения значения; пустой список удаляет их
+func setAttributeValueImagesTx(tx *sql.Tx, valueID int, imageURLs []string) error {
+	if len(imageURLs) == 0 {
+		if _, err := tx.Exec(`DELETE FROM attribute_value_image WHERE attribute_value_id = $1`, valueID); err != nil {
+			return fmt.Errorf("не удалось удалить изображения значения: %v", err)
+		}
+		return nil
+	}
+	_, err := tx.Exec(`
+        INSERT INTO attribute_value_image (attribute_value_id, image_url)
+        VALUES ($1, $2)
+        ON CONFLICT (attribute_value_id) DO UPDATE SET image_url = EXCLUDED.image_url
+    `, valueID, pq.Array(imageURLs))
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить изображения значения: %v", err)
+	}
+	return nil
+}
+
+// DeleteAttributeValue удаляет значение атрибута с изображениями. Значение не должно быть выбрано у продуктов.
+func DeleteAttributeValue(valueID int) (err error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	if _, err = tx.Exec(`DELETE FROM attribute_value_image WHERE attribute_value_id = $1`, valueID); err != nil {
+		return fmt.Errorf("не удалось удалить изображения значения: %v", err)
+	}
+	if _, err = tx.Exec(`DELETE FROM attribute_value WHERE id = $1`, valueID); err != nil {
+		return fmt.Errorf("не удалось удалить значение атрибута: %v", err)
+	}
+	return nil
+}
+
+// MergeAttributeValues переводит продукты, вариации и изображения значений sourceIDs на значение
+// targetID и удаляет sourceIDs в одной транзакции
+func MergeAttributeValues(targetID int, sourceIDs []int) (err error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	sources := make([]int64, len(sourceIDs))
+	for i, id := range sourceIDs {
+		sources[i] = int64(id)
+	}
+	return mergeAttributeValuesTx(tx, int64(targetID), sources)
+}
@@ -0,0 +1,542 @@
+// internal/db/attribute_version.go
+
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/lib/pq"
+)
+
+// storedValueText текстовое представление сохранённого значения атрибута.
+// Значения продуктов хранятся в value_json, старые записи — в value.
+const storedValueText = `COALESCE(av.value_json::jsonb #>> '{}', av.value)`
+
+// attributeValueProducts связи значений атрибутов с продуктами, в том числе через вариации
+const attributeValueProducts = `
+    SELECT attribute_value_id, product_id FROM product_attribute_values
+    UNION
+    SELECT vav.attribute_value_id, pv.product_id
+    FROM variation_attribute_values vav
+    JOIN product_variation pv ON pv.id = vav.product_variation_id
+`
+
+// numericFromJSON выражение value_numeric для JSON-значения из параметра param: число или NULL
+func numericFromJSON(param string) string {
+	return `CASE WHEN jsonb_typeof(` + param + `::jsonb) = 'number' THEN (` + param + `::jsonb #>> '{}')::numeric END`
+}
+
+// GetCategoryAttributeByID возвращает атрибут по ID, nil если не найден
+func GetCategoryAttributeByID(attributeID int) (*models.CategoryAttribute, error) {
+	query := `
+        SELECT id, category_id, name, description, type_of_option, value, is_linked, is_required, sort_order, is_filterable,
+               COALESCE(dimension, ''), COALESCE(unit, '')
+        FROM attributes
+        WHERE id = $1
+    `
+
+	var attribute models.CategoryAttribute
+	err := DB.QueryRow(query, attributeID).Scan(
+		&attribute.ID,
+		&attribute.CategoryID,
+		&attribute.Name,
+		&attribute.Description,
+		&attribute.TypeOfOption,
+		&attribute.Value,
+		&attribute.IsLinked,
+		&attribute.IsRequired,
+		&attribute.SortOrder,
+		&attribute.IsFilterable,
+		&attribute.Dimension,
+		&attribute.Unit,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("не удалось получить атрибут: %v", err)
+	}
+	return &attribute, nil
+}
+
+// GetAttributeOptionUsage возвращает продукты, у которых сохранены указанные значения атрибута
+func GetAttributeOptionUsage(attributeID int, options []string) ([]models.AttributeOptionUsage, error) {
+	query := `
+        SELECT v.opt, array_agg(DISTINCT l.product_id ORDER BY l.product_id)
+        FROM (
+            SELECT av.id, ` + storedValueText + ` AS opt
+            FROM attribute_value av
+            WHERE av.attribute_id = $1
+        ) v
+        JOIN (` + attributeValueProducts + `) l ON l.attribute_value_id = v.id
+        WHERE v.opt = ANY($2)
+        GROUP BY v.opt
+        ORDER BY v.opt
+    `
+	rows, err := DB.Query(query, attributeID, pq.Array(options))
+	if err != nil {
+		log.Printf("GetAttributeOptionUsage: ошибка при выполнении запроса для attribute_id %d: %v", attributeID, err)
+		return nil, fmt.Errorf("не удалось получить использование значений атрибута: %v", err)
+	}
+	defer rows.Close()
+
+	var usage []models.AttributeOptionUsage
+	for rows.Next() {
+		var u models.AttributeOptionUsage
+		var productIDs []int64
+		if err := rows.Scan(&u.Option, pq.Array(&productIDs)); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании использования значения: %v", err)
+		}
+		for _, id := range productIDs {
+			u.ProductIDs = append(u.ProductIDs, int(id))
+		}
+		usage = append(usage, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return usage, nil
+}
+
+// CountAttributeUsage возвращает количество продуктов, у которых сохранено значение атрибута
+func CountAttributeUsage(attributeID int) (int, error) {
+	query := `
+        SELECT COUNT(DISTINCT l.product_id)
+        FROM attribute_value av
+        JOIN (` + attributeValueProducts + `) l ON l.attribute_value_id = av.id
+        WHERE av.attribute_id = $1
+    `
+	var count int
+	if err := DB.QueryRow(query, attributeID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("не удалось посчитать использование атрибута: %v", err)
+	}
+	return count, nil
+}
+
+// UpdateCategoryAttributeVersioned сохраняет новое определение атрибута как следующую версию.
+// Сохранённые значения переносятся по migrations ("старая опция" -> "новая опция") в той же транзакции.
+// unitFactor отличный от 1 пересчитывает сохранённые числовые значения при смене единицы измерения.
+func UpdateCategoryAttributeVersioned(attribute *models.CategoryAttribute, migrations map[string]string, unitFactor float64, changedBy int, summary string) (err error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	for from, to := range migrations {
+		if err = migrateAttributeOptionTx(tx, attribute.ID, from, to); err != nil {
+			return err
+		}
+	}
+
+	if unitFactor != 1 {
+		_, err = tx.Exec(`
+            UPDATE attribute_value
+            SET value_numeric = trim_scale(round(value_numeric * $2::numeric, 6))
+            WHERE attribute_id = $1 AND value_numeric IS NOT NULL
+        `, attribute.ID, unitFactor)
+		if err == nil {
+			_, err = tx.Exec(`
+                UPDATE attribute_value
+                SET value_json = to_jsonb(value_numeric), value = value_numeric::text
+                WHERE attribute_id = $1 AND value_numeric IS NOT NULL
+            `, attribute.ID)
+		}
+		if err == nil {
+			// Диапазоны range хранятся парой [от, до] без value_numeric
+			_, err = tx.Exec(`
+                UPDATE attribute_value av
+                SET value_json = r.value_json, value = r.value_json #>> '{}'
+                FROM (
+                    SELECT id, jsonb_build_array(
+                               trim_scale(round((value_json::jsonb ->> 0)::numeric * $2::numeric, 6)),
+                               trim_scale(round((value_json::jsonb ->> 1)::numeric * $2::numeric, 6))
+                           ) AS value_json
+                    FROM attribute_value
+                    WHERE attribute_id = $1 AND jsonb_typeof(value_json::jsonb) = 'array'
+                      AND jsonb_array_length(value_json::jsonb) = 2
+                ) r
+                WHERE av.id = r.id
+            `, attribute.ID, unitFactor)
+		}
+		if err != nil {
+			return fmt.Errorf("не удалось пересчитать значения в новую единицу: %v", err)
+		}
+	}
+
+	_, err = tx.Exec(`
+        UPDATE attributes
+        SET description = $1, type_of_option = $2, value = $3, dimension = NULLIF($4, ''), unit = NULLIF($5, ''),
+            is_linked = $6, version = version + 1
+        WHERE id = $7
+    `, attribute.Description, attribute.TypeOfOption, attribute.Value, attribute.Dimension, attribute.Unit, attribute.IsLinked, attribute.ID)
+	if err != nil {
+		log.Printf("UpdateCategoryAttributeVersioned: ошибка при обновлении атрибута %d: %v", attribute.ID, err)
+		return fmt.Errorf("не удалось обновить атрибут: %v", err)
+	}
+
+	return createAttributeVersion(tx, attribute.ID, changedBy, summary)
+}
+
+// CreateAttributeVersion сохраняет текущее определение атрибута как версию
+func CreateAttributeVersion(attributeID int, changedBy int, summary string) error {
+	return createAttributeVersion(DB, attributeID, changedBy, summary)
+}
+
+func createAttributeVersion(ex execer, attributeID int, changedBy int, summary string) error {
+	_, err := ex.Exec(`
+        INSERT INTO attribute_versions (attribute_id, version, name, description, type_of_option, value, dimension, unit,
+                                        changed_by, change_summary)
+        SELECT id, version, name, description, type_of_option, value::jsonb, dimension, unit, NULLIF($2, 0), $3
+        FROM attributes
+        WHERE id = $1
+    `, attributeID, changedBy, summary)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить версию атрибута: %v", err)
+	}
+	return nil
+}
+
+// GetAttributeVersions возвращает версии атрибута, начиная с последней
+func GetAttributeVersions(attributeID int) ([]models.AttributeVersion, error) {
+	query := `
+        SELECT id, attribute_id, version, name, COALESCE(description, ''), COALESCE(type_of_option, ''),
+               COALESCE(value, 'null'::jsonb), COALESCE(dimension, ''), COALESCE(unit, ''), changed_by, change_summary, created_at
+        FROM attribute_versions
+        WHERE attribute_id = $1
+        ORDER BY version DESC
+    `
+	rows, err := DB.Query(query, attributeID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить версии атрибута: %v", err)
+	}
+	defer rows.Close()
+
+	versions := []models.AttributeVersion{}
+	for rows.Next() {
+		var v models.AttributeVersion
+		var changedBy sql.NullInt64
+		var value []byte
+		err := rows.Scan(&v.ID, &v.AttributeID, &v.Version, &v.Name, &v.Description, &v.TypeOfOption,
+			&value, &v.Dimension, &v.Unit, &changedBy, &v.ChangeSummary, &v.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании версии атрибута: %v", err)
+		}
+		v.Value = value
+		if changedBy.Valid {
+			id := int(changedBy.Int64)
+			v.ChangedBy = &id
+		}
+		versions = append(versions, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return versions, nil
+}
+
+// migrateAttributeOptionTx переносит сохранённое значение from в to. Если значение to уже есть,
+// связи продуктов и вариаций переходят к нему, иначе значение from переименовывается.
+func migrateAttributeOptionTx(tx *sql.Tx, attributeID int, from, to string) error {
+	if err := renameAttributeOptionTranslationsTx(tx, attributeID, from, to); err != nil {
+		return err
+	}
+
+	var sourceIDs []int64
+	err := tx.QueryRow(`
+        SELECT COALESCE(array_agg(av.id ORDER BY av.id), '{}')
+        FROM attribute_value av
+        WHERE av.attribute_id = $1 AND `+storedValueText+` = $2
+    `, attributeID, from).Scan(pq.Array(&sourceIDs))
+	if err != nil {
+		return fmt.Errorf("не удалось найти значения '%s': %v", from, err)
+	}
+	if len(sourceIDs) == 0 {
+		return nil
+	}
+
+	var targetID int64
+	err = tx.QueryRow(`
+        SELECT av.id
+        FROM attribute_value av
+        WHERE av.attribute_id = $1 AND `+storedValueText+` = $2
+        ORDER BY av.id
+        LIMIT 1
+    `, attributeID, to).Scan(&targetID)
+	if err == sql.ErrNoRows {
+		targetID, sourceIDs = sourceIDs[0], sourceIDs[1:]
+		_, err = tx.Exec(`UPDATE attribute_value SET value_json = to_jsonb($2::text), value = $2 WHERE id = $1`, targetID, to)
+		if err != nil {
+			return fmt.Errorf("не удалось переименовать значение '%s': %v", from, err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("не удалось найти значение '%s': %v", to, err)
+	}
+
+	return mergeAttributeValuesTx(tx, targetID, sourceIDs)
+}
+
+// mergeAttributeValuesTx переводит связи продуктов, вариаций и изображения значений sourceIDs
+// на значение targetID и удаляет sourceIDs
+func mergeAttributeValuesTx(tx *sql.Tx, targetID int64, sourceIDs []int64) error {
+	if len(sourceIDs) == 0 {
+		return nil
+	}
+	sources := pq.Array(sourceIDs)
+
+	statements := []struct {
+		query string
+		args  []interface{}
+		what  string
+	}{
+		{`DELETE FROM product_attribute_values p
+          WHERE p.attribute_value_id = ANY($2)
+            AND EXISTS (SELECT 1 FROM product_attribute_values t WHERE t.product_id = p.product_id AND t.attribute_value_id = $1)`,
+			[]interface{}{targetID, sources}, "удалить повторяющиеся значения продуктов"},
+		{`UPDATE product_attribute_values SET attribute_value_id = $1 WHERE attribute_value_id = ANY($2)`,
+			[]interface{}{targetID, sources}, "перенести значения продуктов"},
+		{`DELETE FROM variation_attribute_values v
+          WHERE v.attribute_value_id = ANY($2)
+            AND EXISTS (SELECT 1 FROM variation_attribute_values t WHERE t.product_variation_id = v.product_variation_id AND t.attribute_value_id = $1)`,
+			[]interface{}{targetID, sources}, "удалить повторяющиеся значения вариаций"},
+		{`UPDATE variation_attribute_values SET attribute_value_id = $1 WHERE attribute_value_id = ANY($2)`,
+			[]interface{}{targetID, sources}, "перенести значения вариаций"},
+		{`UPDATE attribute_value_image SET attribute_value_id = $1
+          WHERE id = (
+              SELECT MIN(id) FROM attribute_value_image WHERE attribute_value_id = ANY($2)
+          )
+            AND NOT EXISTS (SELECT 1 FROM attribute_value_image WHERE attribute_value_id = $1)`,
+			[]interface{}{targetID, sources}, "перенести изображения значений"},
+		{`DELETE FROM attribute_value_image WHERE attribute_value_id = ANY($1)`,
+			[]interface{}{sources}, "удалить изображения значений"},
+		{`DELETE FROM attribute_value WHERE id = ANY($1)`,
+			[]interface{}{sources}, "удалить объединённые значения"},
+	}
+	for _, st := range statements {
+		if _, err := tx.Exec(st.query, st.args...); err != nil {
+			return fmt.Errorf("не удалось %s: %v", st.what, err)
+		}
+	}
+	return nil
+}
+
+// getStoredAttributeValuesTx возвращает все сохранённые значения атрибута в виде JSON,
+// блокируя их до конца транзакции
+func getStoredAttributeValuesTx(tx *sql.Tx, attributeID int) ([]models.StoredAttributeValue, error) {
+	rows, err := tx.Query(`
+        SELECT av.id, COALESCE(av.value_json::jsonb, to_jsonb(av.value), 'null'::jsonb)
+        FROM attribute_value av
+        WHERE av.attribute_id = $1
+        ORDER BY av.id
+        FOR UPDATE
+    `, attributeID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить значения атрибута: %v", err)
+	}
+	defer rows.Close()
+
+	var values []models.StoredAttributeValue
+	for rows.Next() {
+		var v models.StoredAttributeValue
+		var raw []byte
+		if err := rows.Scan(&v.ID, &raw); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании значения атрибута: %v", err)
+		}
+		v.Value = raw
+		values = append(values, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return values, nil
+}
+
+// CreateAttributeConversionJob создаёт задание на смену типа атрибута
+func CreateAttributeConversionJob(job *models.AttributeConversionJob) error {
+	mapping, err := json.Marshal(job.Mapping)
+	if err != nil {
+		return fmt.Errorf("не удалось сериализовать mapping: %v", err)
+	}
+	query := `
+        INSERT INTO attribute_conversion_jobs (attribute_id, from_type, to_type, value, mapping, status, created_by)
+        VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, 0))
+        RETURNING id, created_at
+    `
+	err = DB.QueryRow(query, job.AttributeID, job.FromType, job.ToType, []byte(job.Value), mapping, job.Status, job.CreatedBy).
+		Scan(&job.ID, &job.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("не удалось создать задание конвертации: %v", err)
+	}
+	return nil
+}
+
+// HasActiveAttributeConversionJob проверяет, выполняется ли уже конвертация атрибута
+func HasActiveAttributeConversionJob(attributeID int) (bool, error) {
+	var exists bool
+	err := DB.QueryRow(`
+        SELECT EXISTS (
+            SELECT 1 FROM attribute_conversion_jobs
+            WHERE attribute_id = $1 AND status IN ('pending', 'running')
+        )
+    `, attributeID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("не удалось проверить задания конвертации: %v", err)
+	}
+	return exists, nil
+}
+
+// FailInterruptedAttributeConversionJobs завершает ошибкой задания, оставшиеся в pending или running
+// после остановки сервера. Конвертация выполняется одной транзакцией, поэтому прерванное задание ничего не изменило.
+func FailInterruptedAttributeConversionJobs(message string) (int64, error) {
+	result, err := DB.Exec(`
+        UPDATE attribute_conversion_jobs
+        SET status = 'failed', error = $1, finished_at = NOW()
+        WHERE status IN ('pending', 'running')
+    `, message)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось завершить прерванные задания конвертации: %v", err)
+	}
+	return result.RowsAffected()
+}
+
+// GetAttributeConversionJob возвращает задание конвертации, nil если не найдено
+func GetAttributeConversionJob(jobID int) (*models.AttributeConversionJob, error) {
+	query := `
+        SELECT id, attribute_id, from_type, to_type, COALESCE(value, 'null'::jsonb), mapping, status,
+               converted, error, COALESCE(created_by, 0), created_at, finished_at
+        FROM attribute_conversion_jobs
+        WHERE id = $1
+    `
+	var job models.AttributeConversionJob
+	var value, mapping []byte
+	var finishedAt sql.NullTime
+	err := DB.QueryRow(query, jobID).Scan(&job.ID, &job.AttributeID, &job.FromType, &job.ToType, &value, &mapping,
+		&job.Status, &job.Converted, &job.Error, &job.CreatedBy, &job.CreatedAt, &finishedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("не удалось получить задание конвертации: %v", err)
+	}
+	job.Value = value
+	if err := json.Unmarshal(mapping, &job.Mapping); err != nil {
+		return nil, fmt.Errorf("некорректный mapping задания %d: %v", jobID, err)
+	}
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+	return &job, nil
+}
+
+// SetAttributeConversionJobStatus обновляет статус задания конвертации
+func SetAttributeConversionJobStatus(jobID int, status string, converted int, errorMessage string) error {
+	_, err := DB.Exec(`
+        UPDATE attribute_conversion_jobs
+        SET status = $2, converted = $3, error = $4,
+            finished_at = CASE WHEN $2 IN ('done', 'failed') THEN NOW() ELSE NULL END
+        WHERE id = $1
+    `, jobID, status, converted, errorMessage)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить задание конвертации: %v", err)
+	}
+	return nil
+}
+
+// ApplyAttributeConversion читает сохранённые значения атрибута, конвертирует их функцией convert,
+// записывает результат и меняет тип атрибута в одной транзакции. Атрибут и его значения заблокированы
+// до конца транзакции, поэтому конвертируются ровно те значения, которые записываются.
+// Значения, совпавшие после конвертации, объединяются. Возвращает количество сконвертированных значений.
+func ApplyAttributeConversion(job *models.AttributeConversionJob,
+	convert func([]models.StoredAttributeValue) (map[int]json.RawMessage, error)) (count int, err error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	if _, err = tx.Exec(`SELECT id FROM attributes WHERE id = $1 FOR UPDATE`, job.AttributeID); err != nil {
+		return 0, fmt.Errorf("не удалось заблокировать атрибут: %v", err)
+	}
+	values, err := getStoredAttributeValuesTx(tx, job.AttributeID)
+	if err != nil {
+		return 0, err
+	}
+	converted, err := convert(values)
+	if err != nil {
+		return 0, err
+	}
+
+	for id, value := range converted {
+		_, err = tx.Exec(`
+            UPDATE attribute_value
+            SET value_json = $2::jsonb, value = $2::jsonb #>> '{}', value_numeric = `+numericFromJSON("$2")+`
+            WHERE id = $1
+        `, id, []byte(value))
+		if err != nil {
+			return 0, fmt.Errorf("не удалось записать значение %d: %v", id, err)
+		}
+	}
+
+	rows, err := tx.Query(`
+        SELECT array_agg(av.id ORDER BY av.id)
+        FROM attribute_value av
+        WHERE av.attribute_id = $1
+        GROUP BY av.value_json::jsonb
+        HAVING COUNT(*) > 1
+    `, job.AttributeID)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось найти совпавшие значения: %v", err)
+	}
+	var groups [][]int64
+	for rows.Next() {
+		var ids []int64
+		if err = rows.Scan(pq.Array(&ids)); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("ошибка при сканировании совпавших значений: %v", err)
+		}
+		groups = append(groups, ids)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+	for _, ids := range groups {
+		if err = mergeAttributeValuesTx(tx, ids[0], ids[1:]); err != nil {
+			return 0, err
+		}
+	}
+
+	_, err = tx.Exec(`
+        UPDATE attributes SET type_of_option = $2, value = $3, version = version + 1
+        WHERE id = $1
+    `, job.AttributeID, job.ToType, []byte(job.Value))
+	if err != nil {
+		return 0, fmt.Errorf("не удалось изменить тип атрибута: %v", err)
+	}
+
+	summary := fmt.Sprintf("Тип изменён с %s на %s (задание %d)", job.FromType, job.ToType, job.ID)
+	if err = createAttributeVersion(tx, job.AttributeID, job.CreatedBy, summary); err != nil {
+		return 0, err
+	}
+	return len(converted), nil
+}
@@ -0,0 +1,166 @@
+// internal/db/auto_moderation.go
+
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/WhyDias/Marketplace/internal/models"
+)
+
+// GetModerationRules возвращает все правила автомодерации
+func GetModerationRules() ([]models.ModerationRule, error) {
+	rows, err := DB.Query(`SELECT code, enabled, action, weight, params, updated_at FROM moderation_rules ORDER BY code`)
+	if err != nil {
+		log.Printf("GetModerationRules: ошибка при выполнении запроса: %v", err)
+		return nil, fmt.Errorf("ошибка при выполнении запроса: %v", err)
+	}
+	defer rows.Close()
+
+	var rules []models.ModerationRule
+	for rows.Next() {
+		var rule models.ModerationRule
+		if err := rows.Scan(&rule.Code, &rule.Enabled, &rule.Action, &rule.Weight, &rule.Params, &rule.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании правила: %v", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return rules, nil
+}
+
+// GetModerationRule возвращает правило по коду, nil если не найдено
+func GetModerationRule(code string) (*models.ModerationRule, error) {
+	var rule models.ModerationRule
+	err := DB.QueryRow(`SELECT code, enabled, action, weight, params, updated_at FROM moderation_rules WHERE code = $1`, code).
+		Scan(&rule.Code, &rule.Enabled, &rule.Action, &rule.Weight, &rule.Params, &rule.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("не удалось получить правило: %v", err)
+	}
+	return &rule, nil
+}
+
+// UpdateModerationRule сохраняет настройки правила
+func UpdateModerationRule(rule *models.ModerationRule) error {
+	query := `
+        UPDATE moderation_rules
+        SET enabled = $1, action = $2, weight = $3, params = $4, updated_at = NOW()
+        WHERE code = $5
+        RETURNING updated_at
+    `
+	err := DB.QueryRow(query, rule.Enabled, rule.Action, rule.Weight, []byte(rule.Params), rule.Code).Scan(&rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить правило: %v", err)
+	}
+	return nil
+}
+
+// GetAutoModerationSubject собирает данные продукта для проверки правилами
+func GetAutoModerationSubject(productID int) (*models.AutoModerationSubject, error) {
+	subject := models.AutoModerationSubject{ProductID: productID}
+	err := DB.QueryRow(`
+        SELECT p.name, COALESCE(p.description, ''), COALESCE(p.price, 0), p.category_id,
+               COALESCE(s.verification_status = $2, FALSE)
+        FROM product p
+        LEFT JOIN supplier s ON s.id = p.supplier_id
+        WHERE p.id = $1
+    `, productID, models.VerificationStatusApproved).Scan(&subject.Name, &subject.Description, &subject.Price, &subject.CategoryID,
+		&subject.SupplierVerified)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить продукт: %v", err)
+	}
+
+	// Изображения самого продукта и всех его вариаций
+	err = DB.QueryRow(`
+        SELECT (SELECT COUNT(*) FROM product_images WHERE product_id = $1)
+             + (SELECT COUNT(*)
+                FROM product_variation_images vi
+                JOIN product_variation pv ON pv.id = vi.product_variation_id
+                WHERE pv.product_id = $1)
+    `, productID).Scan(&subject.ImageCount)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось посчитать изображения продукта: %v", err)
+	}
+
+	// Обязательные атрибуты категории (включая унаследованные), для которых нет значения
+	// ни у продукта, ни у его вариаций
+	rows, err := DB.Query(`
+        WITH `+effectiveAttributesCTE("$2")+`
+        SELECT a.name
+        FROM effective_attributes a
+        WHERE a.is_required
+          AND NOT EXISTS (
+              SELECT 1
+              FROM product_attribute_values pav
+              JOIN attribute_value av ON av.id = pav.attribute_value_id
+              WHERE pav.product_id = $1 AND av.attribute_id = a.id
+          )
+          AND NOT EXISTS (
+              SELECT 1
+              FROM variation_attribute_values vav
+              JOIN attribute_value av ON av.id = vav.attribute_value_id
+              JOIN product_variation pv ON pv.id = vav.product_variation_id
+              WHERE pv.product_id = $1 AND av.attribute_id = a.id
+          )
+        ORDER BY a.name
+    `, productID, subject.CategoryID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось проверить обязательные атрибуты: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании атрибута: %v", err)
+		}
+		subject.MissingRequiredAttributes = append(subject.MissingRequiredAttributes, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	// Медиана цен подтверждённых продуктов категории
+	var median sql.NullFloat64
+	err = DB.QueryRow(`
+        SELECT percentile_cont(0.5) WITHIN GROUP (ORDER BY price), COUNT(*)
+        FROM product
+        WHERE category_id = $1 AND status_id = 4 AND price > 0 AND id <> $2
+    `, subject.CategoryID, productID).Scan(&median, &subject.CategoryPriceSamples)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить медианную цену категории: %v", err)
+	}
+	subject.CategoryMedianPrice = median.Float64
+
+	return &subject, nil
+}
+
+// CreateAutoModerationResult сохраняет результат автомодерации
+func CreateAutoModerationResult(result *models.AutoModerationResult) error {
+	firedRules, err := json.Marshal(result.FiredRules)
+	if err != nil {
+		return fmt.Errorf("не удалось сериализовать сработавшие правила: %v", err)
+	}
+	if result.FiredRules == nil {
+		firedRules = []byte("[]")
+	}
+
+	query := `
+        INSERT INTO product_auto_moderation (product_id, outcome, score, fired_rules)
+        VALUES ($1, $2, $3, $4)
+        RETURNING created_at
+    `
+	err = DB.QueryRow(query, result.ProductID, result.Outcome, result.Score, firedRules).Scan(&result.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить результат автомодерации: %v", err)
+	}
+	return nil
+}
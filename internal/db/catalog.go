@@ -0,0 +1,232 @@
+// internal/db/catalog.go
+
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/WhyDias/Marketplace/internal/utils"
+)
+
+// productCardColumns столбцы карточки продукта p в порядке scanProductCard
+const productCardColumns = `
+        p.id, p.name, COALESCE(p.slug, ''), p.category_id, COALESCE(p.market_id, 0), p.supplier_id,
+        COALESCE(p.price, 0), COALESCE(img.image_url, ''),
+        EXISTS (SELECT 1 FROM supplier sv WHERE sv.id = p.supplier_id AND sv.verification_status = 'approved'),
+        NOT EXISTS (SELECT 1 FROM supplier sa WHERE sa.id = p.supplier_id AND sa.on_vacation)
+`
+
+// productVisibleCondition условие на продукт p: поставщик не скрыл продукты на время отпуска
+const productVisibleCondition = `NOT EXISTS (
+            SELECT 1 FROM supplier sh
+            WHERE sh.id = p.supplier_id AND sh.on_vacation AND sh.vacation_mode = '` + models.VacationModeHidden + `'
+        )`
+
+// productCardImageJoin основное изображение продукта p, без него — первое по порядку
+const productCardImageJoin = `
+        LEFT JOIN LATERAL (
+            SELECT image_url FROM product_images
+            WHERE product_id = p.id
+            ORDER BY is_primary DESC, position, id
+            LIMIT 1
+        ) img ON TRUE
+`
+
+// numericAttributeLower и numericAttributeUpper нижняя и верхняя граница значения av: число в value_numeric или диапазон range [от, до]
+const (
+	numericAttributeLower = `COALESCE(av.value_numeric,
+            CASE WHEN jsonb_typeof(av.value_json::jsonb) = 'array' THEN (av.value_json::jsonb ->> 0)::numeric END)`
+	numericAttributeUpper = `COALESCE(av.value_numeric,
+            CASE WHEN jsonb_typeof(av.value_json::jsonb) = 'array' THEN (av.value_json::jsonb ->> 1)::numeric END)`
+)
+
+// productAttributeValuesFrom значения атрибута из параметра param у продукта p и его вариаций
+func productAttributeValuesFrom(param string) string {
+	return `attribute_value av
+        WHERE av.attribute_id = ` + param + `
+          AND av.id IN (
+              SELECT attribute_value_id FROM product_attribute_values WHERE product_id = p.id
+              UNION ALL
+              SELECT vav.attribute_value_id
+              FROM variation_attribute_values vav
+              JOIN product_variation pv ON pv.id = vav.product_variation_id
+              WHERE pv.product_id = p.id
+          )`
+}
+
+// categorySubtreeCondition условие на категорию продукта p: категория с ID из параметра и её подкатегории
+func categorySubtreeCondition(param string) string {
+	return `p.category_id IN (
+            SELECT c.id FROM categories c
+            WHERE c.path <@ (SELECT path FROM categories WHERE id = ` + param + `)
+        )`
+}
+
+func scanProductCard(row rowScanner, card *models.ProductCard, extra ...interface{}) error {
+	dest := append([]interface{}{
+		&card.ID, &card.Name, &card.Slug, &card.CategoryID, &card.MarketID, &card.SupplierID,
+		&card.Price, &card.ImageURL, &card.VerifiedSeller, &card.Available,
+	}, extra...)
+	return row.Scan(dest...)
+}
+
+// haversineKm SQL-выражение расстояния в километрах по формуле гаверсинусов
+// от точки (latParam, lngParam) до координат в столбцах latColumn и lngColumn
+func haversineKm(latColumn, lngColumn, latParam, lngParam string) string {
+	return fmt.Sprintf(`(2 * %[5]g * ASIN(LEAST(1, SQRT(
+            POWER(SIN(RADIANS(%[1]s - %[3]s::float8) / 2), 2) +
+            COS(RADIANS(%[3]s::float8)) * COS(RADIANS(%[1]s)) * POWER(SIN(RADIANS(%[2]s - %[4]s::float8) / 2), 2)
+        ))))`, latColumn, lngColumn, latParam, lngParam, utils.EarthRadiusKm)
+}
+
+// geoQuery собирает условия поиска в радиусе: прямоугольник координат отбирается по индексу
+// idx_market_location, точное расстояние считается только для попавших в него рынков
+type geoQuery struct {
+	args []interface{}
+}
+
+func (q *geoQuery) param(value interface{}) string {
+	q.args = append(q.args, value)
+	return fmt.Sprintf("$%d", len(q.args))
+}
+
+// near возвращает выражение расстояния до рынка m и условие на прямоугольник координат
+func (q *geoQuery) near(near *models.GeoFilter) (string, string) {
+	box := utils.BoundingBoxAround(near.Lat, near.Lng, near.RadiusKm)
+	lat, lng := q.param(near.Lat), q.param(near.Lng)
+	distance := haversineKm("m.latitude", "m.longitude", lat, lng)
+	where := fmt.Sprintf("m.latitude BETWEEN %s AND %s AND m.longitude BETWEEN %s AND %s",
+		q.param(box.MinLat), q.param(box.MaxLat), q.param(box.MinLng), q.param(box.MaxLng))
+	return distance, where
+}
+
+// GetCatalogProductCards возвращает страницу одобренных продуктов и их общее количество.
+// С filter.Near продукты отбираются по рынкам в радиусе и сортируются по расстоянию.
+func GetCatalogProductCards(filter models.CatalogFilter) ([]models.ProductCard, int, error) {
+	q := &geoQuery{}
+	distance := "NULL::float8"
+	conditions := []string{"p.status_id = " + q.param(models.ProductStatusApproved), productVisibleCondition}
+	if filter.CategoryID != 0 {
+		conditions = append(conditions, categorySubtreeCondition(q.param(filter.CategoryID)))
+	}
+	for _, f := range filter.NumericAttributes {
+		condition := `EXISTS (SELECT 1 FROM ` + productAttributeValuesFrom(q.param(f.AttributeID))
+		if f.Min != nil {
+			condition += ` AND ` + numericAttributeUpper + ` >= ` + q.param(*f.Min)
+		}
+		if f.Max != nil {
+			condition += ` AND ` + numericAttributeLower + ` <= ` + q.param(*f.Max)
+		}
+		conditions = append(conditions, condition+`)`)
+	}
+	outer := "TRUE"
+	order := "id DESC"
+	if filter.Near != nil {
+		var box string
+		distance, box = q.near(filter.Near)
+		conditions = append(conditions, box)
+		outer = "distance_km <= " + q.param(filter.Near.RadiusKm)
+		order = "distance_km, id DESC"
+	}
+	sortValue := "NULL::numeric"
+	if filter.SortAttributeID != 0 {
+		// По возрастанию продукт сортируется по наименьшему значению среди вариаций, по убыванию — по наибольшему
+		if filter.SortDesc {
+			sortValue = `(SELECT MAX(` + numericAttributeUpper + `) FROM ` + productAttributeValuesFrom(q.param(filter.SortAttributeID)) + `)`
+			order = "sort_value DESC NULLS LAST, " + order
+		} else {
+			sortValue = `(SELECT MIN(` + numericAttributeLower + `) FROM ` + productAttributeValuesFrom(q.param(filter.SortAttributeID)) + `)`
+			order = "sort_value NULLS LAST, " + order
+		}
+	}
+
+	inner := `
+        SELECT ` + productCardColumns + `, ` + distance + ` AS distance_km, ` + sortValue + ` AS sort_value
+        FROM product p
+        LEFT JOIN market m ON m.id = p.market_id
+    ` + productCardImageJoin + `
+        WHERE ` + strings.Join(conditions, " AND ")
+
+	var total int
+	err := DB.QueryRow(`SELECT COUNT(*) FROM (`+inner+`) cards WHERE `+outer, q.args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("не удалось посчитать продукты каталога: %v", err)
+	}
+
+	query := `SELECT * FROM (` + inner + `) cards WHERE ` + outer +
+		` ORDER BY ` + order + ` LIMIT ` + q.param(filter.Limit) + ` OFFSET ` + q.param(filter.Offset)
+	rows, err := DB.Query(query, q.args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("не удалось получить продукты каталога: %v", err)
+	}
+	defer rows.Close()
+
+	cards := []models.ProductCard{}
+	for rows.Next() {
+		var card models.ProductCard
+		var distanceKm sql.NullFloat64
+		var sortValue sql.NullFloat64
+		if err := scanProductCard(rows, &card, &distanceKm, &sortValue); err != nil {
+			return nil, 0, fmt.Errorf("ошибка при сканировании продукта: %v", err)
+		}
+		if distanceKm.Valid {
+			card.DistanceKm = &distanceKm.Float64
+		}
+		cards = append(cards, card)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return cards, total, nil
+}
+
+// GetNearbyMarkets возвращает рынки в радиусе, ближайшие первыми. С categoryID остаются
+// только рынки с одобренными продуктами категории и её подкатегорий.
+func GetNearbyMarkets(near models.GeoFilter, categoryID int) ([]models.NearbyMarket, error) {
+	q := &geoQuery{}
+	distance, box := q.near(&near)
+	productConditions := []string{"p.market_id = m.id", "p.status_id = " + q.param(models.ProductStatusApproved), productVisibleCondition}
+	if categoryID != 0 {
+		productConditions = append(productConditions, categorySubtreeCondition(q.param(categoryID)))
+	}
+	outer := "distance_km <= " + q.param(near.RadiusKm)
+	if categoryID != 0 {
+		outer += " AND products > 0"
+	}
+
+	query := `
+        SELECT * FROM (
+            SELECT ` + marketColumns + `, ` + distance + ` AS distance_km,
+                   (SELECT COUNT(*) FROM product p WHERE ` + strings.Join(productConditions, " AND ") + `) AS products
+            FROM market m
+            WHERE ` + box + `
+        ) nearby
+        WHERE ` + outer + `
+        ORDER BY distance_km, id
+    `
+	rows, err := DB.Query(query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось найти рынки рядом: %v", err)
+	}
+	defer rows.Close()
+
+	markets := []models.NearbyMarket{}
+	for rows.Next() {
+		var market models.NearbyMarket
+		if err := scanMarket(rows, &market.Market, &market.DistanceKm, &market.Products); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании рынка: %v", err)
+		}
+		markets = append(markets, market)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return markets, nil
+}
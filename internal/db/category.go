@@ -0,0 +1,202 @@
+// internal/db/category.go
+
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/lib/pq"
+)
+
+// CategoryPathExists проверяет, занят ли path другой категорией
+func CategoryPathExists(path string) (bool, error) {
+	var exists bool
+	err := DB.QueryRow(`SELECT EXISTS (SELECT 1 FROM categories WHERE path = $1::ltree)`, path).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("не удалось проверить path категории: %v", err)
+	}
+	return exists, nil
+}
+
+// RenameCategory меняет название категории. Возвращает false, если категория не найдена.
+func RenameCategory(categoryID int, name string) (bool, error) {
+	result, err := DB.Exec(`UPDATE categories SET name = $1 WHERE id = $2`, name, categoryID)
+	if err != nil {
+		return false, fmt.Errorf("не удалось переименовать категорию: %v", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("ошибка при получении количества затронутых строк: %v", err)
+	}
+	return affected > 0, nil
+}
+
+// MoveCategorySubtree переносит категорию под нового родителя, переписывая path
+// у неё и всех подкатегорий в одной транзакции. Прежние path записываются в переадресации.
+func MoveCategorySubtree(categoryID int, oldPath, newPath string, parentID sql.NullInt64) (err error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	// Прежние path поддерева переадресуют на перенесённые категории
+	if err = recordCategoryRedirectsTx(tx, oldPath); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+        UPDATE categories
+        SET path = CASE
+                WHEN path = $1::ltree THEN $2::ltree
+                ELSE $2::ltree || subpath(path, nlevel($1::ltree))
+            END
+        WHERE path <@ $1::ltree
+    `, oldPath, newPath)
+	if err != nil {
+		log.Printf("MoveCategorySubtree: ошибка при переносе %s в %s: %v", oldPath, newPath, err)
+		return fmt.Errorf("не удалось обновить path подкатегорий: %v", err)
+	}
+
+	_, err = tx.Exec(`UPDATE categories SET parent_id = $1 WHERE id = $2`, parentID, categoryID)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить родителя категории: %v", err)
+	}
+
+	return clearCategoryRedirectsTx(tx, newPath)
+}
+
+// DeleteCategorySubtree удаляет категорию со всеми подкатегориями. Продукты и поставщики
+// переносятся в целевую категорию, атрибуты переносятся туда же: атрибут с уже существующим
+// в целевой категории названием объединяется с ним, его значения переходят к атрибуту целевой категории.
+func DeleteCategorySubtree(path string, targetID int) (result *models.CategoryDeleteResult, err error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	var ids []int64
+	err = tx.QueryRow(`SELECT COALESCE(array_agg(id), '{}') FROM categories WHERE path <@ $1::ltree`, path).
+		Scan(pq.Array(&ids))
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить подкатегории: %v", err)
+	}
+	subtree := pq.Array(ids)
+	result = &models.CategoryDeleteResult{DeletedCategories: len(ids)}
+
+	// Продукты
+	res, err := tx.Exec(`UPDATE product SET category_id = $1 WHERE category_id = ANY($2)`, targetID, subtree)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось перенести продукты: %v", err)
+	}
+	if result.ReassignedProducts, err = rowsAffected(res); err != nil {
+		return nil, err
+	}
+
+	// Поставщики: связь с целевой категорией добавляется, если её ещё нет
+	err = tx.QueryRow(`SELECT COUNT(DISTINCT supplier_id) FROM supplier_categories WHERE category_id = ANY($1)`, subtree).
+		Scan(&result.ReassignedSuppliers)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось посчитать поставщиков категории: %v", err)
+	}
+	_, err = tx.Exec(`
+        INSERT INTO supplier_categories (supplier_id, category_id)
+        SELECT DISTINCT sc.supplier_id, $1::int
+        FROM supplier_categories sc
+        WHERE sc.category_id = ANY($2)
+          AND NOT EXISTS (
+              SELECT 1 FROM supplier_categories t
+              WHERE t.supplier_id = sc.supplier_id AND t.category_id = $1
+          )
+    `, targetID, subtree)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось перенести поставщиков: %v", err)
+	}
+	if _, err = tx.Exec(`DELETE FROM supplier_categories WHERE category_id = ANY($1)`, subtree); err != nil {
+		return nil, fmt.Errorf("не удалось удалить связи поставщиков: %v", err)
+	}
+
+	// Атрибуты, которых нет в целевой категории, переносятся (по одному на название)
+	res, err = tx.Exec(`
+        UPDATE attributes SET category_id = $1
+        WHERE id IN (
+            SELECT DISTINCT ON (name) id
+            FROM attributes
+            WHERE category_id = ANY($2)
+              AND name NOT IN (SELECT name FROM attributes WHERE category_id = $1)
+            ORDER BY name, id
+        )
+    `, targetID, subtree)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось перенести атрибуты: %v", err)
+	}
+	if result.MovedAttributes, err = rowsAffected(res); err != nil {
+		return nil, err
+	}
+
+	// Значения остальных атрибутов переходят к одноимённым атрибутам целевой категории
+	_, err = tx.Exec(`
+        UPDATE attribute_value av
+        SET attribute_id = t.id
+        FROM attributes a
+        JOIN attributes t ON t.category_id = $1 AND t.name = a.name
+        WHERE av.attribute_id = a.id AND a.category_id = ANY($2)
+    `, targetID, subtree)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось перенести значения атрибутов: %v", err)
+	}
+	if _, err = tx.Exec(`DELETE FROM attributes WHERE category_id = ANY($1)`, subtree); err != nil {
+		return nil, fmt.Errorf("не удалось удалить атрибуты категорий: %v", err)
+	}
+
+	_, err = tx.Exec(`DELETE FROM image_hashes WHERE owner_type = $1 AND owner_id = ANY($2)`, models.ImageOwnerCategory, subtree)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось удалить хэши изображений категорий: %v", err)
+	}
+
+	// Адреса удалённых категорий переадресуют на целевую
+	_, err = tx.Exec(`UPDATE slug_redirects SET entity_id = $1 WHERE entity_type = $2 AND entity_id = ANY($3)`,
+		targetID, models.SlugEntityCategory, subtree)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось перенести переадресации категорий: %v", err)
+	}
+	_, err = tx.Exec(`
+        INSERT INTO slug_redirects (entity_type, old_slug, entity_id)
+        SELECT $1, path::text, $2 FROM categories WHERE id = ANY($3)
+        ON CONFLICT (entity_type, old_slug) DO UPDATE SET entity_id = EXCLUDED.entity_id, created_at = NOW()
+    `, models.SlugEntityCategory, targetID, subtree)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось записать переадресации категорий: %v", err)
+	}
+
+	if _, err = tx.Exec(`DELETE FROM categories WHERE id = ANY($1)`, subtree); err != nil {
+		log.Printf("DeleteCategorySubtree: ошибка при удалении категорий %s: %v", path, err)
+		return nil, fmt.Errorf("не удалось удалить категории: %v", err)
+	}
+
+	return result, nil
+}
+
+func rowsAffected(res sql.Result) (int, error) {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при получении количества затронутых строк: %v", err)
+	}
+	return int(affected), nil
+}
@@ -0,0 +1,108 @@
+// internal/db/category_attribute.go
+
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// effectiveAttributesCTE строит CTE effective_attributes с атрибутами, действующими для категории,
+// переданной параметром param. Категория получает атрибуты всех предков по path; из одноимённых
+// берётся атрибут ближайшей категории, поэтому подкатегория может переопределить унаследованный
+// атрибут. Атрибут, скрытый в категории ниже той, где он объявлен, не попадает в результат.
+func effectiveAttributesCTE(param string) string {
+	return `
+        effective_attributes AS (
+            SELECT r.*
+            FROM (
+                SELECT DISTINCT ON (a.name)
+                       a.id, a.name, a.category_id, a.description, a.type_of_option, a.value,
+                       a.is_linked, a.is_required, a.sort_order, a.is_filterable,
+                       COALESCE(a.dimension, '') AS dimension, COALESCE(a.unit, '') AS unit,
+                       c.path AS source_path, target.path AS target_path,
+                       a.category_id <> target.id AS inherited
+                FROM categories target
+                JOIN categories c ON target.path <@ c.path
+                JOIN attributes a ON a.category_id = c.id
+                WHERE target.id = ` + param + `
+                ORDER BY a.name, nlevel(c.path) DESC
+            ) r
+            WHERE NOT EXISTS (
+                SELECT 1
+                FROM category_hidden_attributes h
+                JOIN categories hc ON hc.id = h.category_id
+                WHERE h.attribute_name = r.name
+                  AND r.target_path <@ hc.path
+                  AND nlevel(hc.path) > nlevel(r.source_path)
+            )
+        )`
+}
+
+// HideCategoryAttribute скрывает унаследованный атрибут у категории и её подкатегорий
+func HideCategoryAttribute(categoryID int, name string) error {
+	query := `
+        INSERT INTO category_hidden_attributes (category_id, attribute_name)
+        VALUES ($1, $2)
+        ON CONFLICT (category_id, attribute_name) DO NOTHING
+    `
+	if _, err := DB.Exec(query, categoryID, name); err != nil {
+		return fmt.Errorf("не удалось скрыть атрибут: %v", err)
+	}
+	return nil
+}
+
+// UnhideCategoryAttribute снова показывает скрытый атрибут. Возвращает false, если он не был скрыт.
+func UnhideCategoryAttribute(categoryID int, name string) (bool, error) {
+	result, err := DB.Exec(`DELETE FROM category_hidden_attributes WHERE category_id = $1 AND attribute_name = $2`, categoryID, name)
+	if err != nil {
+		return false, fmt.Errorf("не удалось вернуть атрибут: %v", err)
+	}
+	affected, err := rowsAffected(result)
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// GetInheritedAttributeSourceID возвращает ID атрибута с таким названием, объявленного у предка категории,
+// 0 если такого нет
+func GetInheritedAttributeSourceID(categoryID int, name string) (int, error) {
+	query := `
+        SELECT a.id
+        FROM categories target
+        JOIN categories c ON target.path <@ c.path AND c.id <> target.id
+        JOIN attributes a ON a.category_id = c.id
+        WHERE target.id = $1 AND a.name = $2
+        LIMIT 1
+    `
+	var id int
+	err := DB.QueryRow(query, categoryID, name).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("не удалось найти унаследованный атрибут: %v", err)
+	}
+	return id, nil
+}
+
+// UpdateAttributeFlags меняет флаги атрибута; nil оставляет флаг прежним.
+// Возвращает false, если атрибут не найден.
+func UpdateAttributeFlags(attributeID int, isRequired *bool, sortOrder *int, isFilterable *bool) (bool, error) {
+	result, err := DB.Exec(`
+        UPDATE attributes
+        SET is_required = COALESCE($2, is_required),
+            sort_order = COALESCE($3, sort_order),
+            is_filterable = COALESCE($4, is_filterable)
+        WHERE id = $1
+    `, attributeID, isRequired, sortOrder, isFilterable)
+	if err != nil {
+		return false, fmt.Errorf("не удалось обновить флаги атрибута: %v", err)
+	}
+	affected, err := rowsAffected(result)
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
@@ -0,0 +1,290 @@
+// internal/db/color.go
+
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/lib/pq"
+)
+
+// GetColorFamilies возвращает семейства цветов в порядке вывода
+func GetColorFamilies() ([]models.ColorFamily, error) {
+	rows, err := DB.Query(`
+        SELECT code, name, COALESCE(swatch_hex, ''), sort_order
+        FROM color_families
+        ORDER BY sort_order, code
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить семейства цветов: %v", err)
+	}
+	defer rows.Close()
+
+	families := []models.ColorFamily{}
+	for rows.Next() {
+		var family models.ColorFamily
+		if err := rows.Scan(&family.Code, &family.Name, &family.SwatchHex, &family.SortOrder); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании семейства цветов: %v", err)
+		}
+		families = append(families, family)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return families, nil
+}
+
+// ColorFamilyExists проверяет, что семейство цветов есть в справочнике
+func ColorFamilyExists(code string) (bool, error) {
+	var exists bool
+	err := DB.QueryRow(`SELECT EXISTS (SELECT 1 FROM color_families WHERE code = $1)`, code).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("не удалось проверить семейство цветов: %v", err)
+	}
+	return exists, nil
+}
+
+// paletteColorQuery цвета палитры с числом вариаций
+const paletteColorQuery = `
+    SELECT c.id, c.name, COALESCE(c.code, ''), COALESCE(c.family, ''), c.sort_order,
+           (SELECT COUNT(*) FROM variation_colors vc WHERE vc.color_id = c.id)
+    FROM colors c
+`
+
+func scanPaletteColor(row interface{ Scan(...interface{}) error }) (models.PaletteColor, error) {
+	var color models.PaletteColor
+	err := row.Scan(&color.ID, &color.Name, &color.Code, &color.Family, &color.SortOrder, &color.Variations)
+	return color, err
+}
+
+// GetPaletteColors возвращает цвета палитры; family ограничивает выборку семейством
+func GetPaletteColors(family string) ([]models.PaletteColor, error) {
+	rows, err := DB.Query(paletteColorQuery+`
+        WHERE $1 = '' OR c.family = $1
+        ORDER BY c.sort_order, c.name
+    `, family)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить палитру цветов: %v", err)
+	}
+	defer rows.Close()
+
+	colors := []models.PaletteColor{}
+	for rows.Next() {
+		color, err := scanPaletteColor(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании цвета: %v", err)
+		}
+		colors = append(colors, color)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return colors, nil
+}
+
+// GetPaletteColor возвращает цвет палитры по ID, nil если его нет
+func GetPaletteColor(colorID int) (*models.PaletteColor, error) {
+	color, err := scanPaletteColor(DB.QueryRow(paletteColorQuery+` WHERE c.id = $1`, colorID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("не удалось получить цвет: %v", err)
+	}
+	return &color, nil
+}
+
+// FindPaletteColorID ищет цвет палитры по ID, иначе по названию без учёта регистра, иначе по коду.
+// Возвращает 0, если цвета нет.
+func FindPaletteColorID(color models.Color) (int, error) {
+	var id int
+	err := DB.QueryRow(`
+        SELECT id FROM colors
+        WHERE CASE
+            WHEN $1 > 0 THEN id = $1
+            WHEN $2 <> '' THEN lower(name) = lower($2)
+            ELSE code = $3
+        END
+        ORDER BY sort_order, id
+        LIMIT 1
+    `, color.ID, color.Name, color.Code).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("не удалось найти цвет: %v", err)
+	}
+	return id, nil
+}
+
+// FindPaletteColorIDByName возвращает ID цвета с таким названием, кроме excludeID; 0 если его нет
+func FindPaletteColorIDByName(name string, excludeID int) (int, error) {
+	var id int
+	err := DB.QueryRow(`SELECT id FROM colors WHERE lower(name) = lower($1) AND id <> $2`, name, excludeID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("не удалось проверить название цвета: %v", err)
+	}
+	return id, nil
+}
+
+// CreatePaletteColor добавляет цвет в палитру
+func CreatePaletteColor(color *models.PaletteColor) error {
+	err := DB.QueryRow(`
+        INSERT INTO colors (name, code, family, sort_order)
+        VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4)
+        RETURNING id
+    `, color.Name, color.Code, color.Family, color.SortOrder).Scan(&color.ID)
+	if err != nil {
+		return fmt.Errorf("не удалось создать цвет: %v", err)
+	}
+	return nil
+}
+
+// UpdatePaletteColor сохраняет цвет палитры
+func UpdatePaletteColor(color *models.PaletteColor) error {
+	_, err := DB.Exec(`
+        UPDATE colors
+        SET name = $2, code = NULLIF($3, ''), family = NULLIF($4, ''), sort_order = $5
+        WHERE id = $1
+    `, color.ID, color.Name, color.Code, color.Family, color.SortOrder)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить цвет: %v", err)
+	}
+	return nil
+}
+
+// DeletePaletteColor удаляет цвет палитры. Цвет не должен быть выбран у вариаций.
+func DeletePaletteColor(colorID int) error {
+	if _, err := DB.Exec(`DELETE FROM colors WHERE id = $1`, colorID); err != nil {
+		return fmt.Errorf("не удалось удалить цвет: %v", err)
+	}
+	return nil
+}
+
+// SetVariationColors заменяет цвета вариации
+func SetVariationColors(variationID int, colorIDs []int) (err error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	if _, err = tx.Exec(`DELETE FROM variation_colors WHERE variation_id = $1`, variationID); err != nil {
+		return fmt.Errorf("не удалось удалить цвета вариации: %v", err)
+	}
+	for _, colorID := range colorIDs {
+		if err = CreateVariationColorLinkTx(tx, variationID, colorID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetProductColorSwatches возвращает цвета вариаций продукта с ID вариаций
+func GetProductColorSwatches(productID int) ([]models.ColorSwatch, error) {
+	rows, err := DB.Query(`
+        SELECT c.id, c.name, COALESCE(c.code, ''), COALESCE(c.family, ''), array_agg(DISTINCT pv.id)
+        FROM product_variation pv
+        JOIN variation_colors vc ON vc.variation_id = pv.id
+        JOIN colors c ON c.id = vc.color_id
+        WHERE pv.product_id = $1
+        GROUP BY c.id, c.name, c.code, c.family, c.sort_order
+        ORDER BY c.sort_order, c.name
+    `, productID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить цвета продукта: %v", err)
+	}
+	defer rows.Close()
+
+	var swatches []models.ColorSwatch
+	for rows.Next() {
+		var swatch models.ColorSwatch
+		var variationIDs []int64
+		if err := rows.Scan(&swatch.ID, &swatch.Name, &swatch.Code, &swatch.Family, pq.Array(&variationIDs)); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании цвета продукта: %v", err)
+		}
+		for _, id := range variationIDs {
+			swatch.VariationIDs = append(swatch.VariationIDs, int(id))
+		}
+		swatches = append(swatches, swatch)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return swatches, nil
+}
+
+// GetColorFacets возвращает семейства и цвета одобренных продуктов с числом продуктов.
+// categoryID ограничивает выборку категорией с подкатегориями, 0 — весь каталог.
+func GetColorFacets(categoryID int) ([]models.ColorFacet, error) {
+	rows, err := DB.Query(`
+        SELECT f.code, f.name, COALESCE(f.swatch_hex, ''), c.id, c.name, COALESCE(c.code, ''),
+               COUNT(DISTINCT pv.product_id)
+        FROM variation_colors vc
+        JOIN colors c ON c.id = vc.color_id
+        JOIN color_families f ON f.code = c.family
+        JOIN product_variation pv ON pv.id = vc.variation_id
+        JOIN product p ON p.id = pv.product_id
+        WHERE p.status_id = $1
+          AND `+productVisibleCondition+`
+          AND ($2 = 0 OR p.category_id IN (
+                SELECT cat.id FROM categories cat
+                WHERE cat.path <@ (SELECT path FROM categories WHERE id = $2)
+          ))
+        GROUP BY GROUPING SETS (
+            (f.code, f.name, f.swatch_hex, f.sort_order),
+            (f.code, f.name, f.swatch_hex, f.sort_order, c.id, c.name, c.code, c.sort_order)
+        )
+        ORDER BY f.sort_order, f.code, c.id IS NOT NULL, c.sort_order, c.name
+    `, models.ProductStatusApproved, categoryID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить фасет цветов: %v", err)
+	}
+	defer rows.Close()
+
+	facets := []models.ColorFacet{}
+	for rows.Next() {
+		var family models.ColorFacet
+		var colorID sql.NullInt64
+		var colorName, colorCode sql.NullString
+		var products int
+		if err := rows.Scan(&family.Family, &family.Name, &family.SwatchHex, &colorID, &colorName, &colorCode, &products); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании фасета цветов: %v", err)
+		}
+
+		// Строка семейства идёт перед строками его цветов
+		if !colorID.Valid {
+			family.Products = products
+			family.Colors = []models.ColorFacetValue{}
+			facets = append(facets, family)
+			continue
+		}
+		last := &facets[len(facets)-1]
+		last.Colors = append(last.Colors, models.ColorFacetValue{
+			ID:       int(colorID.Int64),
+			Name:     colorName.String,
+			Code:     colorCode.String,
+			Products: products,
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return facets, nil
+}
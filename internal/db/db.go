@@ -158,10 +158,16 @@ func GetCategoryByPath(path string) (*models.Category, error) {
 	return category, nil
 }
 
+// CreateCategory создаёт категорию. parent_id определяется по path: родитель — категория
+// с path без последней метки.
 func CreateCategory(category *models.Category) error {
-	query := `INSERT INTO categories (name, path, image_url)
-	          VALUES ($1, $2, $3) RETURNING id`
-	err := DB.QueryRow(query, category.Name, category.Path, category.ImageURL).Scan(&category.ID)
+	query := `INSERT INTO categories (name, path, image_url, parent_id)
+	          VALUES ($1, $2, $3, (
+	              SELECT id FROM categories
+	              WHERE nlevel($2::ltree) > 1 AND path = subpath($2::ltree, 0, nlevel($2::ltree) - 1)
+	          ))
+	          RETURNING id, parent_id`
+	err := DB.QueryRow(query, category.Name, category.Path, category.ImageURL).Scan(&category.ID, &category.ParentID)
 	if err != nil {
 		log.Printf("CreateCategory: ошибка при выполнении запроса: %v", err)
 		return fmt.Errorf("ошибка при создании категории: %v", err)
@@ -256,6 +262,7 @@ func CreateAttributeValueImage(image *models.AttributeValueImage) error {
 	query := `
         INSERT INTO attribute_value_image (attribute_value_id, image_url)
         VALUES ($1, $2)
+        ON CONFLICT (attribute_value_id) DO UPDATE SET image_url = EXCLUDED.image_url
         RETURNING id
     `
 	err := DB.QueryRow(query, image.AttributeValueID, pq.Array(image.ImageURLs)).Scan(&image.ID)
@@ -302,12 +309,15 @@ func GetAllCategories() ([]models.Category, error) {
 
 func CreateCategoryAttribute(attribute *models.CategoryAttribute) (int, error) {
 	query := `
-		INSERT INTO attributes (category_id, name, description, type_of_option, value)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO attributes (category_id, name, description, type_of_option, value, is_linked, dimension, unit,
+		                        is_required, sort_order, is_filterable)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), $9, $10, $11)
 		RETURNING id
 	`
 	var createdAttributeID int
-	err := DB.QueryRow(query, attribute.CategoryID, attribute.Name, attribute.Description, attribute.TypeOfOption, attribute.Value).Scan(&createdAttributeID)
+	err := DB.QueryRow(query, attribute.CategoryID, attribute.Name, attribute.Description, attribute.TypeOfOption, attribute.Value,
+		attribute.IsLinked, attribute.Dimension, attribute.Unit,
+		attribute.IsRequired, attribute.SortOrder, attribute.IsFilterable).Scan(&createdAttributeID)
 	if err != nil {
 		return 0, err
 	}
@@ -353,9 +363,9 @@ func AddCategoryAttribute(attr models.CategoryAttribute) error {
 
 func CreateProduct(product *models.Product) error {
 	query := `
-		INSERT INTO product (name, category_id, market_id, status_id, supplier_id, description, price, stock)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
-	err := DB.QueryRow(query, product.Name, product.CategoryID, product.MarketID, product.StatusID, product.SupplierID, product.Description, product.Price, product.Stock).Scan(&product.ID)
+		INSERT INTO product (name, category_id, market_id, status_id, supplier_id, description, price, stock, slug)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, '')) RETURNING id`
+	err := DB.QueryRow(query, product.Name, product.CategoryID, product.MarketID, product.StatusID, product.SupplierID, product.Description, product.Price, product.Stock, product.Slug).Scan(&product.ID)
 	if err != nil {
 		return err
 	}
@@ -410,9 +420,11 @@ func GetOrCreateAttributeValue(attributeID int, value string) (int, error) {
 
 func GetCategoryAttributesByCategoryID(categoryID int) ([]models.CategoryAttribute, error) {
 	query := `
-		SELECT id, category_id, name, description, type_of_option, value, is_linked
+		SELECT id, category_id, name, description, type_of_option, value, is_linked, is_required, sort_order, is_filterable,
+		       COALESCE(dimension, ''), COALESCE(unit, '')
 		FROM attributes
 		WHERE category_id = $1
+		ORDER BY sort_order, id
 	`
 
 	rows, err := DB.Query(query, categoryID)
@@ -425,7 +437,8 @@ func GetCategoryAttributesByCategoryID(categoryID int) ([]models.CategoryAttribu
 	var attributes []models.CategoryAttribute
 	for rows.Next() {
 		var attr models.CategoryAttribute
-		err := rows.Scan(&attr.ID, &attr.CategoryID, &attr.Name, &attr.Description, &attr.TypeOfOption, &attr.Value, &attr.IsLinked)
+		err := rows.Scan(&attr.ID, &attr.CategoryID, &attr.Name, &attr.Description, &attr.TypeOfOption, &attr.Value, &attr.IsLinked,
+			&attr.IsRequired, &attr.SortOrder, &attr.IsFilterable, &attr.Dimension, &attr.Unit)
 		if err != nil {
 			log.Printf("GetCategoryAttributesByCategoryID: ошибка при сканировании строки: %v", err)
 			return nil, fmt.Errorf("ошибка при сканировании строки: %v", err)
@@ -450,12 +463,15 @@ func CreateProductVariationTx(tx *sql.Tx, variation *models.ProductVariation) er
 	return nil
 }
 
+// GetCategoryAttributes возвращает атрибуты категории вместе с унаследованными от предков
 func GetCategoryAttributes(categoryID int) ([]models.Attribute, error) {
 	var attributes []models.Attribute
 	query := `
-        SELECT id, name, description, type_of_option, value
-        FROM attributes
-        WHERE category_id = $1
+        WITH ` + effectiveAttributesCTE("$1") + `
+        SELECT id, name, description, type_of_option, value, category_id, is_linked, is_required, sort_order, is_filterable,
+               dimension, unit, inherited, source_path::text
+        FROM effective_attributes
+        ORDER BY sort_order, inherited DESC, source_path, name
     `
 	rows, err := DB.Query(query, categoryID)
 	if err != nil {
@@ -468,9 +484,12 @@ func GetCategoryAttributes(categoryID int) ([]models.Attribute, error) {
 		var description sql.NullString
 
 		// Сканирование данных
-		if err := rows.Scan(&attribute.ID, &attribute.Name, &description, &attribute.TypeOfOption, &attribute.Value); err != nil {
+		if err := rows.Scan(&attribute.ID, &attribute.Name, &description, &attribute.TypeOfOption, &attribute.Value,
+			&attribute.CategoryID, &attribute.IsLinked, &attribute.IsRequired, &attribute.SortOrder, &attribute.IsFilterable,
+			&attribute.Dimension, &attribute.Unit, &attribute.Inherited, &attribute.SourceCategoryPath); err != nil {
 			return nil, err
 		}
+		attribute.SourceCategoryID = attribute.CategoryID
 
 		// Обработка sql.NullString для Description
 		if description.Valid {
@@ -497,22 +516,24 @@ func CreateProductAttributeValue(attributeValue *models.ProductAttributeValue) e
 
 func CreateProductImage(image *models.ProductImage) error {
 	query := `
-        INSERT INTO product_images (product_id, image_urls, image_path)
-        VALUES ($1, $2, $3)
+        INSERT INTO product_images (product_id, image_url, image_path, position, alt_text, is_primary)
+        VALUES ($1, $2, $3, $4, $5, $6)
     `
-	_, err := DB.Exec(query, image.ProductID, image.ImageURLs, image.ImagePath)
+	_, err := DB.Exec(query, image.ProductID, image.ImageURL, image.ImagePath, image.Position, image.AltText, image.IsPrimary)
 	if err != nil {
 		return fmt.Errorf("ошибка при создании изображения продукта: %v", err)
 	}
 	return nil
 }
 
+// GetSupplierByUserID возвращает поставщика, в команде которого состоит пользователь
 func GetSupplierByUserID(userID int) (*models.Supplier, error) {
 	var supplier models.Supplier
 	query := `
-        SELECT id, name, market_id
-        FROM supplier
-        WHERE user_id = $1
+        SELECT s.id, s.name, s.market_id
+        FROM supplier s
+        JOIN supplier_members m ON m.supplier_id = s.id
+        WHERE m.user_id = $1
     `
 	err := DB.QueryRow(query, userID).Scan(&supplier.ID, &supplier.Name, &supplier.MarketID)
 	if err != nil {
@@ -548,11 +569,11 @@ func CreateVariationAttributeValue(variationAttributeValue *models.VariationAttr
 
 func CreateProductVariationImage(image *models.ProductVariationImage) error {
 	query := `
-		INSERT INTO product_variation_images (product_variation_id, image_urls, image_path)
-		VALUES ($1, $2, $3)
+		INSERT INTO product_variation_images (product_variation_id, image_url, image_path, position, alt_text, is_primary)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id`
 
-	err := DB.QueryRow(query, image.ProductVariationID, image.ImageURLs, image.ImagePath).Scan(&image.ID)
+	err := DB.QueryRow(query, image.ProductVariationID, image.ImageURL, image.ImagePath, image.Position, image.AltText, image.IsPrimary).Scan(&image.ID)
 	if err != nil {
 		return fmt.Errorf("ошибка при создании изображения вариации продукта: %v", err)
 	}
@@ -621,13 +642,15 @@ func UpdateAttributeValue(attributeID int, value json.RawMessage) error {
 	return err
 }
 
+// GetAttributeIDByNameAndCategory ищет атрибут среди действующих для категории, включая унаследованные
 func GetAttributeIDByNameAndCategory(attributeName string, categoryID int) (int, error) {
 	var attributeID int
 
 	query := `
+        WITH ` + effectiveAttributesCTE("$2") + `
         SELECT id
-        FROM attributes
-        WHERE name = $1 AND category_id = $2
+        FROM effective_attributes
+        WHERE name = $1
     `
 
 	err := DB.QueryRow(query, attributeName, categoryID).Scan(&attributeID)
@@ -736,12 +759,16 @@ func UpdateAttribute(attribute *models.Attribute) error {
 	return nil
 }
 
+// GetAttributesByCategoryAndIsLinked возвращает атрибуты категории с учётом унаследованных от предков
 func GetAttributesByCategoryAndIsLinked(categoryID int, isLinked bool) ([]models.Attribute, error) {
 	var attributes []models.Attribute
 
-	query := `SELECT id, name, category_id, description, type_of_option, is_linked, value
-			  FROM attributes
-			  WHERE category_id = $1 AND is_linked = $2`
+	query := `WITH ` + effectiveAttributesCTE("$1") + `
+			  SELECT id, name, category_id, description, type_of_option, is_linked, value, is_required, sort_order, is_filterable,
+			         dimension, unit, inherited, source_path::text
+			  FROM effective_attributes
+			  WHERE is_linked = $2
+			  ORDER BY sort_order, inherited DESC, source_path, name`
 
 	rows, err := DB.Query(query, categoryID, isLinked)
 	if err != nil {
@@ -752,11 +779,14 @@ func GetAttributesByCategoryAndIsLinked(categoryID int, isLinked bool) ([]models
 
 	for rows.Next() {
 		var attribute models.Attribute
-		err := rows.Scan(&attribute.ID, &attribute.Name, &attribute.CategoryID, &attribute.Description, &attribute.TypeOfOption, &attribute.IsLinked, &attribute.Value)
+		err := rows.Scan(&attribute.ID, &attribute.Name, &attribute.CategoryID, &attribute.Description, &attribute.TypeOfOption, &attribute.IsLinked, &attribute.Value,
+			&attribute.IsRequired, &attribute.SortOrder, &attribute.IsFilterable,
+			&attribute.Dimension, &attribute.Unit, &attribute.Inherited, &attribute.SourceCategoryPath)
 		if err != nil {
 			log.Printf("Ошибка при сканировании строки атрибута: %v", err)
 			return nil, fmt.Errorf("не удалось обработать данные атрибута: %v", err)
 		}
+		attribute.SourceCategoryID = attribute.CategoryID
 		attributes = append(attributes, attribute)
 	}
 
@@ -764,7 +794,12 @@ func GetAttributesByCategoryAndIsLinked(categoryID int, isLinked bool) ([]models
 }
 
 func GetCategoryAttributeByName(categoryID int, name string) (*models.CategoryAttribute, error) {
-	query := `SELECT id, category_id, name, description, type_of_option, value FROM attributes WHERE category_id = $1 AND name = $2`
+	query := `
+		SELECT id, category_id, name, description, type_of_option, value, is_linked, is_required, sort_order, is_filterable,
+		       COALESCE(dimension, ''), COALESCE(unit, '')
+		FROM attributes
+		WHERE category_id = $1 AND name = $2
+	`
 
 	var attribute models.CategoryAttribute
 	err := DB.QueryRow(query, categoryID, name).Scan(
@@ -774,6 +809,12 @@ func GetCategoryAttributeByName(categoryID int, name string) (*models.CategoryAt
 		&attribute.Description,
 		&attribute.TypeOfOption,
 		&attribute.Value,
+		&attribute.IsLinked,
+		&attribute.IsRequired,
+		&attribute.SortOrder,
+		&attribute.IsFilterable,
+		&attribute.Dimension,
+		&attribute.Unit,
 	)
 
 	if err == sql.ErrNoRows {
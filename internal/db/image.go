@@ -0,0 +1,351 @@
+// internal/db/image.go
+
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/WhyDias/Marketplace/internal/models"
+)
+
+// imageTable описывает таблицу изображений для владельца определённого типа
+type imageTable struct {
+	table       string
+	ownerColumn string
+}
+
+var imageTables = map[models.ImageOwner]imageTable{
+	models.ImageOwnerProduct:   {table: "product_images", ownerColumn: "product_id"},
+	models.ImageOwnerVariation: {table: "product_variation_images", ownerColumn: "product_variation_id"},
+}
+
+func getImageTable(owner models.ImageOwner) (imageTable, error) {
+	t, ok := imageTables[owner]
+	if !ok {
+		return imageTable{}, fmt.Errorf("неизвестный тип владельца изображения: %s", owner)
+	}
+	return t, nil
+}
+
+// GetImages возвращает изображения продукта или вариации в порядке position
+func GetImages(owner models.ImageOwner, ownerID int) ([]models.Image, error) {
+	t, err := getImageTable(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+        SELECT id, %s, image_url, position, alt_text, is_primary, variants
+        FROM %s
+        WHERE %s = $1
+        ORDER BY position, id
+    `, t.ownerColumn, t.table, t.ownerColumn)
+
+	rows, err := DB.Query(query, ownerID)
+	if err != nil {
+		log.Printf("GetImages: ошибка при выполнении запроса для %s %d: %v", owner, ownerID, err)
+		return nil, fmt.Errorf("ошибка при выполнении запроса: %v", err)
+	}
+	defer rows.Close()
+
+	var images []models.Image
+	for rows.Next() {
+		image, err := scanImage(rows)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, *image)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return images, nil
+}
+
+// GetImageByID возвращает изображение владельца по ID, nil если не найдено
+func GetImageByID(owner models.ImageOwner, ownerID int, imageID int) (*models.Image, error) {
+	t, err := getImageTable(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+        SELECT id, %s, image_url, position, alt_text, is_primary, variants
+        FROM %s
+        WHERE id = $1 AND %s = $2
+    `, t.ownerColumn, t.table, t.ownerColumn)
+
+	image, err := scanImage(DB.QueryRow(query, imageID, ownerID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("не удалось получить изображение: %v", err)
+	}
+
+	return image, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanImage читает строку изображения вместе с миниатюрами
+func scanImage(row rowScanner) (*models.Image, error) {
+	var image models.Image
+	var variantsJSON []byte
+	err := row.Scan(&image.ID, &image.OwnerID, &image.ImageURL, &image.Position, &image.AltText, &image.IsPrimary, &variantsJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(variantsJSON) > 0 {
+		if err := json.Unmarshal(variantsJSON, &image.Variants); err != nil {
+			return nil, fmt.Errorf("некорректные миниатюры изображения %d: %v", image.ID, err)
+		}
+	}
+	image.SrcSet = models.BuildSrcSet(image.Variants)
+
+	return &image, nil
+}
+
+// CreateImage добавляет изображение в конец списка.
+// Первое изображение владельца автоматически становится главным.
+func CreateImage(owner models.ImageOwner, image *models.Image) (err error) {
+	t, err := getImageTable(owner)
+	if err != nil {
+		return err
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	// Блокируем существующие изображения владельца, чтобы позиции не пересеклись
+	lockQuery := fmt.Sprintf(`SELECT id FROM %s WHERE %s = $1 FOR UPDATE`, t.table, t.ownerColumn)
+	if _, err = tx.Exec(lockQuery, image.OwnerID); err != nil {
+		return fmt.Errorf("не удалось заблокировать изображения: %v", err)
+	}
+
+	variantsJSON, err := json.Marshal(image.Variants)
+	if err != nil {
+		return fmt.Errorf("не удалось сериализовать миниатюры: %v", err)
+	}
+	if image.Variants == nil {
+		variantsJSON = []byte("[]")
+	}
+
+	query := fmt.Sprintf(`
+        INSERT INTO %[1]s (%[2]s, image_url, alt_text, variants, position, is_primary)
+        SELECT $1, $2, $3, $4,
+               COALESCE((SELECT MAX(position) + 1 FROM %[1]s WHERE %[2]s = $1), 0),
+               NOT EXISTS (SELECT 1 FROM %[1]s WHERE %[2]s = $1 AND is_primary)
+        RETURNING id, position, is_primary
+    `, t.table, t.ownerColumn)
+
+	err = tx.QueryRow(query, image.OwnerID, image.ImageURL, image.AltText, variantsJSON).Scan(&image.ID, &image.Position, &image.IsPrimary)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить изображение: %v", err)
+	}
+	image.SrcSet = models.BuildSrcSet(image.Variants)
+
+	return nil
+}
+
+// DeleteImage удаляет одно изображение, уплотняет позиции оставшихся
+// и назначает главным первое изображение, если было удалено главное
+func DeleteImage(owner models.ImageOwner, ownerID int, imageID int) (err error) {
+	t, err := getImageTable(owner)
+	if err != nil {
+		return err
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	var wasPrimary bool
+	deleteQuery := fmt.Sprintf(`DELETE FROM %s WHERE id = $1 AND %s = $2 RETURNING is_primary`, t.table, t.ownerColumn)
+	err = tx.QueryRow(deleteQuery, imageID, ownerID).Scan(&wasPrimary)
+	if err != nil {
+		return fmt.Errorf("не удалось удалить изображение: %v", err)
+	}
+
+	if err = deleteImageHashes(tx, owner, ownerID, imageID); err != nil {
+		return err
+	}
+
+	compactQuery := fmt.Sprintf(`
+        UPDATE %[1]s i
+        SET position = o.new_position
+        FROM (
+            SELECT id, ROW_NUMBER() OVER (ORDER BY position, id) - 1 AS new_position
+            FROM %[1]s
+            WHERE %[2]s = $1
+        ) o
+        WHERE i.id = o.id
+    `, t.table, t.ownerColumn)
+	if _, err = tx.Exec(compactQuery, ownerID); err != nil {
+		return fmt.Errorf("не удалось обновить позиции изображений: %v", err)
+	}
+
+	if wasPrimary {
+		promoteQuery := fmt.Sprintf(`
+            UPDATE %[1]s SET is_primary = TRUE
+            WHERE id = (SELECT id FROM %[1]s WHERE %[2]s = $1 ORDER BY position, id LIMIT 1)
+        `, t.table, t.ownerColumn)
+		if _, err = tx.Exec(promoteQuery, ownerID); err != nil {
+			return fmt.Errorf("не удалось назначить главное изображение: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// ReorderImages задаёт новый порядок изображений.
+// imageIDs должен содержать все изображения владельца ровно по одному разу.
+func ReorderImages(owner models.ImageOwner, ownerID int, imageIDs []int) (err error) {
+	t, err := getImageTable(owner)
+	if err != nil {
+		return err
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	var count int
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s = $1`, t.table, t.ownerColumn)
+	if err = tx.QueryRow(countQuery, ownerID).Scan(&count); err != nil {
+		return fmt.Errorf("не удалось получить количество изображений: %v", err)
+	}
+	if count != len(imageIDs) {
+		return fmt.Errorf("список должен содержать все %d изображений", count)
+	}
+
+	updateQuery := fmt.Sprintf(`UPDATE %s SET position = $1 WHERE id = $2 AND %s = $3`, t.table, t.ownerColumn)
+	seen := make(map[int]bool, len(imageIDs))
+	for position, imageID := range imageIDs {
+		if seen[imageID] {
+			return fmt.Errorf("изображение %d указано несколько раз", imageID)
+		}
+		seen[imageID] = true
+
+		var result sql.Result
+		result, err = tx.Exec(updateQuery, position, imageID, ownerID)
+		if err != nil {
+			return fmt.Errorf("не удалось обновить позицию изображения %d: %v", imageID, err)
+		}
+		var affected int64
+		affected, err = result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("ошибка при получении количества затронутых строк: %v", err)
+		}
+		if affected == 0 {
+			return fmt.Errorf("изображение %d не найдено", imageID)
+		}
+	}
+
+	return nil
+}
+
+// SetPrimaryImage делает изображение главным, снимая флаг с остальных
+func SetPrimaryImage(owner models.ImageOwner, ownerID int, imageID int) (err error) {
+	t, err := getImageTable(owner)
+	if err != nil {
+		return err
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	resetQuery := fmt.Sprintf(`UPDATE %s SET is_primary = FALSE WHERE %s = $1 AND is_primary`, t.table, t.ownerColumn)
+	if _, err = tx.Exec(resetQuery, ownerID); err != nil {
+		return fmt.Errorf("не удалось сбросить главное изображение: %v", err)
+	}
+
+	setQuery := fmt.Sprintf(`UPDATE %s SET is_primary = TRUE WHERE id = $1 AND %s = $2`, t.table, t.ownerColumn)
+	result, err := tx.Exec(setQuery, imageID, ownerID)
+	if err != nil {
+		return fmt.Errorf("не удалось назначить главное изображение: %v", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при получении количества затронутых строк: %v", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("изображение %d не найдено", imageID)
+	}
+
+	return nil
+}
+
+// GetImageOwnerSupplierID возвращает supplier_id продукта, которому принадлежит продукт или вариация
+func GetImageOwnerSupplierID(owner models.ImageOwner, ownerID int) (int, error) {
+	_, supplierID, err := GetImageOwnerProduct(owner, ownerID)
+	return supplierID, err
+}
+
+// GetImageOwnerProduct возвращает product_id и supplier_id для продукта или вариации, нули если не найдено
+func GetImageOwnerProduct(owner models.ImageOwner, ownerID int) (int, int, error) {
+	var query string
+	switch owner {
+	case models.ImageOwnerProduct:
+		query = `SELECT id, supplier_id FROM product WHERE id = $1`
+	case models.ImageOwnerVariation:
+		query = `
+            SELECT p.id, p.supplier_id
+            FROM product_variation pv
+            JOIN product p ON p.id = pv.product_id
+            WHERE pv.id = $1
+        `
+	default:
+		return 0, 0, fmt.Errorf("неизвестный тип владельца изображения: %s", owner)
+	}
+
+	var productID, supplierID int
+	err := DB.QueryRow(query, ownerID).Scan(&productID, &supplierID)
+	if err == sql.ErrNoRows {
+		return 0, 0, nil
+	} else if err != nil {
+		return 0, 0, fmt.Errorf("не удалось получить поставщика: %v", err)
+	}
+	return productID, supplierID, nil
+}
@@ -0,0 +1,128 @@
+// internal/db/image_hash.go
+
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/lib/pq"
+)
+
+// CreateImageHash сохраняет перцептивный хэш изображения.
+// bands — байты хэша с номером позиции, по ним ищутся похожие изображения.
+func CreateImageHash(h *models.ImageHash, bands []int64) error {
+	query := `
+        INSERT INTO image_hashes (owner_type, owner_id, image_id, image_url, hash, hash_bands, product_id, supplier_id)
+        VALUES ($1, $2, NULLIF($3, 0), $4, $5, $6, NULLIF($7, 0), NULLIF($8, 0))
+        RETURNING id
+    `
+	err := DB.QueryRow(query, h.OwnerType, h.OwnerID, h.ImageID, h.ImageURL, int64(h.Hash), pq.Array(bands), h.ProductID, h.SupplierID).Scan(&h.ID)
+	if err != nil {
+		log.Printf("CreateImageHash: ошибка при сохранении хэша для %s %d: %v", h.OwnerType, h.OwnerID, err)
+		return fmt.Errorf("не удалось сохранить хэш изображения: %v", err)
+	}
+	return nil
+}
+
+// FindImageHashCandidates возвращает хэши изображений продуктов других поставщиков,
+// у которых совпадает хотя бы один байт. Точное расстояние проверяет вызывающий код.
+func FindImageHashCandidates(bands []int64, excludeSupplierID int) ([]models.ImageHash, error) {
+	query := `
+        SELECT id, owner_type, owner_id, COALESCE(image_id, 0), image_url, hash, product_id, supplier_id
+        FROM image_hashes
+        WHERE hash_bands && $1
+          AND product_id IS NOT NULL
+          AND supplier_id IS NOT NULL
+          AND supplier_id <> $2
+    `
+	rows, err := DB.Query(query, pq.Array(bands), excludeSupplierID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при поиске похожих изображений: %v", err)
+	}
+	defer rows.Close()
+
+	var hashes []models.ImageHash
+	for rows.Next() {
+		var h models.ImageHash
+		var hash int64
+		if err := rows.Scan(&h.ID, &h.OwnerType, &h.OwnerID, &h.ImageID, &h.ImageURL, &hash, &h.ProductID, &h.SupplierID); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании хэша изображения: %v", err)
+		}
+		h.Hash = uint64(hash)
+		hashes = append(hashes, h)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return hashes, nil
+}
+
+// CreateProductDuplicateImage отмечает, что изображение продукта совпадает с изображением другого продукта
+func CreateProductDuplicateImage(productID, imageHashID, matchedProductID, matchedImageHashID, distance int) error {
+	query := `
+        INSERT INTO product_duplicate_images (product_id, image_hash_id, matched_product_id, matched_image_hash_id, distance)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (image_hash_id, matched_image_hash_id) DO NOTHING
+    `
+	_, err := DB.Exec(query, productID, imageHashID, matchedProductID, matchedImageHashID, distance)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить совпадение изображений: %v", err)
+	}
+	return nil
+}
+
+// GetProductDuplicateImages возвращает совпадения изображений продукта с продуктами других поставщиков
+func GetProductDuplicateImages(productID int) ([]models.DuplicateImageMatch, error) {
+	query := `
+        SELECT h.image_url, d.matched_product_id, p.name, p.supplier_id, mh.image_url, d.distance
+        FROM product_duplicate_images d
+        JOIN image_hashes h ON h.id = d.image_hash_id
+        JOIN image_hashes mh ON mh.id = d.matched_image_hash_id
+        JOIN product p ON p.id = d.matched_product_id
+        WHERE d.product_id = $1
+        ORDER BY d.distance, d.id
+    `
+	rows, err := DB.Query(query, productID)
+	if err != nil {
+		log.Printf("GetProductDuplicateImages: ошибка при выполнении запроса для product_id %d: %v", productID, err)
+		return nil, fmt.Errorf("ошибка при выполнении запроса: %v", err)
+	}
+	defer rows.Close()
+
+	var matches []models.DuplicateImageMatch
+	for rows.Next() {
+		var m models.DuplicateImageMatch
+		if err := rows.Scan(&m.ImageURL, &m.MatchedProductID, &m.MatchedProductName, &m.MatchedSupplierID, &m.MatchedImageURL, &m.Distance); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании совпадения: %v", err)
+		}
+		m.MatchedImagesLink = fmt.Sprintf("/api/products/%d/images", m.MatchedProductID)
+		matches = append(matches, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return matches, nil
+}
+
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// deleteImageHashes удаляет хэши изображений владельца; imageID = 0 удаляет все
+func deleteImageHashes(ex execer, owner models.ImageOwner, ownerID int, imageID int) error {
+	query := `
+        DELETE FROM image_hashes
+        WHERE owner_type = $1 AND owner_id = $2 AND ($3 = 0 OR image_id = $3)
+    `
+	if _, err := ex.Exec(query, owner, ownerID, imageID); err != nil {
+		return fmt.Errorf("не удалось удалить хэши изображений: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,390 @@
+// internal/db/market.go
+
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/lib/pq"
+)
+
+// marketColumns столбцы рынка в порядке scanMarket
+const marketColumns = `
+        m.id, m.name, COALESCE(m.address, ''), m.latitude, m.longitude,
+        COALESCE(to_char(m.opens_at, 'HH24:MI'), ''), COALESCE(to_char(m.closes_at, 'HH24:MI'), ''), m.days_off
+`
+
+func scanMarket(row rowScanner, market *models.Market, extra ...interface{}) error {
+	var latitude, longitude sql.NullFloat64
+	var daysOff pq.Int64Array
+	dest := append([]interface{}{
+		&market.ID, &market.Name, &market.Address, &latitude, &longitude,
+		&market.OpensAt, &market.ClosesAt, &daysOff,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return err
+	}
+	market.Latitude, market.Longitude = nil, nil
+	if latitude.Valid && longitude.Valid {
+		market.Latitude, market.Longitude = &latitude.Float64, &longitude.Float64
+	}
+	market.DaysOff = make([]int, len(daysOff))
+	for i, day := range daysOff {
+		market.DaysOff[i] = int(day)
+	}
+	return nil
+}
+
+// GetMarkets возвращает все рынки
+func GetMarkets() ([]models.Market, error) {
+	rows, err := DB.Query(`SELECT ` + marketColumns + ` FROM market m ORDER BY m.name, m.id`)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить рынки: %v", err)
+	}
+	defer rows.Close()
+
+	markets := []models.Market{}
+	for rows.Next() {
+		var market models.Market
+		if err := scanMarket(rows, &market); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании рынка: %v", err)
+		}
+		markets = append(markets, market)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return markets, nil
+}
+
+// GetMarketByID возвращает рынок, nil если его нет
+func GetMarketByID(marketID int) (*models.Market, error) {
+	var market models.Market
+	err := scanMarket(DB.QueryRow(`SELECT `+marketColumns+` FROM market m WHERE m.id = $1`, marketID), &market)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("не удалось получить рынок: %v", err)
+	}
+	return &market, nil
+}
+
+// marketDaysOff дни недели для столбца days_off
+func marketDaysOff(market *models.Market) pq.Int64Array {
+	days := make(pq.Int64Array, len(market.DaysOff))
+	for i, day := range market.DaysOff {
+		days[i] = int64(day)
+	}
+	return days
+}
+
+// CreateMarket добавляет рынок
+func CreateMarket(market *models.Market) error {
+	query := `
+        INSERT INTO market (name, address, latitude, longitude, opens_at, closes_at, days_off)
+        VALUES ($1, NULLIF($2, ''), $3, $4, NULLIF($5, '')::time, NULLIF($6, '')::time, $7)
+        RETURNING id
+    `
+	err := DB.QueryRow(query, market.Name, market.Address, market.Latitude, market.Longitude,
+		market.OpensAt, market.ClosesAt, marketDaysOff(market)).Scan(&market.ID)
+	if err != nil {
+		return fmt.Errorf("не удалось добавить рынок: %v", err)
+	}
+	return nil
+}
+
+// UpdateMarket сохраняет рынок
+func UpdateMarket(market *models.Market) error {
+	query := `
+        UPDATE market
+        SET name = $1, address = NULLIF($2, ''), latitude = $3, longitude = $4,
+            opens_at = NULLIF($5, '')::time, closes_at = NULLIF($6, '')::time, days_off = $7
+        WHERE id = $8
+    `
+	_, err := DB.Exec(query, market.Name, market.Address, market.Latitude, market.Longitude,
+		market.OpensAt, market.ClosesAt, marketDaysOff(market), market.ID)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить рынок: %v", err)
+	}
+	return nil
+}
+
+// MarketNameExists проверяет, есть ли другой рынок с таким названием
+func MarketNameExists(name string, excludeID int) (bool, error) {
+	var exists bool
+	err := DB.QueryRow(`SELECT EXISTS (SELECT 1 FROM market WHERE LOWER(name) = LOWER($1) AND id <> $2)`,
+		name, excludeID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("не удалось проверить название рынка: %v", err)
+	}
+	return exists, nil
+}
+
+// CountMarketUsage возвращает количество поставщиков и продуктов рынка
+func CountMarketUsage(marketID int) (int, int, error) {
+	var suppliers, products int
+	err := DB.QueryRow(`
+        SELECT (SELECT COUNT(*) FROM supplier WHERE market_id = $1),
+               (SELECT COUNT(*) FROM product WHERE market_id = $1)
+    `, marketID).Scan(&suppliers, &products)
+	if err != nil {
+		return 0, 0, fmt.Errorf("не удалось посчитать поставщиков рынка: %v", err)
+	}
+	return suppliers, products, nil
+}
+
+// DeleteMarket удаляет рынок вместе с рядами и местами
+func DeleteMarket(marketID int) error {
+	if _, err := DB.Exec(`DELETE FROM market WHERE id = $1`, marketID); err != nil {
+		return fmt.Errorf("не удалось удалить рынок: %v", err)
+	}
+	return nil
+}
+
+// GetMarketRows возвращает ряды рынка с местами и занявшими их поставщиками
+func GetMarketRows(marketID int) ([]models.MarketRow, error) {
+	query := `
+        SELECT r.id, r.market_id, r.name, r.sort_order, st.id, st.name, s.id
+        FROM market_rows r
+        LEFT JOIN market_stalls st ON st.row_id = r.id
+        LEFT JOIN supplier s ON s.stall_id = st.id
+        WHERE r.market_id = $1
+        ORDER BY r.sort_order, r.name, r.id, st.name, st.id
+    `
+	rows, err := DB.Query(query, marketID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить ряды рынка: %v", err)
+	}
+	defer rows.Close()
+
+	marketRows := []models.MarketRow{}
+	for rows.Next() {
+		var row models.MarketRow
+		var stallID, supplierID sql.NullInt64
+		var stallName sql.NullString
+		if err := rows.Scan(&row.ID, &row.MarketID, &row.Name, &row.SortOrder, &stallID, &stallName, &supplierID); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании ряда: %v", err)
+		}
+		if n := len(marketRows); n == 0 || marketRows[n-1].ID != row.ID {
+			row.Stalls = []models.MarketStall{}
+			marketRows = append(marketRows, row)
+		}
+		if stallID.Valid {
+			stall := models.MarketStall{ID: int(stallID.Int64), RowID: row.ID, Name: stallName.String}
+			if supplierID.Valid {
+				id := int(supplierID.Int64)
+				stall.SupplierID = &id
+			}
+			last := &marketRows[len(marketRows)-1]
+			last.Stalls = append(last.Stalls, stall)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return marketRows, nil
+}
+
+// GetMarketRow возвращает ряд без мест, nil если его нет
+func GetMarketRow(rowID int) (*models.MarketRow, error) {
+	var row models.MarketRow
+	err := DB.QueryRow(`SELECT id, market_id, name, sort_order FROM market_rows WHERE id = $1`, rowID).
+		Scan(&row.ID, &row.MarketID, &row.Name, &row.SortOrder)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("не удалось получить ряд: %v", err)
+	}
+	return &row, nil
+}
+
+// MarketRowNameExists проверяет, есть ли на рынке другой ряд с таким названием
+func MarketRowNameExists(marketID int, name string, excludeID int) (bool, error) {
+	var exists bool
+	err := DB.QueryRow(`SELECT EXISTS (SELECT 1 FROM market_rows WHERE market_id = $1 AND name = $2 AND id <> $3)`,
+		marketID, name, excludeID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("не удалось проверить название ряда: %v", err)
+	}
+	return exists, nil
+}
+
+// CreateMarketRow добавляет ряд на рынок
+func CreateMarketRow(row *models.MarketRow) error {
+	err := DB.QueryRow(`INSERT INTO market_rows (market_id, name, sort_order) VALUES ($1, $2, $3) RETURNING id`,
+		row.MarketID, row.Name, row.SortOrder).Scan(&row.ID)
+	if err != nil {
+		return fmt.Errorf("не удалось добавить ряд: %v", err)
+	}
+	return nil
+}
+
+// UpdateMarketRow сохраняет ряд и копирует новое название ряда поставщикам
+func UpdateMarketRow(row *models.MarketRow) (err error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	if _, err = tx.Exec(`UPDATE market_rows SET name = $1, sort_order = $2 WHERE id = $3`, row.Name, row.SortOrder, row.ID); err != nil {
+		return fmt.Errorf("не удалось обновить ряд: %v", err)
+	}
+	_, err = tx.Exec(`
+        UPDATE supplier SET row_name = $1, updated_at = NOW()
+        WHERE stall_id IN (SELECT id FROM market_stalls WHERE row_id = $2)
+    `, row.Name, row.ID)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить ряд поставщиков: %v", err)
+	}
+	return nil
+}
+
+// CountOccupiedStalls возвращает количество занятых мест в ряду
+func CountOccupiedStalls(rowID int) (int, error) {
+	var count int
+	err := DB.QueryRow(`
+        SELECT COUNT(*) FROM supplier s
+        JOIN market_stalls st ON st.id = s.stall_id
+        WHERE st.row_id = $1
+    `, rowID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось посчитать занятые места: %v", err)
+	}
+	return count, nil
+}
+
+// DeleteMarketRow удаляет ряд вместе с местами
+func DeleteMarketRow(rowID int) error {
+	if _, err := DB.Exec(`DELETE FROM market_rows WHERE id = $1`, rowID); err != nil {
+		return fmt.Errorf("не удалось удалить ряд: %v", err)
+	}
+	return nil
+}
+
+// GetExistingStallNames возвращает названия из names, которые уже есть в ряду
+func GetExistingStallNames(rowID int, names []string) ([]string, error) {
+	return queryStrings(`SELECT name FROM market_stalls WHERE row_id = $1 AND name = ANY($2) ORDER BY name`,
+		rowID, pq.Array(names))
+}
+
+// CreateMarketStalls добавляет места в ряд
+func CreateMarketStalls(rowID int, names []string) (stalls []models.MarketStall, err error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	for _, name := range names {
+		stall := models.MarketStall{RowID: rowID, Name: name}
+		if err = tx.QueryRow(`INSERT INTO market_stalls (row_id, name) VALUES ($1, $2) RETURNING id`, rowID, name).Scan(&stall.ID); err != nil {
+			return nil, fmt.Errorf("не удалось добавить место %s: %v", name, err)
+		}
+		stalls = append(stalls, stall)
+	}
+	return stalls, nil
+}
+
+// GetMarketStall возвращает место с занявшим его поставщиком, nil если места нет
+func GetMarketStall(stallID int) (*models.MarketStall, error) {
+	var stall models.MarketStall
+	var supplierID sql.NullInt64
+	err := DB.QueryRow(`
+        SELECT st.id, st.row_id, st.name, s.id
+        FROM market_stalls st
+        LEFT JOIN supplier s ON s.stall_id = st.id
+        WHERE st.id = $1
+    `, stallID).Scan(&stall.ID, &stall.RowID, &stall.Name, &supplierID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("не удалось получить место: %v", err)
+	}
+	if supplierID.Valid {
+		id := int(supplierID.Int64)
+		stall.SupplierID = &id
+	}
+	return &stall, nil
+}
+
+// UpdateMarketStall переименовывает место и копирует новое название поставщику, занявшему его
+func UpdateMarketStall(stallID int, name string) (err error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	if _, err = tx.Exec(`UPDATE market_stalls SET name = $1 WHERE id = $2`, name, stallID); err != nil {
+		return fmt.Errorf("не удалось переименовать место: %v", err)
+	}
+	if _, err = tx.Exec(`UPDATE supplier SET place_name = $1, updated_at = NOW() WHERE stall_id = $2`, name, stallID); err != nil {
+		return fmt.Errorf("не удалось обновить место поставщика: %v", err)
+	}
+	return nil
+}
+
+// DeleteMarketStall удаляет место
+func DeleteMarketStall(stallID int) error {
+	if _, err := DB.Exec(`DELETE FROM market_stalls WHERE id = $1`, stallID); err != nil {
+		return fmt.Errorf("не удалось удалить место: %v", err)
+	}
+	return nil
+}
+
+// GetRowSuppliers возвращает поставщиков, занявших места в ряду. DisplayName содержит
+// название поставщика как есть, пустое если поставщик его не указал.
+func GetRowSuppliers(rowID int) ([]models.RowSupplier, error) {
+	query := `
+        SELECT s.id, COALESCE(s.name, ''), COALESCE(s.logo_url, ''), st.id, st.name
+        FROM market_stalls st
+        JOIN supplier s ON s.stall_id = st.id
+        WHERE st.row_id = $1
+        ORDER BY st.name, st.id
+    `
+	rows, err := DB.Query(query, rowID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить поставщиков ряда: %v", err)
+	}
+	defer rows.Close()
+
+	suppliers := []models.RowSupplier{}
+	for rows.Next() {
+		var supplier models.RowSupplier
+		if err := rows.Scan(&supplier.SupplierID, &supplier.DisplayName, &supplier.LogoURL, &supplier.StallID, &supplier.StallName); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании поставщика: %v", err)
+		}
+		suppliers = append(suppliers, supplier)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return suppliers, nil
+}
@@ -0,0 +1,281 @@
+// internal/db/moderation.go
+
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/WhyDias/Marketplace/internal/models"
+)
+
+// moderationQueueWhere условия выборки очереди: $1 — market_id, $2 — category_id (0 — без фильтра)
+const moderationQueueWhere = `
+        WHERE p.status_id = 2
+          AND ($1 = 0 OR p.market_id = $1)
+          AND ($2 = 0 OR p.category_id IN (
+                SELECT c.id FROM categories c
+                WHERE c.path <@ (SELECT path FROM categories WHERE id = $2)
+          ))
+`
+
+// CountModerationQueue возвращает размер очереди и количество продуктов, ожидающих дольше sla
+func CountModerationQueue(filter models.ModerationQueueFilter, sla time.Duration) (int, int, error) {
+	query := `
+        SELECT COUNT(*),
+               COUNT(*) FILTER (WHERE p.submitted_at < NOW() - make_interval(secs => $3))
+        FROM product p
+    ` + moderationQueueWhere
+
+	var total, breaches int
+	err := DB.QueryRow(query, filter.MarketID, filter.CategoryID, int64(sla.Seconds())).Scan(&total, &breaches)
+	if err != nil {
+		log.Printf("CountModerationQueue: ошибка при выполнении запроса: %v", err)
+		return 0, 0, fmt.Errorf("ошибка при подсчёте очереди модерации: %v", err)
+	}
+	return total, breaches, nil
+}
+
+// GetModerationQueue возвращает продукты, ожидающие модерации, начиная с самых старых
+func GetModerationQueue(filter models.ModerationQueueFilter) ([]models.ModerationQueueItem, error) {
+	query := `
+        SELECT p.id, p.name, p.category_id, p.market_id, p.supplier_id, p.submitted_at,
+               EXTRACT(EPOCH FROM NOW() - p.submitted_at)::BIGINT,
+               mc.moderator_id, mc.expires_at,
+               (SELECT COUNT(*) FROM product_duplicate_images d WHERE d.product_id = p.id),
+               am.outcome, am.score, am.fired_rules, am.created_at
+        FROM product p
+        LEFT JOIN moderation_claims mc ON mc.product_id = p.id AND mc.expires_at > NOW()
+        LEFT JOIN LATERAL (
+            SELECT outcome, score, fired_rules, created_at
+            FROM product_auto_moderation
+            WHERE product_id = p.id
+            ORDER BY created_at DESC, id DESC
+            LIMIT 1
+        ) am ON TRUE
+    ` + moderationQueueWhere + `
+        ORDER BY p.submitted_at, p.id
+        LIMIT $3 OFFSET $4
+    `
+
+	rows, err := DB.Query(query, filter.MarketID, filter.CategoryID, filter.Limit, filter.Offset)
+	if err != nil {
+		log.Printf("GetModerationQueue: ошибка при выполнении запроса: %v", err)
+		return nil, fmt.Errorf("ошибка при выполнении запроса: %v", err)
+	}
+	defer rows.Close()
+
+	items := []models.ModerationQueueItem{}
+	for rows.Next() {
+		var item models.ModerationQueueItem
+		var claimedBy sql.NullInt64
+		var claimExpiresAt sql.NullTime
+		var autoOutcome sql.NullString
+		var autoScore sql.NullInt64
+		var autoFiredRules []byte
+		var autoCreatedAt sql.NullTime
+		err := rows.Scan(&item.ProductID, &item.Name, &item.CategoryID, &item.MarketID, &item.SupplierID, &item.SubmittedAt,
+			&item.TimeInQueue, &claimedBy, &claimExpiresAt, &item.DuplicateImages,
+			&autoOutcome, &autoScore, &autoFiredRules, &autoCreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании строки очереди: %v", err)
+		}
+		if autoOutcome.Valid {
+			result := &models.AutoModerationResult{
+				ProductID: item.ProductID,
+				Outcome:   autoOutcome.String,
+				Score:     int(autoScore.Int64),
+				CreatedAt: autoCreatedAt.Time,
+			}
+			if err := json.Unmarshal(autoFiredRules, &result.FiredRules); err != nil {
+				return nil, fmt.Errorf("некорректный результат автомодерации продукта %d: %v", item.ProductID, err)
+			}
+			item.AutoModeration = result
+		}
+		if claimedBy.Valid {
+			moderatorID := int(claimedBy.Int64)
+			item.ClaimedBy = &moderatorID
+		}
+		if claimExpiresAt.Valid {
+			item.ClaimExpiresAt = &claimExpiresAt.Time
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return items, nil
+}
+
+// GetProductStatusID возвращает status_id продукта, 0 если продукт не найден
+func GetProductStatusID(productID int) (int, error) {
+	var statusID int
+	err := DB.QueryRow(`SELECT status_id FROM product WHERE id = $1`, productID).Scan(&statusID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("не удалось получить статус продукта: %v", err)
+	}
+	return statusID, nil
+}
+
+// LockProductStatusIDTx блокирует продукт до конца транзакции и возвращает его статус, 0 если продукта нет
+func LockProductStatusIDTx(tx *sql.Tx, productID int) (int, error) {
+	var statusID int
+	err := tx.QueryRow(`SELECT status_id FROM product WHERE id = $1 FOR UPDATE`, productID).Scan(&statusID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("не удалось получить статус продукта: %v", err)
+	}
+	return statusID, nil
+}
+
+// ClaimProduct захватывает продукт модератором на ttl.
+// Повторный захват тем же модератором продлевает срок. Если продукт захвачен
+// другим модератором и срок не истёк, возвращает nil.
+func ClaimProduct(productID int, moderatorID int, ttl time.Duration) (*models.ModerationClaim, error) {
+	query := `
+        INSERT INTO moderation_claims (product_id, moderator_id, claimed_at, expires_at)
+        VALUES ($1, $2, NOW(), NOW() + make_interval(secs => $3))
+        ON CONFLICT (product_id) DO UPDATE
+        SET moderator_id = EXCLUDED.moderator_id,
+            claimed_at = CASE WHEN moderation_claims.moderator_id = EXCLUDED.moderator_id
+                              THEN moderation_claims.claimed_at ELSE EXCLUDED.claimed_at END,
+            expires_at = EXCLUDED.expires_at
+        WHERE moderation_claims.moderator_id = EXCLUDED.moderator_id
+           OR moderation_claims.expires_at <= NOW()
+        RETURNING product_id, moderator_id, claimed_at, expires_at
+    `
+
+	var claim models.ModerationClaim
+	err := DB.QueryRow(query, productID, moderatorID, int64(ttl.Seconds())).Scan(&claim.ProductID, &claim.ModeratorID, &claim.ClaimedAt, &claim.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("не удалось захватить продукт: %v", err)
+	}
+	return &claim, nil
+}
+
+// ReleaseProductClaim снимает захват продукта модератором. Возвращает false, если захвата не было.
+func ReleaseProductClaim(productID int, moderatorID int) (bool, error) {
+	result, err := DB.Exec(`DELETE FROM moderation_claims WHERE product_id = $1 AND moderator_id = $2`, productID, moderatorID)
+	if err != nil {
+		return false, fmt.Errorf("не удалось снять захват продукта: %v", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("ошибка при получении количества затронутых строк: %v", err)
+	}
+	return affected > 0, nil
+}
+
+// GetActiveClaimModeratorTx возвращает модератора, захватившего продукт, 0 если активного захвата нет.
+// Строка захвата блокируется до конца транзакции.
+func GetActiveClaimModeratorTx(tx *sql.Tx, productID int) (int, error) {
+	var moderatorID int
+	err := tx.QueryRow(`
+        SELECT moderator_id FROM moderation_claims
+        WHERE product_id = $1 AND expires_at > NOW()
+        FOR UPDATE
+    `, productID).Scan(&moderatorID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("не удалось проверить захват продукта: %v", err)
+	}
+	return moderatorID, nil
+}
+
+// DeleteProductClaimTx снимает захват после принятия решения по продукту
+func DeleteProductClaimTx(tx *sql.Tx, productID int) error {
+	if _, err := tx.Exec(`DELETE FROM moderation_claims WHERE product_id = $1`, productID); err != nil {
+		return fmt.Errorf("не удалось снять захват продукта: %v", err)
+	}
+	return nil
+}
+
+// moderatorUserCondition условие на пользователя u: у него роль модератора или администратора
+const moderatorUserCondition = `EXISTS (
+            SELECT 1 FROM user_roles ur JOIN roles r ON r.id = ur.role_id
+            WHERE ur.user_id = u.id AND r.name IN ('` + models.RoleModerator + `', '` + models.RoleAdmin + `')
+        )`
+
+// GetProductModerationHistory возвращает хронологию модерации продукта: смены статусов
+// и комментарии, не привязанные к смене статуса, в порядке времени
+func GetProductModerationHistory(productID int) ([]models.ModerationHistoryEntry, error) {
+	query := `
+        SELECT 'status_change', h.created_at, h.changed_by, COALESCE(u.username, ''), ` + moderatorUserCondition + `,
+               h.from_status_id, h.to_status_id, COALESCE(c.content, ''), h.id, h.comment_id
+        FROM product_status_history h
+        LEFT JOIN users u ON u.id = h.changed_by
+        LEFT JOIN comments c ON c.id = h.comment_id
+        WHERE h.product_id = $1
+        UNION ALL
+        SELECT 'comment', c.created_at, c.user_id, COALESCE(u.username, ''), ` + moderatorUserCondition + `,
+               NULL, NULL, c.content, c.id, c.id
+        FROM comments c
+        LEFT JOIN users u ON u.id = c.user_id
+        WHERE c.product_id = $1
+          AND NOT EXISTS (SELECT 1 FROM product_status_history h WHERE h.comment_id = c.id)
+        ORDER BY 2, 9
+    `
+
+	rows, err := DB.Query(query, productID)
+	if err != nil {
+		log.Printf("GetProductModerationHistory: ошибка при выполнении запроса для product_id %d: %v", productID, err)
+		return nil, fmt.Errorf("ошибка при выполнении запроса: %v", err)
+	}
+	defer rows.Close()
+
+	history := []models.ModerationHistoryEntry{}
+	var commentIDs []int
+	for rows.Next() {
+		var entry models.ModerationHistoryEntry
+		var userID, fromStatusID, toStatusID, commentID sql.NullInt64
+		var id int
+		err := rows.Scan(&entry.Type, &entry.CreatedAt, &userID, &entry.UserName, &entry.ByModerator, &fromStatusID, &toStatusID, &entry.Comment, &id, &commentID)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании записи истории: %v", err)
+		}
+		if userID.Valid {
+			v := int(userID.Int64)
+			entry.UserID = &v
+		}
+		if fromStatusID.Valid {
+			v := int(fromStatusID.Int64)
+			entry.FromStatusID = &v
+			entry.FromStatus = models.ProductStatusNames[v]
+		}
+		if toStatusID.Valid {
+			v := int(toStatusID.Int64)
+			entry.ToStatusID = &v
+			entry.ToStatus = models.ProductStatusNames[v]
+		}
+		history = append(history, entry)
+		commentIDs = append(commentIDs, int(commentID.Int64))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	// Причины отклонения привязаны к комментарию смены статуса
+	reasons, err := GetProductRejectionReasons(productID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range history {
+		if commentIDs[i] != 0 {
+			history[i].RejectionReasons = reasons[commentIDs[i]]
+		}
+	}
+
+	return history, nil
+}
@@ -96,20 +96,23 @@ func FetchProductsBySupplierAndStatus(supplierID, statusID int) ([]models.Produc
 
 func GetProductByID(productID int) (*models.Product, error) {
 	query := `
-        SELECT id, name, description, category_id, market_id, status_id, supplier_id
-        FROM product
-        WHERE id = $1
+        SELECT p.id, p.name, COALESCE(p.slug, ''), p.description, p.category_id, p.market_id, p.status_id, p.supplier_id,
+               NOT EXISTS (SELECT 1 FROM supplier sa WHERE sa.id = p.supplier_id AND sa.on_vacation)
+        FROM product p
+        WHERE p.id = $1
     `
 
 	var product models.Product
 	err := DB.QueryRow(query, productID).Scan(
 		&product.ID,
 		&product.Name,
+		&product.Slug,
 		&product.Description,
 		&product.CategoryID,
 		&product.MarketID,
 		&product.StatusID,
 		&product.SupplierID,
+		&product.Available,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("Не удалось получить продукт: %v", err)
@@ -118,53 +121,6 @@ func GetProductByID(productID int) (*models.Product, error) {
 	return &product, nil
 }
 
-type UpdateProductRequest struct {
-	Name        string                    `json:"name"`
-	Description string                    `json:"description"`
-	CategoryID  int                       `json:"category_id"`
-	Images      []string                  `json:"images"`
-	Variations  []ProductVariationRequest `json:"variations"`
-}
-
-type ProductVariationRequest struct {
-	SKU        string                  `json:"sku" binding:"required"`
-	Price      float64                 `json:"price" binding:"required"`
-	Stock      int                     `json:"stock" binding:"required"`
-	Images     []string                `json:"images"`
-	Attributes []AttributeValueRequest `json:"attributes"`
-	Colors     []Color                 `json:"colors"` // Добавили поле Colors
-}
-
-type Color struct {
-	Name string `json:"name"`
-	Code string `json:"code"`
-}
-
-type AttributeValueRequest struct {
-	Name  string `json:"name" binding:"required"`
-	Value string `json:"value" binding:"required"`
-}
-
-func GetColorIDByNameAndCode(tx *sql.Tx, name, code string) (int, error) {
-	var id int
-	query := `SELECT id FROM colors WHERE name = $1 AND code = $2`
-	err := tx.QueryRow(query, name, code).Scan(&id)
-	if err != nil {
-		return 0, err
-	}
-	return id, nil
-}
-
-func CreateColorTx(tx *sql.Tx, color models.Color) (int, error) {
-	var id int
-	query := `INSERT INTO colors (name, code) VALUES ($1, $2) RETURNING id`
-	err := tx.QueryRow(query, color.Name, color.Code).Scan(&id)
-	if err != nil {
-		return 0, fmt.Errorf("Не удалось создать цвет: %v", err)
-	}
-	return id, nil
-}
-
 func CreateVariationColorLinkTx(tx *sql.Tx, variationID, colorID int) error {
 	query := `INSERT INTO variation_colors (variation_id, color_id) VALUES ($1, $2)`
 	_, err := tx.Exec(query, variationID, colorID)
@@ -217,9 +173,19 @@ func GetProductsBySupplierAndStatus(supplierID int, statusID int) ([]models.Prod
             p.market_id, 
             p.status_id, 
             p.price, 
-            p.stock
+            p.stock,
+            COALESCE(r.content, '') AS rejection_reason,
+            r.comment_id
         FROM product p
         JOIN categories c ON p.category_id = c.id
+        LEFT JOIN LATERAL (
+            SELECT cm.content, cm.id AS comment_id
+            FROM product_status_history h
+            JOIN comments cm ON cm.id = h.comment_id
+            WHERE h.product_id = p.id AND h.to_status_id = 3
+            ORDER BY h.created_at DESC, h.id DESC
+            LIMIT 1
+        ) r ON p.status_id = 3
         WHERE p.supplier_id = $1 AND p.status_id = $2
     `
 
@@ -230,23 +196,38 @@ func GetProductsBySupplierAndStatus(supplierID int, statusID int) ([]models.Prod
 	defer rows.Close()
 
 	var products []models.Product
+	var rejectionCommentIDs []int64
 	for rows.Next() {
 		var product models.Product
+		var rejectionCommentID sql.NullInt64
 		err := rows.Scan(
 			&product.ID,
 			&product.Name,
 			&product.Description,
 			&product.CategoryID,
+			&product.CategoryName,
 			&product.SupplierID,
 			&product.MarketID,
 			&product.StatusID,
 			&product.Price,
 			&product.Stock,
+			&product.RejectionReason,
+			&rejectionCommentID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("Ошибка при чтении продукта: %v", err)
 		}
 		products = append(products, product)
+		rejectionCommentIDs = append(rejectionCommentIDs, rejectionCommentID.Int64)
+	}
+
+	// Подробные причины последнего отклонения
+	details, err := getRejectionReasonsByComments(rejectionCommentIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range products {
+		products[i].RejectionDetails = details[int(rejectionCommentIDs[i])]
 	}
 
 	return products, nil
@@ -275,8 +256,8 @@ func CreateOrGetAttributeValue(attributeID int, value interface{}) (int, error)
 	err = DB.QueryRow(query, attributeID, valueJSON).Scan(&attributeValueID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			// Значение не найдено, создаем новое
-			query = "INSERT INTO attribute_value (attribute_id, value_json) VALUES ($1, $2) RETURNING id"
+			// Значение не найдено, создаем новое. Числа дублируются в value_numeric для фильтрации и сортировки.
+			query = "INSERT INTO attribute_value (attribute_id, value_json, value_numeric) VALUES ($1, $2, " + numericFromJSON("$2") + ") RETURNING id"
 			err = DB.QueryRow(query, attributeID, valueJSON).Scan(&attributeValueID)
 			if err != nil {
 				return 0, fmt.Errorf("Ошибка при создании значения атрибута: %v", err)
@@ -289,6 +270,59 @@ func CreateOrGetAttributeValue(attributeID int, value interface{}) (int, error)
 	return attributeValueID, nil
 }
 
+// GetProductAttributeValues возвращает значения атрибутов продукта и его вариаций
+func GetProductAttributeValues(productID int) ([]models.ProductAttributeValueView, error) {
+	query := `
+        SELECT a.id, a.name, COALESCE(a.type_of_option, ''), COALESCE(a.dimension, ''), COALESCE(a.unit, ''),
+               COALESCE(av.value_json::jsonb, to_jsonb(av.value), 'null'::jsonb), av.value_numeric, v.variation_id
+        FROM (
+            SELECT attribute_value_id, NULL::int AS variation_id
+            FROM product_attribute_values
+            WHERE product_id = $1
+            UNION ALL
+            SELECT vav.attribute_value_id, pv.id
+            FROM variation_attribute_values vav
+            JOIN product_variation pv ON pv.id = vav.product_variation_id
+            WHERE pv.product_id = $1
+        ) v
+        JOIN attribute_value av ON av.id = v.attribute_value_id
+        JOIN attributes a ON a.id = av.attribute_id
+        ORDER BY v.variation_id NULLS FIRST, a.name
+    `
+	rows, err := DB.Query(query, productID)
+	if err != nil {
+		log.Printf("GetProductAttributeValues: ошибка при выполнении запроса для product_id %d: %v", productID, err)
+		return nil, fmt.Errorf("не удалось получить атрибуты продукта: %v", err)
+	}
+	defer rows.Close()
+
+	values := []models.ProductAttributeValueView{}
+	for rows.Next() {
+		var v models.ProductAttributeValueView
+		var raw []byte
+		var numeric sql.NullFloat64
+		var variationID sql.NullInt64
+		if err := rows.Scan(&v.AttributeID, &v.Name, &v.TypeOfOption, &v.Dimension, &v.Unit, &raw, &numeric, &variationID); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании атрибута продукта: %v", err)
+		}
+		v.Value = raw
+		if numeric.Valid {
+			v.Numeric = &numeric.Float64
+		}
+		if variationID.Valid {
+			id := int(variationID.Int64)
+			v.VariationID = &id
+		}
+		values = append(values, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return values, nil
+}
+
 func UpdateProduct(product *models.Product) error {
 	query := `
         UPDATE product
@@ -339,7 +373,7 @@ func DeleteVariationAttributes(variationID int) error {
 	return nil
 }
 
-func DeleteProductVariation(variationID int) error {
+func DeleteProductVariation(variationID int) (err error) {
 	// Начинаем транзакцию, чтобы удалить связанные данные
 	tx, err := DB.Begin()
 	if err != nil {
@@ -349,7 +383,7 @@ func DeleteProductVariation(variationID int) error {
 		if err != nil {
 			tx.Rollback()
 		} else {
-			tx.Commit()
+			err = tx.Commit()
 		}
 	}()
 
@@ -359,12 +393,23 @@ func DeleteProductVariation(variationID int) error {
 		return fmt.Errorf("не удалось удалить атрибуты вариации: %v", err)
 	}
 
+	// Удаляем цвета вариации
+	_, err = tx.Exec(`DELETE FROM variation_colors WHERE variation_id = $1`, variationID)
+	if err != nil {
+		return fmt.Errorf("не удалось удалить цвета вариации: %v", err)
+	}
+
 	// Удаляем изображения вариации
 	_, err = tx.Exec(`DELETE FROM product_variation_images WHERE product_variation_id = $1`, variationID)
 	if err != nil {
 		return fmt.Errorf("не удалось удалить изображения вариации: %v", err)
 	}
 
+	// Хеши изображений ссылаются на продукт, а не на вариацию, и каскадно не удаляются
+	if err = deleteImageHashes(tx, models.ImageOwnerVariation, variationID, 0); err != nil {
+		return err
+	}
+
 	// Удаляем саму вариацию
 	_, err = tx.Exec(`DELETE FROM product_variation WHERE id = $1`, variationID)
 	if err != nil {
@@ -375,24 +420,16 @@ func DeleteProductVariation(variationID int) error {
 }
 
 func DeleteVariationImages(variationID int) error {
-	// Получаем ссылки на изображения для удаления из хранилища, если необходимо
-	query := `
-        SELECT image_urls
-        FROM product_variation_images
-        WHERE product_variation_id = $1
-    `
-	var imagesJSON string
-	err := DB.QueryRow(query, variationID).Scan(&imagesJSON)
-	if err != nil && err != sql.ErrNoRows {
-		return fmt.Errorf("не удалось получить изображения вариации: %v", err)
-	}
-
 	// Удаляем записи из базы данных
-	_, err = DB.Exec(`DELETE FROM product_variation_images WHERE product_variation_id = $1`, variationID)
+	_, err := DB.Exec(`DELETE FROM product_variation_images WHERE product_variation_id = $1`, variationID)
 	if err != nil {
 		return fmt.Errorf("не удалось удалить изображения вариации из базы данных: %v", err)
 	}
 
+	if err := deleteImageHashes(DB, models.ImageOwnerVariation, variationID, 0); err != nil {
+		return err
+	}
+
 	// Если необходимо, можно добавить код для удаления файлов из Yandex Cloud Storage
 
 	return nil
@@ -423,17 +460,72 @@ func GetProductVariations(productID int) ([]models.ProductVariation, error) {
 	return variations, nil
 }
 
-// UpdateProductStatusTx обновляет статус продукта в транзакции
-func UpdateProductStatusTx(tx *sql.Tx, productID int, statusID int) error {
+// UpdateProductStatusTx меняет статус продукта и записывает смену в историю.
+// commentID связывает запись истории с комментарием модератора, 0 — без комментария.
+func UpdateProductStatusTx(tx *sql.Tx, productID int, statusID int, changedBy int, commentID int) error {
+	var fromStatusID int
+	err := tx.QueryRow(`SELECT status_id FROM product WHERE id = $1 FOR UPDATE`, productID).Scan(&fromStatusID)
+	if err != nil {
+		return fmt.Errorf("не удалось получить статус продукта: %v", err)
+	}
+
 	query := `
         UPDATE product
-        SET status_id = $1
+        SET status_id = $1,
+            submitted_at = CASE WHEN $1 = 2 THEN NOW() ELSE submitted_at END
         WHERE id = $2
     `
-	_, err := tx.Exec(query, statusID, productID)
+	_, err = tx.Exec(query, statusID, productID)
 	if err != nil {
 		return fmt.Errorf("не удалось обновить статус продукта: %v", err)
 	}
+
+	return insertProductStatusChange(tx, productID, fromStatusID, statusID, changedBy, commentID)
+}
+
+// ResubmitRejectedProduct возвращает отклонённый продукт на модерацию с записью в истории статусов.
+// Время поступления в очередь обновляется. Возвращает false, если продукт не отклонён.
+func ResubmitRejectedProduct(productID int, changedBy int) (resubmitted bool, err error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return false, fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	var statusID int
+	err = tx.QueryRow(`SELECT status_id FROM product WHERE id = $1 FOR UPDATE`, productID).Scan(&statusID)
+	if err != nil {
+		return false, fmt.Errorf("не удалось получить статус продукта: %v", err)
+	}
+	if statusID != models.ProductStatusRejected {
+		return false, nil
+	}
+
+	if err = UpdateProductStatusTx(tx, productID, models.ProductStatusPending, changedBy, 0); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CreateProductStatusChange записывает в историю статус нового продукта
+func CreateProductStatusChange(productID int, statusID int, changedBy int) error {
+	return insertProductStatusChange(DB, productID, 0, statusID, changedBy, 0)
+}
+
+func insertProductStatusChange(ex execer, productID, fromStatusID, toStatusID, changedBy, commentID int) error {
+	query := `
+        INSERT INTO product_status_history (product_id, from_status_id, to_status_id, changed_by, comment_id)
+        VALUES ($1, NULLIF($2, 0), $3, NULLIF($4, 0), NULLIF($5, 0))
+    `
+	if _, err := ex.Exec(query, productID, fromStatusID, toStatusID, changedBy, commentID); err != nil {
+		return fmt.Errorf("не удалось записать историю статусов продукта: %v", err)
+	}
 	return nil
 }
 
@@ -441,7 +533,7 @@ func UpdateProductStatusTx(tx *sql.Tx, productID int, statusID int) error {
 func CreateCommentTx(tx *sql.Tx, comment *models.Comment) error {
 	query := `
         INSERT INTO comments (user_id, product_id, content)
-        VALUES ($1, $2, $3)
+        VALUES (NULLIF($1, 0), $2, $3)
         RETURNING id, created_at, updated_at
     `
 	err := tx.QueryRow(query, comment.UserID, comment.ProductID, comment.Content).Scan(&comment.ID, &comment.CreatedAt, &comment.UpdatedAt)
@@ -0,0 +1,279 @@
+// internal/db/product_stats.go
+
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/WhyDias/Marketplace/internal/models"
+)
+
+// CreateProductEvent сохраняет событие продукта для статистики
+func CreateProductEvent(productID int, eventType string) error {
+	_, err := DB.Exec(`INSERT INTO product_events (product_id, event_type) VALUES ($1, $2)`, productID, eventType)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить событие продукта: %v", err)
+	}
+	return nil
+}
+
+// AddProductFavorite добавляет продукт в избранное пользователя и сохраняет событие favorite.
+// Возвращает false, если продукт уже был в избранном.
+func AddProductFavorite(userID int, productID int) (added bool, err error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return false, fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	result, err := tx.Exec(`
+        INSERT INTO product_favorites (user_id, product_id)
+        VALUES ($1, $2)
+        ON CONFLICT DO NOTHING
+    `, userID, productID)
+	if err != nil {
+		return false, fmt.Errorf("не удалось добавить продукт в избранное: %v", err)
+	}
+	inserted, err := result.RowsAffected()
+	if err != nil || inserted == 0 {
+		return false, err
+	}
+
+	_, err = tx.Exec(`INSERT INTO product_events (product_id, event_type) VALUES ($1, $2)`, productID, models.ProductEventFavorite)
+	if err != nil {
+		return false, fmt.Errorf("не удалось сохранить событие продукта: %v", err)
+	}
+	return true, nil
+}
+
+// RemoveProductFavorite убирает продукт из избранного пользователя
+func RemoveProductFavorite(userID int, productID int) error {
+	_, err := DB.Exec(`DELETE FROM product_favorites WHERE user_id = $1 AND product_id = $2`, userID, productID)
+	if err != nil {
+		return fmt.Errorf("не удалось убрать продукт из избранного: %v", err)
+	}
+	return nil
+}
+
+// GetFavoriteProductCards возвращает одобренные продукты из избранного пользователя, последние добавленные первыми
+func GetFavoriteProductCards(userID int) ([]models.ProductCard, error) {
+	query := `
+        SELECT ` + productCardColumns + `
+        FROM product_favorites f
+        JOIN product p ON p.id = f.product_id
+    ` + productCardImageJoin + `
+        WHERE f.user_id = $1 AND p.status_id = $2
+        ORDER BY f.created_at DESC, p.id DESC
+    `
+	rows, err := DB.Query(query, userID, models.ProductStatusApproved)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить избранное: %v", err)
+	}
+	defer rows.Close()
+
+	cards := []models.ProductCard{}
+	for rows.Next() {
+		var card models.ProductCard
+		if err := scanProductCard(rows, &card); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании продукта: %v", err)
+		}
+		cards = append(cards, card)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return cards, nil
+}
+
+// AggregateProductEvents переносит новые события в дневные итоги и возвращает количество обновлённых итогов.
+// События моложе settle не учитываются: BIGSERIAL выдаёт id до фиксации транзакции, и событие
+// с меньшим id может появиться позже уже учтённого.
+func AggregateProductEvents(settle time.Duration) (aggregated int64, err error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	var lastEventID int64
+	err = tx.QueryRow(`SELECT last_event_id FROM product_stats_state WHERE id = 1 FOR UPDATE`).Scan(&lastEventID)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось получить состояние агрегатора: %v", err)
+	}
+
+	var maxEventID int64
+	err = tx.QueryRow(`
+        SELECT COALESCE(MAX(id), $1)
+        FROM product_events
+        WHERE id > $1 AND created_at < NOW() - make_interval(secs => $2)
+    `, lastEventID, settle.Seconds()).Scan(&maxEventID)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось получить последнее событие: %v", err)
+	}
+
+	if maxEventID > lastEventID {
+		result, err := tx.Exec(`
+            INSERT INTO product_daily_stats (product_id, day, views, favorites, contact_clicks)
+            SELECT product_id, created_at::date,
+                   COUNT(*) FILTER (WHERE event_type = $3),
+                   COUNT(*) FILTER (WHERE event_type = $4),
+                   COUNT(*) FILTER (WHERE event_type = $5)
+            FROM product_events
+            WHERE id > $1 AND id <= $2
+            GROUP BY product_id, created_at::date
+            ON CONFLICT (product_id, day) DO UPDATE
+            SET views = product_daily_stats.views + EXCLUDED.views,
+                favorites = product_daily_stats.favorites + EXCLUDED.favorites,
+                contact_clicks = product_daily_stats.contact_clicks + EXCLUDED.contact_clicks
+        `, lastEventID, maxEventID, models.ProductEventView, models.ProductEventFavorite, models.ProductEventContactClick)
+		if err != nil {
+			return 0, fmt.Errorf("не удалось обновить дневные итоги: %v", err)
+		}
+		if aggregated, err = result.RowsAffected(); err != nil {
+			return 0, err
+		}
+	}
+
+	_, err = tx.Exec(`UPDATE product_stats_state SET last_event_id = $1, aggregated_at = NOW() WHERE id = 1`, maxEventID)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось сохранить состояние агрегатора: %v", err)
+	}
+	return aggregated, nil
+}
+
+// DeleteAggregatedProductEvents удаляет учтённые в дневных итогах события старше before
+func DeleteAggregatedProductEvents(before time.Time) (int64, error) {
+	result, err := DB.Exec(`
+        DELETE FROM product_events
+        WHERE created_at < $1
+          AND id <= (SELECT last_event_id FROM product_stats_state WHERE id = 1)
+    `, before)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось удалить старые события продуктов: %v", err)
+	}
+	return result.RowsAffected()
+}
+
+// GetProductStatsAggregatedAt возвращает время последнего запуска агрегатора, nil если он ещё не запускался
+func GetProductStatsAggregatedAt() (*time.Time, error) {
+	var aggregatedAt sql.NullTime
+	err := DB.QueryRow(`SELECT aggregated_at FROM product_stats_state WHERE id = 1`).Scan(&aggregatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("не удалось получить состояние агрегатора: %v", err)
+	}
+	if !aggregatedAt.Valid {
+		return nil, nil
+	}
+	return &aggregatedAt.Time, nil
+}
+
+// CountSupplierProductsByStatus возвращает количество продуктов поставщика по статусам
+func CountSupplierProductsByStatus(supplierID int) (map[int]int, error) {
+	rows, err := DB.Query(`
+        SELECT status_id, COUNT(*)
+        FROM product
+        WHERE supplier_id = $1
+        GROUP BY status_id
+    `, supplierID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось посчитать продукты поставщика: %v", err)
+	}
+	defer rows.Close()
+
+	counts := map[int]int{}
+	for rows.Next() {
+		var statusID, count int
+		if err := rows.Scan(&statusID, &count); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании количества продуктов: %v", err)
+		}
+		counts[statusID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return counts, nil
+}
+
+// GetLowStockVariations возвращает вариации продуктов поставщика с остатком не больше threshold, меньшие остатки первыми
+func GetLowStockVariations(supplierID int, threshold int, limit int) ([]models.LowStockVariation, error) {
+	rows, err := DB.Query(`
+        SELECT pv.id, p.id, p.name, COALESCE(pv.sku, ''), pv.stock
+        FROM product_variation pv
+        JOIN product p ON p.id = pv.product_id
+        WHERE p.supplier_id = $1 AND pv.stock <= $2
+        ORDER BY pv.stock, p.id, pv.id
+        LIMIT $3
+    `, supplierID, threshold, limit)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить вариации с малым остатком: %v", err)
+	}
+	defer rows.Close()
+
+	variations := []models.LowStockVariation{}
+	for rows.Next() {
+		var v models.LowStockVariation
+		if err := rows.Scan(&v.VariationID, &v.ProductID, &v.ProductName, &v.SKU, &v.Stock); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании вариации: %v", err)
+		}
+		variations = append(variations, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return variations, nil
+}
+
+// GetSupplierProductStats возвращает показатели всех продуктов поставщика за дни from..to включительно,
+// самые просматриваемые первыми
+func GetSupplierProductStats(supplierID int, from, to time.Time) ([]models.ProductStats, error) {
+	rows, err := DB.Query(`
+        SELECT p.id, p.name, p.status_id,
+               COALESCE(SUM(s.views), 0), COALESCE(SUM(s.favorites), 0), COALESCE(SUM(s.contact_clicks), 0)
+        FROM product p
+        LEFT JOIN product_daily_stats s ON s.product_id = p.id AND s.day BETWEEN $2 AND $3
+        WHERE p.supplier_id = $1
+        GROUP BY p.id
+        ORDER BY 4 DESC, p.id DESC
+    `, supplierID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить статистику продуктов: %v", err)
+	}
+	defer rows.Close()
+
+	stats := []models.ProductStats{}
+	for rows.Next() {
+		var s models.ProductStats
+		if err := rows.Scan(&s.ProductID, &s.Name, &s.StatusID, &s.Views, &s.Favorites, &s.ContactClicks); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании статистики продукта: %v", err)
+		}
+		stats = append(stats, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,223 @@
+// internal/db/rejection_reason.go
+
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/lib/pq"
+)
+
+// GetRejectionReasons возвращает справочник причин отклонения
+func GetRejectionReasons(activeOnly bool) ([]models.RejectionReason, error) {
+	query := `
+        SELECT id, code, title, description, is_active, sort_order
+        FROM rejection_reasons
+        WHERE is_active OR NOT $1
+        ORDER BY sort_order, id
+    `
+	rows, err := DB.Query(query, activeOnly)
+	if err != nil {
+		log.Printf("GetRejectionReasons: ошибка при выполнении запроса: %v", err)
+		return nil, fmt.Errorf("ошибка при выполнении запроса: %v", err)
+	}
+	defer rows.Close()
+
+	reasons := []models.RejectionReason{}
+	for rows.Next() {
+		var r models.RejectionReason
+		if err := rows.Scan(&r.ID, &r.Code, &r.Title, &r.Description, &r.IsActive, &r.SortOrder); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании причины отклонения: %v", err)
+		}
+		reasons = append(reasons, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return reasons, nil
+}
+
+// GetRejectionReasonsByCodes возвращает активные причины по кодам
+func GetRejectionReasonsByCodes(codes []string) (map[string]models.RejectionReason, error) {
+	query := `
+        SELECT id, code, title, description, is_active, sort_order
+        FROM rejection_reasons
+        WHERE code = ANY($1) AND is_active
+    `
+	rows, err := DB.Query(query, pq.Array(codes))
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить причины отклонения: %v", err)
+	}
+	defer rows.Close()
+
+	reasons := make(map[string]models.RejectionReason)
+	for rows.Next() {
+		var r models.RejectionReason
+		if err := rows.Scan(&r.ID, &r.Code, &r.Title, &r.Description, &r.IsActive, &r.SortOrder); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании причины отклонения: %v", err)
+		}
+		reasons[r.Code] = r
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return reasons, nil
+}
+
+// GetRejectionReasonIDByCode возвращает ID причины по коду, 0 если не найдена
+func GetRejectionReasonIDByCode(code string) (int, error) {
+	var id int
+	err := DB.QueryRow(`SELECT id FROM rejection_reasons WHERE code = $1`, code).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("не удалось получить причину отклонения: %v", err)
+	}
+	return id, nil
+}
+
+// CreateRejectionReason добавляет причину в справочник
+func CreateRejectionReason(reason *models.RejectionReason) error {
+	query := `
+        INSERT INTO rejection_reasons (code, title, description, is_active, sort_order)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id
+    `
+	err := DB.QueryRow(query, reason.Code, reason.Title, reason.Description, reason.IsActive, reason.SortOrder).Scan(&reason.ID)
+	if err != nil {
+		return fmt.Errorf("не удалось создать причину отклонения: %v", err)
+	}
+	return nil
+}
+
+// UpdateRejectionReason изменяет причину. Возвращает false, если причина не найдена.
+func UpdateRejectionReason(reason *models.RejectionReason) (bool, error) {
+	query := `
+        UPDATE rejection_reasons
+        SET code = $1, title = $2, description = $3, is_active = $4, sort_order = $5, updated_at = NOW()
+        WHERE id = $6
+    `
+	result, err := DB.Exec(query, reason.Code, reason.Title, reason.Description, reason.IsActive, reason.SortOrder, reason.ID)
+	if err != nil {
+		return false, fmt.Errorf("не удалось обновить причину отклонения: %v", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("ошибка при получении количества затронутых строк: %v", err)
+	}
+	return affected > 0, nil
+}
+
+// CreateProductRejectionReasonTx сохраняет причину отклонения продукта
+func CreateProductRejectionReasonTx(tx *sql.Tx, reason *models.ProductRejectionReason) error {
+	query := `
+        INSERT INTO product_rejection_reasons (product_id, comment_id, reason_id, field, variation_id, image_id, note)
+        VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7)
+        RETURNING id, created_at
+    `
+	err := tx.QueryRow(query, reason.ProductID, reason.CommentID, reason.ReasonID, reason.Field,
+		reason.VariationID, reason.ImageID, reason.Note).Scan(&reason.ID, &reason.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить причину отклонения: %v", err)
+	}
+	return nil
+}
+
+// GetProductRejectionReasons возвращает причины всех отклонений продукта, сгруппированные по комментарию
+func GetProductRejectionReasons(productID int) (map[int][]models.ProductRejectionReason, error) {
+	return queryProductRejectionReasons(`WHERE pr.product_id = $1`, productID)
+}
+
+// getRejectionReasonsByComments возвращает причины, привязанные к комментариям отклонения
+func getRejectionReasonsByComments(commentIDs []int64) (map[int][]models.ProductRejectionReason, error) {
+	return queryProductRejectionReasons(`WHERE pr.comment_id = ANY($1)`, pq.Array(commentIDs))
+}
+
+func queryProductRejectionReasons(where string, arg interface{}) (map[int][]models.ProductRejectionReason, error) {
+	query := `
+        SELECT pr.id, pr.product_id, pr.comment_id, pr.reason_id, r.code, r.title,
+               COALESCE(pr.field, ''), pr.variation_id, pr.image_id, pr.note, pr.created_at
+        FROM product_rejection_reasons pr
+        JOIN rejection_reasons r ON r.id = pr.reason_id
+    ` + where + `
+        ORDER BY pr.id
+    `
+	rows, err := DB.Query(query, arg)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить причины отклонения продукта: %v", err)
+	}
+	defer rows.Close()
+
+	reasons := make(map[int][]models.ProductRejectionReason)
+	for rows.Next() {
+		var r models.ProductRejectionReason
+		var variationID, imageID sql.NullInt64
+		err := rows.Scan(&r.ID, &r.ProductID, &r.CommentID, &r.ReasonID, &r.Code, &r.Title,
+			&r.Field, &variationID, &imageID, &r.Note, &r.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании причины отклонения: %v", err)
+		}
+		if variationID.Valid {
+			v := int(variationID.Int64)
+			r.VariationID = &v
+		}
+		if imageID.Valid {
+			v := int(imageID.Int64)
+			r.ImageID = &v
+		}
+		reasons[r.CommentID] = append(reasons[r.CommentID], r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return reasons, nil
+}
+
+// GetRejectionReasonStats считает, как часто указывалась каждая причина за период.
+// Нулевые from и to не ограничивают период.
+func GetRejectionReasonStats(from, to time.Time) ([]models.RejectionReasonStat, error) {
+	query := `
+        SELECT r.code, r.title, COUNT(pr.id), COUNT(DISTINCT pr.product_id)
+        FROM rejection_reasons r
+        JOIN product_rejection_reasons pr ON pr.reason_id = r.id
+        WHERE ($1::timestamp IS NULL OR pr.created_at >= $1)
+          AND ($2::timestamp IS NULL OR pr.created_at < $2)
+        GROUP BY r.id, r.code, r.title
+        ORDER BY COUNT(pr.id) DESC, r.sort_order
+    `
+	rows, err := DB.Query(query, nullTime(from), nullTime(to))
+	if err != nil {
+		log.Printf("GetRejectionReasonStats: ошибка при выполнении запроса: %v", err)
+		return nil, fmt.Errorf("ошибка при выполнении запроса: %v", err)
+	}
+	defer rows.Close()
+
+	stats := []models.RejectionReasonStat{}
+	for rows.Next() {
+		var s models.RejectionReasonStat
+		if err := rows.Scan(&s.Code, &s.Title, &s.Count, &s.Products); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании статистики: %v", err)
+		}
+		stats = append(stats, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return stats, nil
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
@@ -0,0 +1,207 @@
+// internal/db/slug.go
+
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/lib/pq"
+)
+
+// productSlugIndex уникальный индекс slug продуктов из миграции 016
+const productSlugIndex = "idx_product_slug"
+
+// IsProductSlugConflict сообщает, что запись не удалась из-за slug, который успел занять другой продукт
+func IsProductSlugConflict(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == productSlugIndex
+}
+
+// ProductSlugExists проверяет, занят ли slug другим продуктом
+func ProductSlugExists(slug string, excludeID int) (bool, error) {
+	var exists bool
+	err := DB.QueryRow(`SELECT EXISTS (SELECT 1 FROM product WHERE slug = $1 AND id <> $2)`, slug, excludeID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("не удалось проверить slug продукта: %v", err)
+	}
+	return exists, nil
+}
+
+// SetProductSlug меняет slug продукта. Прежний slug записывается в переадресации.
+func SetProductSlug(productID int, slug string) (err error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	var oldSlug string
+	if err = tx.QueryRow(`SELECT COALESCE(slug, '') FROM product WHERE id = $1`, productID).Scan(&oldSlug); err != nil {
+		return fmt.Errorf("не удалось получить slug продукта: %v", err)
+	}
+	if oldSlug == slug {
+		return nil
+	}
+
+	if oldSlug != "" {
+		_, err = tx.Exec(`
+            INSERT INTO slug_redirects (entity_type, old_slug, entity_id)
+            VALUES ($1, $2, $3)
+            ON CONFLICT (entity_type, old_slug) DO UPDATE SET entity_id = EXCLUDED.entity_id, created_at = NOW()
+        `, models.SlugEntityProduct, oldSlug, productID)
+		if err != nil {
+			return fmt.Errorf("не удалось записать переадресацию продукта: %v", err)
+		}
+	}
+	if _, err = tx.Exec(`UPDATE product SET slug = $1 WHERE id = $2`, slug, productID); err != nil {
+		return fmt.Errorf("не удалось обновить slug продукта: %w", err)
+	}
+
+	// Новый slug больше не переадресует на другой продукт
+	_, err = tx.Exec(`DELETE FROM slug_redirects WHERE entity_type = $1 AND old_slug = $2`, models.SlugEntityProduct, slug)
+	if err != nil {
+		return fmt.Errorf("не удалось удалить переадресацию: %v", err)
+	}
+	return nil
+}
+
+// GetProductIDBySlug возвращает ID продукта по slug, 0 если его нет
+func GetProductIDBySlug(slug string) (int, error) {
+	var id int
+	err := DB.QueryRow(`SELECT id FROM product WHERE slug = $1`, slug).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("не удалось найти продукт по slug: %v", err)
+	}
+	return id, nil
+}
+
+// GetProductsWithoutSlug возвращает ID и названия продуктов без slug
+func GetProductsWithoutSlug() ([]models.Product, error) {
+	rows, err := DB.Query(`SELECT id, name FROM product WHERE slug IS NULL ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить продукты без slug: %v", err)
+	}
+	defer rows.Close()
+
+	var products []models.Product
+	for rows.Next() {
+		var product models.Product
+		if err := rows.Scan(&product.ID, &product.Name); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании продукта: %v", err)
+		}
+		products = append(products, product)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return products, nil
+}
+
+// GetCategoryIDByPath возвращает ID категории по path, 0 если её нет
+func GetCategoryIDByPath(path string) (int, error) {
+	var id int
+	err := DB.QueryRow(`SELECT id FROM categories WHERE path = $1::ltree`, path).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("не удалось найти категорию по path: %v", err)
+	}
+	return id, nil
+}
+
+// recordCategoryRedirectsTx записывает текущие path категорий поддерева root в переадресации
+func recordCategoryRedirectsTx(tx *sql.Tx, root string) error {
+	_, err := tx.Exec(`
+        INSERT INTO slug_redirects (entity_type, old_slug, entity_id)
+        SELECT $1, path::text, id FROM categories WHERE path <@ $2::ltree
+        ON CONFLICT (entity_type, old_slug) DO UPDATE SET entity_id = EXCLUDED.entity_id, created_at = NOW()
+    `, models.SlugEntityCategory, root)
+	if err != nil {
+		return fmt.Errorf("не удалось записать переадресации категорий: %v", err)
+	}
+	return nil
+}
+
+// clearCategoryRedirectsTx удаляет переадресации с path, которые заняли категории поддерева root
+func clearCategoryRedirectsTx(tx *sql.Tx, root string) error {
+	_, err := tx.Exec(`
+        DELETE FROM slug_redirects
+        WHERE entity_type = $1 AND old_slug IN (SELECT path::text FROM categories WHERE path <@ $2::ltree)
+    `, models.SlugEntityCategory, root)
+	if err != nil {
+		return fmt.Errorf("не удалось удалить переадресации категорий: %v", err)
+	}
+	return nil
+}
+
+// FindSlugRedirect возвращает ID сущности, которой раньше принадлежал slug, 0 если переадресации нет
+func FindSlugRedirect(entityType, oldSlug string) (int, error) {
+	var id int
+	err := DB.QueryRow(`SELECT entity_id FROM slug_redirects WHERE entity_type = $1 AND old_slug = $2`,
+		entityType, oldSlug).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("не удалось найти переадресацию: %v", err)
+	}
+	return id, nil
+}
+
+// GetSitemapProductSlugs возвращает slug одобренных продуктов
+func GetSitemapProductSlugs() ([]string, error) {
+	return queryStrings(`
+        SELECT p.slug FROM product p
+        WHERE p.status_id = $1 AND p.slug IS NOT NULL AND `+productVisibleCondition+`
+        ORDER BY p.id
+    `, models.ProductStatusApproved)
+}
+
+// GetSitemapCategoryPaths возвращает path категорий, в поддереве которых есть одобренные продукты
+func GetSitemapCategoryPaths() ([]string, error) {
+	return queryStrings(`
+        SELECT c.path::text FROM categories c
+        WHERE EXISTS (
+            SELECT 1 FROM product p
+            JOIN categories pc ON pc.id = p.category_id
+            WHERE p.status_id = $1 AND pc.path <@ c.path AND `+productVisibleCondition+`
+        )
+        ORDER BY c.path
+    `, models.ProductStatusApproved)
+}
+
+// queryStrings выполняет запрос с одним текстовым столбцом
+func queryStrings(query string, args ...interface{}) ([]string, error) {
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось выполнить запрос: %v", err)
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании строки: %v", err)
+		}
+		values = append(values, value)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return values, nil
+}
@@ -11,20 +11,25 @@ import (
 	"github.com/WhyDias/Marketplace/internal/models"
 )
 
-// FetchSupplierByUserID получает поставщика по user_id
+// FetchSupplierByUserID получает поставщика, в команде которого состоит пользователь
 func FetchSupplierByUserID(userID int) (*models.Supplier, error) {
 	supplier := &models.Supplier{}
 
 	query := `
-        SELECT id, user_id, phone_number, is_verified, created_at, updated_at
-        FROM supplier
-        WHERE user_id = $1
-        LIMIT 1
+        SELECT s.id, COALESCE(s.user_id, 0), COALESCE(s.name, ''), COALESCE(s.market_id, 0), COALESCE(s.place_name, ''),
+               COALESCE(s.row_name, ''), s.phone_number, s.is_verified, s.created_at, s.updated_at
+        FROM supplier s
+        JOIN supplier_members m ON m.supplier_id = s.id
+        WHERE m.user_id = $1
     `
 
 	err := DB.QueryRow(query, userID).Scan(
 		&supplier.ID,
 		&supplier.UserID,
+		&supplier.Name,
+		&supplier.MarketID,
+		&supplier.PlaceName,
+		&supplier.RowName,
 		&supplier.PhoneNumber,
 		&supplier.IsVerified,
 		&supplier.CreatedAt,
@@ -43,49 +48,72 @@ func FetchSupplierByUserID(userID int) (*models.Supplier, error) {
 	return supplier, nil
 }
 
-func UpdateSupplierDetailsByUserID(userID int, marketID int, place string, rowName string, categoryIDs []int) error {
+// UpdateSupplierDetailsByUserID закрепляет за поставщиком место на рынке и заменяет его категории.
+// Если место занято другим поставщиком, ничего не меняет и возвращает ID этого поставщика.
+func UpdateSupplierDetailsByUserID(userID int, stallID int, categoryIDs []int) (occupiedBy int, err error) {
 	tx, err := DB.Begin()
 	if err != nil {
-		return fmt.Errorf("could not begin transaction: %v", err)
+		return 0, fmt.Errorf("could not begin transaction: %v", err)
 	}
 
 	defer func() {
 		if err != nil {
 			tx.Rollback()
 		} else {
-			tx.Commit()
+			err = tx.Commit()
 		}
 	}()
 
-	// Update supplier details
-	query := `UPDATE supplier SET market_id = $1, place_name = $2, row_name = $3, updated_at = $4 WHERE user_id = $5`
-	_, err = tx.Exec(query, marketID, place, rowName, time.Now(), userID)
+	// Get supplier ID
+	var supplierID int
+	err = tx.QueryRow(`SELECT supplier_id FROM supplier_members WHERE user_id = $1`, userID).Scan(&supplierID)
 	if err != nil {
-		return fmt.Errorf("could not update supplier details: %v", err)
+		return 0, fmt.Errorf("could not get supplier ID: %v", err)
 	}
 
-	// Get supplier ID
-	var supplierID int
-	err = tx.QueryRow(`SELECT id FROM supplier WHERE user_id = $1`, userID).Scan(&supplierID)
+	// Блокировка места не даёт двум поставщикам занять его одновременно
+	var marketID int
+	var place, rowName string
+	err = tx.QueryRow(`
+        SELECT r.market_id, st.name, r.name
+        FROM market_stalls st
+        JOIN market_rows r ON r.id = st.row_id
+        WHERE st.id = $1
+        FOR UPDATE OF st
+    `, stallID).Scan(&marketID, &place, &rowName)
+	if err != nil {
+		return 0, fmt.Errorf("could not lock stall: %v", err)
+	}
+	err = tx.QueryRow(`SELECT COALESCE(MIN(id), 0) FROM supplier WHERE stall_id = $1 AND id <> $2`, stallID, supplierID).Scan(&occupiedBy)
+	if err != nil {
+		return 0, fmt.Errorf("could not check stall: %v", err)
+	}
+	if occupiedBy != 0 {
+		return occupiedBy, nil
+	}
+
+	// Update supplier details
+	query := `UPDATE supplier SET stall_id = $1, market_id = $2, place_name = $3, row_name = $4, updated_at = $5 WHERE id = $6`
+	_, err = tx.Exec(query, stallID, marketID, place, rowName, time.Now(), supplierID)
 	if err != nil {
-		return fmt.Errorf("could not get supplier ID: %v", err)
+		return 0, fmt.Errorf("could not update supplier details: %v", err)
 	}
 
 	// Delete existing categories
 	_, err = tx.Exec(`DELETE FROM supplier_categories WHERE supplier_id = $1`, supplierID)
 	if err != nil {
-		return fmt.Errorf("could not delete existing categories: %v", err)
+		return 0, fmt.Errorf("could not delete existing categories: %v", err)
 	}
 
 	// Insert new categories
 	for _, categoryID := range categoryIDs {
 		_, err = tx.Exec(`INSERT INTO supplier_categories (supplier_id, category_id) VALUES ($1, $2)`, supplierID, categoryID)
 		if err != nil {
-			return fmt.Errorf("could not insert category: %v", err)
+			return 0, fmt.Errorf("could not insert category: %v", err)
 		}
 	}
 
-	return nil
+	return 0, nil
 }
 
 func GetMarketIDBySupplierID(supplierID int) (int, error) {
@@ -123,9 +151,10 @@ func GetCategoriesBySupplierID(supplierID int) ([]models.Category, error) {
 	return categories, nil
 }
 
+// GetSupplierIDByUserID возвращает ID поставщика, в команде которого состоит пользователь
 func GetSupplierIDByUserID(userID int) (int, error) {
 	var supplierID int
-	query := `SELECT id FROM supplier WHERE user_id = $1`
+	query := `SELECT supplier_id FROM supplier_members WHERE user_id = $1`
 	err := DB.QueryRow(query, userID).Scan(&supplierID)
 	if err != nil {
 		if err == sql.ErrNoRows {
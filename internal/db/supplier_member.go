@@ -0,0 +1,282 @@
+// internal/db/supplier_member.go
+
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/WhyDias/Marketplace/internal/models"
+)
+
+const supplierMemberColumns = `m.supplier_id, m.user_id, u.username, m.role, COALESCE(m.invited_by, 0), m.created_at`
+
+func scanSupplierMember(row rowScanner, member *models.SupplierMember) error {
+	return row.Scan(&member.SupplierID, &member.UserID, &member.PhoneNumber, &member.Role, &member.InvitedBy, &member.CreatedAt)
+}
+
+// GetSupplierMember возвращает участие пользователя в команде поставщика, nil если он не состоит ни в одной команде
+func GetSupplierMember(userID int) (*models.SupplierMember, error) {
+	var member models.SupplierMember
+	row := DB.QueryRow(`
+        SELECT `+supplierMemberColumns+`
+        FROM supplier_members m
+        JOIN users u ON u.id = m.user_id
+        WHERE m.user_id = $1
+    `, userID)
+	err := scanSupplierMember(row, &member)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("не удалось получить участника команды поставщика: %v", err)
+	}
+	return &member, nil
+}
+
+// GetSupplierMembers возвращает команду поставщика: владельца первым, остальных в порядке вступления
+func GetSupplierMembers(supplierID int) ([]models.SupplierMember, error) {
+	rows, err := DB.Query(`
+        SELECT `+supplierMemberColumns+`
+        FROM supplier_members m
+        JOIN users u ON u.id = m.user_id
+        WHERE m.supplier_id = $1
+        ORDER BY m.role <> 'owner', m.created_at, m.id
+    `, supplierID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить команду поставщика: %v", err)
+	}
+	defer rows.Close()
+
+	members := []models.SupplierMember{}
+	for rows.Next() {
+		var member models.SupplierMember
+		if err := scanSupplierMember(rows, &member); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании участника команды: %v", err)
+		}
+		members = append(members, member)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return members, nil
+}
+
+// SetSupplierOwner делает пользователя владельцем поставщика. Прежний владелец исключается из команды.
+// Участие пользователя в другой команде не заменяется: в этом случае возвращается ошибка.
+func SetSupplierOwner(supplierID int, userID int) (err error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	_, err = tx.Exec(`DELETE FROM supplier_members WHERE supplier_id = $1 AND role = $2 AND user_id <> $3`,
+		supplierID, models.SupplierRoleOwner, userID)
+	if err != nil {
+		return fmt.Errorf("не удалось исключить прежнего владельца: %v", err)
+	}
+
+	result, err := tx.Exec(`
+        INSERT INTO supplier_members (supplier_id, user_id, role)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (user_id) DO UPDATE SET role = EXCLUDED.role
+        WHERE supplier_members.supplier_id = EXCLUDED.supplier_id
+    `, supplierID, userID, models.SupplierRoleOwner)
+	if err != nil {
+		return fmt.Errorf("не удалось назначить владельца поставщика: %v", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("не удалось назначить владельца поставщика: %v", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("пользователь %d уже состоит в команде другого поставщика", userID)
+	}
+	return nil
+}
+
+// UpdateSupplierMemberRole меняет роль участника команды
+func UpdateSupplierMemberRole(supplierID int, userID int, role string) error {
+	_, err := DB.Exec(`UPDATE supplier_members SET role = $1 WHERE supplier_id = $2 AND user_id = $3`, role, supplierID, userID)
+	if err != nil {
+		return fmt.Errorf("не удалось изменить роль участника команды: %v", err)
+	}
+	return nil
+}
+
+// DeleteSupplierMember исключает пользователя из команды поставщика
+func DeleteSupplierMember(supplierID int, userID int) error {
+	_, err := DB.Exec(`DELETE FROM supplier_members WHERE supplier_id = $1 AND user_id = $2`, supplierID, userID)
+	if err != nil {
+		return fmt.Errorf("не удалось исключить участника команды: %v", err)
+	}
+	return nil
+}
+
+const supplierInviteColumns = `id, supplier_id, user_id, phone_number, role, status, COALESCE(invited_by, 0), expires_at, created_at`
+
+func scanSupplierInvite(row rowScanner, invite *models.SupplierInvite) error {
+	return row.Scan(&invite.ID, &invite.SupplierID, &invite.UserID, &invite.PhoneNumber, &invite.Role, &invite.Status,
+		&invite.InvitedBy, &invite.ExpiresAt, &invite.CreatedAt)
+}
+
+// CreateSupplierInvite сохраняет приглашение. Прежние ожидающие приглашения пользователя отзываются.
+func CreateSupplierInvite(invite *models.SupplierInvite) (err error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	_, err = tx.Exec(`UPDATE supplier_invites SET status = $1 WHERE user_id = $2 AND status = $3`,
+		models.SupplierInviteRevoked, invite.UserID, models.SupplierInvitePending)
+	if err != nil {
+		return fmt.Errorf("не удалось отозвать прежние приглашения: %v", err)
+	}
+
+	err = tx.QueryRow(`
+        INSERT INTO supplier_invites (supplier_id, user_id, phone_number, role, invited_by, expires_at)
+        VALUES ($1, $2, $3, $4, NULLIF($5, 0), $6)
+        RETURNING id, status, created_at
+    `, invite.SupplierID, invite.UserID, invite.PhoneNumber, invite.Role, invite.InvitedBy, invite.ExpiresAt).
+		Scan(&invite.ID, &invite.Status, &invite.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить приглашение: %v", err)
+	}
+	return nil
+}
+
+// GetPendingSupplierInvites возвращает действующие приглашения в команду поставщика
+func GetPendingSupplierInvites(supplierID int) ([]models.SupplierInvite, error) {
+	rows, err := DB.Query(`
+        SELECT `+supplierInviteColumns+`
+        FROM supplier_invites
+        WHERE supplier_id = $1 AND status = $2 AND expires_at > NOW()
+        ORDER BY created_at, id
+    `, supplierID, models.SupplierInvitePending)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить приглашения: %v", err)
+	}
+	defer rows.Close()
+
+	invites := []models.SupplierInvite{}
+	for rows.Next() {
+		var invite models.SupplierInvite
+		if err := scanSupplierInvite(rows, &invite); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании приглашения: %v", err)
+		}
+		invites = append(invites, invite)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return invites, nil
+}
+
+// GetSupplierInvite возвращает приглашение, nil если его нет
+func GetSupplierInvite(inviteID int) (*models.SupplierInvite, error) {
+	var invite models.SupplierInvite
+	row := DB.QueryRow(`SELECT `+supplierInviteColumns+` FROM supplier_invites WHERE id = $1`, inviteID)
+	err := scanSupplierInvite(row, &invite)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("не удалось получить приглашение: %v", err)
+	}
+	return &invite, nil
+}
+
+// RevokeSupplierInvite отзывает ожидающее приглашение
+func RevokeSupplierInvite(inviteID int) error {
+	_, err := DB.Exec(`UPDATE supplier_invites SET status = $1 WHERE id = $2 AND status = $3`,
+		models.SupplierInviteRevoked, inviteID, models.SupplierInvitePending)
+	if err != nil {
+		return fmt.Errorf("не удалось отозвать приглашение: %v", err)
+	}
+	return nil
+}
+
+// HasPendingSupplierInvite проверяет, есть ли у пользователя действующее приглашение в команду
+func HasPendingSupplierInvite(userID int) (bool, error) {
+	var exists bool
+	err := DB.QueryRow(`
+        SELECT EXISTS (SELECT 1 FROM supplier_invites WHERE user_id = $1 AND status = $2 AND expires_at > NOW())
+    `, userID, models.SupplierInvitePending).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("не удалось проверить приглашения пользователя: %v", err)
+	}
+	return exists, nil
+}
+
+// AcceptSupplierInvite принимает действующее приглашение пользователя и добавляет его в команду.
+// Непустой passwordHash в той же транзакции становится паролем пользователя.
+// Возвращает ID поставщика, 0 если приглашения нет или пользователь уже состоит в команде.
+func AcceptSupplierInvite(userID int, passwordHash string) (supplierID int, err error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	var invite models.SupplierInvite
+	row := tx.QueryRow(`
+        SELECT `+supplierInviteColumns+`
+        FROM supplier_invites
+        WHERE user_id = $1 AND status = $2 AND expires_at > NOW()
+        FOR UPDATE
+    `, userID, models.SupplierInvitePending)
+	err = scanSupplierInvite(row, &invite)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("не удалось получить приглашение: %v", err)
+	}
+
+	result, err := tx.Exec(`
+        INSERT INTO supplier_members (supplier_id, user_id, role, invited_by)
+        VALUES ($1, $2, $3, NULLIF($4, 0))
+        ON CONFLICT (user_id) DO NOTHING
+    `, invite.SupplierID, userID, invite.Role, invite.InvitedBy)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось добавить участника команды: %v", err)
+	}
+	if inserted, err := result.RowsAffected(); err != nil || inserted == 0 {
+		return 0, err
+	}
+
+	_, err = tx.Exec(`UPDATE supplier_invites SET status = $1, accepted_at = NOW() WHERE id = $2`,
+		models.SupplierInviteAccepted, invite.ID)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось принять приглашение: %v", err)
+	}
+
+	if passwordHash != "" {
+		_, err = tx.Exec(`UPDATE users SET password_hash = $1, updated_at = NOW() WHERE id = $2`, passwordHash, userID)
+		if err != nil {
+			return 0, fmt.Errorf("не удалось установить пароль: %v", err)
+		}
+	}
+	return invite.SupplierID, nil
+}
@@ -0,0 +1,125 @@
+// internal/db/supplier_profile.go
+
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/WhyDias/Marketplace/internal/models"
+)
+
+// GetSupplierByID возвращает поставщика, nil если его нет
+func GetSupplierByID(supplierID int) (*models.Supplier, error) {
+	query := `
+        SELECT s.id, COALESCE(s.user_id, 0), COALESCE(s.name, ''), COALESCE(s.market_id, 0), COALESCE(s.place_name, ''),
+               COALESCE(s.row_name, ''), COALESCE(s.phone_number, ''), s.is_verified, s.created_at, s.updated_at,
+               COALESCE(s.stall_id, 0), COALESCE(st.row_id, 0), s.verification_status
+        FROM supplier s
+        LEFT JOIN market_stalls st ON st.id = s.stall_id
+        WHERE s.id = $1
+    `
+
+	var supplier models.Supplier
+	err := DB.QueryRow(query, supplierID).Scan(
+		&supplier.ID,
+		&supplier.UserID,
+		&supplier.Name,
+		&supplier.MarketID,
+		&supplier.PlaceName,
+		&supplier.RowName,
+		&supplier.PhoneNumber,
+		&supplier.IsVerified,
+		&supplier.CreatedAt,
+		&supplier.UpdatedAt,
+		&supplier.StallID,
+		&supplier.RowID,
+		&supplier.VerificationStatus,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("не удалось получить поставщика: %v", err)
+	}
+	return &supplier, nil
+}
+
+// GetSupplierProfile возвращает профиль витрины поставщика
+func GetSupplierProfile(supplierID int) (*models.SupplierProfile, error) {
+	query := `
+        SELECT COALESCE(logo_url, ''), COALESCE(banner_url, ''), COALESCE(description, ''),
+               COALESCE(telegram, ''), COALESCE(instagram, ''), show_phone
+        FROM supplier
+        WHERE id = $1
+    `
+
+	var profile models.SupplierProfile
+	err := DB.QueryRow(query, supplierID).Scan(
+		&profile.LogoURL,
+		&profile.BannerURL,
+		&profile.Description,
+		&profile.Telegram,
+		&profile.Instagram,
+		&profile.ShowPhone,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить профиль поставщика: %v", err)
+	}
+	return &profile, nil
+}
+
+// UpdateSupplierProfile сохраняет профиль витрины поставщика
+func UpdateSupplierProfile(supplierID int, profile *models.SupplierProfile) error {
+	query := `
+        UPDATE supplier
+        SET logo_url = NULLIF($1, ''), banner_url = NULLIF($2, ''), description = NULLIF($3, ''),
+            telegram = NULLIF($4, ''), instagram = NULLIF($5, ''), show_phone = $6, updated_at = NOW()
+        WHERE id = $7
+    `
+	_, err := DB.Exec(query, profile.LogoURL, profile.BannerURL, profile.Description,
+		profile.Telegram, profile.Instagram, profile.ShowPhone, supplierID)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить профиль поставщика: %v", err)
+	}
+	return nil
+}
+
+// GetSupplierProductCards возвращает страницу одобренных продуктов поставщика, начиная с новых,
+// и общее количество одобренных продуктов
+func GetSupplierProductCards(supplierID, limit, offset int) ([]models.ProductCard, int, error) {
+	var total int
+	err := DB.QueryRow(`SELECT COUNT(*) FROM product p WHERE p.supplier_id = $1 AND p.status_id = $2 AND `+productVisibleCondition,
+		supplierID, models.ProductStatusApproved).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("не удалось посчитать продукты поставщика: %v", err)
+	}
+
+	query := `
+        SELECT ` + productCardColumns + `
+        FROM product p
+    ` + productCardImageJoin + `
+        WHERE p.supplier_id = $1 AND p.status_id = $2 AND ` + productVisibleCondition + `
+        ORDER BY p.id DESC
+        LIMIT $3 OFFSET $4
+    `
+	rows, err := DB.Query(query, supplierID, models.ProductStatusApproved, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("не удалось получить продукты поставщика: %v", err)
+	}
+	defer rows.Close()
+
+	cards := []models.ProductCard{}
+	for rows.Next() {
+		var card models.ProductCard
+		if err := scanProductCard(rows, &card); err != nil {
+			return nil, 0, fmt.Errorf("ошибка при сканировании продукта: %v", err)
+		}
+		cards = append(cards, card)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return cards, total, nil
+}
@@ -0,0 +1,242 @@
+// internal/db/supplier_schedule.go
+
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/WhyDias/Marketplace/internal/models"
+)
+
+// sqlDate дата в виде ГГГГ-ММ-ДД. Даты передаются строкой, чтобы часовой пояс сессии не сдвигал день.
+func sqlDate(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// GetSupplierWorkingHours возвращает часы работы поставщика по дням недели, с понедельника
+func GetSupplierWorkingHours(supplierID int) ([]models.SupplierWorkingDay, error) {
+	rows, err := DB.Query(`
+        SELECT weekday, to_char(opens_at, 'HH24:MI'), to_char(closes_at, 'HH24:MI')
+        FROM supplier_working_hours
+        WHERE supplier_id = $1
+        ORDER BY weekday
+    `, supplierID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить часы работы поставщика: %v", err)
+	}
+	defer rows.Close()
+
+	days := []models.SupplierWorkingDay{}
+	for rows.Next() {
+		var day models.SupplierWorkingDay
+		if err := rows.Scan(&day.Weekday, &day.OpensAt, &day.ClosesAt); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании часов работы: %v", err)
+		}
+		days = append(days, day)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return days, nil
+}
+
+// ReplaceSupplierWorkingHours заменяет часы работы поставщика на всю неделю
+func ReplaceSupplierWorkingHours(supplierID int, days []models.SupplierWorkingDay) (err error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	if _, err = tx.Exec(`DELETE FROM supplier_working_hours WHERE supplier_id = $1`, supplierID); err != nil {
+		return fmt.Errorf("не удалось удалить часы работы поставщика: %v", err)
+	}
+	for _, day := range days {
+		_, err = tx.Exec(`
+            INSERT INTO supplier_working_hours (supplier_id, weekday, opens_at, closes_at)
+            VALUES ($1, $2, $3::time, $4::time)
+        `, supplierID, day.Weekday, day.OpensAt, day.ClosesAt)
+		if err != nil {
+			return fmt.Errorf("не удалось сохранить часы работы поставщика: %v", err)
+		}
+	}
+	return nil
+}
+
+// GetSupplierScheduleExceptions возвращает исключения поставщика с даты from, ближайшие первыми
+func GetSupplierScheduleExceptions(supplierID int, from time.Time) ([]models.SupplierScheduleException, error) {
+	rows, err := DB.Query(`
+        SELECT to_char(day, 'YYYY-MM-DD'), closed,
+               COALESCE(to_char(opens_at, 'HH24:MI'), ''), COALESCE(to_char(closes_at, 'HH24:MI'), ''), note
+        FROM supplier_schedule_exceptions
+        WHERE supplier_id = $1 AND day >= $2::date
+        ORDER BY day
+    `, supplierID, sqlDate(from))
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить исключения расписания: %v", err)
+	}
+	defer rows.Close()
+
+	exceptions := []models.SupplierScheduleException{}
+	for rows.Next() {
+		var e models.SupplierScheduleException
+		if err := rows.Scan(&e.Date, &e.Closed, &e.OpensAt, &e.ClosesAt, &e.Note); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании исключения расписания: %v", err)
+		}
+		exceptions = append(exceptions, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return exceptions, nil
+}
+
+// UpsertSupplierScheduleException сохраняет исключение расписания на дату exception.Date
+func UpsertSupplierScheduleException(supplierID int, exception models.SupplierScheduleException) error {
+	_, err := DB.Exec(`
+        INSERT INTO supplier_schedule_exceptions (supplier_id, day, closed, opens_at, closes_at, note)
+        VALUES ($1, $2::date, $3, NULLIF($4, '')::time, NULLIF($5, '')::time, $6)
+        ON CONFLICT (supplier_id, day) DO UPDATE
+        SET closed = EXCLUDED.closed, opens_at = EXCLUDED.opens_at,
+            closes_at = EXCLUDED.closes_at, note = EXCLUDED.note
+    `, supplierID, exception.Date, exception.Closed, exception.OpensAt, exception.ClosesAt, exception.Note)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить исключение расписания: %v", err)
+	}
+	return nil
+}
+
+// DeleteSupplierScheduleException удаляет исключение расписания, false если его не было
+func DeleteSupplierScheduleException(supplierID int, day time.Time) (bool, error) {
+	result, err := DB.Exec(`DELETE FROM supplier_schedule_exceptions WHERE supplier_id = $1 AND day = $2::date`,
+		supplierID, sqlDate(day))
+	if err != nil {
+		return false, fmt.Errorf("не удалось удалить исключение расписания: %v", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// GetSupplierVacation возвращает отпуск поставщика, nil если он не задан
+func GetSupplierVacation(supplierID int) (*models.SupplierVacation, error) {
+	var vacation models.SupplierVacation
+	var startsOn, endsOn sql.NullString
+	err := DB.QueryRow(`
+        SELECT to_char(vacation_starts_on, 'YYYY-MM-DD'), to_char(vacation_ends_on, 'YYYY-MM-DD'), vacation_mode, on_vacation
+        FROM supplier
+        WHERE id = $1
+    `, supplierID).Scan(&startsOn, &endsOn, &vacation.Mode, &vacation.Active)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("не удалось получить отпуск поставщика: %v", err)
+	}
+	if !startsOn.Valid || !endsOn.Valid {
+		return nil, nil
+	}
+	vacation.StartsOn, vacation.EndsOn = startsOn.String, endsOn.String
+	return &vacation, nil
+}
+
+// SetSupplierVacation задаёт отпуск поставщика. Если today попадает в отпуск, он начинается сразу.
+func SetSupplierVacation(supplierID int, startsOn, endsOn time.Time, mode string, today time.Time) error {
+	_, err := DB.Exec(`
+        UPDATE supplier
+        SET vacation_starts_on = $1::date, vacation_ends_on = $2::date, vacation_mode = $3,
+            on_vacation = $4::date BETWEEN $1::date AND $2::date, updated_at = NOW()
+        WHERE id = $5
+    `, sqlDate(startsOn), sqlDate(endsOn), mode, sqlDate(today), supplierID)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить отпуск поставщика: %v", err)
+	}
+	return nil
+}
+
+// ClearSupplierVacation отменяет отпуск поставщика
+func ClearSupplierVacation(supplierID int) error {
+	_, err := DB.Exec(`
+        UPDATE supplier
+        SET vacation_starts_on = NULL, vacation_ends_on = NULL, on_vacation = FALSE, updated_at = NOW()
+        WHERE id = $1
+    `, supplierID)
+	if err != nil {
+		return fmt.Errorf("не удалось отменить отпуск поставщика: %v", err)
+	}
+	return nil
+}
+
+// SyncSupplierVacations начинает отпуска, в которые попадает today, и завершает остальные.
+// Возвращает ID поставщиков, ушедших в отпуск и вернувшихся из него.
+func SyncSupplierVacations(today time.Time) (started, ended []int, err error) {
+	if started, err = queryInts(`
+        UPDATE supplier SET on_vacation = TRUE, updated_at = NOW()
+        WHERE NOT on_vacation AND $1::date BETWEEN vacation_starts_on AND vacation_ends_on
+        RETURNING id
+    `, sqlDate(today)); err != nil {
+		return nil, nil, fmt.Errorf("не удалось начать отпуска поставщиков: %v", err)
+	}
+	if ended, err = queryInts(`
+        UPDATE supplier SET on_vacation = FALSE, updated_at = NOW()
+        WHERE on_vacation AND NOT COALESCE($1::date BETWEEN vacation_starts_on AND vacation_ends_on, FALSE)
+        RETURNING id
+    `, sqlDate(today)); err != nil {
+		return nil, nil, fmt.Errorf("не удалось завершить отпуска поставщиков: %v", err)
+	}
+	return started, ended, nil
+}
+
+// GetProductVacationMode возвращает режим отпуска поставщика продукта, пустую строку если поставщик не в отпуске
+func GetProductVacationMode(productID int) (string, error) {
+	var mode string
+	err := DB.QueryRow(`
+        SELECT s.vacation_mode
+        FROM product p
+        JOIN supplier s ON s.id = p.supplier_id
+        WHERE p.id = $1 AND s.on_vacation
+    `, productID).Scan(&mode)
+	if err == sql.ErrNoRows {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("не удалось получить отпуск поставщика продукта: %v", err)
+	}
+	return mode, nil
+}
+
+// queryInts выполняет запрос с одним целочисленным столбцом
+func queryInts(query string, args ...interface{}) ([]int, error) {
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось выполнить запрос: %v", err)
+	}
+	defer rows.Close()
+
+	var values []int
+	for rows.Next() {
+		var value int
+		if err := rows.Scan(&value); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании строки: %v", err)
+		}
+		values = append(values, value)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return values, nil
+}
@@ -0,0 +1,278 @@
+// internal/db/supplier_verification.go
+
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/lib/pq"
+)
+
+// GetSupplierVerification возвращает статус проверки поставщика без документов и истории, nil если поставщика нет
+func GetSupplierVerification(supplierID int) (*models.SupplierVerification, error) {
+	verification := models.SupplierVerification{SupplierID: supplierID}
+	var submittedAt, verifiedAt sql.NullTime
+	err := DB.QueryRow(`
+        SELECT verification_status, verification_submitted_at, verified_at
+        FROM supplier
+        WHERE id = $1
+    `, supplierID).Scan(&verification.Status, &submittedAt, &verifiedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("не удалось получить статус проверки поставщика: %v", err)
+	}
+	if submittedAt.Valid {
+		verification.SubmittedAt = &submittedAt.Time
+	}
+	if verifiedAt.Valid {
+		verification.VerifiedAt = &verifiedAt.Time
+	}
+	return &verification, nil
+}
+
+// IsVerifiedSeller проверяет, прошёл ли поставщик проверку документов
+func IsVerifiedSeller(supplierID int) (bool, error) {
+	var verified bool
+	err := DB.QueryRow(`
+        SELECT EXISTS (SELECT 1 FROM supplier WHERE id = $1 AND verification_status = $2)
+    `, supplierID, models.VerificationStatusApproved).Scan(&verified)
+	if err != nil {
+		return false, fmt.Errorf("не удалось проверить статус поставщика: %v", err)
+	}
+	return verified, nil
+}
+
+// IsProductSupplierVerified проверяет, прошёл ли проверку документов поставщик продукта
+func IsProductSupplierVerified(productID int) (bool, error) {
+	var verified bool
+	err := DB.QueryRow(`
+        SELECT EXISTS (
+            SELECT 1
+            FROM product p
+            JOIN supplier s ON s.id = p.supplier_id
+            WHERE p.id = $1 AND s.verification_status = $2
+        )
+    `, productID, models.VerificationStatusApproved).Scan(&verified)
+	if err != nil {
+		return false, fmt.Errorf("не удалось проверить статус поставщика продукта: %v", err)
+	}
+	return verified, nil
+}
+
+const supplierDocumentColumns = `id, supplier_id, doc_type, file_name, content_type, size_bytes, uploaded_at, object_key`
+
+func scanSupplierDocument(row rowScanner, doc *models.SupplierDocument) error {
+	return row.Scan(&doc.ID, &doc.SupplierID, &doc.DocType, &doc.FileName, &doc.ContentType, &doc.Size,
+		&doc.UploadedAt, &doc.ObjectKey)
+}
+
+// GetSupplierDocuments возвращает документы поставщика в порядке загрузки
+func GetSupplierDocuments(supplierID int) ([]models.SupplierDocument, error) {
+	rows, err := DB.Query(`
+        SELECT `+supplierDocumentColumns+`
+        FROM supplier_documents
+        WHERE supplier_id = $1
+        ORDER BY uploaded_at, id
+    `, supplierID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить документы поставщика: %v", err)
+	}
+	defer rows.Close()
+
+	documents := []models.SupplierDocument{}
+	for rows.Next() {
+		var doc models.SupplierDocument
+		if err := scanSupplierDocument(rows, &doc); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании документа: %v", err)
+		}
+		documents = append(documents, doc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return documents, nil
+}
+
+// GetSupplierDocument возвращает документ, nil если его нет
+func GetSupplierDocument(documentID int) (*models.SupplierDocument, error) {
+	var doc models.SupplierDocument
+	row := DB.QueryRow(`SELECT `+supplierDocumentColumns+` FROM supplier_documents WHERE id = $1`, documentID)
+	err := scanSupplierDocument(row, &doc)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("не удалось получить документ: %v", err)
+	}
+	return &doc, nil
+}
+
+// CreateSupplierDocument сохраняет загруженный документ поставщика
+func CreateSupplierDocument(doc *models.SupplierDocument) error {
+	query := `
+        INSERT INTO supplier_documents (supplier_id, doc_type, file_name, content_type, size_bytes, object_key)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING id, uploaded_at
+    `
+	err := DB.QueryRow(query, doc.SupplierID, doc.DocType, doc.FileName, doc.ContentType, doc.Size, doc.ObjectKey).
+		Scan(&doc.ID, &doc.UploadedAt)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить документ: %v", err)
+	}
+	return nil
+}
+
+// DeleteSupplierDocument удаляет запись о документе
+func DeleteSupplierDocument(documentID int) error {
+	if _, err := DB.Exec(`DELETE FROM supplier_documents WHERE id = $1`, documentID); err != nil {
+		return fmt.Errorf("не удалось удалить документ: %v", err)
+	}
+	return nil
+}
+
+// GetSupplierDocumentTypes возвращает типы документов, загруженных поставщиком
+func GetSupplierDocumentTypes(supplierID int) ([]string, error) {
+	var types []string
+	err := DB.QueryRow(`
+        SELECT COALESCE(array_agg(DISTINCT doc_type), '{}')
+        FROM supplier_documents
+        WHERE supplier_id = $1
+    `, supplierID).Scan(pq.Array(&types))
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить типы документов поставщика: %v", err)
+	}
+	return types, nil
+}
+
+// GetVerificationHistory возвращает историю проверки поставщика в хронологическом порядке
+func GetVerificationHistory(supplierID int) ([]models.VerificationHistoryEntry, error) {
+	rows, err := DB.Query(`
+        SELECT id, from_status, to_status, COALESCE(changed_by, 0), comment, created_at
+        FROM supplier_verification_history
+        WHERE supplier_id = $1
+        ORDER BY created_at, id
+    `, supplierID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить историю проверки поставщика: %v", err)
+	}
+	defer rows.Close()
+
+	history := []models.VerificationHistoryEntry{}
+	for rows.Next() {
+		var entry models.VerificationHistoryEntry
+		if err := rows.Scan(&entry.ID, &entry.FromStatus, &entry.ToStatus, &entry.ChangedBy, &entry.Comment, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании истории проверки: %v", err)
+		}
+		history = append(history, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return history, nil
+}
+
+// ChangeVerificationStatus переводит поставщика в статус toStatus, если текущий статус входит в allowedFrom,
+// и записывает смену в историю. Возвращает текущий статус и признак того, что статус изменён.
+// changedBy = 0 означает действие самого поставщика.
+func ChangeVerificationStatus(supplierID int, allowedFrom []string, toStatus string, changedBy int, comment string) (fromStatus string, changed bool, err error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return "", false, fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	err = tx.QueryRow(`SELECT verification_status FROM supplier WHERE id = $1 FOR UPDATE`, supplierID).Scan(&fromStatus)
+	if err != nil {
+		return "", false, fmt.Errorf("не удалось получить статус проверки поставщика: %v", err)
+	}
+	allowed := false
+	for _, status := range allowedFrom {
+		if status == fromStatus {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return fromStatus, false, nil
+	}
+
+	// Дата отправки остаётся у проверенных и отклонённых заявок, дата проверки — только у проверенных
+	_, err = tx.Exec(`
+        UPDATE supplier
+        SET verification_status = $1,
+            verification_submitted_at = CASE WHEN $1 = 'pending' THEN NOW() ELSE verification_submitted_at END,
+            verified_at = CASE WHEN $1 = 'approved' THEN NOW() END,
+            updated_at = NOW()
+        WHERE id = $2
+    `, toStatus, supplierID)
+	if err != nil {
+		return "", false, fmt.Errorf("не удалось обновить статус проверки поставщика: %v", err)
+	}
+
+	_, err = tx.Exec(`
+        INSERT INTO supplier_verification_history (supplier_id, from_status, to_status, changed_by, comment)
+        VALUES ($1, $2, $3, NULLIF($4, 0), $5)
+    `, supplierID, fromStatus, toStatus, changedBy, comment)
+	if err != nil {
+		return "", false, fmt.Errorf("не удалось записать историю проверки поставщика: %v", err)
+	}
+
+	return fromStatus, true, nil
+}
+
+// CountVerificationQueue возвращает количество поставщиков, ожидающих проверки
+func CountVerificationQueue() (int, error) {
+	var total int
+	err := DB.QueryRow(`SELECT COUNT(*) FROM supplier WHERE verification_status = $1`, models.VerificationStatusPending).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при подсчёте очереди проверки поставщиков: %v", err)
+	}
+	return total, nil
+}
+
+// GetVerificationQueue возвращает поставщиков, ожидающих проверки, начиная с самых давних заявок
+func GetVerificationQueue(limit, offset int) ([]models.VerificationQueueItem, error) {
+	rows, err := DB.Query(`
+        SELECT s.id, COALESCE(s.name, ''), COALESCE(s.phone_number, ''), COALESCE(s.market_id, 0),
+               COALESCE((SELECT array_agg(DISTINCT d.doc_type) FROM supplier_documents d WHERE d.supplier_id = s.id), '{}'),
+               s.verification_submitted_at,
+               EXTRACT(EPOCH FROM NOW() - s.verification_submitted_at)::BIGINT
+        FROM supplier s
+        WHERE s.verification_status = $1
+        ORDER BY s.verification_submitted_at, s.id
+        LIMIT $2 OFFSET $3
+    `, models.VerificationStatusPending, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при выполнении запроса: %v", err)
+	}
+	defer rows.Close()
+
+	items := []models.VerificationQueueItem{}
+	for rows.Next() {
+		var item models.VerificationQueueItem
+		err := rows.Scan(&item.SupplierID, &item.Name, &item.PhoneNumber, &item.MarketID,
+			pq.Array(&item.DocumentTypes), &item.SubmittedAt, &item.TimeInQueue)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании очереди проверки: %v", err)
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return items, nil
+}
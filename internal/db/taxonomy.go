@@ -0,0 +1,148 @@
+// internal/db/taxonomy.go
+
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/lib/pq"
+)
+
+// GetAllCategoryAttributes возвращает собственные атрибуты всех категорий, упорядоченные по категории и ID
+func GetAllCategoryAttributes() ([]models.CategoryAttribute, error) {
+	rows, err := DB.Query(`
+        SELECT id, category_id, name, description, type_of_option, value, is_linked, is_required, sort_order, is_filterable,
+               COALESCE(dimension, ''), COALESCE(unit, '')
+        FROM attributes
+        ORDER BY category_id, sort_order, id
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить атрибуты категорий: %v", err)
+	}
+	defer rows.Close()
+
+	var attributes []models.CategoryAttribute
+	for rows.Next() {
+		var attr models.CategoryAttribute
+		err := rows.Scan(&attr.ID, &attr.CategoryID, &attr.Name, &attr.Description, &attr.TypeOfOption, &attr.Value, &attr.IsLinked,
+			&attr.IsRequired, &attr.SortOrder, &attr.IsFilterable, &attr.Dimension, &attr.Unit)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании атрибута: %v", err)
+		}
+		attributes = append(attributes, attr)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return attributes, nil
+}
+
+// GetAllHiddenAttributes возвращает скрытые атрибуты всех категорий: category_id -> названия
+func GetAllHiddenAttributes() (map[int][]string, error) {
+	rows, err := DB.Query(`SELECT category_id, attribute_name FROM category_hidden_attributes ORDER BY category_id, attribute_name`)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить скрытые атрибуты: %v", err)
+	}
+	defer rows.Close()
+
+	hidden := make(map[int][]string)
+	for rows.Next() {
+		var categoryID int
+		var name string
+		if err := rows.Scan(&categoryID, &name); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании скрытого атрибута: %v", err)
+		}
+		hidden[categoryID] = append(hidden[categoryID], name)
+	}
+	return hidden, rows.Err()
+}
+
+// CountCategoryUsage возвращает количество продуктов и поставщиков, привязанных к категории
+func CountCategoryUsage(categoryID int) (products int, suppliers int, err error) {
+	err = DB.QueryRow(`
+        SELECT (SELECT COUNT(*) FROM product WHERE category_id = $1),
+               (SELECT COUNT(*) FROM supplier_categories WHERE category_id = $1)
+    `, categoryID).Scan(&products, &suppliers)
+	if err != nil {
+		return 0, 0, fmt.Errorf("не удалось посчитать использование категории: %v", err)
+	}
+	return products, suppliers, nil
+}
+
+// DeleteAttribute удаляет атрибут вместе с его значениями. Значения не должны быть выбраны у продуктов.
+func DeleteAttribute(attributeID int) (err error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	return deleteAttributesTx(tx, []int64{int64(attributeID)})
+}
+
+// DeleteEmptyCategory удаляет категорию без подкатегорий, продуктов и поставщиков вместе с её атрибутами
+func DeleteEmptyCategory(categoryID int) (err error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	var attributeIDs []int64
+	err = tx.QueryRow(`SELECT COALESCE(array_agg(id), '{}') FROM attributes WHERE category_id = $1`, categoryID).
+		Scan(pq.Array(&attributeIDs))
+	if err != nil {
+		return fmt.Errorf("не удалось получить атрибуты категории: %v", err)
+	}
+	if err = deleteAttributesTx(tx, attributeIDs); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM image_hashes WHERE owner_type = $1 AND owner_id = $2`, models.ImageOwnerCategory, categoryID)
+	if err != nil {
+		return fmt.Errorf("не удалось удалить хэши изображений категории: %v", err)
+	}
+
+	if _, err = tx.Exec(`DELETE FROM categories WHERE id = $1`, categoryID); err != nil {
+		log.Printf("DeleteEmptyCategory: ошибка при удалении категории %d: %v", categoryID, err)
+		return fmt.Errorf("не удалось удалить категорию: %v", err)
+	}
+	return nil
+}
+
+// deleteAttributesTx удаляет атрибуты вместе с их значениями и изображениями значений
+func deleteAttributesTx(tx *sql.Tx, attributeIDs []int64) error {
+	ids := pq.Array(attributeIDs)
+	statements := []struct {
+		query   string
+		failure string
+	}{
+		{`DELETE FROM attribute_value_image WHERE attribute_value_id IN (SELECT id FROM attribute_value WHERE attribute_id = ANY($1))`,
+			"не удалось удалить изображения значений атрибута"},
+		{`DELETE FROM attribute_value WHERE attribute_id = ANY($1)`, "не удалось удалить значения атрибута"},
+		{`DELETE FROM attributes WHERE id = ANY($1)`, "не удалось удалить атрибут"},
+	}
+	for _, st := range statements {
+		if _, err := tx.Exec(st.query, ids); err != nil {
+			return fmt.Errorf("%s: %v", st.failure, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,307 @@
+// internal/db/translation.go
+
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/lib/pq"
+)
+
+// translationTables таблицы переводов и ключевые столбцы по видам записей каталога
+var translationTables = map[string]struct {
+	table string
+	key   string
+}{
+	models.TranslationCategory:        {"category_translations", "category_id"},
+	models.TranslationAttribute:       {"attribute_translations", "attribute_id"},
+	models.TranslationAttributeOption: {"attribute_option_translations", "attribute_id"},
+	models.TranslationProduct:         {"product_translations", "product_id"},
+}
+
+// missingTranslationQueries записи каталога без перевода на язык $1: id, опция, исходный текст и общее количество.
+// Для продуктов учитываются только одобренные.
+var missingTranslationQueries = map[string]string{
+	models.TranslationCategory: `
+        SELECT c.id, '', c.name, COUNT(*) OVER ()
+        FROM categories c
+        WHERE NOT EXISTS (SELECT 1 FROM category_translations t WHERE t.category_id = c.id AND t.lang = $1)
+        ORDER BY c.path
+        LIMIT $2`,
+	models.TranslationAttribute: `
+        SELECT a.id, '', a.name, COUNT(*) OVER ()
+        FROM attributes a
+        WHERE NOT EXISTS (SELECT 1 FROM attribute_translations t WHERE t.attribute_id = a.id AND t.lang = $1)
+        ORDER BY a.id
+        LIMIT $2`,
+	models.TranslationAttributeOption: `
+        SELECT a.id, o.opt, o.opt, COUNT(*) OVER ()
+        FROM attributes a
+        CROSS JOIN LATERAL jsonb_array_elements_text(a.value::jsonb) o(opt)
+        WHERE a.type_of_option = 'dropdown'
+          AND jsonb_typeof(a.value::jsonb) = 'array'
+          AND NOT EXISTS (
+              SELECT 1 FROM attribute_option_translations t
+              WHERE t.attribute_id = a.id AND t.option = o.opt AND t.lang = $1
+          )
+        ORDER BY a.id, o.opt
+        LIMIT $2`,
+	models.TranslationProduct: `
+        SELECT p.id, '', p.name, COUNT(*) OVER ()
+        FROM product p
+        WHERE p.status_id = ` + fmt.Sprint(models.ProductStatusApproved) + `
+          AND NOT EXISTS (SELECT 1 FROM product_translations t WHERE t.product_id = p.id AND t.lang = $1)
+        ORDER BY p.id
+        LIMIT $2`,
+}
+
+// UpsertCategoryTranslation сохраняет перевод названия категории
+func UpsertCategoryTranslation(categoryID int, lang, name string) error {
+	_, err := DB.Exec(`
+        INSERT INTO category_translations (category_id, lang, name)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (category_id, lang) DO UPDATE
+        SET name = EXCLUDED.name, updated_at = NOW()
+    `, categoryID, lang, name)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить перевод категории: %v", err)
+	}
+	return nil
+}
+
+// UpsertAttributeTranslation сохраняет перевод названия и описания атрибута
+func UpsertAttributeTranslation(attributeID int, lang, name, description string) error {
+	_, err := DB.Exec(`
+        INSERT INTO attribute_translations (attribute_id, lang, name, description)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (attribute_id, lang) DO UPDATE
+        SET name = EXCLUDED.name, description = EXCLUDED.description, updated_at = NOW()
+    `, attributeID, lang, name, description)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить перевод атрибута: %v", err)
+	}
+	return nil
+}
+
+// UpsertAttributeOptionTranslation сохраняет перевод значения атрибута
+func UpsertAttributeOptionTranslation(attributeID int, option, lang, value string) error {
+	_, err := DB.Exec(`
+        INSERT INTO attribute_option_translations (attribute_id, option, lang, value)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (attribute_id, option, lang) DO UPDATE
+        SET value = EXCLUDED.value, updated_at = NOW()
+    `, attributeID, option, lang, value)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить перевод значения атрибута: %v", err)
+	}
+	return nil
+}
+
+// UpsertProductTranslation сохраняет перевод названия и описания продукта
+func UpsertProductTranslation(productID int, lang, name, description string) error {
+	_, err := DB.Exec(`
+        INSERT INTO product_translations (product_id, lang, name, description)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (product_id, lang) DO UPDATE
+        SET name = EXCLUDED.name, description = EXCLUDED.description, updated_at = NOW()
+    `, productID, lang, name, description)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить перевод продукта: %v", err)
+	}
+	return nil
+}
+
+// DeleteTranslation удаляет перевод записи каталога. Возвращает false, если перевода не было.
+func DeleteTranslation(entity string, id int, lang, option string) (bool, error) {
+	t, ok := translationTables[entity]
+	if !ok {
+		return false, fmt.Errorf("неизвестный вид записи: %s", entity)
+	}
+	query := `DELETE FROM ` + t.table + ` WHERE ` + t.key + ` = $1 AND lang = $2`
+	args := []interface{}{id, lang}
+	if entity == models.TranslationAttributeOption {
+		query += ` AND option = $3`
+		args = append(args, option)
+	}
+	result, err := DB.Exec(query, args...)
+	if err != nil {
+		return false, fmt.Errorf("не удалось удалить перевод: %v", err)
+	}
+	affected, err := rowsAffected(result)
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// GetTranslations возвращает все переводы записи каталога
+func GetTranslations(entity string, id int) ([]models.Translation, error) {
+	var query string
+	switch entity {
+	case models.TranslationCategory:
+		query = `SELECT lang, '', name, '', '', updated_at FROM category_translations WHERE category_id = $1`
+	case models.TranslationAttribute:
+		query = `SELECT lang, '', name, description, '', updated_at FROM attribute_translations WHERE attribute_id = $1`
+	case models.TranslationAttributeOption:
+		query = `SELECT lang, option, '', '', value, updated_at FROM attribute_option_translations WHERE attribute_id = $1`
+	case models.TranslationProduct:
+		query = `SELECT lang, '', name, description, '', updated_at FROM product_translations WHERE product_id = $1`
+	default:
+		return nil, fmt.Errorf("неизвестный вид записи: %s", entity)
+	}
+
+	rows, err := DB.Query(query+` ORDER BY 2, 1`, id)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить переводы: %v", err)
+	}
+	defer rows.Close()
+
+	translations := []models.Translation{}
+	for rows.Next() {
+		t := models.Translation{Entity: entity, ID: id}
+		if err := rows.Scan(&t.Lang, &t.Option, &t.Name, &t.Description, &t.Value, &t.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании перевода: %v", err)
+		}
+		translations = append(translations, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return translations, nil
+}
+
+// GetCategoryTranslations возвращает переводы названий категорий на язык: category_id -> название
+func GetCategoryTranslations(categoryIDs []int, lang string) (map[int]string, error) {
+	rows, err := DB.Query(`
+        SELECT category_id, name FROM category_translations
+        WHERE category_id = ANY($1) AND lang = $2
+    `, pq.Array(categoryIDs), lang)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить переводы категорий: %v", err)
+	}
+	defer rows.Close()
+
+	names := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании перевода категории: %v", err)
+		}
+		names[id] = name
+	}
+	return names, rows.Err()
+}
+
+// GetAttributeTranslations возвращает переводы атрибутов на язык: attribute_id -> перевод
+func GetAttributeTranslations(attributeIDs []int, lang string) (map[int]models.Translation, error) {
+	return getNameDescriptionTranslations(`
+        SELECT attribute_id, name, description FROM attribute_translations
+        WHERE attribute_id = ANY($1) AND lang = $2
+    `, attributeIDs, lang)
+}
+
+// GetProductTranslations возвращает переводы продуктов на язык: product_id -> перевод
+func GetProductTranslations(productIDs []int, lang string) (map[int]models.Translation, error) {
+	return getNameDescriptionTranslations(`
+        SELECT product_id, name, description FROM product_translations
+        WHERE product_id = ANY($1) AND lang = $2
+    `, productIDs, lang)
+}
+
+func getNameDescriptionTranslations(query string, ids []int, lang string) (map[int]models.Translation, error) {
+	rows, err := DB.Query(query, pq.Array(ids), lang)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить переводы: %v", err)
+	}
+	defer rows.Close()
+
+	translations := make(map[int]models.Translation)
+	for rows.Next() {
+		t := models.Translation{Lang: lang}
+		if err := rows.Scan(&t.ID, &t.Name, &t.Description); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании перевода: %v", err)
+		}
+		translations[t.ID] = t
+	}
+	return translations, rows.Err()
+}
+
+// GetAttributeOptionTranslations возвращает переводы значений атрибутов на язык:
+// attribute_id -> исходное значение -> перевод
+func GetAttributeOptionTranslations(attributeIDs []int, lang string) (map[int]map[string]string, error) {
+	rows, err := DB.Query(`
+        SELECT attribute_id, option, value FROM attribute_option_translations
+        WHERE attribute_id = ANY($1) AND lang = $2
+    `, pq.Array(attributeIDs), lang)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить переводы значений атрибутов: %v", err)
+	}
+	defer rows.Close()
+
+	options := make(map[int]map[string]string)
+	for rows.Next() {
+		var id int
+		var option, value string
+		if err := rows.Scan(&id, &option, &value); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании перевода значения: %v", err)
+		}
+		if options[id] == nil {
+			options[id] = make(map[string]string)
+		}
+		options[id][option] = value
+	}
+	return options, rows.Err()
+}
+
+// GetMissingTranslations возвращает до limit записей вида entity без перевода на язык и их общее количество
+func GetMissingTranslations(entity, lang string, limit int) ([]models.MissingTranslation, int, error) {
+	query, ok := missingTranslationQueries[entity]
+	if !ok {
+		return nil, 0, fmt.Errorf("неизвестный вид записи: %s", entity)
+	}
+
+	rows, err := DB.Query(query, lang, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("не удалось получить записи без перевода: %v", err)
+	}
+	defer rows.Close()
+
+	var items []models.MissingTranslation
+	total := 0
+	for rows.Next() {
+		item := models.MissingTranslation{Entity: entity}
+		if err := rows.Scan(&item.ID, &item.Option, &item.Source, &total); err != nil {
+			return nil, 0, fmt.Errorf("ошибка при сканировании записи без перевода: %v", err)
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("ошибка при итерации по строкам: %v", err)
+	}
+
+	return items, total, nil
+}
+
+// renameAttributeOptionTranslationsTx переносит переводы значения from на значение to,
+// если у to ещё нет перевода на тот же язык
+func renameAttributeOptionTranslationsTx(tx *sql.Tx, attributeID int, from, to string) error {
+	_, err := tx.Exec(`
+        UPDATE attribute_option_translations t
+        SET option = $3, updated_at = NOW()
+        WHERE t.attribute_id = $1 AND t.option = $2
+          AND NOT EXISTS (
+              SELECT 1 FROM attribute_option_translations o
+              WHERE o.attribute_id = $1 AND o.option = $3 AND o.lang = t.lang
+          )
+    `, attributeID, from, to)
+	if err != nil {
+		return fmt.Errorf("не удалось перенести переводы значения '%s': %v", from, err)
+	}
+	return nil
+}
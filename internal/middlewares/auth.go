@@ -45,10 +45,44 @@ func AuthMiddleware(jwtService jwt.JWTService) gin.HandlerFunc {
 			return
 		}
 
-		// Сохраняем userID в контексте для последующего использования
+		// Сохраняем userID и роли в контексте для последующего использования
 		c.Set("user_id", claims.UserID)
+		c.Set("roles", claims.Roles)
 
 		// Переходим к следующему обработчику
 		c.Next()
 	}
 }
+
+// HasRole проверяет, что у пользователя из токена есть хотя бы одна из ролей
+func HasRole(c *gin.Context, roles ...string) bool {
+	userRoles, ok := c.Get("roles")
+	if !ok {
+		return false
+	}
+	names, ok := userRoles.([]string)
+	if !ok {
+		return false
+	}
+	for _, name := range names {
+		for _, role := range roles {
+			if name == role {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RequireRoles пропускает только пользователей, у которых есть хотя бы одна из ролей.
+// Должен подключаться после AuthMiddleware.
+func RequireRoles(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !HasRole(c, roles...) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Недостаточно прав"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,58 @@
+// internal/middlewares/rate_limit.go
+
+package middlewares
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+)
+
+// rateLimitIdleTTL через сколько времени без запросов лимитер клиента удаляется
+const rateLimitIdleTTL = 10 * time.Minute
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// RateLimitByClient ограничивает частоту запросов с одного IP-адреса: burst запросов подряд,
+// дальше один запрос раз в every. У каждого подключения middleware свои счётчики.
+func RateLimitByClient(every time.Duration, burst int) gin.HandlerFunc {
+	var mu sync.Mutex
+	clients := make(map[string]*clientLimiter)
+	lastSweep := time.Now()
+
+	return func(c *gin.Context) {
+		now := time.Now()
+		ip := c.ClientIP()
+
+		mu.Lock()
+		if now.Sub(lastSweep) > rateLimitIdleTTL {
+			for key, client := range clients {
+				if now.Sub(client.lastSeen) > rateLimitIdleTTL {
+					delete(clients, key)
+				}
+			}
+			lastSweep = now
+		}
+		client, ok := clients[ip]
+		if !ok {
+			client = &clientLimiter{limiter: rate.NewLimiter(rate.Every(every), burst)}
+			clients[ip] = client
+		}
+		client.lastSeen = now
+		allowed := client.limiter.AllowN(now, 1)
+		mu.Unlock()
+
+		if !allowed {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Слишком много запросов, попробуйте позже"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
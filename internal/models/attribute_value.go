@@ -0,0 +1,40 @@
+// internal/models/attribute_value.go
+
+package models
+
+import "encoding/json"
+
+// AttributeValueEntry значение атрибута в справочнике
+type AttributeValueEntry struct {
+	ID          int             `json:"id"`
+	AttributeID int             `json:"attribute_id"`
+	Value       json.RawMessage `json:"value"`
+	SortOrder   int             `json:"sort_order"`
+	SwatchHex   string          `json:"swatch_hex,omitempty"` // Образец цвета #rrggbb
+	ImageURLs   []string        `json:"image_urls"`
+	Products    int             `json:"products"` // Продуктов, у которых выбрано значение
+}
+
+// AttributeValueEntryRequest создание или изменение значения атрибута. При изменении не указанные
+// поля не меняются: value без значения, sort_order и swatch_hex без значения, image_urls без значения.
+// Пустой swatch_hex убирает образец цвета, пустой список image_urls — изображения.
+type AttributeValueEntryRequest struct {
+	Value     interface{} `json:"value"`
+	Unit      string      `json:"unit,omitempty"` // Единица числового значения, по умолчанию единица атрибута
+	SortOrder *int        `json:"sort_order"`
+	SwatchHex *string     `json:"swatch_hex"`
+	ImageURLs []string    `json:"image_urls" binding:"omitempty,dive,url"`
+}
+
+// MergeAttributeValuesRequest объединение значений атрибута: продукты и вариации значений
+// source_ids переходят к значению target_id, значения source_ids удаляются
+type MergeAttributeValuesRequest struct {
+	TargetID  int   `json:"target_id" binding:"required"`
+	SourceIDs []int `json:"source_ids" binding:"required,min=1"`
+}
+
+// AttributeValueDuplicates значения атрибута, совпадающие без учёта регистра и пробелов
+type AttributeValueDuplicates struct {
+	Key    string                `json:"key"`
+	Values []AttributeValueEntry `json:"values"`
+}
@@ -0,0 +1,68 @@
+// internal/models/attribute_version.go
+
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AttributeVersion сохранённая версия определения атрибута
+type AttributeVersion struct {
+	ID            int             `json:"id"`
+	AttributeID   int             `json:"attribute_id"`
+	Version       int             `json:"version"`
+	Name          string          `json:"name"`
+	Description   string          `json:"description"`
+	TypeOfOption  string          `json:"type_of_option"`
+	Value         json.RawMessage `json:"value"`
+	Dimension     string          `json:"dimension,omitempty"`
+	Unit          string          `json:"unit,omitempty"`
+	ChangedBy     *int            `json:"changed_by"`
+	ChangeSummary string          `json:"change_summary"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// AttributeOptionUsage продукты, у которых сохранено удаляемое значение атрибута
+type AttributeOptionUsage struct {
+	Option     string `json:"option"`
+	ProductIDs []int  `json:"product_ids"`
+}
+
+// Статусы задания конвертации атрибута
+const (
+	ConversionJobPending = "pending"
+	ConversionJobRunning = "running"
+	ConversionJobDone    = "done"
+	ConversionJobFailed  = "failed"
+)
+
+// AttributeConversionRequest смена типа атрибута с конвертацией сохранённых значений.
+// Mapping заменяет значения до конвертации: "старое значение" -> "новое значение".
+type AttributeConversionRequest struct {
+	ToType  string            `json:"to_type" binding:"required,oneof=dropdown range switcher text numeric"`
+	Value   interface{}       `json:"value,omitempty"`
+	Mapping map[string]string `json:"mapping,omitempty"`
+}
+
+// AttributeConversionJob задание на смену типа атрибута
+type AttributeConversionJob struct {
+	ID          int               `json:"id"`
+	AttributeID int               `json:"attribute_id"`
+	FromType    string            `json:"from_type"`
+	ToType      string            `json:"to_type"`
+	Value       json.RawMessage   `json:"value"`
+	Mapping     map[string]string `json:"mapping"`
+	Status      string            `json:"status"`
+	Converted   int               `json:"converted"` // Сколько сохранённых значений сконвертировано
+	Error       string            `json:"error,omitempty"`
+	CreatedBy   int               `json:"created_by"`
+	CreatedAt   time.Time         `json:"created_at"`
+	FinishedAt  *time.Time        `json:"finished_at,omitempty"`
+}
+
+// StoredAttributeValue значение атрибута, сохранённое у продуктов или вариаций
+type StoredAttributeValue struct {
+	ID    int
+	Value json.RawMessage
+}
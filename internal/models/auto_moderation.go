@@ -0,0 +1,90 @@
+// internal/models/auto_moderation.go
+
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Коды правил автомодерации (таблица moderation_rules)
+const (
+	RuleBannedWords               = "banned_words"
+	RuleContactDetails            = "contact_details"
+	RuleMissingImages             = "missing_images"
+	RulePriceOutlier              = "price_outlier"
+	RuleMissingRequiredAttributes = "missing_required_attributes"
+	// RuleUnverifiedSupplier срабатывает на продукты поставщиков без проверки документов.
+	// Пока правило включено, такие продукты нельзя подтвердить и вручную.
+	RuleUnverifiedSupplier = "unverified_supplier"
+	// RuleAutoApprove не проверка, а настройка: подтверждать ли продукт, не нарушивший ни одного правила
+	RuleAutoApprove = "auto_approve"
+)
+
+// Действия правил и итоговые решения автомодерации
+const (
+	AutoModerationApprove = "approve"
+	AutoModerationReject  = "reject"
+	AutoModerationReview  = "review" // Передать модератору
+)
+
+// ModerationRule настраиваемое правило автомодерации
+type ModerationRule struct {
+	Code      string          `json:"code"`
+	Enabled   bool            `json:"enabled"`
+	Action    string          `json:"action"` // reject или review
+	Weight    int             `json:"weight"` // Вклад в итоговую оценку при срабатывании
+	Params    json.RawMessage `json:"params"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// UpdateModerationRuleRequest изменение правила автомодерации
+type UpdateModerationRuleRequest struct {
+	Enabled *bool           `json:"enabled"`
+	Action  string          `json:"action"`
+	Weight  *int            `json:"weight"`
+	Params  json.RawMessage `json:"params"`
+}
+
+// BannedWordsParams параметры правила banned_words
+type BannedWordsParams struct {
+	Words []string `json:"words"`
+}
+
+// PriceOutlierParams параметры правила price_outlier
+type PriceOutlierParams struct {
+	MinRatio   float64 `json:"min_ratio"`   // Цена ниже медианы * min_ratio считается подозрительной
+	MaxRatio   float64 `json:"max_ratio"`   // Цена выше медианы * max_ratio считается подозрительной
+	MinSamples int     `json:"min_samples"` // Минимум подтверждённых продуктов в категории для расчёта медианы
+}
+
+// FiredRule сработавшее правило с пояснением
+type FiredRule struct {
+	Code    string   `json:"code"`
+	Action  string   `json:"action"`
+	Message string   `json:"message"`
+	Details []string `json:"details,omitempty"`
+}
+
+// AutoModerationResult результат автомодерации продукта
+type AutoModerationResult struct {
+	ProductID  int         `json:"product_id"`
+	Outcome    string      `json:"outcome"`
+	Score      int         `json:"score"`
+	FiredRules []FiredRule `json:"fired_rules"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+// AutoModerationSubject данные продукта, по которым проверяются правила
+type AutoModerationSubject struct {
+	ProductID                 int
+	Name                      string
+	Description               string
+	Price                     float64
+	CategoryID                int
+	ImageCount                int
+	MissingRequiredAttributes []string
+	CategoryMedianPrice       float64
+	CategoryPriceSamples      int
+	SupplierVerified          bool // Поставщик прошёл проверку документов
+}
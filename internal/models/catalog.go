@@ -0,0 +1,37 @@
+// internal/models/catalog.go
+
+package models
+
+// GeoFilter поиск в радиусе от точки покупателя
+type GeoFilter struct {
+	Lat      float64
+	Lng      float64
+	RadiusKm float64
+}
+
+// NumericAttributeFilter отбор по числовому атрибуту. Границы в канонической единице атрибута,
+// nil — без границы. Диапазон range подходит, если пересекается с [Min, Max].
+type NumericAttributeFilter struct {
+	AttributeID int
+	Min         *float64
+	Max         *float64
+}
+
+// CatalogFilter параметры списка одобренных продуктов
+type CatalogFilter struct {
+	CategoryID int        // Вместе с подкатегориями
+	Near       *GeoFilter // Продукты рынков в радиусе, ближайшие первыми
+	Limit      int
+	Offset     int
+
+	// AttributeRanges отбор по числовым атрибутам из запроса: "<ID атрибута>:<от>..<до>",
+	// границы можно не указывать и можно указывать с единицей ("12:1,5 кг..3 кг")
+	AttributeRanges []string
+	// Sort сортировка по числовому атрибуту: "attr:<ID>" по возрастанию, "-attr:<ID>" по убыванию
+	Sort string
+
+	// Заполняются сервисом из AttributeRanges и Sort
+	NumericAttributes []NumericAttributeFilter
+	SortAttributeID   int
+	SortDesc          bool
+}
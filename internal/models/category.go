@@ -17,7 +17,8 @@ type Category struct {
 	Name     string        `json:"name"`
 	Path     string        `json:"path"`
 	ImageURL string        `json:"image_url"`
-	ParentID sql.NullInt64 `json:"parent_id"` // Используем sql.NullInt64
+	ParentID sql.NullInt64 `json:"parent_id"`      // Используем sql.NullInt64
+	Slug     string        `json:"slug,omitempty"` // URL категории из path, заполняется при поиске по slug
 }
 
 type CategoryNode struct {
@@ -36,6 +37,20 @@ type CategoryAttribute struct {
 	TypeOfOption *string         `json:"type_of_option"`
 	Value        json.RawMessage `json:"value"`
 	IsLinked     bool            `json:"is_linked"`
+
+	// Величина и каноническая единица числового атрибута
+	Dimension string `json:"dimension,omitempty"`
+	Unit      string `json:"unit,omitempty"`
+
+	// Обязательность, порядок вывода и участие в фильтрах задаёт администратор
+	IsRequired   bool `json:"is_required"`
+	SortOrder    int  `json:"sort_order"`
+	IsFilterable bool `json:"is_filterable"`
+
+	// Атрибут унаследован от категории-предка
+	Inherited          bool   `json:"inherited"`
+	SourceCategoryID   int    `json:"source_category_id"`
+	SourceCategoryPath string `json:"source_category_path"`
 }
 
 type CategoryAttributeResponse struct {
@@ -45,6 +60,11 @@ type CategoryAttributeResponse struct {
 	TypeOfOption *string     `json:"type_of_option,omitempty"` // *string с omitempty
 	Value        interface{} `json:"value"`
 	IsLinked     bool        `json:"is_linked"`
+	Dimension    string      `json:"dimension,omitempty"`
+	Unit         string      `json:"unit,omitempty"`
+	IsRequired   bool        `json:"is_required"`
+	SortOrder    int         `json:"sort_order"`
+	IsFilterable bool        `json:"is_filterable"`
 }
 
 type AddCategoryAttributesRequest struct {
@@ -58,4 +78,54 @@ type AttributeRequest struct {
 	TypeOfOption string      `json:"type_of_option" binding:"required,oneof=dropdown range switcher text numeric"`
 	Value        interface{} `json:"value,omitempty"`
 	IsLinked     bool        `json:"is_linked"`
+
+	// Dimension и Unit задают величину и каноническую единицу атрибутов numeric и range:
+	// значения продуктов пересчитываются в Unit. Единица без величины определяет величину сама.
+	Dimension string `json:"dimension" binding:"omitempty,max=20"`
+	Unit      string `json:"unit" binding:"omitempty,max=20"`
+
+	// ValueMigrations переносит сохранённые значения dropdown при изменении списка опций:
+	// "старая опция" -> "новая опция". Нужен для переименования опции и для удаления опции,
+	// которая уже выбрана у продуктов.
+	ValueMigrations map[string]string `json:"value_migrations,omitempty"`
+}
+
+// UpdateAttributeFlagsRequest изменение флагов атрибута, не указанные поля не меняются
+type UpdateAttributeFlagsRequest struct {
+	IsRequired   *bool `json:"is_required"`
+	SortOrder    *int  `json:"sort_order"`
+	IsFilterable *bool `json:"is_filterable"`
+}
+
+// CreateCategoryRequest создание подкатегории. Path строится из path родителя и метки;
+// если метка не указана, она получается транслитерацией названия.
+type CreateCategoryRequest struct {
+	ParentID *int   `json:"parent_id"` // Без родителя создаётся корневая категория
+	Name     string `json:"name" binding:"required,max=255"`
+	Label    string `json:"label" binding:"omitempty,max=100"`
+	ImageURL string `json:"image_url" binding:"omitempty,url"`
+}
+
+// RenameCategoryRequest изменение названия категории, path не меняется
+type RenameCategoryRequest struct {
+	Name string `json:"name" binding:"required,max=255"`
+}
+
+// MoveCategoryRequest перенос категории со всеми подкатегориями
+type MoveCategoryRequest struct {
+	ParentID *int   `json:"parent_id"`                         // Без родителя категория становится корневой
+	Label    string `json:"label" binding:"omitempty,max=100"` // Новая метка в path, по умолчанию прежняя
+}
+
+// CategoryDeleteResult итог удаления категории с подкатегориями
+type CategoryDeleteResult struct {
+	DeletedCategories   int `json:"deleted_categories"`
+	ReassignedProducts  int `json:"reassigned_products"`
+	ReassignedSuppliers int `json:"reassigned_suppliers"`
+	MovedAttributes     int `json:"moved_attributes"`
+}
+
+// HiddenAttributeRequest скрытие унаследованного атрибута у категории и её подкатегорий
+type HiddenAttributeRequest struct {
+	Name string `json:"name" binding:"required,max=255"`
 }
@@ -0,0 +1,55 @@
+// internal/models/color.go
+
+package models
+
+// ColorFamily семейство цветов для фильтров каталога
+type ColorFamily struct {
+	Code      string `json:"code"`
+	Name      string `json:"name"`
+	SwatchHex string `json:"swatch_hex,omitempty"`
+	SortOrder int    `json:"sort_order"`
+}
+
+// PaletteColor цвет палитры, из которой поставщики выбирают цвета вариаций
+type PaletteColor struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	Code       string `json:"code,omitempty"`   // #rrggbb
+	Family     string `json:"family,omitempty"` // Код семейства цветов
+	SortOrder  int    `json:"sort_order"`
+	Variations int    `json:"variations"` // Вариаций с этим цветом
+}
+
+// PaletteColorRequest создание или изменение цвета палитры. При изменении не указанные поля не меняются.
+type PaletteColorRequest struct {
+	Name      *string `json:"name"`
+	Code      *string `json:"code"` // #rrggbb или #rgb
+	Family    *string `json:"family"`
+	SortOrder *int    `json:"sort_order"`
+}
+
+// ColorSwatch цвет в карточке продукта с вариациями, у которых он выбран
+type ColorSwatch struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	Code         string `json:"code,omitempty"`
+	Family       string `json:"family,omitempty"`
+	VariationIDs []int  `json:"variation_ids"`
+}
+
+// ColorFacetValue цвет в фасете каталога
+type ColorFacetValue struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Code     string `json:"code,omitempty"`
+	Products int    `json:"products"`
+}
+
+// ColorFacet семейство цветов в фасете каталога: число одобренных продуктов и цвета семейства
+type ColorFacet struct {
+	Family    string            `json:"family"`
+	Name      string            `json:"name"`
+	SwatchHex string            `json:"swatch_hex,omitempty"`
+	Products  int               `json:"products"`
+	Colors    []ColorFacetValue `json:"colors"`
+}
@@ -0,0 +1,83 @@
+// internal/models/image.go
+
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ImageOwner определяет, к чему относится изображение: к продукту или к вариации
+type ImageOwner string
+
+const (
+	ImageOwnerProduct   ImageOwner = "product"
+	ImageOwnerVariation ImageOwner = "variation"
+	// ImageOwnerCategory используется только для хэшей: у категории одно изображение в categories.image_url
+	ImageOwnerCategory ImageOwner = "category"
+)
+
+// Image одно изображение продукта или вариации
+type Image struct {
+	ID        int    `json:"id"`
+	OwnerID   int    `json:"owner_id"` // product_id или product_variation_id
+	ImageURL  string `json:"image_url"`
+	Position  int    `json:"position"`
+	AltText   string `json:"alt_text"`
+	IsPrimary bool   `json:"is_primary"`
+
+	Variants []ImageVariant `json:"variants"`
+	SrcSet   string         `json:"srcset"`
+}
+
+// ImageVariant миниатюра изображения определённой ширины
+type ImageVariant struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	URL    string `json:"url"`
+}
+
+// UploadedImage результат загрузки изображения со всеми миниатюрами
+type UploadedImage struct {
+	URL      string         `json:"url"` // Самая крупная миниатюра
+	Variants []ImageVariant `json:"variants"`
+	SrcSet   string         `json:"srcset"`
+	Hash     uint64         `json:"-"` // Перцептивный хэш (dHash)
+}
+
+// BuildSrcSet формирует значение атрибута srcset: "url 200w, url 600w, ..."
+func BuildSrcSet(variants []ImageVariant) string {
+	parts := make([]string, 0, len(variants))
+	for _, v := range variants {
+		parts = append(parts, fmt.Sprintf("%s %dw", v.URL, v.Width))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ImageHash перцептивный хэш загруженного изображения
+type ImageHash struct {
+	ID         int
+	OwnerType  ImageOwner
+	OwnerID    int
+	ImageID    int // 0 для изображений категорий
+	ImageURL   string
+	Hash       uint64
+	ProductID  int // 0, если изображение не относится к продукту
+	SupplierID int
+}
+
+// DuplicateImageMatch изображение продукта, похожее на изображение другого поставщика
+type DuplicateImageMatch struct {
+	ImageURL           string `json:"image_url"`
+	MatchedProductID   int    `json:"matched_product_id"`
+	MatchedProductName string `json:"matched_product_name"`
+	MatchedSupplierID  int    `json:"matched_supplier_id"`
+	MatchedImageURL    string `json:"matched_image_url"`
+	MatchedImagesLink  string `json:"matched_images_link"` // Ссылка на изображения найденного продукта
+	Distance           int    `json:"distance"`            // Расстояние Хэмминга между хэшами, 0 — идентичные
+}
+
+// ReorderImagesRequest новый порядок изображений
+type ReorderImagesRequest struct {
+	ImageIDs []int `json:"image_ids" binding:"required,min=1"`
+}
@@ -2,6 +2,82 @@ package models
 
 // Market модель рынка
 type Market struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
+	ID        int      `json:"id"`
+	Name      string   `json:"name"`
+	Address   string   `json:"address,omitempty"`
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+	OpensAt   string   `json:"opens_at,omitempty"`  // ЧЧ:ММ
+	ClosesAt  string   `json:"closes_at,omitempty"` // ЧЧ:ММ
+	DaysOff   []int    `json:"days_off"`            // Дни недели ISO: 1 — понедельник, 7 — воскресенье
+}
+
+// MarketRequest создание или изменение рынка, не указанные поля не меняются.
+// Пустая строка убирает адрес и часы работы.
+type MarketRequest struct {
+	Name      *string  `json:"name" binding:"omitempty,max=255"`
+	Address   *string  `json:"address"`
+	Latitude  *float64 `json:"latitude" binding:"omitempty,min=-90,max=90"`
+	Longitude *float64 `json:"longitude" binding:"omitempty,min=-180,max=180"`
+	OpensAt   *string  `json:"opens_at"`
+	ClosesAt  *string  `json:"closes_at"`
+	DaysOff   *[]int   `json:"days_off" binding:"omitempty,dive,min=1,max=7"`
+
+	// ClearLocation убирает координаты рынка
+	ClearLocation bool `json:"clear_location"`
+}
+
+// MarketRow ряд рынка с местами
+type MarketRow struct {
+	ID        int           `json:"id"`
+	MarketID  int           `json:"market_id"`
+	Name      string        `json:"name"`
+	SortOrder int           `json:"sort_order"`
+	Stalls    []MarketStall `json:"stalls"`
+}
+
+// MarketStall место в ряду
+type MarketStall struct {
+	ID         int    `json:"id"`
+	RowID      int    `json:"row_id"`
+	Name       string `json:"name"`
+	SupplierID *int   `json:"supplier_id"` // Поставщик, занявший место
+}
+
+// MarketDetails рынок с рядами и местами
+type MarketDetails struct {
+	Market
+	Rows []MarketRow `json:"rows"`
+}
+
+// MarketRowRequest создание или изменение ряда
+type MarketRowRequest struct {
+	Name      string `json:"name" binding:"required,max=50"`
+	SortOrder int    `json:"sort_order"`
+}
+
+// MarketStallsRequest добавление мест в ряд
+type MarketStallsRequest struct {
+	Names []string `json:"names" binding:"required,min=1,dive,required,max=50"`
+}
+
+// MarketStallRequest переименование места
+type MarketStallRequest struct {
+	Name string `json:"name" binding:"required,max=50"`
+}
+
+// RowSupplier поставщик в ряду рынка
+type RowSupplier struct {
+	SupplierID  int    `json:"supplier_id"`
+	DisplayName string `json:"display_name"`
+	LogoURL     string `json:"logo_url"`
+	StallID     int    `json:"stall_id"`
+	StallName   string `json:"stall_name"`
+}
+
+// NearbyMarket рынок в радиусе поиска
+type NearbyMarket struct {
+	Market
+	DistanceKm float64 `json:"distance_km"`
+	Products   int     `json:"products"` // Одобренные продукты рынка, с category_id — в категории
 }
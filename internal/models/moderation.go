@@ -0,0 +1,120 @@
+// internal/models/moderation.go
+
+package models
+
+import "time"
+
+// Статусы продукта (таблица product.status_id)
+const (
+	ProductStatusPending  = 2 // Ожидает модерации
+	ProductStatusRejected = 3 // Отклонён
+	ProductStatusApproved = 4 // Подтверждён
+)
+
+// ProductStatusNames названия статусов продукта для истории модерации
+var ProductStatusNames = map[int]string{
+	ProductStatusPending:  "На модерации",
+	ProductStatusRejected: "Отклонён",
+	ProductStatusApproved: "Подтверждён",
+}
+
+// Типы записей истории модерации
+const (
+	ModerationHistoryStatusChange = "status_change"
+	ModerationHistoryComment      = "comment"
+)
+
+// ModerationHistoryEntry запись хронологии модерации продукта: смена статуса или комментарий
+type ModerationHistoryEntry struct {
+	Type         string    `json:"type"`
+	CreatedAt    time.Time `json:"created_at"`
+	UserID       *int      `json:"user_id"`
+	UserName     string    `json:"user_name"`
+	ByModerator  bool      `json:"by_moderator"` // Запись сделал модератор или администратор
+	FromStatusID *int      `json:"from_status_id,omitempty"`
+	FromStatus   string    `json:"from_status,omitempty"`
+	ToStatusID   *int      `json:"to_status_id,omitempty"`
+	ToStatus     string    `json:"to_status,omitempty"`
+	Comment      string    `json:"comment,omitempty"`
+
+	RejectionReasons []ProductRejectionReason `json:"rejection_reasons,omitempty"`
+}
+
+// ModerationQueueFilter параметры выборки очереди модерации
+type ModerationQueueFilter struct {
+	MarketID   int
+	CategoryID int // Вместе с подкатегориями
+	Limit      int
+	Offset     int
+}
+
+// ModerationQueueItem продукт, ожидающий модерации
+type ModerationQueueItem struct {
+	ProductID       int        `json:"product_id"`
+	Name            string     `json:"name"`
+	CategoryID      int        `json:"category_id"`
+	MarketID        int        `json:"market_id"`
+	SupplierID      int        `json:"supplier_id"`
+	SubmittedAt     time.Time  `json:"submitted_at"`
+	TimeInQueue     int64      `json:"time_in_queue_seconds"`
+	SLABreached     bool       `json:"sla_breached"`
+	ClaimedBy       *int       `json:"claimed_by"`
+	ClaimExpiresAt  *time.Time `json:"claim_expires_at"`
+	DuplicateImages int        `json:"duplicate_images"` // Совпадения изображений с другими поставщиками
+
+	AutoModeration *AutoModerationResult `json:"auto_moderation,omitempty"` // Правила, сработавшие при автомодерации
+}
+
+// ModerationQueue страница очереди модерации со сводкой по SLA
+type ModerationQueue struct {
+	Items       []ModerationQueueItem `json:"items"`
+	Total       int                   `json:"total"`
+	SLABreaches int                   `json:"sla_breaches"`
+	SLAHours    int                   `json:"sla_hours"`
+}
+
+// ModerationClaim захват продукта модератором на время проверки
+type ModerationClaim struct {
+	ProductID   int       `json:"product_id"`
+	ModeratorID int       `json:"moderator_id"`
+	ClaimedAt   time.Time `json:"claimed_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// Действия массовой модерации
+const (
+	BulkModerationApprove = "approve"
+	BulkModerationReject  = "reject"
+)
+
+// BulkModerationRequest массовое подтверждение или отклонение продуктов.
+// Content и Reasons применяются ко всем продуктам, у которых не заданы свои.
+type BulkModerationRequest struct {
+	Action  string                 `json:"action" binding:"required,oneof=approve reject"`
+	Items   []BulkModerationItem   `json:"items" binding:"required,min=1,dive"`
+	Content string                 `json:"content"`
+	Reasons []RejectionReasonInput `json:"reasons" binding:"dive"`
+}
+
+// BulkModerationItem продукт в массовой модерации со своими комментарием и причинами
+type BulkModerationItem struct {
+	ProductID int                    `json:"product_id" binding:"required"`
+	Content   string                 `json:"content,omitempty"`
+	Reasons   []RejectionReasonInput `json:"reasons,omitempty" binding:"dive"`
+}
+
+// BulkModerationItemResult результат модерации одного продукта
+type BulkModerationItemResult struct {
+	ProductID int    `json:"product_id"`
+	Success   bool   `json:"success"`
+	Status    int    `json:"status"` // HTTP-код, который вернул бы одиночный запрос
+	Error     string `json:"error,omitempty"`
+	Err       error  `json:"-"`
+}
+
+// BulkModerationResult итог массовой модерации
+type BulkModerationResult struct {
+	Results   []BulkModerationItemResult `json:"results"`
+	Succeeded int                        `json:"succeeded"`
+	Failed    int                        `json:"failed"`
+}
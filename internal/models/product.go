@@ -21,9 +21,11 @@ type AttributeValue struct {
 	Value       string `json:"value"`
 }
 
+// Color цвет вариации из палитры. Цвет ищется по id, иначе по названию, иначе по коду.
 type Color struct {
-	Name string `json:"name"`
-	Code string `json:"code"`
+	ID   int    `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+	Code string `json:"code,omitempty"`
 }
 
 type UpdateProductRequest struct {
@@ -76,12 +78,14 @@ type ProductVariationRequest struct {
 	SKU        string                  `json:"sku"`
 	Attributes []AttributeValueRequest `json:"attributes" binding:"required"`
 	Price      float64                 `json:"price"`
+	Colors     []Color                 `json:"colors"` // nil при обновлении оставляет цвета вариации без изменений
 	Images     []*multipart.FileHeader // Images will be assigned later
 }
 
 type AttributeValueRequest struct {
 	Name  string      `json:"name" binding:"required"`
 	Value interface{} `json:"value" binding:"required"`
+	Unit  string      `json:"unit,omitempty"` // Единица числового значения, по умолчанию единица атрибута
 }
 
 type VariationAttributeValue struct {
@@ -93,8 +97,11 @@ type VariationAttributeValue struct {
 type ProductVariationImage struct {
 	ID                 int    `json:"id"`                   // Уникальный идентификатор изображения
 	ProductVariationID int    `json:"product_variation_id"` // ID вариации продукта, к которой относится изображение
-	ImageURLs          string `json:"image_urls"`           // URL изображения
+	ImageURL           string `json:"image_url"`            // URL изображения
 	ImagePath          string `json:"image_path"`           // Путь к изображению на сервере
+	Position           int    `json:"position"`             // Порядковый номер изображения
+	AltText            string `json:"alt_text"`             // Альтернативный текст
+	IsPrimary          bool   `json:"is_primary"`           // Главное изображение вариации
 }
 
 type AddProductRequest struct {
@@ -127,6 +134,30 @@ type Attribute struct {
 	TypeOfOption string          `json:"type_of_option"`
 	Value        json.RawMessage `json:"value"`
 	IsLinked     bool            `json:"is_linked"`
+	Dimension    string          `json:"dimension,omitempty"`
+	Unit         string          `json:"unit,omitempty"`
+	IsRequired   bool            `json:"is_required"`
+	SortOrder    int             `json:"sort_order"`
+	IsFilterable bool            `json:"is_filterable"`
+
+	// Атрибут унаследован от категории-предка
+	Inherited          bool   `json:"inherited"`
+	SourceCategoryID   int    `json:"source_category_id"`
+	SourceCategoryPath string `json:"source_category_path"`
+}
+
+// ProductAttributeValueView значение атрибута продукта или его вариации для покупателя.
+// Числовые значения возвращаются в единице Unit.
+type ProductAttributeValueView struct {
+	AttributeID  int             `json:"attribute_id"`
+	Name         string          `json:"name"`
+	TypeOfOption string          `json:"type_of_option"`
+	VariationID  *int            `json:"variation_id,omitempty"`
+	Value        json.RawMessage `json:"value"`
+	Unit         string          `json:"unit,omitempty"`
+
+	Dimension string   `json:"-"`
+	Numeric   *float64 `json:"-"` // Значение в канонической единице атрибута
 }
 
 type ProductAttributeValue struct {
@@ -135,22 +166,35 @@ type ProductAttributeValue struct {
 }
 
 type Product struct {
-	ID          int     `json:"id"`
-	Name        string  `json:"name"`
-	CategoryID  int     `json:"category_id"`
-	MarketID    int     `json:"market_id"`
-	StatusID    int     `json:"status_id"`
-	SupplierID  int     `json:"supplier_id"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
-	Stock       int     `json:"stock"`
+	ID              int     `json:"id"`
+	Name            string  `json:"name"`
+	Slug            string  `json:"slug,omitempty"`
+	CategoryID      int     `json:"category_id"`
+	CategoryName    string  `json:"category_name,omitempty"`
+	MarketID        int     `json:"market_id"`
+	StatusID        int     `json:"status_id"`
+	SupplierID      int     `json:"supplier_id"`
+	Description     string  `json:"description"`
+	Price           float64 `json:"price"`
+	Stock           int     `json:"stock"`
+	RejectionReason string  `json:"rejection_reason,omitempty"` // Комментарий к последнему отклонению
+
+	RejectionDetails []ProductRejectionReason `json:"rejection_details,omitempty"` // Причины последнего отклонения
+
+	Colors []ColorSwatch `json:"colors,omitempty"` // Цвета вариаций
+
+	VerifiedSeller bool `json:"verified_seller"` // Поставщик прошёл проверку документов
+	Available      bool `json:"available"`       // false, пока поставщик в отпуске
 }
 
 type ProductImage struct {
 	ID        int    `json:"id"`
 	ProductID int    `json:"product_id"`
-	ImageURLs string `json:"image_urls"` // Хранит JSON-строку, которая представляет массив ссылок
+	ImageURL  string `json:"image_url"`
 	ImagePath string `json:"image_path"`
+	Position  int    `json:"position"`
+	AltText   string `json:"alt_text"`
+	IsPrimary bool   `json:"is_primary"`
 }
 
 type Comment struct {
@@ -162,6 +206,8 @@ type Comment struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// ModerationCommentRequest решение модератора. При отклонении нужен комментарий или хотя бы одна причина.
 type ModerationCommentRequest struct {
-	Content string `json:"content" binding:"required"`
+	Content string                 `json:"content"`
+	Reasons []RejectionReasonInput `json:"reasons" binding:"dive"`
 }
@@ -0,0 +1,66 @@
+// internal/models/product_stats.go
+
+package models
+
+import "time"
+
+// Типы событий продукта для статистики поставщика
+const (
+	ProductEventView         = "view"
+	ProductEventFavorite     = "favorite"
+	ProductEventContactClick = "contact_click"
+)
+
+// ProductStats показатели продукта за период
+type ProductStats struct {
+	ProductID     int    `json:"product_id"`
+	Name          string `json:"name"`
+	StatusID      int    `json:"status_id"`
+	Views         int    `json:"views"`
+	Favorites     int    `json:"favorites"`
+	ContactClicks int    `json:"contact_clicks"`
+}
+
+// ProductStatsTotals показатели всех продуктов поставщика за период
+type ProductStatsTotals struct {
+	Views         int `json:"views"`
+	Favorites     int `json:"favorites"`
+	ContactClicks int `json:"contact_clicks"`
+}
+
+// ProductStatusCount количество продуктов в статусе
+type ProductStatusCount struct {
+	StatusID int    `json:"status_id"`
+	Status   string `json:"status"`
+	Count    int    `json:"count"`
+}
+
+// LowStockVariation вариация с остатком не больше порога
+type LowStockVariation struct {
+	VariationID int    `json:"variation_id"`
+	ProductID   int    `json:"product_id"`
+	ProductName string `json:"product_name"`
+	SKU         string `json:"sku"`
+	Stock       int    `json:"stock"`
+}
+
+// SupplierDashboardFilter параметры кабинета поставщика
+type SupplierDashboardFilter struct {
+	From              time.Time // Первый день периода
+	To                time.Time // Последний день периода включительно
+	LowStockThreshold int
+}
+
+// SupplierDashboard кабинет поставщика: продукты по статусам, малые остатки и показатели за период.
+// Показатели берутся из дневных итогов и отстают от событий на время между запусками агрегатора.
+type SupplierDashboard struct {
+	From              string               `json:"from"`
+	To                string               `json:"to"`
+	StatusCounts      []ProductStatusCount `json:"status_counts"`
+	LowStockThreshold int                  `json:"low_stock_threshold"`
+	LowStock          []LowStockVariation  `json:"low_stock"`
+	Totals            ProductStatsTotals   `json:"totals"`
+	Products          []ProductStats       `json:"products"`
+	TopProducts       []ProductStats       `json:"top_products"`
+	StatsUpdatedAt    *time.Time           `json:"stats_updated_at"` // Последний запуск агрегатора
+}
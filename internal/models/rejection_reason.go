@@ -0,0 +1,58 @@
+// internal/models/rejection_reason.go
+
+package models
+
+import "time"
+
+// RejectionReason причина отклонения из справочника
+type RejectionReason struct {
+	ID          int    `json:"id"`
+	Code        string `json:"code"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	IsActive    bool   `json:"is_active"`
+	SortOrder   int    `json:"sort_order"`
+}
+
+// RejectionReasonRequest создание или изменение причины в справочнике
+type RejectionReasonRequest struct {
+	Code        string `json:"code" binding:"required,max=50"`
+	Title       string `json:"title" binding:"required,max=255"`
+	Description string `json:"description"`
+	IsActive    *bool  `json:"is_active"`
+	SortOrder   int    `json:"sort_order"`
+}
+
+// RejectionReasonInput причина, указанная модератором при отклонении продукта.
+// Field, VariationID и ImageID необязательны и уточняют, что именно нужно исправить.
+// ImageID относится к изображению вариации, если указан VariationID, иначе к изображению продукта.
+type RejectionReasonInput struct {
+	Code        string `json:"code" binding:"required"`
+	Field       string `json:"field,omitempty" binding:"max=100"`
+	VariationID *int   `json:"variation_id,omitempty"`
+	ImageID     *int   `json:"image_id,omitempty"`
+	Note        string `json:"note,omitempty"`
+}
+
+// ProductRejectionReason причина, по которой продукт был отклонён
+type ProductRejectionReason struct {
+	ID          int       `json:"id"`
+	ProductID   int       `json:"product_id"`
+	CommentID   int       `json:"comment_id"`
+	ReasonID    int       `json:"reason_id"`
+	Code        string    `json:"code"`
+	Title       string    `json:"title"`
+	Field       string    `json:"field,omitempty"`
+	VariationID *int      `json:"variation_id,omitempty"`
+	ImageID     *int      `json:"image_id,omitempty"`
+	Note        string    `json:"note,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// RejectionReasonStat сколько раз причина указывалась при отклонении
+type RejectionReasonStat struct {
+	Code     string `json:"code"`
+	Title    string `json:"title"`
+	Count    int    `json:"count"`    // Сколько раз указана
+	Products int    `json:"products"` // Сколько разных продуктов
+}
@@ -2,6 +2,13 @@
 
 package models
 
+// Названия ролей (таблица roles.name)
+const (
+	RoleSupplier  = "supplier"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
 type Role struct {
 	ID   int    `json:"id"`
 	Name string `json:"name"`
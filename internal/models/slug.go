@@ -0,0 +1,9 @@
+// internal/models/slug.go
+
+package models
+
+// Сущности, для которых записываются переадресации со старых slug
+const (
+	SlugEntityProduct  = "product"
+	SlugEntityCategory = "category"
+)
@@ -9,14 +9,18 @@ type Supplier struct {
 	ID          int        `json:"id"`
 	Name        string     `json:"name"`
 	UserID      int        `json:"user_id"`
-	IsVerified  bool       `json:"is_verified"`
+	IsVerified  bool       `json:"is_verified"` // Телефон подтверждён через WhatsApp
 	PlaceName   string     `json:"place_name"`
 	RowName     string     `json:"row_name"`
 	PhoneNumber string     `json:"phone_number"`
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
 	MarketID    int        `json:"market_id"`
+	StallID     int        `json:"stall_id,omitempty"` // Место на рынке, place_name и row_name копируют его название и название ряда
+	RowID       int        `json:"row_id,omitempty"`
 	Categories  []Category `json:"categories,omitempty"`
+
+	VerificationStatus string `json:"verification_status"` // Проверка по документам: none, pending, approved или rejected
 }
 
 type RegisterSupplierRequest struct {
@@ -38,3 +42,84 @@ type UpdateSupplierRequest struct {
 type UpdateSupplierDetailsResponse struct {
 	Message string `json:"message"`
 }
+
+// Способы связи с поставщиком на витрине
+const (
+	SupplierContactPhone     = "phone"
+	SupplierContactWhatsApp  = "whatsapp"
+	SupplierContactTelegram  = "telegram"
+	SupplierContactInstagram = "instagram"
+)
+
+// SupplierProfile оформление витрины и способы связи, которыми управляет поставщик
+type SupplierProfile struct {
+	LogoURL     string `json:"logo_url"`
+	BannerURL   string `json:"banner_url"`
+	Description string `json:"description"`
+	Telegram    string `json:"telegram"`
+	Instagram   string `json:"instagram"`
+	ShowPhone   bool   `json:"show_phone"`
+}
+
+// UpdateSupplierProfileRequest изменение профиля витрины, не указанные поля не меняются.
+// Логотип и баннер передаются файлами logo и banner в том же multipart-запросе.
+type UpdateSupplierProfileRequest struct {
+	Description  *string `form:"description" json:"description" binding:"omitempty,max=2000"`
+	Telegram     *string `form:"telegram" json:"telegram" binding:"omitempty,max=64"`
+	Instagram    *string `form:"instagram" json:"instagram" binding:"omitempty,max=64"`
+	ShowPhone    *bool   `form:"show_phone" json:"show_phone"`
+	RemoveLogo   bool    `form:"remove_logo" json:"remove_logo"`
+	RemoveBanner bool    `form:"remove_banner" json:"remove_banner"`
+}
+
+// SupplierContact способ связи с поставщиком
+type SupplierContact struct {
+	Type  string `json:"type"` // phone, whatsapp, telegram или instagram
+	Value string `json:"value"`
+	URL   string `json:"url"`
+}
+
+// ProductCard одобренный продукт в списке
+type ProductCard struct {
+	ID         int     `json:"id"`
+	Name       string  `json:"name"`
+	Slug       string  `json:"slug"`
+	CategoryID int     `json:"category_id"`
+	MarketID   int     `json:"market_id"`
+	SupplierID int     `json:"supplier_id"`
+	Price      float64 `json:"price"`
+	ImageURL   string  `json:"image_url"` // Основное изображение
+
+	VerifiedSeller bool `json:"verified_seller"` // Поставщик прошёл проверку документов
+	Available      bool `json:"available"`       // false, пока поставщик в отпуске
+
+	DistanceKm *float64 `json:"distance_km,omitempty"` // Расстояние до рынка при поиске рядом
+}
+
+// ProductCardPage страница списка продуктов
+type ProductCardPage struct {
+	Items  []ProductCard `json:"items"`
+	Total  int           `json:"total"`
+	Limit  int           `json:"limit"`
+	Offset int           `json:"offset"`
+}
+
+// SupplierStorefront публичная витрина поставщика
+type SupplierStorefront struct {
+	ID             int               `json:"id"`
+	DisplayName    string            `json:"display_name"`
+	IsVerified     bool              `json:"is_verified"`
+	VerifiedSeller bool              `json:"verified_seller"` // Поставщик прошёл проверку документов
+	LogoURL        string            `json:"logo_url"`
+	BannerURL      string            `json:"banner_url"`
+	Description    string            `json:"description"`
+	Market         *Market           `json:"market"` // Рынок не указан, пока поставщик не заполнил данные
+	RowID          int               `json:"row_id,omitempty"`
+	StallID        int               `json:"stall_id,omitempty"`
+	PlaceName      string            `json:"place_name"`
+	RowName        string            `json:"row_name"`
+	Categories     []Category        `json:"categories"`
+	Contacts       []SupplierContact `json:"contacts"`
+	Schedule       SupplierSchedule  `json:"schedule"`
+	Products       ProductCardPage   `json:"products"`
+}
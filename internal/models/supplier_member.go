@@ -0,0 +1,68 @@
+// internal/models/supplier_member.go
+
+package models
+
+import "time"
+
+// Роли участников команды поставщика
+const (
+	SupplierRoleOwner         = "owner"          // Владелец: всё, включая команду и проверку документов
+	SupplierRoleManager       = "manager"        // Менеджер: продукты, витрина, место на рынке, приглашение контент-редакторов
+	SupplierRoleContentEditor = "content_editor" // Контент-редактор: только продукты и их изображения
+)
+
+// Статусы приглашений в команду поставщика
+const (
+	SupplierInvitePending  = "pending"
+	SupplierInviteAccepted = "accepted"
+	SupplierInviteRevoked  = "revoked"
+)
+
+// SupplierMember участник команды поставщика
+type SupplierMember struct {
+	SupplierID  int       `json:"supplier_id"`
+	UserID      int       `json:"user_id"`
+	PhoneNumber string    `json:"phone_number"` // Username пользователя
+	Role        string    `json:"role"`
+	InvitedBy   int       `json:"invited_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// SupplierInvite приглашение в команду поставщика по номеру телефона
+type SupplierInvite struct {
+	ID          int       `json:"id"`
+	SupplierID  int       `json:"supplier_id"`
+	UserID      int       `json:"-"`
+	PhoneNumber string    `json:"phone_number"`
+	Role        string    `json:"role"`
+	Status      string    `json:"status"`
+	InvitedBy   int       `json:"invited_by,omitempty"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// SupplierTeam участники команды и ожидающие приглашения
+type SupplierTeam struct {
+	Members []SupplierMember `json:"members"`
+	Invites []SupplierInvite `json:"invites"`
+}
+
+// InviteSupplierMemberRequest приглашение участника в команду
+type InviteSupplierMemberRequest struct {
+	PhoneNumber string `json:"phone_number" binding:"required,e164"`
+	Role        string `json:"role" binding:"required"` // manager или content_editor
+}
+
+// AcceptSupplierInviteRequest принятие приглашения в команду кодом из WhatsApp.
+// Пароль обязателен, если у приглашённого его ещё нет.
+type AcceptSupplierInviteRequest struct {
+	PhoneNumber     string `json:"phone_number" binding:"required,e164"`
+	Code            string `json:"code" binding:"required,len=6"`
+	Password        string `json:"password" binding:"omitempty,min=6"`
+	ConfirmPassword string `json:"confirm_password" binding:"eqfield=Password"`
+}
+
+// UpdateSupplierMemberRequest смена роли участника команды
+type UpdateSupplierMemberRequest struct {
+	Role string `json:"role" binding:"required"` // manager или content_editor
+}
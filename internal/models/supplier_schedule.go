@@ -0,0 +1,61 @@
+// internal/models/supplier_schedule.go
+
+package models
+
+// Поведение продуктов поставщика в отпуске
+const (
+	VacationModeUnavailable = "unavailable" // Продукты остаются в каталоге с отметкой «нет в наличии»
+	VacationModeHidden      = "hidden"      // Продукты не показываются покупателям
+)
+
+// SupplierWorkingDay часы работы в день недели
+type SupplierWorkingDay struct {
+	Weekday  int    `json:"weekday"`   // ISO: 1 — понедельник, 7 — воскресенье
+	OpensAt  string `json:"opens_at"`  // ЧЧ:ММ
+	ClosesAt string `json:"closes_at"` // ЧЧ:ММ
+}
+
+// SupplierScheduleException часы работы на отдельную дату вместо обычных
+type SupplierScheduleException struct {
+	Date     string `json:"date"` // ГГГГ-ММ-ДД
+	Closed   bool   `json:"closed"`
+	OpensAt  string `json:"opens_at,omitempty"`  // ЧЧ:ММ, если не closed
+	ClosesAt string `json:"closes_at,omitempty"` // ЧЧ:ММ, если не closed
+	Note     string `json:"note"`
+}
+
+// SupplierVacation отпуск поставщика
+type SupplierVacation struct {
+	StartsOn string `json:"starts_on"` // ГГГГ-ММ-ДД
+	EndsOn   string `json:"ends_on"`   // ГГГГ-ММ-ДД, последний день отпуска
+	Mode     string `json:"mode"`      // unavailable или hidden
+	Active   bool   `json:"active"`    // Отпуск идёт сейчас
+}
+
+// SupplierSchedule расписание поставщика
+type SupplierSchedule struct {
+	WorkingHours []SupplierWorkingDay        `json:"working_hours"` // Дни недели без часов — выходные
+	Exceptions   []SupplierScheduleException `json:"exceptions"`    // Исключения с сегодняшнего дня
+	Vacation     *SupplierVacation           `json:"vacation"`
+	OpenNow      bool                        `json:"open_now"`
+}
+
+// UpdateWorkingHoursRequest замена часов работы на всю неделю
+type UpdateWorkingHoursRequest struct {
+	Days []SupplierWorkingDay `json:"days" binding:"max=7"`
+}
+
+// ScheduleExceptionRequest исключение на дату из пути запроса
+type ScheduleExceptionRequest struct {
+	Closed   bool   `json:"closed"`
+	OpensAt  string `json:"opens_at"`
+	ClosesAt string `json:"closes_at"`
+	Note     string `json:"note" binding:"max=255"`
+}
+
+// VacationRequest установка отпуска
+type VacationRequest struct {
+	StartsOn string `json:"starts_on" binding:"required"` // ГГГГ-ММ-ДД
+	EndsOn   string `json:"ends_on" binding:"required"`   // ГГГГ-ММ-ДД, последний день отпуска
+	Mode     string `json:"mode"`                         // unavailable (по умолчанию) или hidden
+}
@@ -0,0 +1,82 @@
+// internal/models/supplier_verification.go
+
+package models
+
+import "time"
+
+// Статусы проверки поставщика по документам
+const (
+	VerificationStatusNone     = "none"     // Документы ещё не отправлены на проверку
+	VerificationStatusPending  = "pending"  // В очереди проверки
+	VerificationStatusApproved = "approved" // Проверенный продавец
+	VerificationStatusRejected = "rejected"
+)
+
+// Типы документов поставщика
+const (
+	SupplierDocumentPatent         = "patent"
+	SupplierDocumentINNCertificate = "inn_certificate"
+	SupplierDocumentStallLease     = "stall_lease"
+)
+
+// SupplierDocument документ поставщика в закрытом хранилище
+type SupplierDocument struct {
+	ID          int       `json:"id"`
+	SupplierID  int       `json:"supplier_id"`
+	DocType     string    `json:"doc_type"` // patent, inn_certificate или stall_lease
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	UploadedAt  time.Time `json:"uploaded_at"`
+	ObjectKey   string    `json:"-"`
+	URL         string    `json:"url,omitempty"` // Временная ссылка на файл
+}
+
+// VerificationHistoryEntry смена статуса проверки поставщика
+type VerificationHistoryEntry struct {
+	ID         int       `json:"id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	ChangedBy  int       `json:"changed_by,omitempty"` // 0 — действие самого поставщика
+	Comment    string    `json:"comment,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// SupplierVerification состояние проверки поставщика с документами и историей
+type SupplierVerification struct {
+	SupplierID  int                        `json:"supplier_id"`
+	Status      string                     `json:"status"`
+	SubmittedAt *time.Time                 `json:"submitted_at,omitempty"`
+	VerifiedAt  *time.Time                 `json:"verified_at,omitempty"`
+	Documents   []SupplierDocument         `json:"documents"`
+	History     []VerificationHistoryEntry `json:"history"`
+}
+
+// UploadSupplierDocumentRequest загрузка документа, сам файл передаётся полем file
+type UploadSupplierDocumentRequest struct {
+	DocType string `form:"doc_type" binding:"required"`
+}
+
+// VerificationDecisionRequest решение администратора по проверке поставщика
+type VerificationDecisionRequest struct {
+	Comment string `json:"comment" binding:"max=1000"`
+}
+
+// VerificationQueueItem поставщик, ожидающий проверки документов
+type VerificationQueueItem struct {
+	SupplierID    int       `json:"supplier_id"`
+	Name          string    `json:"name"`
+	PhoneNumber   string    `json:"phone_number"`
+	MarketID      int       `json:"market_id"`
+	DocumentTypes []string  `json:"document_types"`
+	SubmittedAt   time.Time `json:"submitted_at"`
+	TimeInQueue   int64     `json:"time_in_queue"` // Секунды с момента отправки
+}
+
+// VerificationQueue страница очереди проверки поставщиков
+type VerificationQueue struct {
+	Items  []VerificationQueueItem `json:"items"`
+	Total  int                     `json:"total"`
+	Limit  int                     `json:"limit"`
+	Offset int                     `json:"offset"`
+}
@@ -0,0 +1,73 @@
+// internal/models/taxonomy.go
+
+package models
+
+// TaxonomyVersion версия формата документа таксономии
+const TaxonomyVersion = 1
+
+// Форматы документа таксономии
+const (
+	TaxonomyFormatYAML = "yaml"
+	TaxonomyFormatJSON = "json"
+)
+
+// TaxonomyDocument дерево категорий с атрибутами для переноса между окружениями.
+// Категории перечисляются списком по path; родитель идёт раньше подкатегорий.
+type TaxonomyDocument struct {
+	Version    int                `json:"version" yaml:"version"`
+	Categories []TaxonomyCategory `json:"categories" yaml:"categories"`
+}
+
+// TaxonomyCategory категория документа таксономии. Ключ категории — path.
+type TaxonomyCategory struct {
+	Path             string              `json:"path" yaml:"path"`
+	Name             string              `json:"name" yaml:"name"`
+	ImageURL         string              `json:"image_url,omitempty" yaml:"image_url,omitempty"`
+	Attributes       []TaxonomyAttribute `json:"attributes,omitempty" yaml:"attributes,omitempty"`
+	HiddenAttributes []string            `json:"hidden_attributes,omitempty" yaml:"hidden_attributes,omitempty"`
+}
+
+// TaxonomyAttribute собственный атрибут категории. Ключ атрибута — название внутри категории.
+type TaxonomyAttribute struct {
+	Name         string      `json:"name" yaml:"name"`
+	Description  string      `json:"description" yaml:"description"`
+	TypeOfOption string      `json:"type_of_option" yaml:"type_of_option"`
+	Value        interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+	IsLinked     bool        `json:"is_linked" yaml:"is_linked"`
+	Dimension    string      `json:"dimension,omitempty" yaml:"dimension,omitempty"`
+	Unit         string      `json:"unit,omitempty" yaml:"unit,omitempty"`
+	IsRequired   bool        `json:"is_required,omitempty" yaml:"is_required,omitempty"`
+	SortOrder    int         `json:"sort_order,omitempty" yaml:"sort_order,omitempty"`
+	IsFilterable bool        `json:"is_filterable,omitempty" yaml:"is_filterable,omitempty"`
+}
+
+// Действия и виды записей в разнице таксономии
+const (
+	TaxonomyActionCreate = "create"
+	TaxonomyActionUpdate = "update"
+	TaxonomyActionDelete = "delete"
+
+	TaxonomyKindCategory        = "category"
+	TaxonomyKindAttribute       = "attribute"
+	TaxonomyKindHiddenAttribute = "hidden_attribute"
+)
+
+// TaxonomyChange одно изменение при импорте таксономии
+type TaxonomyChange struct {
+	Action  string `json:"action"`
+	Kind    string `json:"kind"`
+	Path    string `json:"path"`
+	Name    string `json:"name,omitempty"`    // Название атрибута
+	Details string `json:"details,omitempty"` // Что меняется
+}
+
+// TaxonomyDiff разница между документом и текущей таксономией. Applied false означает
+// предпросмотр или отказ из-за конфликтов.
+type TaxonomyDiff struct {
+	Applied   bool             `json:"applied"`
+	Created   int              `json:"created"`
+	Updated   int              `json:"updated"`
+	Deleted   int              `json:"deleted"`
+	Changes   []TaxonomyChange `json:"changes"`
+	Conflicts []string         `json:"conflicts,omitempty"`
+}
@@ -0,0 +1,61 @@
+// internal/models/translation.go
+
+package models
+
+import "time"
+
+// Языки каталога. Исходные тексты хранятся на русском, переводы — на остальных языках.
+const (
+	LangRussian = "ru"
+	LangKyrgyz  = "ky"
+	LangEnglish = "en"
+
+	DefaultLanguage = LangRussian
+)
+
+// TranslationLanguages языки, на которые переводится каталог
+var TranslationLanguages = []string{LangKyrgyz, LangEnglish}
+
+// Виды переводимых записей каталога
+const (
+	TranslationCategory        = "category"
+	TranslationAttribute       = "attribute"
+	TranslationAttributeOption = "attribute_option"
+	TranslationProduct         = "product"
+)
+
+// TranslationRequest перевод записи каталога. Для категории используется name, для атрибута
+// и продукта — name и description, для значения атрибута — option (исходный текст) и value.
+type TranslationRequest struct {
+	Name        string `json:"name" binding:"omitempty,max=255"`
+	Description string `json:"description"`
+	Option      string `json:"option" binding:"omitempty,max=255"`
+	Value       string `json:"value" binding:"omitempty,max=255"`
+}
+
+// Translation сохранённый перевод записи каталога
+type Translation struct {
+	Entity      string    `json:"entity"`
+	ID          int       `json:"id"`
+	Lang        string    `json:"lang"`
+	Option      string    `json:"option,omitempty"`
+	Name        string    `json:"name,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Value       string    `json:"value,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// MissingTranslation запись каталога без перевода на язык
+type MissingTranslation struct {
+	Entity string `json:"entity"`
+	ID     int    `json:"id"`
+	Option string `json:"option,omitempty"`
+	Source string `json:"source"` // Исходный текст на русском
+}
+
+// MissingTranslationsReport записи без перевода на язык, по видам записей
+type MissingTranslationsReport struct {
+	Lang   string               `json:"lang"`
+	Counts map[string]int       `json:"counts"`
+	Items  []MissingTranslation `json:"items"`
+}
@@ -20,3 +20,15 @@ func (s *AttributeService) AddAttributeValueImage(image *models.AttributeValueIm
 func (s *AttributeService) GetAttributeValueImage(attributeValueID int) (*models.AttributeValueImage, error) {
 	return db.GetAttributeValueImageByAttributeValueID(attributeValueID)
 }
+
+// UpdateAttributeFlags меняет обязательность, порядок и участие атрибута в фильтрах
+func (s *AttributeService) UpdateAttributeFlags(attributeID int, req models.UpdateAttributeFlagsRequest) (*models.CategoryAttribute, error) {
+	updated, err := db.UpdateAttributeFlags(attributeID, req.IsRequired, req.SortOrder, req.IsFilterable)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, ErrAttributeNotFound
+	}
+	return db.GetCategoryAttributeByID(attributeID)
+}
@@ -0,0 +1,291 @@
+// internal/services/attribute_unit.go
+
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/WhyDias/Marketplace/internal/db"
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/WhyDias/Marketplace/internal/utils"
+)
+
+var (
+	ErrInvalidAttributeUnit     = errors.New("некорректная единица измерения атрибута")
+	ErrAttributeUnitChangeInUse = errors.New("единицу атрибута нельзя заменить единицей другой величины, пока его значения сохранены у продуктов")
+	ErrInvalidCatalogFilter     = errors.New("некорректный отбор по атрибуту")
+)
+
+// isNumericAttribute атрибуты, значения которых хранятся числами и могут иметь единицу измерения
+func isNumericAttribute(typeOfOption string) bool {
+	return typeOfOption == "numeric" || typeOfOption == "range"
+}
+
+// resolveAttributeUnit проверяет величину и каноническую единицу атрибута и возвращает их коды.
+// Единица без величины определяет величину сама.
+func resolveAttributeUnit(typeOfOption, dimension, unit string) (string, string, error) {
+	if dimension == "" && unit == "" {
+		return "", "", nil
+	}
+	if !isNumericAttribute(typeOfOption) {
+		return "", "", fmt.Errorf("%w: единица измерения задаётся только для numeric и range", ErrInvalidAttributeUnit)
+	}
+	if unit == "" {
+		return "", "", fmt.Errorf("%w: для величины %s укажите каноническую единицу", ErrInvalidAttributeUnit, dimension)
+	}
+	u, ok := utils.LookupUnit(unit)
+	if !ok {
+		return "", "", fmt.Errorf("%w: неизвестная единица %s", ErrInvalidAttributeUnit, unit)
+	}
+	if dimension != "" && dimension != u.Dimension {
+		return "", "", fmt.Errorf("%w: единица %s не измеряет величину %s", ErrInvalidAttributeUnit, u.Code, dimension)
+	}
+	return u.Dimension, u.Code, nil
+}
+
+// planUnitChange проверяет смену единицы существующего атрибута. Если новая единица той же величины,
+// сохранённые значения пересчитываются; единицу другой величины можно задать только неиспользуемому атрибуту.
+func planUnitChange(change *attributeChange) (string, error) {
+	existing := change.existing
+	oldUnit, newUnit := existing.Unit, change.unit
+	if oldUnit == newUnit {
+		return "", nil
+	}
+
+	switch {
+	case newUnit == "":
+		return "Единица измерения удалена", nil
+	case oldUnit == "":
+		return "Единица измерения: " + newUnit, nil
+	}
+
+	factor, err := utils.UnitFactor(oldUnit, newUnit)
+	if err == nil {
+		change.unitFactor = factor
+		return fmt.Sprintf("Единица изменена с %s на %s, значения пересчитаны", oldUnit, newUnit), nil
+	}
+
+	usage, err := db.CountAttributeUsage(existing.ID)
+	if err != nil {
+		return "", err
+	}
+	if usage > 0 {
+		return "", fmt.Errorf("%w (атрибут '%s', продуктов: %d)", ErrAttributeUnitChangeInUse, existing.Name, usage)
+	}
+	return fmt.Sprintf("Единица изменена с %s на %s", oldUnit, newUnit), nil
+}
+
+// normalizeAttributeValue приводит значение числового атрибута продукта к числу в канонической единице
+// атрибута. Единица берётся из запроса или из самого значения ("1,5 кг"). Значение range может быть
+// числом или парой [от, до], каждая граница приводится так же. Остальные значения не меняются.
+func normalizeAttributeValue(attributeID int, req models.AttributeValueRequest) (interface{}, error) {
+	attribute, err := db.GetCategoryAttributeByID(attributeID)
+	if err != nil {
+		return nil, err
+	}
+	if attribute == nil {
+		return nil, ErrAttributeNotFound
+	}
+
+	if attribute.TypeOfOption == nil || !isNumericAttribute(*attribute.TypeOfOption) {
+		if req.Unit != "" {
+			return nil, fmt.Errorf("%w: атрибут '%s' не числовой", ErrInvalidAttributeUnit, attribute.Name)
+		}
+		return req.Value, nil
+	}
+
+	unit := ""
+	if req.Unit != "" {
+		u, ok := utils.LookupUnit(req.Unit)
+		if !ok {
+			return nil, fmt.Errorf("%w: неизвестная единица %s", ErrInvalidAttributeUnit, req.Unit)
+		}
+		unit = u.Code
+	}
+
+	bounds, isRange := req.Value.([]interface{})
+	if !isRange {
+		return normalizeQuantity(attribute, req.Value, unit)
+	}
+	if *attribute.TypeOfOption != "range" || len(bounds) != 2 {
+		return nil, fmt.Errorf("значение атрибута '%s' должно быть числом", attribute.Name)
+	}
+	from, err := normalizeQuantity(attribute, bounds[0], unit)
+	if err != nil {
+		return nil, err
+	}
+	to, err := normalizeQuantity(attribute, bounds[1], unit)
+	if err != nil {
+		return nil, err
+	}
+	if from > to {
+		return nil, fmt.Errorf("значение атрибута '%s': начало диапазона больше конца", attribute.Name)
+	}
+	return []float64{from, to}, nil
+}
+
+// normalizeQuantity приводит одно числовое значение к канонической единице атрибута.
+// unit — единица из запроса, пустая строка если она не указана.
+func normalizeQuantity(attribute *models.CategoryAttribute, value interface{}, unit string) (float64, error) {
+	var number float64
+	switch v := value.(type) {
+	case float64:
+		number = v
+	case string:
+		parsed, parsedUnit, err := utils.ParseQuantity(v)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrInvalidAttributeUnit, err)
+		}
+		if parsedUnit != "" {
+			if unit != "" && unit != parsedUnit {
+				return 0, fmt.Errorf("%w: единица в значении '%s' не совпадает с %s", ErrInvalidAttributeUnit, v, unit)
+			}
+			unit = parsedUnit
+		}
+		number = parsed
+	default:
+		return 0, fmt.Errorf("значение атрибута '%s' должно быть числом", attribute.Name)
+	}
+
+	if unit == "" || unit == attribute.Unit {
+		return number, nil
+	}
+	if attribute.Unit == "" {
+		return 0, fmt.Errorf("%w: у атрибута '%s' нет единицы измерения", ErrInvalidAttributeUnit, attribute.Name)
+	}
+	number, err := utils.ConvertUnit(number, unit, attribute.Unit)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidAttributeUnit, err)
+	}
+	return number, nil
+}
+
+// numericCatalogAttribute возвращает числовой атрибут для отбора или сортировки каталога
+func numericCatalogAttribute(attributeID int) (*models.CategoryAttribute, error) {
+	attribute, err := db.GetCategoryAttributeByID(attributeID)
+	if err != nil {
+		return nil, err
+	}
+	if attribute == nil {
+		return nil, fmt.Errorf("%w: атрибут %d не найден", ErrInvalidCatalogFilter, attributeID)
+	}
+	if attribute.TypeOfOption == nil || !isNumericAttribute(*attribute.TypeOfOption) {
+		return nil, fmt.Errorf("%w: атрибут '%s' не числовой", ErrInvalidCatalogFilter, attribute.Name)
+	}
+	return attribute, nil
+}
+
+// resolveCatalogAttributeFilters разбирает отбор и сортировку каталога по числовым атрибутам.
+// Границы с единицей пересчитываются в каноническую единицу атрибута, в которой хранится value_numeric.
+func resolveCatalogAttributeFilters(filter *models.CatalogFilter) error {
+	for _, raw := range filter.AttributeRanges {
+		idPart, rangePart, ok := strings.Cut(raw, ":")
+		if !ok {
+			return fmt.Errorf("%w: '%s', ожидается <ID атрибута>:<от>..<до>", ErrInvalidCatalogFilter, raw)
+		}
+		attributeID, err := strconv.Atoi(strings.TrimSpace(idPart))
+		if err != nil {
+			return fmt.Errorf("%w: некорректный ID атрибута в '%s'", ErrInvalidCatalogFilter, raw)
+		}
+		attribute, err := numericCatalogAttribute(attributeID)
+		if err != nil {
+			return err
+		}
+
+		minPart, maxPart, ok := strings.Cut(rangePart, "..")
+		if !ok {
+			return fmt.Errorf("%w: '%s', ожидается <ID атрибута>:<от>..<до>", ErrInvalidCatalogFilter, raw)
+		}
+		f := models.NumericAttributeFilter{AttributeID: attributeID}
+		for _, bound := range []struct {
+			text string
+			dst  **float64
+		}{{minPart, &f.Min}, {maxPart, &f.Max}} {
+			if strings.TrimSpace(bound.text) == "" {
+				continue
+			}
+			number, err := normalizeQuantity(attribute, bound.text, "")
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidCatalogFilter, err)
+			}
+			*bound.dst = &number
+		}
+		filter.NumericAttributes = append(filter.NumericAttributes, f)
+	}
+
+	if filter.Sort != "" {
+		sort := strings.TrimSpace(filter.Sort)
+		filter.SortDesc = strings.HasPrefix(sort, "-")
+		idPart, ok := strings.CutPrefix(strings.TrimPrefix(sort, "-"), "attr:")
+		if !ok {
+			return fmt.Errorf("%w: сортировка '%s', ожидается attr:<ID> или -attr:<ID>", ErrInvalidCatalogFilter, filter.Sort)
+		}
+		attributeID, err := strconv.Atoi(idPart)
+		if err != nil {
+			return fmt.Errorf("%w: некорректный ID атрибута в сортировке '%s'", ErrInvalidCatalogFilter, filter.Sort)
+		}
+		if _, err := numericCatalogAttribute(attributeID); err != nil {
+			return err
+		}
+		filter.SortAttributeID = attributeID
+	}
+	return nil
+}
+
+// ParsePreferredUnits разбирает предпочитаемые единицы покупателя ("kg,cm") в соответствие величина -> единица
+func ParsePreferredUnits(list string) (map[string]string, error) {
+	preferred := make(map[string]string)
+	for _, item := range strings.Split(list, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		u, ok := utils.LookupUnit(item)
+		if !ok {
+			return nil, fmt.Errorf("%w: неизвестная единица %s", ErrInvalidAttributeUnit, strings.TrimSpace(item))
+		}
+		preferred[u.Dimension] = u.Code
+	}
+	return preferred, nil
+}
+
+// displayAttributeValue пересчитывает числовое значение или диапазон [от, до] в предпочитаемую единицу покупателя
+func displayAttributeValue(value *models.ProductAttributeValueView, preferred map[string]string) error {
+	if value.Unit == "" {
+		return nil
+	}
+	target, ok := preferred[value.Dimension]
+	if !ok || target == value.Unit {
+		return nil
+	}
+
+	var numbers []float64
+	if value.Numeric != nil {
+		numbers = []float64{*value.Numeric}
+	} else if err := json.Unmarshal(value.Value, &numbers); err != nil || len(numbers) != 2 {
+		return nil
+	}
+	for i := range numbers {
+		converted, err := utils.ConvertUnit(numbers[i], value.Unit, target)
+		if err != nil {
+			return err
+		}
+		numbers[i] = converted
+	}
+
+	var raw []byte
+	var err error
+	if value.Numeric != nil {
+		raw, err = json.Marshal(numbers[0])
+	} else {
+		raw, err = json.Marshal(numbers)
+	}
+	if err != nil {
+		return err
+	}
+	value.Value, value.Unit = raw, target
+	return nil
+}
@@ -0,0 +1,256 @@
+// internal/services/attribute_value_service.go
+
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/WhyDias/Marketplace/internal/db"
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/WhyDias/Marketplace/internal/utils"
+)
+
+var (
+	ErrAttributeValueNotFound = errors.New("значение атрибута не найдено")
+	ErrAttributeValueExists   = errors.New("такое значение атрибута уже есть: объедините значения")
+	ErrAttributeValueInUse    = errors.New("значение атрибута выбрано у продуктов: объедините его с другим значением")
+	ErrInvalidAttributeValue  = errors.New("некорректное значение атрибута")
+)
+
+// GetAttributeValues возвращает справочник значений атрибута в порядке вывода
+func (s *AttributeService) GetAttributeValues(attributeID int) ([]models.AttributeValueEntry, error) {
+	attribute, err := db.GetCategoryAttributeByID(attributeID)
+	if err != nil {
+		return nil, err
+	}
+	if attribute == nil {
+		return nil, ErrAttributeNotFound
+	}
+	return db.GetAttributeValueEntries(attributeID)
+}
+
+// CreateAttributeValue добавляет значение в справочник атрибута
+func (s *AttributeService) CreateAttributeValue(attributeID int, req models.AttributeValueEntryRequest) (*models.AttributeValueEntry, error) {
+	attribute, err := db.GetCategoryAttributeByID(attributeID)
+	if err != nil {
+		return nil, err
+	}
+	if attribute == nil {
+		return nil, ErrAttributeNotFound
+	}
+
+	entry := &models.AttributeValueEntry{AttributeID: attributeID, ImageURLs: req.ImageURLs}
+	if entry.Value, err = dictionaryValue(attribute, req); err != nil {
+		return nil, err
+	}
+	if err := checkUniqueAttributeValue(entry, 0); err != nil {
+		return nil, err
+	}
+	if req.SortOrder != nil {
+		entry.SortOrder = *req.SortOrder
+	}
+	if req.SwatchHex != nil {
+		if entry.SwatchHex, err = swatchHex(*req.SwatchHex); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := db.CreateAttributeValueEntry(entry); err != nil {
+		return nil, err
+	}
+	return db.GetAttributeValueEntry(entry.ID)
+}
+
+// UpdateAttributeValue меняет значение справочника. Продукты и вариации остаются связаны
+// со значением, переводы переносятся на новый текст.
+func (s *AttributeService) UpdateAttributeValue(attributeID, valueID int, req models.AttributeValueEntryRequest) (*models.AttributeValueEntry, error) {
+	entry, err := getAttributeValueEntry(attributeID, valueID)
+	if err != nil {
+		return nil, err
+	}
+	oldText := storedValueText(entry.Value)
+
+	if req.Value != nil {
+		attribute, err := db.GetCategoryAttributeByID(attributeID)
+		if err != nil {
+			return nil, err
+		}
+		if attribute == nil {
+			return nil, ErrAttributeNotFound
+		}
+		if entry.Value, err = dictionaryValue(attribute, req); err != nil {
+			return nil, err
+		}
+		if err := checkUniqueAttributeValue(entry, entry.ID); err != nil {
+			return nil, err
+		}
+	}
+	if req.SortOrder != nil {
+		entry.SortOrder = *req.SortOrder
+	}
+	if req.SwatchHex != nil {
+		if entry.SwatchHex, err = swatchHex(*req.SwatchHex); err != nil {
+			return nil, err
+		}
+	}
+	if req.ImageURLs != nil {
+		entry.ImageURLs = req.ImageURLs
+	}
+
+	if err := db.UpdateAttributeValueEntry(entry, oldText, storedValueText(entry.Value)); err != nil {
+		return nil, err
+	}
+	return db.GetAttributeValueEntry(entry.ID)
+}
+
+// DeleteAttributeValue удаляет значение справочника, которое не выбрано ни у одного продукта
+func (s *AttributeService) DeleteAttributeValue(attributeID, valueID int) error {
+	entry, err := getAttributeValueEntry(attributeID, valueID)
+	if err != nil {
+		return err
+	}
+	if entry.Products > 0 {
+		return fmt.Errorf("%w (продуктов: %d)", ErrAttributeValueInUse, entry.Products)
+	}
+	return db.DeleteAttributeValue(valueID)
+}
+
+// MergeAttributeValues объединяет значения sourceIDs со значением targetID: продукты и вариации
+// переходят к targetID, значения sourceIDs удаляются
+func (s *AttributeService) MergeAttributeValues(attributeID int, req models.MergeAttributeValuesRequest) (*models.AttributeValueEntry, error) {
+	if _, err := getAttributeValueEntry(attributeID, req.TargetID); err != nil {
+		return nil, err
+	}
+
+	seen := map[int]bool{}
+	sources := make([]int, 0, len(req.SourceIDs))
+	for _, id := range req.SourceIDs {
+		if id == req.TargetID {
+			return nil, fmt.Errorf("%w: значение %d указано и как целевое, и как объединяемое", ErrInvalidAttributeValue, id)
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if _, err := getAttributeValueEntry(attributeID, id); err != nil {
+			return nil, err
+		}
+		sources = append(sources, id)
+	}
+
+	if err := db.MergeAttributeValues(req.TargetID, sources); err != nil {
+		return nil, err
+	}
+	return db.GetAttributeValueEntry(req.TargetID)
+}
+
+// GetDuplicateAttributeValues возвращает группы значений атрибута, которые совпадают
+// без учёта регистра и лишних пробелов
+func (s *AttributeService) GetDuplicateAttributeValues(attributeID int) ([]models.AttributeValueDuplicates, error) {
+	entries, err := s.GetAttributeValues(attributeID)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := map[string]int{}
+	var duplicates []models.AttributeValueDuplicates
+	for _, entry := range entries {
+		key := strings.Join(strings.Fields(strings.ToLower(storedValueText(entry.Value))), " ")
+		i, ok := groups[key]
+		if !ok {
+			i = len(duplicates)
+			groups[key] = i
+			duplicates = append(duplicates, models.AttributeValueDuplicates{Key: key})
+		}
+		duplicates[i].Values = append(duplicates[i].Values, entry)
+	}
+
+	result := []models.AttributeValueDuplicates{}
+	for _, group := range duplicates {
+		if len(group.Values) > 1 {
+			result = append(result, group)
+		}
+	}
+	return result, nil
+}
+
+// getAttributeValueEntry возвращает значение справочника, если оно принадлежит атрибуту
+func getAttributeValueEntry(attributeID, valueID int) (*models.AttributeValueEntry, error) {
+	entry, err := db.GetAttributeValueEntry(valueID)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil || entry.AttributeID != attributeID {
+		return nil, fmt.Errorf("%w: %d", ErrAttributeValueNotFound, valueID)
+	}
+	return entry, nil
+}
+
+// checkUniqueAttributeValue запрещает второе значение атрибута с тем же JSON
+func checkUniqueAttributeValue(entry *models.AttributeValueEntry, excludeID int) error {
+	existingID, err := db.FindAttributeValueID(entry.AttributeID, entry.Value, excludeID)
+	if err != nil {
+		return err
+	}
+	if existingID != 0 {
+		return fmt.Errorf("%w (ID %d)", ErrAttributeValueExists, existingID)
+	}
+	return nil
+}
+
+// dictionaryValue проверяет значение справочника по типу атрибута и приводит числа к единице атрибута
+func dictionaryValue(attribute *models.CategoryAttribute, req models.AttributeValueEntryRequest) (json.RawMessage, error) {
+	typeOfOption := ""
+	if attribute.TypeOfOption != nil {
+		typeOfOption = *attribute.TypeOfOption
+	}
+
+	var value interface{}
+	switch typeOfOption {
+	case "switcher":
+		v, ok := req.Value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%w: значение атрибута '%s' должно быть true или false", ErrInvalidAttributeValue, attribute.Name)
+		}
+		value = v
+
+	case "numeric", "range":
+		switch req.Value.(type) {
+		case float64, string, []interface{}:
+		default:
+			return nil, fmt.Errorf("%w: значение атрибута '%s' должно быть числом", ErrInvalidAttributeValue, attribute.Name)
+		}
+		v, err := normalizeAttributeValue(attribute.ID, models.AttributeValueRequest{Value: req.Value, Unit: req.Unit})
+		if err != nil {
+			return nil, err
+		}
+		value = v
+
+	default:
+		v, ok := req.Value.(string)
+		if !ok || strings.TrimSpace(v) == "" {
+			return nil, fmt.Errorf("%w: значение атрибута '%s' должно быть непустой строкой", ErrInvalidAttributeValue, attribute.Name)
+		}
+		if req.Unit != "" {
+			return nil, fmt.Errorf("%w: атрибут '%s' не числовой", ErrInvalidAttributeUnit, attribute.Name)
+		}
+		value = strings.TrimSpace(v)
+	}
+
+	return json.Marshal(value)
+}
+
+// swatchHex проверяет образец цвета; пустая строка убирает образец
+func swatchHex(raw string) (string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return "", nil
+	}
+	hex, ok := utils.NormalizeHexColor(raw)
+	if !ok {
+		return "", fmt.Errorf("%w: некорректный цвет '%s', ожидается #rrggbb", ErrInvalidAttributeValue, raw)
+	}
+	return hex, nil
+}
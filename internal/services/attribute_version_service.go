@@ -0,0 +1,400 @@
+// internal/services/attribute_version_service.go
+
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/WhyDias/Marketplace/internal/db"
+	"github.com/WhyDias/Marketplace/internal/models"
+)
+
+var (
+	ErrAttributeNotFound          = errors.New("атрибут не найден")
+	ErrAttributeTypeChangeInUse   = errors.New("тип атрибута нельзя изменить, пока его значения сохранены у продуктов: используйте задание конвертации")
+	ErrInvalidValueMigration      = errors.New("некорректный перенос значений атрибута")
+	ErrInvalidAttributeConversion = errors.New("некорректная конвертация атрибута")
+	ErrConversionInProgress       = errors.New("конвертация этого атрибута уже выполняется")
+	ErrConversionJobNotFound      = errors.New("задание конвертации не найдено")
+)
+
+// maxReportedConversionFailures сколько несконвертированных значений перечисляется в ошибке задания
+const maxReportedConversionFailures = 10
+
+// AttributeOptionsInUseError удаляемые опции dropdown выбраны у продуктов и для них не указана замена
+type AttributeOptionsInUseError struct {
+	Attribute string
+	Usage     []models.AttributeOptionUsage
+}
+
+func (e *AttributeOptionsInUseError) Error() string {
+	options := make([]string, 0, len(e.Usage))
+	for _, u := range e.Usage {
+		options = append(options, fmt.Sprintf("%s (продуктов: %d)", u.Option, len(u.ProductIDs)))
+	}
+	return fmt.Sprintf("опции атрибута '%s' используются продуктами, укажите замену в value_migrations: %s",
+		e.Attribute, strings.Join(options, ", "))
+}
+
+// planAttributeChange проверяет изменение существующего атрибута и заполняет переносы значений,
+// пересчёт единицы и описание изменения. Пустое описание означает, что определение не изменилось.
+func planAttributeChange(change *attributeChange) error {
+	existing, req, value := change.existing, change.request, change.value
+
+	unitSummary, err := planUnitChange(change)
+	if err != nil {
+		return err
+	}
+	var parts []string
+	if unitSummary != "" {
+		parts = append(parts, unitSummary)
+	}
+	if existing.IsLinked != req.IsLinked {
+		if req.IsLinked {
+			parts = append(parts, "Атрибут задаётся для вариаций")
+		} else {
+			parts = append(parts, "Атрибут задаётся для продукта")
+		}
+	}
+
+	oldType := ""
+	if existing.TypeOfOption != nil {
+		oldType = *existing.TypeOfOption
+	}
+	oldDescription := ""
+	if existing.Description != nil {
+		oldDescription = *existing.Description
+	}
+
+	if oldType != req.TypeOfOption {
+		if len(req.ValueMigrations) > 0 {
+			return fmt.Errorf("%w: value_migrations применяются только к опциям dropdown", ErrInvalidValueMigration)
+		}
+		usage, err := db.CountAttributeUsage(existing.ID)
+		if err != nil {
+			return err
+		}
+		if usage > 0 {
+			return fmt.Errorf("%w (атрибут '%s', продуктов: %d)", ErrAttributeTypeChangeInUse, existing.Name, usage)
+		}
+		change.summary = strings.Join(append([]string{fmt.Sprintf("Тип изменён с %s на %s", oldType, req.TypeOfOption)}, parts...), "; ")
+		return nil
+	}
+
+	if oldDescription != req.Description {
+		parts = append(parts, "Изменено описание")
+	}
+
+	if req.TypeOfOption != "dropdown" {
+		if len(req.ValueMigrations) > 0 {
+			return fmt.Errorf("%w: value_migrations применяются только к опциям dropdown", ErrInvalidValueMigration)
+		}
+		if !jsonEqual(existing.Value, value) {
+			parts = append(parts, "Изменено значение")
+		}
+		change.summary = strings.Join(parts, "; ")
+		return nil
+	}
+
+	var oldOptions, newOptions []string
+	if err := json.Unmarshal(existing.Value, &oldOptions); err != nil {
+		log.Printf("planAttributeChange: некорректный список опций атрибута %d: %v", existing.ID, err)
+	}
+	if err := json.Unmarshal(value, &newOptions); err != nil {
+		return fmt.Errorf("некорректное значение в dropdown")
+	}
+	oldSet, newSet := stringSet(oldOptions), stringSet(newOptions)
+
+	for from, to := range req.ValueMigrations {
+		if !oldSet[from] || newSet[from] {
+			return fmt.Errorf("%w: опция '%s' должна быть удалена из списка", ErrInvalidValueMigration, from)
+		}
+		if !newSet[to] {
+			return fmt.Errorf("%w: опции '%s' нет в новом списке", ErrInvalidValueMigration, to)
+		}
+	}
+
+	var added, removed, unmigrated []string
+	for _, option := range newOptions {
+		if !oldSet[option] && !isMigrationTarget(req.ValueMigrations, option) {
+			added = append(added, option)
+		}
+	}
+	for _, option := range oldOptions {
+		if newSet[option] {
+			continue
+		}
+		if _, ok := req.ValueMigrations[option]; !ok {
+			removed = append(removed, option)
+			unmigrated = append(unmigrated, option)
+		}
+	}
+
+	// Удалить можно только опции, которые не выбраны ни у одного продукта
+	if len(unmigrated) > 0 {
+		usage, err := db.GetAttributeOptionUsage(existing.ID, unmigrated)
+		if err != nil {
+			return err
+		}
+		if len(usage) > 0 {
+			return &AttributeOptionsInUseError{Attribute: existing.Name, Usage: usage}
+		}
+	}
+
+	if len(added) > 0 {
+		parts = append(parts, "Добавлены опции: "+strings.Join(added, ", "))
+	}
+	if len(removed) > 0 {
+		parts = append(parts, "Удалены опции: "+strings.Join(removed, ", "))
+	}
+	if len(req.ValueMigrations) > 0 {
+		migrations := make([]string, 0, len(req.ValueMigrations))
+		for from, to := range req.ValueMigrations {
+			migrations = append(migrations, from+" → "+to)
+		}
+		sort.Strings(migrations)
+		parts = append(parts, "Перенесены значения: "+strings.Join(migrations, ", "))
+	}
+	if len(parts) == 0 && !jsonEqual(existing.Value, value) {
+		parts = append(parts, "Изменён порядок опций")
+	}
+
+	change.migrations = req.ValueMigrations
+	change.summary = strings.Join(parts, "; ")
+	return nil
+}
+
+func isMigrationTarget(migrations map[string]string, option string) bool {
+	for _, to := range migrations {
+		if to == option {
+			return true
+		}
+	}
+	return false
+}
+
+func stringSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+func jsonEqual(a, b json.RawMessage) bool {
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return string(a) == string(b)
+	}
+	ja, _ := json.Marshal(va)
+	jb, _ := json.Marshal(vb)
+	return string(ja) == string(jb)
+}
+
+// GetAttributeVersions возвращает историю определения атрибута
+func (s *AttributeService) GetAttributeVersions(attributeID int) ([]models.AttributeVersion, error) {
+	attribute, err := db.GetCategoryAttributeByID(attributeID)
+	if err != nil {
+		return nil, err
+	}
+	if attribute == nil {
+		return nil, ErrAttributeNotFound
+	}
+	return db.GetAttributeVersions(attributeID)
+}
+
+// StartConversion создаёт задание на смену типа атрибута и запускает его в фоне.
+// Задание либо конвертирует все сохранённые значения, либо не меняет ничего.
+func (s *AttributeService) StartConversion(attributeID int, userID int, req models.AttributeConversionRequest) (*models.AttributeConversionJob, error) {
+	attribute, err := db.GetCategoryAttributeByID(attributeID)
+	if err != nil {
+		return nil, err
+	}
+	if attribute == nil {
+		return nil, ErrAttributeNotFound
+	}
+
+	fromType := ""
+	if attribute.TypeOfOption != nil {
+		fromType = *attribute.TypeOfOption
+	}
+	if fromType == req.ToType {
+		return nil, fmt.Errorf("%w: атрибут уже имеет тип %s", ErrInvalidAttributeConversion, req.ToType)
+	}
+
+	value, err := attributeValueJSON(req.ToType, req.Value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAttributeConversion, err)
+	}
+
+	active, err := db.HasActiveAttributeConversionJob(attributeID)
+	if err != nil {
+		return nil, err
+	}
+	if active {
+		return nil, ErrConversionInProgress
+	}
+
+	job := &models.AttributeConversionJob{
+		AttributeID: attributeID,
+		FromType:    fromType,
+		ToType:      req.ToType,
+		Value:       value,
+		Mapping:     req.Mapping,
+		Status:      models.ConversionJobPending,
+		CreatedBy:   userID,
+	}
+	if job.Mapping == nil {
+		job.Mapping = map[string]string{}
+	}
+	if err := db.CreateAttributeConversionJob(job); err != nil {
+		return nil, err
+	}
+
+	go runAttributeConversion(*job)
+
+	return job, nil
+}
+
+// GetConversionJob возвращает состояние задания конвертации
+func (s *AttributeService) GetConversionJob(jobID int) (*models.AttributeConversionJob, error) {
+	job, err := db.GetAttributeConversionJob(jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, ErrConversionJobNotFound
+	}
+	return job, nil
+}
+
+// runAttributeConversion конвертирует сохранённые значения атрибута и меняет его тип.
+// Если хотя бы одно значение не конвертируется, задание завершается ошибкой без изменений.
+func runAttributeConversion(job models.AttributeConversionJob) {
+	fail := func(message string) {
+		log.Printf("runAttributeConversion: задание %d завершено с ошибкой: %s", job.ID, message)
+		if err := db.SetAttributeConversionJobStatus(job.ID, models.ConversionJobFailed, 0, message); err != nil {
+			log.Printf("runAttributeConversion: %v", err)
+		}
+	}
+
+	if err := db.SetAttributeConversionJobStatus(job.ID, models.ConversionJobRunning, 0, ""); err != nil {
+		log.Printf("runAttributeConversion: %v", err)
+		return
+	}
+
+	var options []string
+	if job.ToType == "dropdown" {
+		json.Unmarshal(job.Value, &options)
+	}
+
+	count, err := db.ApplyAttributeConversion(&job, func(values []models.StoredAttributeValue) (map[int]json.RawMessage, error) {
+		converted := make(map[int]json.RawMessage, len(values))
+		var failures []string
+		for _, v := range values {
+			newValue, err := convertStoredValue(v.Value, job.ToType, options, job.Mapping)
+			if err != nil {
+				failures = append(failures, err.Error())
+				continue
+			}
+			converted[v.ID] = newValue
+		}
+		if len(failures) > 0 {
+			total := len(failures)
+			if total > maxReportedConversionFailures {
+				failures = failures[:maxReportedConversionFailures]
+			}
+			return nil, fmt.Errorf("не удалось сконвертировать значений: %d. %s", total, strings.Join(failures, "; "))
+		}
+		return converted, nil
+	})
+	if err != nil {
+		fail(err.Error())
+		return
+	}
+
+	if err := db.SetAttributeConversionJobStatus(job.ID, models.ConversionJobDone, count, ""); err != nil {
+		log.Printf("runAttributeConversion: %v", err)
+	}
+}
+
+// FailInterruptedConversions завершает ошибкой задания конвертации, прерванные остановкой сервера,
+// чтобы они не блокировали новые конвертации атрибутов. Вызывается при запуске до приёма запросов.
+func FailInterruptedConversions() {
+	failed, err := db.FailInterruptedAttributeConversionJobs("задание прервано перезапуском сервера, значения не изменены")
+	if err != nil {
+		log.Printf("FailInterruptedConversions: %v", err)
+		return
+	}
+	if failed > 0 {
+		log.Printf("FailInterruptedConversions: прерванных заданий конвертации: %d", failed)
+	}
+}
+
+// convertStoredValue переводит сохранённое значение в новый тип атрибута
+func convertStoredValue(raw json.RawMessage, toType string, options []string, mapping map[string]string) (json.RawMessage, error) {
+	text := storedValueText(raw)
+	if mapped, ok := mapping[text]; ok {
+		text = mapped
+	}
+
+	var value interface{}
+	switch toType {
+	case "text":
+		value = text
+
+	case "dropdown":
+		if !stringSet(options)[text] {
+			return nil, fmt.Errorf("'%s' нет среди опций", text)
+		}
+		value = text
+
+	case "numeric", "range":
+		number, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(text), ",", "."), 64)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' не является числом", text)
+		}
+		value = number
+
+	case "switcher":
+		switch strings.ToLower(strings.TrimSpace(text)) {
+		case "true", "1", "да", "yes":
+			value = true
+		case "false", "0", "нет", "no", "":
+			value = false
+		default:
+			return nil, fmt.Errorf("'%s' не является логическим значением", text)
+		}
+
+	default:
+		return nil, fmt.Errorf("неподдерживаемый тип option: %s", toType)
+	}
+
+	return json.Marshal(value)
+}
+
+// storedValueText возвращает текстовое представление сохранённого JSON-значения
+func storedValueText(raw json.RawMessage) string {
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return string(raw)
+	}
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return string(raw)
+	}
+}
@@ -0,0 +1,404 @@
+// internal/services/auto_moderation_service.go
+
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/WhyDias/Marketplace/internal/db"
+	"github.com/WhyDias/Marketplace/internal/models"
+)
+
+var (
+	ErrModerationRuleNotFound = errors.New("правило автомодерации не найдено")
+	ErrInvalidModerationRule  = errors.New("некорректные параметры правила")
+)
+
+// contactPatterns находят в тексте контакты, по которым покупатель может уйти с площадки
+var contactPatterns = []struct {
+	name    string
+	pattern *regexp.Regexp
+}{
+	{"телефон", regexp.MustCompile(`\+?\d[\d\s\-()]{7,}\d`)},
+	{"email", regexp.MustCompile(`(?i)[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,}`)},
+	{"ссылка", regexp.MustCompile(`(?i)(https?://|www\.)\S+`)},
+	{"мессенджер", regexp.MustCompile(`(?i)(t\.me|wa\.me|telegram\.me)/\S+|(^|\s)@[a-z0-9_]{4,}`)},
+}
+
+// minPhoneDigits минимальное количество цифр в номере телефона
+const minPhoneDigits = 9
+
+type AutoModerationService struct{}
+
+func NewAutoModerationService() *AutoModerationService {
+	return &AutoModerationService{}
+}
+
+// GetRules возвращает правила автомодерации
+func (s *AutoModerationService) GetRules() ([]models.ModerationRule, error) {
+	return db.GetModerationRules()
+}
+
+// UpdateRule меняет настройки правила. Незаданные поля запроса остаются прежними.
+func (s *AutoModerationService) UpdateRule(code string, req models.UpdateModerationRuleRequest) (*models.ModerationRule, error) {
+	rule, err := db.GetModerationRule(code)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil {
+		return nil, ErrModerationRuleNotFound
+	}
+
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	if req.Weight != nil {
+		rule.Weight = *req.Weight
+	}
+	if req.Action != "" {
+		rule.Action = req.Action
+	}
+	if len(req.Params) > 0 {
+		rule.Params = req.Params
+	}
+
+	if err := validateRule(rule); err != nil {
+		return nil, err
+	}
+
+	if err := db.UpdateModerationRule(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// validateRule проверяет действие и параметры правила
+func validateRule(rule *models.ModerationRule) error {
+	if rule.Code == models.RuleAutoApprove {
+		if rule.Action != models.AutoModerationApprove {
+			return fmt.Errorf("%w: для auto_approve допустимо только действие approve", ErrInvalidModerationRule)
+		}
+		return nil
+	}
+	if rule.Action != models.AutoModerationReject && rule.Action != models.AutoModerationReview {
+		return fmt.Errorf("%w: действие должно быть reject или review", ErrInvalidModerationRule)
+	}
+
+	switch rule.Code {
+	case models.RuleBannedWords:
+		var params models.BannedWordsParams
+		if err := json.Unmarshal(rule.Params, &params); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidModerationRule, err)
+		}
+	case models.RulePriceOutlier:
+		var params models.PriceOutlierParams
+		if err := json.Unmarshal(rule.Params, &params); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidModerationRule, err)
+		}
+		if params.MinRatio <= 0 || params.MinRatio >= 1 || params.MaxRatio <= 1 {
+			return fmt.Errorf("%w: должно выполняться 0 < min_ratio < 1 < max_ratio", ErrInvalidModerationRule)
+		}
+	}
+	return nil
+}
+
+// RunForProduct повторно проверяет продукт, ожидающий модерации, и применяет решение
+func (s *AutoModerationService) RunForProduct(productID int) (*models.AutoModerationResult, error) {
+	statusID, err := db.GetProductStatusID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if statusID == 0 {
+		return nil, ErrModerationProductNotFound
+	}
+	if statusID != models.ProductStatusPending {
+		return nil, ErrProductNotPending
+	}
+
+	return autoModerateProduct(productID, false)
+}
+
+// autoModerateProduct проверяет продукт, поступивший на модерацию.
+// Нарушение правила с действием reject отклоняет продукт с перечнем причин,
+// продукт без нарушений подтверждается, если включено auto_approve,
+// в остальных случаях продукт остаётся в очереди с отметками сработавших правил.
+// imagesPending — изображения продукта ещё загружаются отдельными запросами (сразу после создания):
+// правило missing_images не применяется, но продукт без изображений не подтверждается автоматически.
+func autoModerateProduct(productID int, imagesPending bool) (*models.AutoModerationResult, error) {
+	rules, err := db.GetModerationRules()
+	if err != nil {
+		return nil, err
+	}
+
+	subject, err := db.GetAutoModerationSubject(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := evaluateRules(subject, rules, imagesPending)
+
+	if err := db.CreateAutoModerationResult(result); err != nil {
+		return nil, err
+	}
+
+	switch result.Outcome {
+	case models.AutoModerationReject:
+		var reasons []models.ProductRejectionReason
+		reasons, err = autoRejectionReasons(productID, result.FiredRules)
+		if err != nil {
+			return nil, err
+		}
+		err = moderateProduct(productID, 0, models.ProductStatusRejected, autoRejectionComment(result.FiredRules), reasons)
+	case models.AutoModerationApprove:
+		err = moderateProduct(productID, 0, models.ProductStatusApproved, "Подтвержден автоматически", nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("не удалось применить решение автомодерации: %w", err)
+	}
+
+	return result, nil
+}
+
+// evaluateRules применяет включённые правила и определяет итоговое решение
+func evaluateRules(subject *models.AutoModerationSubject, rules []models.ModerationRule, imagesPending bool) *models.AutoModerationResult {
+	result := &models.AutoModerationResult{
+		ProductID:  subject.ProductID,
+		Outcome:    models.AutoModerationReview,
+		FiredRules: []models.FiredRule{},
+	}
+
+	autoApprove := false
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		if rule.Code == models.RuleAutoApprove {
+			autoApprove = true
+			continue
+		}
+		if rule.Code == models.RuleMissingImages && imagesPending {
+			continue
+		}
+
+		message, details, fired := checkRule(subject, rule)
+		if !fired {
+			continue
+		}
+		result.Score += rule.Weight
+		result.FiredRules = append(result.FiredRules, models.FiredRule{
+			Code:    rule.Code,
+			Action:  rule.Action,
+			Message: message,
+			Details: details,
+		})
+		if rule.Action == models.AutoModerationReject {
+			result.Outcome = models.AutoModerationReject
+		}
+	}
+
+	if len(result.FiredRules) == 0 && autoApprove && !(imagesPending && subject.ImageCount == 0) {
+		result.Outcome = models.AutoModerationApprove
+	}
+
+	return result
+}
+
+// checkRule возвращает пояснение и подробности, если правило сработало
+func checkRule(subject *models.AutoModerationSubject, rule models.ModerationRule) (string, []string, bool) {
+	text := subject.Name + "\n" + subject.Description
+
+	switch rule.Code {
+	case models.RuleBannedWords:
+		var params models.BannedWordsParams
+		if err := json.Unmarshal(rule.Params, &params); err != nil {
+			log.Printf("checkRule: некорректные параметры правила %s: %v", rule.Code, err)
+			return "", nil, false
+		}
+		if found := findBannedWords(text, params.Words); len(found) > 0 {
+			return "Запрещённые слова в названии или описании", found, true
+		}
+
+	case models.RuleContactDetails:
+		var found []string
+		for _, p := range contactPatterns {
+			for _, match := range p.pattern.FindAllString(text, -1) {
+				// Цены и размеры вида "1 000 000" не считаем телефонами
+				if p.name == "телефон" && countDigits(match) < minPhoneDigits {
+					continue
+				}
+				found = append(found, fmt.Sprintf("%s: %s", p.name, strings.TrimSpace(match)))
+				break
+			}
+		}
+		if len(found) > 0 {
+			return "Контактные данные в названии или описании", found, true
+		}
+
+	case models.RuleMissingImages:
+		if subject.ImageCount == 0 {
+			return "У продукта и его вариаций нет изображений", nil, true
+		}
+
+	case models.RulePriceOutlier:
+		var params models.PriceOutlierParams
+		if err := json.Unmarshal(rule.Params, &params); err != nil {
+			log.Printf("checkRule: некорректные параметры правила %s: %v", rule.Code, err)
+			return "", nil, false
+		}
+		if subject.Price <= 0 || subject.CategoryPriceSamples < params.MinSamples || subject.CategoryMedianPrice <= 0 {
+			return "", nil, false
+		}
+		ratio := subject.Price / subject.CategoryMedianPrice
+		if ratio < params.MinRatio || ratio > params.MaxRatio {
+			detail := fmt.Sprintf("цена %.2f, медиана категории %.2f", subject.Price, subject.CategoryMedianPrice)
+			return "Цена сильно отличается от медианной цены категории", []string{detail}, true
+		}
+
+	case models.RuleMissingRequiredAttributes:
+		if len(subject.MissingRequiredAttributes) > 0 {
+			return "Не заполнены обязательные атрибуты", subject.MissingRequiredAttributes, true
+		}
+
+	case models.RuleUnverifiedSupplier:
+		if !subject.SupplierVerified {
+			return "Поставщик не прошёл проверку документов", nil, true
+		}
+	}
+
+	return "", nil, false
+}
+
+// checkSupplierVerificationRequired запрещает подтверждать продукт непроверенного поставщика,
+// пока включено правило unverified_supplier
+func checkSupplierVerificationRequired(productID int) error {
+	rule, err := db.GetModerationRule(models.RuleUnverifiedSupplier)
+	if err != nil {
+		return err
+	}
+	if rule == nil || !rule.Enabled {
+		return nil
+	}
+	verified, err := db.IsProductSupplierVerified(productID)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		return ErrSupplierNotVerified
+	}
+	return nil
+}
+
+// findBannedWords ищет запрещённые слова и фразы без учёта регистра, только целыми словами
+func findBannedWords(text string, words []string) []string {
+	normalized := " " + strings.Join(splitWords(text), " ") + " "
+
+	var found []string
+	for _, word := range words {
+		phrase := strings.Join(splitWords(word), " ")
+		if phrase == "" {
+			continue
+		}
+		if strings.Contains(normalized, " "+phrase+" ") {
+			found = append(found, word)
+		}
+	}
+	return found
+}
+
+func countDigits(s string) int {
+	count := 0
+	for _, r := range s {
+		if unicode.IsDigit(r) {
+			count++
+		}
+	}
+	return count
+}
+
+func splitWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// ruleRejectionReasons сопоставляет правила автомодерации с кодами справочника причин отклонения
+var ruleRejectionReasons = map[string]string{
+	models.RuleBannedWords:               "prohibited_item",
+	models.RuleContactDetails:            "contact_details",
+	models.RuleMissingImages:             "bad_photos",
+	models.RulePriceOutlier:              "incorrect_price",
+	models.RuleMissingRequiredAttributes: "incorrect_attribute",
+}
+
+// autoRejectionReasons переводит сработавшие правила с действием reject в причины отклонения.
+// Причины, отключённые в справочнике, пропускаются.
+func autoRejectionReasons(productID int, firedRules []models.FiredRule) ([]models.ProductRejectionReason, error) {
+	var inputs []models.RejectionReasonInput
+	for _, rule := range firedRules {
+		code, ok := ruleRejectionReasons[rule.Code]
+		if !ok || rule.Action != models.AutoModerationReject {
+			continue
+		}
+		switch rule.Code {
+		case models.RuleMissingRequiredAttributes:
+			for _, name := range rule.Details {
+				inputs = append(inputs, models.RejectionReasonInput{Code: code, Field: "attribute:" + name, Note: rule.Message})
+			}
+		case models.RulePriceOutlier:
+			inputs = append(inputs, models.RejectionReasonInput{Code: code, Field: "price", Note: strings.Join(rule.Details, ", ")})
+		default:
+			inputs = append(inputs, models.RejectionReasonInput{Code: code, Note: strings.Join(rule.Details, ", ")})
+		}
+	}
+	if len(inputs) == 0 {
+		return nil, nil
+	}
+
+	codes := make([]string, 0, len(inputs))
+	for _, input := range inputs {
+		codes = append(codes, input.Code)
+	}
+	catalog, err := db.GetRejectionReasonsByCodes(codes)
+	if err != nil {
+		return nil, err
+	}
+
+	reasons := make([]models.ProductRejectionReason, 0, len(inputs))
+	for _, input := range inputs {
+		catalogReason, ok := catalog[input.Code]
+		if !ok {
+			continue
+		}
+		reasons = append(reasons, models.ProductRejectionReason{
+			ProductID: productID,
+			ReasonID:  catalogReason.ID,
+			Code:      catalogReason.Code,
+			Title:     catalogReason.Title,
+			Field:     input.Field,
+			Note:      input.Note,
+		})
+	}
+	return reasons, nil
+}
+
+// autoRejectionComment формирует комментарий к автоматическому отклонению
+func autoRejectionComment(firedRules []models.FiredRule) string {
+	var lines []string
+	for _, rule := range firedRules {
+		if rule.Action != models.AutoModerationReject {
+			continue
+		}
+		line := rule.Message
+		if len(rule.Details) > 0 {
+			line += ": " + strings.Join(rule.Details, ", ")
+		}
+		lines = append(lines, line)
+	}
+	return "Отклонено автоматически. " + strings.Join(lines, "; ")
+}
@@ -3,11 +3,16 @@
 package services
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/WhyDias/Marketplace/internal/db"
 	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/WhyDias/Marketplace/internal/utils"
 	"log"
+	"regexp"
+	"strings"
 )
 
 type CategoryService struct{}
@@ -67,164 +72,227 @@ func buildCategoryTree(categories []models.Category) []models.CategoryNode {
 	return roots
 }
 
+// attributeChange запланированное изменение атрибута категории
+type attributeChange struct {
+	request    models.AttributeRequest
+	value      json.RawMessage
+	existing   *models.CategoryAttribute
+	dimension  string
+	unit       string
+	unitFactor float64
+	migrations map[string]string
+	summary    string
+
+	// Флаги, которые задаёт администратор; nil оставляет флаг прежним
+	flags models.UpdateAttributeFlagsRequest
+}
+
+// AddCategoryAttributes добавляет или обновляет атрибуты категории. Изменение существующего
+// атрибута сохраняется как новая версия. Сначала проверяются все атрибуты запроса,
+// и только затем изменения записываются.
 func (s *CategoryService) AddCategoryAttributes(userID int, req *models.AddCategoryAttributesRequest) error {
 	log.Printf("User ID %d добавляет атрибуты для категории ID %d", userID, req.CategoryID)
 
-	for _, attrReq := range req.Attributes {
-		var valueJSON json.RawMessage
-		var err error
-		var stringValues []string
-		var rangeValues []int
+	changes, err := planCategoryAttributes(req.CategoryID, req.Attributes)
+	if err != nil {
+		return err
+	}
+	return applyAttributeChanges(req.CategoryID, userID, changes)
+}
 
-		// Преобразование значений в JSON в зависимости от типа атрибута
-		switch attrReq.TypeOfOption {
-		case "dropdown":
-			values, ok := attrReq.Value.([]interface{})
-			if !ok {
-				return fmt.Errorf("некорректный тип value для dropdown")
-			}
-			stringValues = make([]string, len(values))
-			for i, v := range values {
-				str, ok := v.(string)
-				if !ok {
-					return fmt.Errorf("некорректное значение в dropdown")
-				}
-				stringValues[i] = str
-			}
-			valueJSON, _ = json.Marshal(stringValues)
+// planCategoryAttributes проверяет атрибуты запроса и сопоставляет их с уже объявленными
+// в категории. Для categoryID 0 все атрибуты считаются новыми.
+func planCategoryAttributes(categoryID int, attributes []models.AttributeRequest) ([]attributeChange, error) {
+	changes := make([]attributeChange, 0, len(attributes))
+	for _, attrReq := range attributes {
+		valueJSON, err := attributeValueJSON(attrReq.TypeOfOption, attrReq.Value)
+		if err != nil {
+			return nil, err
+		}
 
-		case "range":
-			values, ok := attrReq.Value.([]interface{})
-			if !ok || len(values) != 2 {
-				return fmt.Errorf("некорректный тип value для range")
-			}
-			rangeValues = make([]int, 2)
-			for i, v := range values {
-				num, ok := v.(float64) // JSON числа unmarshaled как float64
-				if !ok {
-					return fmt.Errorf("некорректное значение в range")
-				}
-				rangeValues[i] = int(num)
+		// Проверка на наличие атрибута с таким именем для данной категории
+		var existingCategoryAttribute *models.CategoryAttribute
+		if categoryID != 0 {
+			existingCategoryAttribute, err = db.GetCategoryAttributeByName(categoryID, attrReq.Name)
+			if err != nil {
+				log.Printf("Ошибка при проверке существования атрибута: %v", err)
+				return nil, fmt.Errorf("не удалось проверить существование атрибута: %v", err)
 			}
-			valueJSON, _ = json.Marshal(rangeValues)
+		}
 
-		case "switcher":
-			var boolVal bool
-			if attrReq.Value != nil {
-				value, ok := attrReq.Value.(bool)
-				if !ok {
-					return fmt.Errorf("некорректный тип value для switcher")
-				}
-				boolVal = value
-			} else {
-				boolVal = false
-			}
-			valueJSON, _ = json.Marshal(boolVal)
+		dimension, unit, err := resolveAttributeUnit(attrReq.TypeOfOption, attrReq.Dimension, attrReq.Unit)
+		if err != nil {
+			return nil, err
+		}
 
-		case "text":
-			var textVal string
-			if attrReq.Value != nil {
-				str, ok := attrReq.Value.(string)
-				if !ok {
-					return fmt.Errorf("некорректный тип value для text")
-				}
-				textVal = str
-			} else {
-				textVal = ""
+		change := attributeChange{
+			request:    attrReq,
+			value:      valueJSON,
+			existing:   existingCategoryAttribute,
+			dimension:  dimension,
+			unit:       unit,
+			unitFactor: 1,
+		}
+		if existingCategoryAttribute != nil {
+			if err := planAttributeChange(&change); err != nil {
+				return nil, err
 			}
-			valueJSON, _ = json.Marshal(textVal)
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
 
-		case "numeric":
-			var numericVal int
-			if attrReq.Value != nil {
-				num, ok := attrReq.Value.(float64)
-				if !ok {
-					return fmt.Errorf("некорректный тип value для numeric")
-				}
-				numericVal = int(num)
-			} else {
-				numericVal = 0
+// applyAttributeChanges записывает проверенные изменения атрибутов категории
+func applyAttributeChanges(categoryID, userID int, changes []attributeChange) error {
+	for _, change := range changes {
+		attrReq := change.request
+		if change.existing != nil {
+			if err := updateAttributeFlags(change.existing.ID, change.flags); err != nil {
+				return err
+			}
+			if change.summary == "" {
+				continue // Определение не изменилось
+			}
+			// Атрибут с таким именем существует - сохраняем новую версию
+			existing := change.existing
+			existing.Description = &attrReq.Description
+			existing.TypeOfOption = &attrReq.TypeOfOption
+			existing.Value = change.value
+			existing.IsLinked = attrReq.IsLinked
+			existing.Dimension = change.dimension
+			existing.Unit = change.unit
+
+			err := db.UpdateCategoryAttributeVersioned(existing, change.migrations, change.unitFactor, userID, change.summary)
+			if err != nil {
+				log.Printf("Ошибка при обновлении атрибута категории: %v", err)
+				return fmt.Errorf("не удалось обновить атрибут категории: %v", err)
 			}
-			valueJSON, _ = json.Marshal(numericVal)
+			continue
+		}
 
-		default:
-			return fmt.Errorf("неподдерживаемый тип option: %s", attrReq.TypeOfOption)
+		// Атрибут с таким именем не найден - создаём новый
+		categoryAttribute := models.CategoryAttribute{
+			CategoryID:   categoryID,
+			Name:         attrReq.Name,
+			Description:  &attrReq.Description,
+			TypeOfOption: &attrReq.TypeOfOption,
+			Value:        change.value,
+			IsLinked:     attrReq.IsLinked,
+			Dimension:    change.dimension,
+			Unit:         change.unit,
+		}
+		if change.flags.IsRequired != nil {
+			categoryAttribute.IsRequired = *change.flags.IsRequired
+		}
+		if change.flags.SortOrder != nil {
+			categoryAttribute.SortOrder = *change.flags.SortOrder
+		}
+		if change.flags.IsFilterable != nil {
+			categoryAttribute.IsFilterable = *change.flags.IsFilterable
 		}
 
-		// Проверка на наличие атрибута с таким именем для данной категории
-		existingCategoryAttribute, err := db.GetCategoryAttributeByName(req.CategoryID, attrReq.Name)
+		attributeID, err := db.CreateCategoryAttribute(&categoryAttribute)
 		if err != nil {
-			log.Printf("Ошибка при проверке существования атрибута: %v", err)
-			return fmt.Errorf("не удалось проверить существование атрибута: %v", err)
+			log.Printf("Ошибка при создании нового атрибута категории: %v", err)
+			return fmt.Errorf("не удалось создать новый атрибут категории: %v", err)
 		}
+		if err := db.CreateAttributeVersion(attributeID, userID, "Атрибут создан"); err != nil {
+			log.Printf("Ошибка при сохранении версии атрибута %d: %v", attributeID, err)
+		}
+	}
 
-		if existingCategoryAttribute != nil {
-			// Атрибут с таким именем существует - обновляем его
-			existingCategoryAttribute.Description = &attrReq.Description
-			existingCategoryAttribute.TypeOfOption = &attrReq.TypeOfOption
-			existingCategoryAttribute.Value = valueJSON
+	return nil
+}
 
-			err = db.UpdateCategoryAttribute(existingCategoryAttribute)
-			if err != nil {
-				log.Printf("Ошибка при обновлении атрибута категории: %v", err)
-				return fmt.Errorf("не удалось обновить атрибут категории: %v", err)
+// updateAttributeFlags записывает изменённые флаги атрибута, если они есть
+func updateAttributeFlags(attributeID int, flags models.UpdateAttributeFlagsRequest) error {
+	if flags.IsRequired == nil && flags.SortOrder == nil && flags.IsFilterable == nil {
+		return nil
+	}
+	if _, err := db.UpdateAttributeFlags(attributeID, flags.IsRequired, flags.SortOrder, flags.IsFilterable); err != nil {
+		log.Printf("Ошибка при обновлении флагов атрибута %d: %v", attributeID, err)
+		return fmt.Errorf("не удалось обновить флаги атрибута: %v", err)
+	}
+	return nil
+}
+
+// attributeValueJSON преобразует значение атрибута из запроса в JSON в зависимости от типа атрибута
+func attributeValueJSON(typeOfOption string, rawValue interface{}) (json.RawMessage, error) {
+	var valueJSON json.RawMessage
+
+	switch typeOfOption {
+	case "dropdown":
+		values, ok := rawValue.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("некорректный тип value для dropdown")
+		}
+		stringValues := make([]string, len(values))
+		for i, v := range values {
+			str, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("некорректное значение в dropdown")
 			}
-		} else {
-			// Атрибут с таким именем не найден - создаём новый
-			categoryAttribute := models.CategoryAttribute{
-				CategoryID:   req.CategoryID,
-				Name:         attrReq.Name,
-				Description:  &attrReq.Description,
-				TypeOfOption: &attrReq.TypeOfOption,
-				Value:        valueJSON,
+			stringValues[i] = str
+		}
+		valueJSON, _ = json.Marshal(stringValues)
+
+	case "range":
+		values, ok := rawValue.([]interface{})
+		if !ok || len(values) != 2 {
+			return nil, fmt.Errorf("некорректный тип value для range")
+		}
+		rangeValues := make([]float64, 2)
+		for i, v := range values {
+			num, ok := v.(float64) // JSON числа unmarshaled как float64
+			if !ok {
+				return nil, fmt.Errorf("некорректное значение в range")
 			}
+			rangeValues[i] = num
+		}
+		if rangeValues[0] > rangeValues[1] {
+			return nil, fmt.Errorf("некорректное значение в range: минимум больше максимума")
+		}
+		valueJSON, _ = json.Marshal(rangeValues)
 
-			_, err := db.CreateCategoryAttribute(&categoryAttribute)
-			if err != nil {
-				log.Printf("Ошибка при создании нового атрибута категории: %v", err)
-				return fmt.Errorf("не удалось создать новый атрибут категории: %v", err)
+	case "switcher":
+		var boolVal bool
+		if rawValue != nil {
+			value, ok := rawValue.(bool)
+			if !ok {
+				return nil, fmt.Errorf("некорректный тип value для switcher")
 			}
+			boolVal = value
 		}
+		valueJSON, _ = json.Marshal(boolVal)
 
-		// Теперь добавляем или обновляем запись в таблице attributes
-		//existingAttribute, err := db.GetAttributeByNameAndCategoryID(attrReq.Name, req.CategoryID)
-		//if err != nil {
-		//	log.Printf("Ошибка при проверке существования атрибута в attributes: %v", err)
-		//	return fmt.Errorf("не удалось проверить существование атрибута в attributes: %v", err)
-		//}
-		//
-		//if existingAttribute != nil {
-		//	// Обновляем атрибут
-		//	existingAttribute.Description = attrReq.Description
-		//	existingAttribute.TypeOfOption = attrReq.TypeOfOption
-		//	existingAttribute.Value = valueJSON
-		//	existingAttribute.IsLinked = attrReq.IsLinked
-		//
-		//	err = db.UpdateAttribute(existingAttribute)
-		//	if err != nil {
-		//		log.Printf("Ошибка при обновлении атрибута в таблице attributes: %v", err)
-		//		return fmt.Errorf("не удалось обновить атрибут в таблице attributes: %v", err)
-		//	}
-		//} else {
-		//	// Создаём новый атрибут
-		//	attribute := models.Attribute{
-		//		Name:         attrReq.Name,
-		//		CategoryID:   req.CategoryID,
-		//		Description:  attrReq.Description,
-		//		TypeOfOption: attrReq.TypeOfOption,
-		//		IsLinked:     attrReq.IsLinked,
-		//		Value:        valueJSON,
-		//	}
-		//
-		//	_, err := db.CreateAttribute(&attribute)
-		//	if err != nil {
-		//		log.Printf("Ошибка при создании атрибута в таблице attributes: %v", err)
-		//		return fmt.Errorf("не удалось создать атрибут в таблице attributes: %v", err)
-		//	}
-		//}
+	case "text":
+		var textVal string
+		if rawValue != nil {
+			str, ok := rawValue.(string)
+			if !ok {
+				return nil, fmt.Errorf("некорректный тип value для text")
+			}
+			textVal = str
+		}
+		valueJSON, _ = json.Marshal(textVal)
+
+	case "numeric":
+		var numericVal float64
+		if rawValue != nil {
+			num, ok := rawValue.(float64)
+			if !ok {
+				return nil, fmt.Errorf("некорректный тип value для numeric")
+			}
+			numericVal = num
+		}
+		valueJSON, _ = json.Marshal(numericVal)
+
+	default:
+		return nil, fmt.Errorf("неподдерживаемый тип option: %s", typeOfOption)
 	}
 
-	return nil
+	return valueJSON, nil
 }
 
 // GetCategoryByID возвращает категорию по её ID
@@ -236,7 +304,7 @@ func StringPtr(s string) *string {
 	return &s
 }
 
-// GetCategoryAttributes возвращает атрибуты для заданной категории
+// GetCategoryAttributes возвращает атрибуты для заданной категории, включая унаследованные от предков
 func (s *CategoryService) GetCategoryAttributes(categoryID int) ([]models.CategoryAttribute, error) {
 	// Получаем атрибуты категории из базы данных
 	attributes, err := db.GetCategoryAttributes(categoryID)
@@ -254,6 +322,16 @@ func (s *CategoryService) GetCategoryAttributes(categoryID int) ([]models.Catego
 			Description:  StringPtr(attr.Description),  // Преобразуем строку в *string, если требуется
 			TypeOfOption: StringPtr(attr.TypeOfOption), // Преобразуем строку в *string, если требуется
 			Value:        attr.Value,
+			IsLinked:     attr.IsLinked,
+			Dimension:    attr.Dimension,
+			Unit:         attr.Unit,
+			IsRequired:   attr.IsRequired,
+			SortOrder:    attr.SortOrder,
+			IsFilterable: attr.IsFilterable,
+
+			Inherited:          attr.Inherited,
+			SourceCategoryID:   attr.SourceCategoryID,
+			SourceCategoryPath: attr.SourceCategoryPath,
 		}
 	}
 
@@ -287,7 +365,7 @@ func (s *CategoryService) GetCategoryAttributesByCategoryID(userID int, category
 			value = dropdownValues
 
 		case "range":
-			var rangeValues []int
+			var rangeValues []float64
 			if err := json.Unmarshal(attr.Value, &rangeValues); err != nil {
 				log.Printf("Ошибка десериализации value для range: %v", err)
 				return nil, fmt.Errorf("некорректное значение атрибута %s", attr.Name)
@@ -311,7 +389,7 @@ func (s *CategoryService) GetCategoryAttributesByCategoryID(userID int, category
 			value = textValue
 
 		case "numeric":
-			var numericValue int
+			var numericValue float64
 			if err := json.Unmarshal(attr.Value, &numericValue); err != nil {
 				log.Printf("Ошибка десериализации value для numeric: %v", err)
 				return nil, fmt.Errorf("некорректное значение атрибута %s", attr.Name)
@@ -330,6 +408,11 @@ func (s *CategoryService) GetCategoryAttributesByCategoryID(userID int, category
 			TypeOfOption: attr.TypeOfOption,
 			Value:        value,
 			IsLinked:     attr.IsLinked,
+			Dimension:    attr.Dimension,
+			Unit:         attr.Unit,
+			IsRequired:   attr.IsRequired,
+			SortOrder:    attr.SortOrder,
+			IsFilterable: attr.IsFilterable,
 		})
 	}
 
@@ -408,3 +491,207 @@ func (s *CategoryService) GetAttributesByCategoryAndIsLinked(categoryID int, isL
 	}
 	return attributes, nil
 }
+
+var (
+	ErrCategoryNotFound       = errors.New("категория не найдена")
+	ErrCategoryPathExists     = errors.New("категория с таким path уже существует")
+	ErrInvalidCategoryLabel   = errors.New("метка категории может содержать только латинские буквы, цифры и _")
+	ErrInvalidCategoryMove    = errors.New("нельзя перенести категорию в её собственную подкатегорию")
+	ErrInvalidCategoryTarget  = errors.New("целевая категория не должна входить в удаляемое поддерево")
+	ErrCategoryTargetNotFound = errors.New("целевая категория не найдена")
+	ErrAttributeNotInherited  = errors.New("у предков категории нет атрибута с таким названием")
+	ErrAttributeNotHidden     = errors.New("атрибут не скрыт в этой категории")
+)
+
+// categoryLabelPattern допустимая метка ltree
+var categoryLabelPattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// categoryLabel возвращает метку для path: указанную явно или транслитерацию названия
+func categoryLabel(label, name string) (string, error) {
+	if label == "" {
+		label = utils.Slugify(name, '_')
+	}
+	if !categoryLabelPattern.MatchString(label) {
+		return "", ErrInvalidCategoryLabel
+	}
+	return label, nil
+}
+
+// categoryPath дописывает метку к path родителя
+func categoryPath(parent *models.Category, label string) string {
+	if parent == nil {
+		return label
+	}
+	return parent.Path + "." + label
+}
+
+// getCategoryOrNil возвращает категорию по ID или ошибку notFound, если её нет. Для nil ID возвращает nil.
+func getCategoryOrNil(categoryID *int, notFound error) (*models.Category, error) {
+	if categoryID == nil {
+		return nil, nil
+	}
+	category, err := db.GetCategoryByID(*categoryID)
+	if err != nil {
+		return nil, err
+	}
+	if category == nil {
+		return nil, notFound
+	}
+	return category, nil
+}
+
+// CreateCategory создаёт категорию под указанным родителем, path и parent_id выводятся из родителя
+func (s *CategoryService) CreateCategory(req models.CreateCategoryRequest) (*models.Category, error) {
+	return createCategory(req.ParentID, req.Name, req.Label, req.ImageURL)
+}
+
+// createCategory создаёт категорию под родителем parentID. Явно указанная метка должна быть
+// свободна; метка из транслитерации названия при совпадении получает числовой суффикс.
+func createCategory(parentID *int, name, label, imageURL string) (*models.Category, error) {
+	parent, err := getCategoryOrNil(parentID, ErrCategoryNotFound)
+	if err != nil {
+		return nil, err
+	}
+
+	explicit := label != ""
+	label, err = categoryLabel(label, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var path string
+	if explicit {
+		path = categoryPath(parent, label)
+		exists, err := db.CategoryPathExists(path)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, ErrCategoryPathExists
+		}
+	} else if path, err = uniqueCategoryPath(parent, label); err != nil {
+		return nil, err
+	}
+
+	category := &models.Category{
+		Name:     name,
+		Path:     path,
+		ImageURL: imageURL,
+	}
+	if err := db.CreateCategory(category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// RenameCategory меняет название категории, path остаётся прежним
+func (s *CategoryService) RenameCategory(categoryID int, name string) (*models.Category, error) {
+	updated, err := db.RenameCategory(categoryID, name)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, ErrCategoryNotFound
+	}
+	return db.GetCategoryByID(categoryID)
+}
+
+// MoveCategory переносит категорию со всеми подкатегориями под нового родителя
+func (s *CategoryService) MoveCategory(categoryID int, req models.MoveCategoryRequest) (*models.Category, error) {
+	category, err := getCategoryOrNil(&categoryID, ErrCategoryNotFound)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := getCategoryOrNil(req.ParentID, ErrCategoryNotFound)
+	if err != nil {
+		return nil, err
+	}
+	if parent != nil && isInSubtree(parent.Path, category.Path) {
+		return nil, ErrInvalidCategoryMove
+	}
+
+	label := req.Label
+	if label == "" {
+		label = category.Path[strings.LastIndex(category.Path, ".")+1:]
+	} else if !categoryLabelPattern.MatchString(label) {
+		return nil, ErrInvalidCategoryLabel
+	}
+
+	newPath := categoryPath(parent, label)
+	if newPath == category.Path {
+		return category, nil
+	}
+	exists, err := db.CategoryPathExists(newPath)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrCategoryPathExists
+	}
+
+	var parentID sql.NullInt64
+	if parent != nil {
+		parentID = sql.NullInt64{Int64: int64(parent.ID), Valid: true}
+	}
+	if err := db.MoveCategorySubtree(category.ID, category.Path, newPath, parentID); err != nil {
+		return nil, err
+	}
+	return db.GetCategoryByID(categoryID)
+}
+
+// DeleteCategory удаляет категорию с подкатегориями, перенося продукты, поставщиков
+// и атрибуты в целевую категорию
+func (s *CategoryService) DeleteCategory(categoryID int, targetID int) (*models.CategoryDeleteResult, error) {
+	category, err := getCategoryOrNil(&categoryID, ErrCategoryNotFound)
+	if err != nil {
+		return nil, err
+	}
+	target, err := getCategoryOrNil(&targetID, ErrCategoryTargetNotFound)
+	if err != nil {
+		return nil, err
+	}
+	if isInSubtree(target.Path, category.Path) {
+		return nil, ErrInvalidCategoryTarget
+	}
+
+	result, err := db.DeleteCategorySubtree(category.Path, target.ID)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("DeleteCategory: удалена категория %s (%d шт.), продуктов перенесено в %s: %d",
+		category.Path, result.DeletedCategories, target.Path, result.ReassignedProducts)
+	return result, nil
+}
+
+// isInSubtree проверяет, что path совпадает с root или лежит под ним
+func isInSubtree(path, root string) bool {
+	return path == root || strings.HasPrefix(path, root+".")
+}
+
+// HideInheritedAttribute скрывает унаследованный атрибут у категории и её подкатегорий.
+// Подкатегория может вернуть атрибут, объявив свой с тем же названием.
+func (s *CategoryService) HideInheritedAttribute(categoryID int, name string) error {
+	if _, err := getCategoryOrNil(&categoryID, ErrCategoryNotFound); err != nil {
+		return err
+	}
+	sourceID, err := db.GetInheritedAttributeSourceID(categoryID, name)
+	if err != nil {
+		return err
+	}
+	if sourceID == 0 {
+		return ErrAttributeNotInherited
+	}
+	return db.HideCategoryAttribute(categoryID, name)
+}
+
+// UnhideInheritedAttribute отменяет скрытие унаследованного атрибута
+func (s *CategoryService) UnhideInheritedAttribute(categoryID int, name string) error {
+	removed, err := db.UnhideCategoryAttribute(categoryID, name)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrAttributeNotHidden
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+// internal/services/color_service.go
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/WhyDias/Marketplace/internal/db"
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/WhyDias/Marketplace/internal/utils"
+)
+
+var (
+	ErrColorNotFound = errors.New("цвет не найден")
+	ErrColorExists   = errors.New("цвет с таким названием уже есть в палитре")
+	ErrColorInUse    = errors.New("цвет выбран у вариаций")
+	ErrInvalidColor  = errors.New("некорректный цвет")
+)
+
+// ColorService управляет палитрой цветов вариаций
+type ColorService struct{}
+
+func NewColorService() *ColorService {
+	return &ColorService{}
+}
+
+// GetColorFamilies возвращает семейства цветов
+func (s *ColorService) GetColorFamilies() ([]models.ColorFamily, error) {
+	return db.GetColorFamilies()
+}
+
+// GetColors возвращает палитру; family ограничивает выборку семейством
+func (s *ColorService) GetColors(family string) ([]models.PaletteColor, error) {
+	return db.GetPaletteColors(family)
+}
+
+// CreateColor добавляет цвет в палитру. Название обязательно.
+func (s *ColorService) CreateColor(req models.PaletteColorRequest) (*models.PaletteColor, error) {
+	if req.Name == nil {
+		return nil, fmt.Errorf("%w: не указано название", ErrInvalidColor)
+	}
+	color := &models.PaletteColor{}
+	if err := applyPaletteColorRequest(color, req); err != nil {
+		return nil, err
+	}
+	if err := db.CreatePaletteColor(color); err != nil {
+		return nil, err
+	}
+	return db.GetPaletteColor(color.ID)
+}
+
+// UpdateColor меняет цвет палитры. Вариации остаются связаны с цветом.
+func (s *ColorService) UpdateColor(colorID int, req models.PaletteColorRequest) (*models.PaletteColor, error) {
+	color, err := db.GetPaletteColor(colorID)
+	if err != nil {
+		return nil, err
+	}
+	if color == nil {
+		return nil, ErrColorNotFound
+	}
+	if err := applyPaletteColorRequest(color, req); err != nil {
+		return nil, err
+	}
+	if err := db.UpdatePaletteColor(color); err != nil {
+		return nil, err
+	}
+	return db.GetPaletteColor(colorID)
+}
+
+// DeleteColor удаляет цвет, который не выбран ни у одной вариации
+func (s *ColorService) DeleteColor(colorID int) error {
+	color, err := db.GetPaletteColor(colorID)
+	if err != nil {
+		return err
+	}
+	if color == nil {
+		return ErrColorNotFound
+	}
+	if color.Variations > 0 {
+		return fmt.Errorf("%w (вариаций: %d)", ErrColorInUse, color.Variations)
+	}
+	return db.DeletePaletteColor(colorID)
+}
+
+// GetColorFacets возвращает фасет цветов каталога для категории с подкатегориями, 0 — весь каталог
+func (s *ColorService) GetColorFacets(categoryID int) ([]models.ColorFacet, error) {
+	return db.GetColorFacets(categoryID)
+}
+
+// applyPaletteColorRequest проверяет и переносит изменения запроса в цвет палитры
+func applyPaletteColorRequest(color *models.PaletteColor, req models.PaletteColorRequest) error {
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return fmt.Errorf("%w: пустое название", ErrInvalidColor)
+		}
+		existingID, err := db.FindPaletteColorIDByName(name, color.ID)
+		if err != nil {
+			return err
+		}
+		if existingID != 0 {
+			return fmt.Errorf("%w: %s (ID %d)", ErrColorExists, name, existingID)
+		}
+		color.Name = name
+	}
+	if req.Code != nil {
+		color.Code = ""
+		if strings.TrimSpace(*req.Code) != "" {
+			hex, ok := utils.NormalizeHexColor(*req.Code)
+			if !ok {
+				return fmt.Errorf("%w: код '%s', ожидается #rrggbb", ErrInvalidColor, *req.Code)
+			}
+			color.Code = hex
+		}
+	}
+	if req.Family != nil {
+		color.Family = strings.TrimSpace(*req.Family)
+		if color.Family != "" {
+			exists, err := db.ColorFamilyExists(color.Family)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				return fmt.Errorf("%w: неизвестное семейство %s", ErrInvalidColor, color.Family)
+			}
+		}
+	}
+	if req.SortOrder != nil {
+		color.SortOrder = *req.SortOrder
+	}
+	return nil
+}
+
+// resolveVariationColors находит цвета вариаций в палитре и записывает их ID в запрос.
+// Поставщики выбирают цвета только из палитры.
+func resolveVariationColors(variations []models.ProductVariationRequest) error {
+	for i := range variations {
+		seen := map[int]bool{}
+		resolved := variations[i].Colors[:0]
+		for _, color := range variations[i].Colors {
+			color.Name = strings.TrimSpace(color.Name)
+			if color.Code != "" {
+				hex, ok := utils.NormalizeHexColor(color.Code)
+				if !ok {
+					return fmt.Errorf("%w: код '%s' (вариация %d)", ErrInvalidColor, color.Code, i+1)
+				}
+				color.Code = hex
+			}
+			if color.ID == 0 && color.Name == "" && color.Code == "" {
+				return fmt.Errorf("%w: укажите id, название или код цвета (вариация %d)", ErrInvalidColor, i+1)
+			}
+
+			id, err := db.FindPaletteColorID(color)
+			if err != nil {
+				return err
+			}
+			if id == 0 {
+				return fmt.Errorf("%w: цвета %s нет в палитре (вариация %d)", ErrInvalidColor, colorLabel(color), i+1)
+			}
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			color.ID = id
+			resolved = append(resolved, color)
+		}
+		variations[i].Colors = resolved
+	}
+	return nil
+}
+
+// colorLabel описание цвета из запроса для сообщений об ошибках
+func colorLabel(color models.Color) string {
+	switch {
+	case color.ID > 0:
+		return fmt.Sprintf("ID %d", color.ID)
+	case color.Name != "":
+		return "'" + color.Name + "'"
+	default:
+		return color.Code
+	}
+}
+
+// colorIDs возвращает ID цветов вариации после resolveVariationColors
+func colorIDs(colors []models.Color) []int {
+	ids := make([]int, len(colors))
+	for i, color := range colors {
+		ids[i] = color.ID
+	}
+	return ids
+}
@@ -0,0 +1,61 @@
+// internal/services/image_hash_service.go
+
+package services
+
+import (
+	"github.com/WhyDias/Marketplace/internal/db"
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/WhyDias/Marketplace/internal/utils"
+)
+
+// DuplicateImageMaxDistance максимальное расстояние Хэмминга, при котором изображения считаются одинаковыми.
+// Должно быть меньше 8, иначе поиск кандидатов по байтам хэша может пропустить совпадение.
+const DuplicateImageMaxDistance = 6
+
+// recordImageHash сохраняет хэш загруженного изображения и для продуктов отмечает
+// совпадения с изображениями других поставщиков
+func recordImageHash(owner models.ImageOwner, ownerID int, imageID int, uploaded *models.UploadedImage) error {
+	h := models.ImageHash{
+		OwnerType: owner,
+		OwnerID:   ownerID,
+		ImageID:   imageID,
+		ImageURL:  uploaded.URL,
+		Hash:      uploaded.Hash,
+	}
+
+	if owner == models.ImageOwnerProduct || owner == models.ImageOwnerVariation {
+		productID, supplierID, err := db.GetImageOwnerProduct(owner, ownerID)
+		if err != nil {
+			return err
+		}
+		h.ProductID = productID
+		h.SupplierID = supplierID
+	}
+
+	bands := utils.HashBands(h.Hash)
+	if err := db.CreateImageHash(&h, bands); err != nil {
+		return err
+	}
+
+	// Изображения категорий в модерации продуктов не участвуют
+	if h.ProductID == 0 || h.SupplierID == 0 {
+		return nil
+	}
+
+	candidates, err := db.FindImageHashCandidates(bands, h.SupplierID)
+	if err != nil {
+		return err
+	}
+
+	for _, candidate := range candidates {
+		distance := utils.HammingDistance(h.Hash, candidate.Hash)
+		if distance > DuplicateImageMaxDistance {
+			continue
+		}
+		if err := db.CreateProductDuplicateImage(h.ProductID, h.ID, candidate.ProductID, candidate.ID, distance); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
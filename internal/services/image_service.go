@@ -0,0 +1,170 @@
+// internal/services/image_service.go
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"mime/multipart"
+
+	"github.com/WhyDias/Marketplace/internal/db"
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/WhyDias/Marketplace/internal/utils"
+)
+
+var (
+	ErrImageOwnerNotFound = errors.New("продукт или вариация не найдены")
+	ErrImageNotFound      = errors.New("изображение не найдено")
+	ErrImageForbidden     = errors.New("нет доступа к изображениям этого продукта")
+	ErrInvalidImageOrder  = errors.New("некорректный порядок изображений")
+)
+
+type ImageService struct{}
+
+func NewImageService() *ImageService {
+	return &ImageService{}
+}
+
+// imageFolderPath возвращает путь внутри бакета для изображений владельца
+func imageFolderPath(owner models.ImageOwner, ownerID int) string {
+	if owner == models.ImageOwnerVariation {
+		return fmt.Sprintf("variations/%d", ownerID)
+	}
+	return fmt.Sprintf("products/%d", ownerID)
+}
+
+// checkOwnership проверяет, что продукт или вариация принадлежат поставщику пользователя
+func (s *ImageService) checkOwnership(owner models.ImageOwner, ownerID int, userID int) error {
+	ownerSupplierID, err := db.GetImageOwnerSupplierID(owner, ownerID)
+	if err != nil {
+		return err
+	}
+	if ownerSupplierID == 0 {
+		return ErrImageOwnerNotFound
+	}
+
+	supplierID, err := db.GetSupplierIDByUserID(userID)
+	if err != nil {
+		return fmt.Errorf("не удалось получить поставщика: %v", err)
+	}
+	if supplierID != ownerSupplierID {
+		return ErrImageForbidden
+	}
+	return nil
+}
+
+// GetImages возвращает изображения продукта или вариации
+func (s *ImageService) GetImages(owner models.ImageOwner, ownerID int) ([]models.Image, error) {
+	return db.GetImages(owner, ownerID)
+}
+
+// AddImages загружает файлы и добавляет их в конец списка изображений
+func (s *ImageService) AddImages(owner models.ImageOwner, ownerID int, userID int, files []*multipart.FileHeader, altText string) ([]models.Image, error) {
+	if err := s.checkOwnership(owner, ownerID, userID); err != nil {
+		return nil, err
+	}
+
+	return saveImages(owner, ownerID, files, altText)
+}
+
+// saveImages обрабатывает файлы, загружает миниатюры в Yandex Cloud Storage
+// и сохраняет по одной записи на изображение
+func saveImages(owner models.ImageOwner, ownerID int, files []*multipart.FileHeader, altText string) ([]models.Image, error) {
+	var images []models.Image
+	for _, fileHeader := range files {
+		if fileHeader == nil {
+			continue
+		}
+
+		uploaded, err := utils.UploadImageToYandex(fileHeader, imageFolderPath(owner, ownerID))
+		if err != nil {
+			return nil, fmt.Errorf("не удалось загрузить изображение %s: %w", fileHeader.Filename, err)
+		}
+
+		image := models.Image{
+			OwnerID:  ownerID,
+			ImageURL: uploaded.URL,
+			AltText:  altText,
+			Variants: uploaded.Variants,
+		}
+		if err := db.CreateImage(owner, &image); err != nil {
+			log.Printf("saveImages: ошибка при сохранении изображения для %s %d: %v", owner, ownerID, err)
+			return nil, err
+		}
+		images = append(images, image)
+
+		// Изображение уже сохранено, поэтому ошибка поиска дубликатов не прерывает загрузку
+		if err := recordImageHash(owner, ownerID, image.ID, uploaded); err != nil {
+			log.Printf("saveImages: не удалось сохранить хэш изображения %d для %s %d: %v", image.ID, owner, ownerID, err)
+		}
+	}
+
+	return images, nil
+}
+
+// DeleteImage удаляет одно изображение
+func (s *ImageService) DeleteImage(owner models.ImageOwner, ownerID int, userID int, imageID int) error {
+	if err := s.checkOwnership(owner, ownerID, userID); err != nil {
+		return err
+	}
+
+	image, err := db.GetImageByID(owner, ownerID, imageID)
+	if err != nil {
+		return err
+	}
+	if image == nil {
+		return ErrImageNotFound
+	}
+
+	return db.DeleteImage(owner, ownerID, imageID)
+}
+
+// ReorderImages задаёт новый порядок изображений. imageIDs должен содержать все изображения
+// владельца ровно по одному разу.
+func (s *ImageService) ReorderImages(owner models.ImageOwner, ownerID int, userID int, imageIDs []int) error {
+	if err := s.checkOwnership(owner, ownerID, userID); err != nil {
+		return err
+	}
+
+	images, err := db.GetImages(owner, ownerID)
+	if err != nil {
+		return err
+	}
+	if len(imageIDs) != len(images) {
+		return fmt.Errorf("%w: список должен содержать все %d изображений", ErrInvalidImageOrder, len(images))
+	}
+	known := make(map[int]bool, len(images))
+	for _, image := range images {
+		known[image.ID] = true
+	}
+	seen := make(map[int]bool, len(imageIDs))
+	for _, imageID := range imageIDs {
+		if !known[imageID] {
+			return fmt.Errorf("%w: изображение %d не найдено", ErrInvalidImageOrder, imageID)
+		}
+		if seen[imageID] {
+			return fmt.Errorf("%w: изображение %d указано несколько раз", ErrInvalidImageOrder, imageID)
+		}
+		seen[imageID] = true
+	}
+
+	return db.ReorderImages(owner, ownerID, imageIDs)
+}
+
+// SetPrimaryImage назначает главное изображение
+func (s *ImageService) SetPrimaryImage(owner models.ImageOwner, ownerID int, userID int, imageID int) error {
+	if err := s.checkOwnership(owner, ownerID, userID); err != nil {
+		return err
+	}
+
+	image, err := db.GetImageByID(owner, ownerID, imageID)
+	if err != nil {
+		return err
+	}
+	if image == nil {
+		return ErrImageNotFound
+	}
+
+	return db.SetPrimaryImage(owner, ownerID, imageID)
+}
@@ -0,0 +1,402 @@
+// internal/services/market_service.go
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/WhyDias/Marketplace/internal/db"
+	"github.com/WhyDias/Marketplace/internal/models"
+)
+
+var (
+	ErrMarketNotFound    = errors.New("рынок не найден")
+	ErrMarketExists      = errors.New("рынок с таким названием уже есть")
+	ErrMarketInUse       = errors.New("на рынке есть поставщики или продукты")
+	ErrInvalidMarket     = errors.New("некорректные данные рынка")
+	ErrMarketRowNotFound = errors.New("ряд не найден")
+	ErrMarketRowExists   = errors.New("ряд с таким названием уже есть на рынке")
+	ErrMarketRowInUse    = errors.New("в ряду есть занятые места")
+	ErrStallNotFound     = errors.New("место не найдено")
+	ErrStallExists       = errors.New("место с таким названием уже есть в ряду")
+	ErrStallTaken        = errors.New("место занято другим поставщиком")
+	ErrInvalidLocation   = errors.New("некорректные координаты или радиус поиска")
+)
+
+// Радиус поиска рядом, км
+const (
+	DefaultNearbyRadiusKm = 10
+	MaxNearbyRadiusKm     = 200
+)
+
+// MarketService управляет справочником рынков, рядов и мест
+type MarketService struct{}
+
+func NewMarketService() *MarketService {
+	return &MarketService{}
+}
+
+// GetMarkets возвращает все рынки
+func (s *MarketService) GetMarkets() ([]models.Market, error) {
+	return db.GetMarkets()
+}
+
+// GetNearbyMarkets возвращает рынки в радиусе от точки, ближайшие первыми. С categoryID
+// остаются рынки, где есть одобренные продукты категории и её подкатегорий.
+func (s *MarketService) GetNearbyMarkets(near models.GeoFilter, categoryID int) ([]models.NearbyMarket, error) {
+	if err := normalizeGeoFilter(&near); err != nil {
+		return nil, err
+	}
+	return db.GetNearbyMarkets(near, categoryID)
+}
+
+// normalizeGeoFilter проверяет координаты и подставляет радиус по умолчанию
+func normalizeGeoFilter(near *models.GeoFilter) error {
+	// NaN не проходит ни одно сравнение, поэтому NaN и бесконечность проверяются явно
+	if !isFiniteNumber(near.Lat) || !isFiniteNumber(near.Lng) ||
+		near.Lat < -90 || near.Lat > 90 || near.Lng < -180 || near.Lng > 180 {
+		return fmt.Errorf("%w: %g,%g", ErrInvalidLocation, near.Lat, near.Lng)
+	}
+	if !isFiniteNumber(near.RadiusKm) || near.RadiusKm < 0 {
+		return fmt.Errorf("%w: радиус %g км", ErrInvalidLocation, near.RadiusKm)
+	}
+	if near.RadiusKm == 0 {
+		near.RadiusKm = DefaultNearbyRadiusKm
+	} else if near.RadiusKm > MaxNearbyRadiusKm {
+		near.RadiusKm = MaxNearbyRadiusKm
+	}
+	return nil
+}
+
+// isFiniteNumber сообщает, что x не NaN и не бесконечность
+func isFiniteNumber(x float64) bool {
+	return !math.IsNaN(x) && !math.IsInf(x, 0)
+}
+
+// GetMarket возвращает рынок с рядами и местами
+func (s *MarketService) GetMarket(marketID int) (*models.MarketDetails, error) {
+	market, err := db.GetMarketByID(marketID)
+	if err != nil {
+		return nil, err
+	}
+	if market == nil {
+		return nil, ErrMarketNotFound
+	}
+	rows, err := db.GetMarketRows(marketID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.MarketDetails{Market: *market, Rows: rows}, nil
+}
+
+// CreateMarket добавляет рынок. Название обязательно.
+func (s *MarketService) CreateMarket(req models.MarketRequest) (*models.Market, error) {
+	if req.Name == nil {
+		return nil, fmt.Errorf("%w: не указано название", ErrInvalidMarket)
+	}
+	market := &models.Market{DaysOff: []int{}}
+	if err := applyMarketRequest(market, req); err != nil {
+		return nil, err
+	}
+	if err := db.CreateMarket(market); err != nil {
+		return nil, err
+	}
+	return db.GetMarketByID(market.ID)
+}
+
+// UpdateMarket меняет рынок
+func (s *MarketService) UpdateMarket(marketID int, req models.MarketRequest) (*models.Market, error) {
+	market, err := db.GetMarketByID(marketID)
+	if err != nil {
+		return nil, err
+	}
+	if market == nil {
+		return nil, ErrMarketNotFound
+	}
+	if err := applyMarketRequest(market, req); err != nil {
+		return nil, err
+	}
+	if err := db.UpdateMarket(market); err != nil {
+		return nil, err
+	}
+	return db.GetMarketByID(marketID)
+}
+
+// DeleteMarket удаляет рынок без поставщиков и продуктов вместе с рядами и местами
+func (s *MarketService) DeleteMarket(marketID int) error {
+	market, err := db.GetMarketByID(marketID)
+	if err != nil {
+		return err
+	}
+	if market == nil {
+		return ErrMarketNotFound
+	}
+	suppliers, products, err := db.CountMarketUsage(marketID)
+	if err != nil {
+		return err
+	}
+	if suppliers > 0 || products > 0 {
+		return fmt.Errorf("%w (поставщиков: %d, продуктов: %d)", ErrMarketInUse, suppliers, products)
+	}
+	return db.DeleteMarket(marketID)
+}
+
+// CreateRow добавляет ряд на рынок
+func (s *MarketService) CreateRow(marketID int, req models.MarketRowRequest) (*models.MarketRow, error) {
+	market, err := db.GetMarketByID(marketID)
+	if err != nil {
+		return nil, err
+	}
+	if market == nil {
+		return nil, ErrMarketNotFound
+	}
+	row := &models.MarketRow{MarketID: marketID, SortOrder: req.SortOrder, Stalls: []models.MarketStall{}}
+	if row.Name, err = checkRowName(marketID, req.Name, 0); err != nil {
+		return nil, err
+	}
+	if err := db.CreateMarketRow(row); err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+// UpdateRow переименовывает ряд и меняет его порядок
+func (s *MarketService) UpdateRow(rowID int, req models.MarketRowRequest) (*models.MarketRow, error) {
+	row, err := getMarketRow(rowID)
+	if err != nil {
+		return nil, err
+	}
+	if row.Name, err = checkRowName(row.MarketID, req.Name, rowID); err != nil {
+		return nil, err
+	}
+	row.SortOrder = req.SortOrder
+	if err := db.UpdateMarketRow(row); err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+// DeleteRow удаляет ряд, в котором нет занятых мест
+func (s *MarketService) DeleteRow(rowID int) error {
+	if _, err := getMarketRow(rowID); err != nil {
+		return err
+	}
+	occupied, err := db.CountOccupiedStalls(rowID)
+	if err != nil {
+		return err
+	}
+	if occupied > 0 {
+		return fmt.Errorf("%w (занято мест: %d)", ErrMarketRowInUse, occupied)
+	}
+	return db.DeleteMarketRow(rowID)
+}
+
+// AddStalls добавляет места в ряд
+func (s *MarketService) AddStalls(rowID int, req models.MarketStallsRequest) ([]models.MarketStall, error) {
+	if _, err := getMarketRow(rowID); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	seen := map[string]bool{}
+	for _, name := range req.Names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("%w: пустое название места", ErrInvalidMarket)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w: %s", ErrStallExists, name)
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	existing, err := db.GetExistingStallNames(rowID, names)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrStallExists, strings.Join(existing, ", "))
+	}
+
+	return db.CreateMarketStalls(rowID, names)
+}
+
+// RenameStall переименовывает место
+func (s *MarketService) RenameStall(stallID int, req models.MarketStallRequest) (*models.MarketStall, error) {
+	stall, err := getMarketStall(stallID)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: пустое название места", ErrInvalidMarket)
+	}
+	if name == stall.Name {
+		return stall, nil
+	}
+	existing, err := db.GetExistingStallNames(stall.RowID, []string{name})
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrStallExists, name)
+	}
+	if err := db.UpdateMarketStall(stallID, name); err != nil {
+		return nil, err
+	}
+	stall.Name = name
+	return stall, nil
+}
+
+// DeleteStall удаляет свободное место
+func (s *MarketService) DeleteStall(stallID int) error {
+	stall, err := getMarketStall(stallID)
+	if err != nil {
+		return err
+	}
+	if stall.SupplierID != nil {
+		return fmt.Errorf("%w (поставщик %d)", ErrStallTaken, *stall.SupplierID)
+	}
+	return db.DeleteMarketStall(stallID)
+}
+
+// GetRowSuppliers возвращает поставщиков, занявших места в ряду
+func (s *MarketService) GetRowSuppliers(rowID int) ([]models.RowSupplier, error) {
+	row, err := getMarketRow(rowID)
+	if err != nil {
+		return nil, err
+	}
+	suppliers, err := db.GetRowSuppliers(rowID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range suppliers {
+		suppliers[i].DisplayName = supplierDisplayName(&models.Supplier{
+			ID:        suppliers[i].SupplierID,
+			Name:      suppliers[i].DisplayName,
+			RowName:   row.Name,
+			PlaceName: suppliers[i].StallName,
+		})
+	}
+	return suppliers, nil
+}
+
+func getMarketRow(rowID int) (*models.MarketRow, error) {
+	row, err := db.GetMarketRow(rowID)
+	if err != nil {
+		return nil, err
+	}
+	if row == nil {
+		return nil, ErrMarketRowNotFound
+	}
+	return row, nil
+}
+
+func getMarketStall(stallID int) (*models.MarketStall, error) {
+	stall, err := db.GetMarketStall(stallID)
+	if err != nil {
+		return nil, err
+	}
+	if stall == nil {
+		return nil, ErrStallNotFound
+	}
+	return stall, nil
+}
+
+// checkRowName проверяет, что название ряда не пустое и не занято другим рядом рынка
+func checkRowName(marketID int, name string, excludeID int) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("%w: пустое название ряда", ErrInvalidMarket)
+	}
+	exists, err := db.MarketRowNameExists(marketID, name, excludeID)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return "", fmt.Errorf("%w: %s", ErrMarketRowExists, name)
+	}
+	return name, nil
+}
+
+// normalizeClockTime приводит время к виду ЧЧ:ММ, пустая строка убирает время.
+// Некорректное время оборачивается в invalid.
+func normalizeClockTime(value string, invalid error) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return "", fmt.Errorf("%w: время '%s', ожидается ЧЧ:ММ", invalid, value)
+	}
+	return t.Format("15:04"), nil
+}
+
+// applyMarketRequest проверяет и переносит изменения запроса в рынок
+func applyMarketRequest(market *models.Market, req models.MarketRequest) error {
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return fmt.Errorf("%w: пустое название", ErrInvalidMarket)
+		}
+		exists, err := db.MarketNameExists(name, market.ID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf("%w: %s", ErrMarketExists, name)
+		}
+		market.Name = name
+	}
+	if req.Address != nil {
+		market.Address = strings.TrimSpace(*req.Address)
+	}
+
+	if req.ClearLocation {
+		market.Latitude, market.Longitude = nil, nil
+	}
+	if (req.Latitude == nil) != (req.Longitude == nil) {
+		return fmt.Errorf("%w: укажите широту и долготу вместе", ErrInvalidMarket)
+	}
+	if req.Latitude != nil {
+		market.Latitude, market.Longitude = req.Latitude, req.Longitude
+	}
+
+	var err error
+	if req.OpensAt != nil {
+		if market.OpensAt, err = normalizeClockTime(*req.OpensAt, ErrInvalidMarket); err != nil {
+			return err
+		}
+	}
+	if req.ClosesAt != nil {
+		if market.ClosesAt, err = normalizeClockTime(*req.ClosesAt, ErrInvalidMarket); err != nil {
+			return err
+		}
+	}
+	if (market.OpensAt == "") != (market.ClosesAt == "") {
+		return fmt.Errorf("%w: укажите время открытия и закрытия вместе", ErrInvalidMarket)
+	}
+
+	if req.DaysOff != nil {
+		seen := map[int]bool{}
+		days := []int{}
+		for _, day := range *req.DaysOff {
+			if day < 1 || day > 7 {
+				return fmt.Errorf("%w: день недели %d, ожидается от 1 до 7", ErrInvalidMarket, day)
+			}
+			if !seen[day] {
+				seen[day] = true
+				days = append(days, day)
+			}
+		}
+		sort.Ints(days)
+		market.DaysOff = days
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+// internal/services/moderation_service.go
+
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/WhyDias/Marketplace/internal/db"
+	"github.com/WhyDias/Marketplace/internal/models"
+)
+
+const (
+	// ModerationClaimTTL время, на которое модератор захватывает продукт
+	ModerationClaimTTL = 30 * time.Minute
+	// ModerationSLA максимальное время ожидания продукта в очереди
+	ModerationSLA = 24 * time.Hour
+
+	defaultModerationQueueLimit = 50
+	maxModerationQueueLimit     = 200
+
+	// BulkModerationMaxItems максимальное количество продуктов в одном массовом запросе
+	BulkModerationMaxItems = 100
+
+	// moderatorDisplayName имя модератора в истории, которую видит поставщик
+	moderatorDisplayName = "Модератор"
+)
+
+var (
+	ErrModerationProductNotFound = errors.New("продукт не найден")
+	ErrProductNotPending         = errors.New("продукт не ожидает модерации")
+	ErrProductClaimed            = errors.New("продукт проверяет другой модератор")
+	ErrModerationClaimNotFound   = errors.New("продукт не захвачен этим модератором")
+	ErrModerationForbidden       = errors.New("нет доступа к истории модерации этого продукта")
+	ErrBulkModerationTooLarge    = fmt.Errorf("за один запрос можно обработать не больше %d продуктов", BulkModerationMaxItems)
+	ErrRejectionCommentRequired  = errors.New("укажите комментарий или причину отклонения")
+	ErrSupplierNotVerified       = errors.New("поставщик не прошёл проверку документов")
+)
+
+type ModerationService struct{}
+
+func NewModerationService() *ModerationService {
+	return &ModerationService{}
+}
+
+// GetQueue возвращает очередь модерации по всем поставщикам, начиная с самых старых продуктов
+func (s *ModerationService) GetQueue(filter models.ModerationQueueFilter) (*models.ModerationQueue, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultModerationQueueLimit
+	} else if filter.Limit > maxModerationQueueLimit {
+		filter.Limit = maxModerationQueueLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	total, breaches, err := db.CountModerationQueue(filter, ModerationSLA)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := db.GetModerationQueue(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	sla := int64(ModerationSLA.Seconds())
+	for i := range items {
+		items[i].SLABreached = items[i].TimeInQueue > sla
+	}
+
+	return &models.ModerationQueue{
+		Items:       items,
+		Total:       total,
+		SLABreaches: breaches,
+		SLAHours:    int(ModerationSLA.Hours()),
+	}, nil
+}
+
+// ClaimProduct захватывает продукт модератором на ModerationClaimTTL
+func (s *ModerationService) ClaimProduct(productID int, moderatorID int) (*models.ModerationClaim, error) {
+	statusID, err := db.GetProductStatusID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if statusID == 0 {
+		return nil, ErrModerationProductNotFound
+	}
+	if statusID != models.ProductStatusPending {
+		return nil, ErrProductNotPending
+	}
+
+	claim, err := db.ClaimProduct(productID, moderatorID, ModerationClaimTTL)
+	if err != nil {
+		return nil, err
+	}
+	if claim == nil {
+		return nil, ErrProductClaimed
+	}
+	return claim, nil
+}
+
+// ReleaseClaim снимает захват продукта модератором
+func (s *ModerationService) ReleaseClaim(productID int, moderatorID int) error {
+	released, err := db.ReleaseProductClaim(productID, moderatorID)
+	if err != nil {
+		return err
+	}
+	if !released {
+		return ErrModerationClaimNotFound
+	}
+	return nil
+}
+
+// finishModerationTx проверяет, что продукт не захвачен другим модератором,
+// и снимает захват после решения по продукту
+func finishModerationTx(tx *sql.Tx, productID int, moderatorID int) error {
+	claimedBy, err := db.GetActiveClaimModeratorTx(tx, productID)
+	if err != nil {
+		return err
+	}
+	if claimedBy != 0 && claimedBy != moderatorID {
+		return ErrProductClaimed
+	}
+	return db.DeleteProductClaimTx(tx, productID)
+}
+
+// GetProductHistory возвращает хронологию модерации продукта.
+// Поставщик видит историю только своих продуктов, модератор — любых.
+func (s *ModerationService) GetProductHistory(productID int, userID int, isModerator bool) ([]models.ModerationHistoryEntry, error) {
+	ownerSupplierID, err := db.GetImageOwnerSupplierID(models.ImageOwnerProduct, productID)
+	if err != nil {
+		return nil, err
+	}
+	if ownerSupplierID == 0 {
+		return nil, ErrModerationProductNotFound
+	}
+
+	if !isModerator {
+		supplierID, err := db.GetSupplierIDByUserID(userID)
+		if err != nil || supplierID != ownerSupplierID {
+			return nil, ErrModerationForbidden
+		}
+	}
+
+	history, err := db.GetProductModerationHistory(productID)
+	if err != nil {
+		return nil, err
+	}
+	if !isModerator {
+		// Имя пользователя — номер телефона, поставщику модератор показывается только ролью
+		for i := range history {
+			if history[i].ByModerator {
+				history[i].UserID = nil
+				history[i].UserName = moderatorDisplayName
+			}
+		}
+	}
+	return history, nil
+}
+
+// GetDuplicateImages возвращает изображения продукта, совпадающие с изображениями других поставщиков
+func (s *ModerationService) GetDuplicateImages(productID int) ([]models.DuplicateImageMatch, error) {
+	return db.GetProductDuplicateImages(productID)
+}
+
+// BulkModerate подтверждает или отклоняет несколько продуктов. Каждый продукт обрабатывается
+// в своей транзакции так же, как одиночным запросом, ошибка по одному продукту не отменяет остальные.
+func (s *ModerationService) BulkModerate(moderatorID int, req models.BulkModerationRequest) (*models.BulkModerationResult, error) {
+	if len(req.Items) > BulkModerationMaxItems {
+		return nil, ErrBulkModerationTooLarge
+	}
+
+	result := &models.BulkModerationResult{Results: make([]models.BulkModerationItemResult, 0, len(req.Items))}
+	seen := make(map[int]bool, len(req.Items))
+	for _, item := range req.Items {
+		if seen[item.ProductID] {
+			continue
+		}
+		seen[item.ProductID] = true
+
+		itemResult := models.BulkModerationItemResult{ProductID: item.ProductID}
+		if err := moderateBulkItem(moderatorID, req, item); err != nil {
+			itemResult.Err = err
+			itemResult.Error = err.Error()
+			result.Failed++
+		} else {
+			itemResult.Success = true
+			result.Succeeded++
+		}
+		result.Results = append(result.Results, itemResult)
+	}
+
+	return result, nil
+}
+
+// moderateBulkItem применяет решение к одному продукту. Статус продукта проверяет moderateProduct в своей транзакции.
+func moderateBulkItem(moderatorID int, req models.BulkModerationRequest, item models.BulkModerationItem) error {
+	if req.Action == models.BulkModerationApprove {
+		return moderateProduct(item.ProductID, moderatorID, models.ProductStatusApproved, "Подтвержден", nil)
+	}
+
+	content, inputs := item.Content, item.Reasons
+	if content == "" && len(inputs) == 0 {
+		content, inputs = req.Content, req.Reasons
+	}
+	if content == "" && len(inputs) == 0 {
+		return ErrRejectionCommentRequired
+	}
+
+	reasons, err := resolveRejectionReasons(item.ProductID, inputs)
+	if err != nil {
+		return err
+	}
+	if content == "" {
+		content = rejectionSummary(reasons)
+	}
+	return moderateProduct(item.ProductID, moderatorID, models.ProductStatusRejected, content, reasons)
+}
@@ -3,14 +3,14 @@
 package services
 
 import (
-	"database/sql"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/WhyDias/Marketplace/internal/db"
 	"github.com/WhyDias/Marketplace/internal/models"
 	"github.com/WhyDias/Marketplace/internal/utils"
 	"log"
 	"mime/multipart"
+	"strings"
 )
 
 type ProductService struct{}
@@ -48,6 +48,18 @@ func (p *ProductService) AddProduct(req *models.ProductRequest, userID int, attr
 		return fmt.Errorf("не удалось получить информацию о поставщике: %v", err)
 	}
 
+	// Обязательные атрибуты проверяются до записи продукта
+	variationNames := make([][]string, len(variations))
+	for i, variation := range variations {
+		variationNames[i] = filledAttributeNames(variation.Attributes)
+	}
+	if err := checkRequiredAttributes(req.CategoryID, filledAttributeNames(attributes), variationNames); err != nil {
+		return err
+	}
+	if err := resolveVariationColors(variations); err != nil {
+		return err
+	}
+
 	// Создаем основной продукт
 	product := models.Product{
 		Name:        req.Name,
@@ -60,10 +72,18 @@ func (p *ProductService) AddProduct(req *models.ProductRequest, userID int, attr
 		Stock:       0, // Устанавливаем 0 по умолчанию
 	}
 
-	if err := db.CreateProduct(&product); err != nil {
+	err = saveProductSlug(req.Name, 0, func(slug string) error {
+		product.Slug = slug
+		return db.CreateProduct(&product)
+	})
+	if err != nil {
 		return fmt.Errorf("не удалось создать продукт: %v", err)
 	}
 
+	if err := db.CreateProductStatusChange(product.ID, product.StatusID, userID); err != nil {
+		return err
+	}
+
 	// Сохраняем общие атрибуты для продукта
 	for _, attribute := range attributes {
 		err := p.SaveProductAttribute(product.ID, req.CategoryID, attribute)
@@ -80,6 +100,12 @@ func (p *ProductService) AddProduct(req *models.ProductRequest, userID int, attr
 		}
 	}
 
+	// Продукт полностью сохранён и поступает на модерацию. Ошибка автомодерации
+	// не отменяет создание: продукт остаётся в очереди для модератора.
+	if _, err := autoModerateProduct(product.ID, true); err != nil {
+		log.Printf("AddProduct: ошибка автомодерации продукта %d: %v", product.ID, err)
+	}
+
 	return nil
 }
 
@@ -99,8 +125,14 @@ func (p *ProductService) SaveProductAttribute(productID int, categoryID int, att
 		return fmt.Errorf("Атрибут '%s' является linked и не может быть общим для продукта", attribute.Name)
 	}
 
+	// Числовые значения сохраняются в канонической единице атрибута
+	value, err := normalizeAttributeValue(attributeID, attribute)
+	if err != nil {
+		return fmt.Errorf("Некорректное значение атрибута '%s': %v", attribute.Name, err)
+	}
+
 	// Создаем или получаем значение атрибута
-	attributeValueID, err := db.CreateOrGetAttributeValue(attributeID, attribute.Value)
+	attributeValueID, err := db.CreateOrGetAttributeValue(attributeID, value)
 	if err != nil {
 		return fmt.Errorf("Ошибка при создании или получении значения атрибута '%s': %v", attribute.Name, err)
 	}
@@ -118,43 +150,158 @@ func (p *ProductService) SaveProductAttribute(productID int, categoryID int, att
 	return nil
 }
 
-func (p *ProductService) GetProductAttributes(categoryID int) ([]models.Attribute, error) {
+var (
+	// ErrProductNotFound продукт не найден или не опубликован
+	ErrProductNotFound = errors.New("продукт не найден")
+	// ErrMissingRequiredAttributes у продукта не заполнены обязательные атрибуты категории
+	ErrMissingRequiredAttributes = errors.New("не заполнены обязательные атрибуты")
+)
+
+// checkRequiredAttributes проверяет, что заполнены обязательные атрибуты категории, включая
+// унаследованные: общие — среди атрибутов продукта, linked — у каждой вариации.
+// Атрибуты продукта и вариаций передаются названиями.
+func checkRequiredAttributes(categoryID int, common []string, variations [][]string) error {
 	attributes, err := db.GetCategoryAttributes(categoryID)
 	if err != nil {
-		log.Printf("GetProductAttributes: Ошибка при получении атрибутов для категории %d: %v", categoryID, err)
-		return nil, fmt.Errorf("не удалось получить атрибуты категории: %v", err)
+		return fmt.Errorf("не удалось получить атрибуты категории: %v", err)
 	}
-	return attributes, nil
+
+	commonSet := stringSet(common)
+	var missing []string
+	for _, attr := range attributes {
+		if !attr.IsRequired {
+			continue
+		}
+		if !attr.IsLinked {
+			if !commonSet[attr.Name] {
+				missing = append(missing, attr.Name)
+			}
+			continue
+		}
+		if len(variations) == 0 {
+			missing = append(missing, attr.Name+" (у продукта нет вариаций)")
+			continue
+		}
+		for i, names := range variations {
+			if !stringSet(names)[attr.Name] {
+				missing = append(missing, fmt.Sprintf("%s (вариация %d)", attr.Name, i+1))
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrMissingRequiredAttributes, strings.Join(missing, ", "))
+	}
+	return nil
 }
-func (s *ProductService) GetMarketIDBySupplierID(supplierID int) (int, error) {
-	return db.GetMarketIDBySupplierID(supplierID)
+
+// filledAttributeNames возвращает названия атрибутов, для которых передано непустое значение
+func filledAttributeNames(attributes []models.AttributeValueRequest) []string {
+	names := make([]string, 0, len(attributes))
+	for _, attribute := range attributes {
+		if attribute.Value == nil {
+			continue
+		}
+		if text, ok := attribute.Value.(string); ok && strings.TrimSpace(text) == "" {
+			continue
+		}
+		names = append(names, attribute.Name)
+	}
+	return names
 }
 
-type UpdateProductRequest struct {
-	Name        string                    `json:"name"`
-	Description string                    `json:"description"`
-	CategoryID  int                       `json:"category_id"`
-	Images      []string                  `json:"images"`
-	Variations  []ProductVariationRequest `json:"variations"`
+// GetPublishedProduct возвращает одобренный продукт. Продукты на модерации и отклонённые не показываются,
+// как и продукты поставщика, скрывшего их на время отпуска.
+func (p *ProductService) GetPublishedProduct(productID int) (*models.Product, error) {
+	statusID, err := db.GetProductStatusID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if statusID != models.ProductStatusApproved {
+		return nil, ErrProductNotFound
+	}
+	vacationMode, err := db.GetProductVacationMode(productID)
+	if err != nil {
+		return nil, err
+	}
+	if vacationMode == models.VacationModeHidden {
+		return nil, ErrProductNotFound
+	}
+	product, err := db.GetProductByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product.Colors, err = db.GetProductColorSwatches(productID); err != nil {
+		return nil, err
+	}
+	if product.VerifiedSeller, err = db.IsVerifiedSeller(product.SupplierID); err != nil {
+		return nil, err
+	}
+	return product, nil
 }
 
-type ProductVariationRequest struct {
-	SKU        string                  `json:"sku" binding:"required"`
-	Price      float64                 `json:"price" binding:"required"`
-	Stock      int                     `json:"stock" binding:"required"`
-	Images     []string                `json:"images"`
-	Attributes []AttributeValueRequest `json:"attributes"`
-	Colors     []Color                 `json:"colors"` // Добавили поле Colors
+const (
+	defaultCatalogLimit = 20
+	maxCatalogLimit     = 100
+)
+
+// GetCatalog возвращает страницу одобренных продуктов. С filter.Near остаются продукты
+// рынков в радиусе, ближайшие первыми.
+func (p *ProductService) GetCatalog(filter models.CatalogFilter) (*models.ProductCardPage, error) {
+	if filter.Near != nil {
+		if err := normalizeGeoFilter(filter.Near); err != nil {
+			return nil, err
+		}
+	}
+	if err := resolveCatalogAttributeFilters(&filter); err != nil {
+		return nil, err
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultCatalogLimit
+	} else if filter.Limit > maxCatalogLimit {
+		filter.Limit = maxCatalogLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	page := &models.ProductCardPage{Limit: filter.Limit, Offset: filter.Offset}
+	var err error
+	if page.Items, page.Total, err = db.GetCatalogProductCards(filter); err != nil {
+		return nil, err
+	}
+	return page, nil
 }
 
-type Color struct {
-	Name string `json:"name"`
-	Code string `json:"code"`
+// GetProductAttributeValues возвращает значения атрибутов продукта и вариаций.
+// Числовые значения пересчитываются в предпочитаемые единицы покупателя (величина -> единица).
+// Неодобренный или скрытый продукт не найден, как и в витрине.
+func (p *ProductService) GetProductAttributeValues(productID int, preferredUnits map[string]string) ([]models.ProductAttributeValueView, error) {
+	if err := checkPublishedProduct(productID); err != nil {
+		return nil, err
+	}
+	values, err := db.GetProductAttributeValues(productID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range values {
+		if err := displayAttributeValue(&values[i], preferredUnits); err != nil {
+			log.Printf("GetProductAttributeValues: не удалось пересчитать атрибут %d продукта %d: %v", values[i].AttributeID, productID, err)
+		}
+	}
+	return values, nil
 }
 
-type AttributeValueRequest struct {
-	Name  string `json:"name" binding:"required"`
-	Value string `json:"value" binding:"required"`
+func (p *ProductService) GetProductAttributes(categoryID int) ([]models.Attribute, error) {
+	attributes, err := db.GetCategoryAttributes(categoryID)
+	if err != nil {
+		log.Printf("GetProductAttributes: Ошибка при получении атрибутов для категории %d: %v", categoryID, err)
+		return nil, fmt.Errorf("не удалось получить атрибуты категории: %v", err)
+	}
+	return attributes, nil
+}
+func (s *ProductService) GetMarketIDBySupplierID(supplierID int) (int, error) {
+	return db.GetMarketIDBySupplierID(supplierID)
 }
 
 func (p *ProductService) UpdateProduct(productID int, req *models.ProductRequest, userID int, attributes []models.AttributeValueRequest, variations []models.ProductVariationRequest) error {
@@ -176,6 +323,7 @@ func (p *ProductService) UpdateProduct(productID int, req *models.ProductRequest
 	}
 
 	// Обновляем поля продукта
+	oldName := existingProduct.Name
 	updatedProduct := existingProduct
 	if req.Name != "" {
 		updatedProduct.Name = req.Name
@@ -190,11 +338,28 @@ func (p *ProductService) UpdateProduct(productID int, req *models.ProductRequest
 		updatedProduct.Price = req.Price
 	}
 
+	if err := checkUpdatedProductAttributes(productID, updatedProduct.CategoryID, attributes, variations); err != nil {
+		return err
+	}
+	if err := resolveVariationColors(variations); err != nil {
+		return err
+	}
+
 	// Обновляем продукт в базе данных
 	if err := db.UpdateProduct(updatedProduct); err != nil {
 		return fmt.Errorf("не удалось обновить продукт: %v", err)
 	}
 
+	// При смене названия slug строится заново, старый переадресует на продукт
+	if updatedProduct.Name != oldName {
+		err := saveProductSlug(req.Name, productID, func(slug string) error {
+			return db.SetProductSlug(productID, slug)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
 	// Обновление атрибутов продукта
 	if len(attributes) > 0 {
 		if err := p.UpdateProductAttributes(productID, updatedProduct.CategoryID, attributes); err != nil {
@@ -209,9 +374,63 @@ func (p *ProductService) UpdateProduct(productID int, req *models.ProductRequest
 		}
 	}
 
+	// Отклонённый продукт после правки поставщиком снова поступает на модерацию
+	resubmitted, err := db.ResubmitRejectedProduct(productID, userID)
+	if err != nil {
+		return fmt.Errorf("не удалось вернуть продукт на модерацию: %v", err)
+	}
+	if resubmitted {
+		if _, err := autoModerateProduct(productID, false); err != nil {
+			log.Printf("UpdateProduct: ошибка автомодерации продукта %d: %v", productID, err)
+		}
+	}
+
 	return nil
 }
 
+// checkUpdatedProductAttributes проверяет обязательные атрибуты продукта после обновления:
+// переданные атрибуты и вариации заменяют сохранённые
+func checkUpdatedProductAttributes(productID, categoryID int, attributes []models.AttributeValueRequest, variations []models.ProductVariationRequest) error {
+	stored, err := db.GetProductAttributeValues(productID)
+	if err != nil {
+		return err
+	}
+
+	var common []string
+	byVariation := make(map[int][]string)
+	for _, value := range stored {
+		if value.VariationID == nil {
+			common = append(common, value.Name)
+		} else {
+			byVariation[*value.VariationID] = append(byVariation[*value.VariationID], value.Name)
+		}
+	}
+	if len(attributes) > 0 {
+		common = filledAttributeNames(attributes)
+	}
+
+	// Переданный список вариаций заменяет сохранённый целиком (UpdateProductVariations удаляет
+	// вариации не из списка и добавляет новые), поэтому проверяется он сам
+	if len(variations) > 0 {
+		variationNames := make([][]string, len(variations))
+		for i, variation := range variations {
+			variationNames[i] = filledAttributeNames(variation.Attributes)
+		}
+		return checkRequiredAttributes(categoryID, common, variationNames)
+	}
+
+	storedVariations, err := db.GetProductVariations(productID)
+	if err != nil {
+		return fmt.Errorf("не удалось получить вариации продукта: %v", err)
+	}
+	variationNames := make([][]string, len(storedVariations))
+	for i, variation := range storedVariations {
+		variationNames[i] = byVariation[variation.ID]
+	}
+
+	return checkRequiredAttributes(categoryID, common, variationNames)
+}
+
 func (p *ProductService) UpdateProductAttributes(productID int, categoryID int, attributes []models.AttributeValueRequest) error {
 	// Удаляем существующие атрибуты продукта
 	if err := db.DeleteProductAttributes(productID); err != nil {
@@ -289,6 +508,13 @@ func (p *ProductService) UpdateProductVariation(variationID int, variationReq mo
 		return fmt.Errorf("не удалось обновить атрибуты вариации: %v", err)
 	}
 
+	// Цвета заменяются, только если переданы
+	if variationReq.Colors != nil {
+		if err := db.SetVariationColors(variationID, colorIDs(variationReq.Colors)); err != nil {
+			return fmt.Errorf("не удалось обновить цвета вариации: %v", err)
+		}
+	}
+
 	// Обновляем изображения вариации
 	if len(variationReq.Images) > 0 {
 		if err := p.UpdateVariationImages(variationID, variationReq.Images); err != nil {
@@ -326,31 +552,6 @@ func (p *ProductService) UpdateVariationImages(variationID int, images []*multip
 	return p.SaveVariationImages(variationID, images)
 }
 
-func (s *ProductService) addVariationColorsTx(tx *sql.Tx, variationID int, colors []models.Color) error {
-	for _, color := range colors {
-		// Проверяем, существует ли цвет
-		colorID, err := db.GetColorIDByNameAndCode(tx, color.Name, color.Code)
-		if err != nil {
-			if err == sql.ErrNoRows {
-				// Создаём новый цвет
-				colorID, err = db.CreateColorTx(tx, color)
-				if err != nil {
-					return err
-				}
-			} else {
-				return err
-			}
-		}
-
-		// Создаём связь между вариацией и цветом
-		err = db.CreateVariationColorLinkTx(tx, variationID, colorID)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
 func (p *ProductService) AddProductVariation(variationReq models.ProductVariationRequest, productID int, categoryID int, supplierID int, index int) error {
 	// Создаем запись для вариации
 	productVariation := models.ProductVariation{
@@ -382,6 +583,13 @@ func (p *ProductService) AddProductVariation(variationReq models.ProductVariatio
 		}
 	}
 
+	// Сохранение цветов вариации из палитры
+	if len(variationReq.Colors) > 0 {
+		if err := db.SetVariationColors(productVariation.ID, colorIDs(variationReq.Colors)); err != nil {
+			return fmt.Errorf("не удалось сохранить цвета вариации: %v", err)
+		}
+	}
+
 	// Сохранение изображений для вариации
 	if len(variationReq.Images) > 0 {
 		err := p.SaveVariationImages(productVariation.ID, variationReq.Images)
@@ -409,8 +617,14 @@ func (p *ProductService) SaveVariationAttribute(variationID int, categoryID int,
 		return fmt.Errorf("Атрибут '%s' не является linked и не может быть использован для вариации", attribute.Name)
 	}
 
+	// Числовые значения сохраняются в канонической единице атрибута
+	value, err := normalizeAttributeValue(attributeID, attribute)
+	if err != nil {
+		return fmt.Errorf("Некорректное значение атрибута '%s': %v", attribute.Name, err)
+	}
+
 	// Создаем или получаем значение атрибута
-	attributeValueID, err := db.CreateOrGetAttributeValue(attributeID, attribute.Value)
+	attributeValueID, err := db.CreateOrGetAttributeValue(attributeID, value)
 	if err != nil {
 		return fmt.Errorf("Ошибка при создании или получении значения атрибута '%s': %v", attribute.Name, err)
 	}
@@ -429,41 +643,12 @@ func (p *ProductService) SaveVariationAttribute(variationID int, categoryID int,
 }
 
 func (p *ProductService) SaveVariationImages(variationID int, images []*multipart.FileHeader) error {
-	var imageURLs []string
-	for _, fileHeader := range images {
-		if fileHeader != nil {
-			// Указываем путь внутри бакета для сохранения изображений вариации
-			folderPath := fmt.Sprintf("variations/%d", variationID)
-
-			// Загружаем файл в Yandex Cloud Storage
-			imageURL, err := utils.UploadFileToYandex(fileHeader, folderPath)
-			if err != nil {
-				return fmt.Errorf("не удалось загрузить изображение вариации: %v", err)
-			}
-
-			imageURLs = append(imageURLs, imageURL)
-		}
-	}
-
-	if len(imageURLs) > 0 {
-		// Преобразуем массив ссылок в JSON
-		imagesJSON, err := json.Marshal(imageURLs)
-		if err != nil {
-			return fmt.Errorf("не удалось преобразовать массив изображений в JSON: %v", err)
-		}
-
-		productVariationImage := &models.ProductVariationImage{
-			ProductVariationID: variationID,
-			ImageURLs:          string(imagesJSON), // Хранится как JSON строка в базе данных
-		}
-
-		if err := db.CreateProductVariationImage(productVariationImage); err != nil {
-			return fmt.Errorf("не удалось сохранить изображения в базе данных: %v", err)
-		}
+	if _, err := saveImages(models.ImageOwnerVariation, variationID, images, ""); err != nil {
+		return fmt.Errorf("не удалось сохранить изображения вариации: %w", err)
 	}
-
 	return nil
 }
+
 func (s *ProductService) GetSupplierByUserID(userID int) (*models.Supplier, error) {
 	return db.GetSupplierByUserID(userID)
 }
@@ -474,41 +659,32 @@ func (s *ProductService) AddProductImage(image *models.ProductImage) error {
 
 // ApproveProduct подтверждает продукт
 func (p *ProductService) ApproveProduct(productID int, userID int) error {
-	// Начинаем транзакцию
-	tx, err := db.DB.Begin()
-	if err != nil {
-		return fmt.Errorf("не удалось начать транзакцию: %v", err)
-	}
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		} else {
-			tx.Commit()
-		}
-	}()
+	return moderateProduct(productID, userID, 4, "Подтвержден", nil)
+}
 
-	// Обновляем статус продукта на 4 (Подтвержденный)
-	err = db.UpdateProductStatusTx(tx, productID, 4)
+// RejectProduct отклоняет продукт с комментарием и причинами из справочника.
+// Если комментарий не указан, он формируется из названий причин.
+func (p *ProductService) RejectProduct(productID int, userID int, content string, inputs []models.RejectionReasonInput) error {
+	reasons, err := resolveRejectionReasons(productID, inputs)
 	if err != nil {
-		return fmt.Errorf("не удалось обновить статус продукта: %v", err)
-	}
-
-	// Добавляем комментарий "Подтвержден"
-	comment := models.Comment{
-		UserID:    userID,
-		ProductID: productID,
-		Content:   "Подтвержден",
+		return err
 	}
-	err = db.CreateCommentTx(tx, &comment)
-	if err != nil {
-		return fmt.Errorf("не удалось добавить комментарий: %v", err)
+	if content == "" {
+		content = rejectionSummary(reasons)
 	}
-
-	return nil
+	return moderateProduct(productID, userID, 3, content, reasons)
 }
 
-// RejectProduct отклоняет продукт с комментарием
-func (p *ProductService) RejectProduct(productID int, userID int, content string) error {
+// moderateProduct меняет статус продукта по решению модератора и сохраняет комментарий.
+// Решение принимается только по продукту, ожидающему модерации: статус проверяется под блокировкой
+// строки продукта, поэтому два решения по одному продукту не применяются. userID = 0 означает решение автомодерации.
+func moderateProduct(productID int, userID int, statusID int, content string, reasons []models.ProductRejectionReason) (err error) {
+	if statusID == models.ProductStatusApproved {
+		if err := checkSupplierVerificationRequired(productID); err != nil {
+			return err
+		}
+	}
+
 	// Начинаем транзакцию
 	tx, err := db.DB.Begin()
 	if err != nil {
@@ -518,14 +694,24 @@ func (p *ProductService) RejectProduct(productID int, userID int, content string
 		if err != nil {
 			tx.Rollback()
 		} else {
-			tx.Commit()
+			err = tx.Commit()
 		}
 	}()
 
-	// Обновляем статус продукта на 3 (Отклоненный)
-	err = db.UpdateProductStatusTx(tx, productID, 3)
+	currentStatusID, err := db.LockProductStatusIDTx(tx, productID)
 	if err != nil {
-		return fmt.Errorf("не удалось обновить статус продукта: %v", err)
+		return err
+	}
+	if currentStatusID == 0 {
+		return ErrModerationProductNotFound
+	}
+	if currentStatusID != models.ProductStatusPending {
+		return ErrProductNotPending
+	}
+
+	// Продукт, захваченный другим модератором, менять нельзя
+	if err = finishModerationTx(tx, productID, userID); err != nil {
+		return err
 	}
 
 	// Добавляем комментарий модератора
@@ -539,5 +725,20 @@ func (p *ProductService) RejectProduct(productID int, userID int, content string
 		return fmt.Errorf("не удалось добавить комментарий: %v", err)
 	}
 
+	// Причины отклонения привязываются к комментарию
+	for i := range reasons {
+		reasons[i].ProductID = productID
+		reasons[i].CommentID = comment.ID
+		if err = db.CreateProductRejectionReasonTx(tx, &reasons[i]); err != nil {
+			return err
+		}
+	}
+
+	// Обновляем статус продукта (4 — подтвержденный, 3 — отклоненный), комментарий попадает в историю
+	err = db.UpdateProductStatusTx(tx, productID, statusID, userID, comment.ID)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить статус продукта: %v", err)
+	}
+
 	return nil
 }
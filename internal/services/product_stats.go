@@ -0,0 +1,106 @@
+// internal/services/product_stats.go
+
+package services
+
+import (
+	"log"
+	"time"
+
+	"github.com/WhyDias/Marketplace/internal/db"
+	"github.com/WhyDias/Marketplace/internal/models"
+)
+
+const (
+	// ProductStatsAggregationInterval период запуска агрегатора статистики продуктов
+	ProductStatsAggregationInterval = 5 * time.Minute
+	// productEventSettleDelay события моложе этого времени агрегатор оставляет до следующего запуска
+	productEventSettleDelay = time.Minute
+	// ProductEventRetention срок хранения учтённых событий продуктов
+	ProductEventRetention = 90 * 24 * time.Hour
+	// ContactClickRateInterval и ContactClickRateBurst ограничивают нажатия на контакты с одного клиента:
+	// ContactClickRateBurst подряд, дальше одно за ContactClickRateInterval
+	ContactClickRateInterval = 10 * time.Second
+	ContactClickRateBurst    = 5
+)
+
+// checkPublishedProduct проверяет, что продукт одобрен и показывается покупателям, а не скрыт на время отпуска
+func checkPublishedProduct(productID int) error {
+	statusID, err := db.GetProductStatusID(productID)
+	if err != nil {
+		return err
+	}
+	if statusID != models.ProductStatusApproved {
+		return ErrProductNotFound
+	}
+	vacationMode, err := db.GetProductVacationMode(productID)
+	if err != nil {
+		return err
+	}
+	if vacationMode == models.VacationModeHidden {
+		return ErrProductNotFound
+	}
+	return nil
+}
+
+// RecordProductView сохраняет просмотр продукта покупателем
+func (p *ProductService) RecordProductView(productID int) error {
+	if err := checkPublishedProduct(productID); err != nil {
+		return err
+	}
+	return db.CreateProductEvent(productID, models.ProductEventView)
+}
+
+// RecordContactClick сохраняет нажатие на контакт поставщика на странице продукта
+func (p *ProductService) RecordContactClick(productID int) error {
+	if err := checkPublishedProduct(productID); err != nil {
+		return err
+	}
+	return db.CreateProductEvent(productID, models.ProductEventContactClick)
+}
+
+// AddFavorite добавляет одобренный продукт в избранное пользователя
+func (p *ProductService) AddFavorite(userID int, productID int) error {
+	if err := checkPublishedProduct(productID); err != nil {
+		return err
+	}
+	_, err := db.AddProductFavorite(userID, productID)
+	return err
+}
+
+// RemoveFavorite убирает продукт из избранного пользователя
+func (p *ProductService) RemoveFavorite(userID int, productID int) error {
+	return db.RemoveProductFavorite(userID, productID)
+}
+
+// GetFavorites возвращает одобренные продукты из избранного пользователя
+func (p *ProductService) GetFavorites(userID int) ([]models.ProductCard, error) {
+	return db.GetFavoriteProductCards(userID)
+}
+
+// StartProductStatsAggregator запускает фоновый перенос событий продуктов в дневные итоги
+// и удаление учтённых событий старше ProductEventRetention
+func StartProductStatsAggregator(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			aggregateProductStats()
+			<-ticker.C
+		}
+	}()
+}
+
+func aggregateProductStats() {
+	updated, err := db.AggregateProductEvents(productEventSettleDelay)
+	if err != nil {
+		log.Printf("aggregateProductStats: %v", err)
+		return
+	}
+	if updated > 0 {
+		log.Printf("aggregateProductStats: обновлено дневных итогов: %d", updated)
+	}
+
+	if _, err := db.DeleteAggregatedProductEvents(time.Now().Add(-ProductEventRetention)); err != nil {
+		log.Printf("aggregateProductStats: %v", err)
+	}
+}
@@ -0,0 +1,180 @@
+// internal/services/rejection_reason_service.go
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/WhyDias/Marketplace/internal/db"
+	"github.com/WhyDias/Marketplace/internal/models"
+)
+
+var (
+	ErrRejectionReasonNotFound   = errors.New("причина отклонения не найдена")
+	ErrRejectionReasonCodeExists = errors.New("причина с таким кодом уже существует")
+	ErrInvalidRejectionReason    = errors.New("некорректная причина отклонения")
+)
+
+type RejectionReasonService struct{}
+
+func NewRejectionReasonService() *RejectionReasonService {
+	return &RejectionReasonService{}
+}
+
+// GetReasons возвращает справочник причин отклонения
+func (s *RejectionReasonService) GetReasons(activeOnly bool) ([]models.RejectionReason, error) {
+	return db.GetRejectionReasons(activeOnly)
+}
+
+// CreateReason добавляет причину в справочник
+func (s *RejectionReasonService) CreateReason(req models.RejectionReasonRequest) (*models.RejectionReason, error) {
+	existingID, err := db.GetRejectionReasonIDByCode(req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if existingID != 0 {
+		return nil, ErrRejectionReasonCodeExists
+	}
+
+	reason := &models.RejectionReason{
+		Code:        req.Code,
+		Title:       req.Title,
+		Description: req.Description,
+		IsActive:    req.IsActive == nil || *req.IsActive,
+		SortOrder:   req.SortOrder,
+	}
+	if err := db.CreateRejectionReason(reason); err != nil {
+		return nil, err
+	}
+	return reason, nil
+}
+
+// UpdateReason изменяет причину. Неактивные причины нельзя указать при отклонении,
+// но они остаются в истории и отчётах.
+func (s *RejectionReasonService) UpdateReason(id int, req models.RejectionReasonRequest) (*models.RejectionReason, error) {
+	existingID, err := db.GetRejectionReasonIDByCode(req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if existingID != 0 && existingID != id {
+		return nil, ErrRejectionReasonCodeExists
+	}
+
+	reason := &models.RejectionReason{
+		ID:          id,
+		Code:        req.Code,
+		Title:       req.Title,
+		Description: req.Description,
+		IsActive:    req.IsActive == nil || *req.IsActive,
+		SortOrder:   req.SortOrder,
+	}
+	updated, err := db.UpdateRejectionReason(reason)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, ErrRejectionReasonNotFound
+	}
+	return reason, nil
+}
+
+// GetStats возвращает самые частые причины отклонения за период
+func (s *RejectionReasonService) GetStats(from, to time.Time) ([]models.RejectionReasonStat, error) {
+	return db.GetRejectionReasonStats(from, to)
+}
+
+// resolveRejectionReasons проверяет коды причин и то, что указанные вариации
+// и изображения принадлежат продукту
+func resolveRejectionReasons(productID int, inputs []models.RejectionReasonInput) ([]models.ProductRejectionReason, error) {
+	if len(inputs) == 0 {
+		return nil, nil
+	}
+
+	codes := make([]string, 0, len(inputs))
+	for _, input := range inputs {
+		codes = append(codes, input.Code)
+	}
+	catalog, err := db.GetRejectionReasonsByCodes(codes)
+	if err != nil {
+		return nil, err
+	}
+
+	var variationIDs map[int]bool
+	reasons := make([]models.ProductRejectionReason, 0, len(inputs))
+	for _, input := range inputs {
+		catalogReason, ok := catalog[input.Code]
+		if !ok {
+			return nil, fmt.Errorf("%w: неизвестный код %s", ErrInvalidRejectionReason, input.Code)
+		}
+
+		if input.VariationID != nil {
+			if variationIDs == nil {
+				variationIDs, err = productVariationIDs(productID)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if !variationIDs[*input.VariationID] {
+				return nil, fmt.Errorf("%w: вариация %d не принадлежит продукту", ErrInvalidRejectionReason, *input.VariationID)
+			}
+		}
+
+		if input.ImageID != nil {
+			owner, ownerID := models.ImageOwnerProduct, productID
+			if input.VariationID != nil {
+				owner, ownerID = models.ImageOwnerVariation, *input.VariationID
+			}
+			image, err := db.GetImageByID(owner, ownerID, *input.ImageID)
+			if err != nil {
+				return nil, err
+			}
+			if image == nil {
+				return nil, fmt.Errorf("%w: изображение %d не найдено", ErrInvalidRejectionReason, *input.ImageID)
+			}
+		}
+
+		reasons = append(reasons, models.ProductRejectionReason{
+			ProductID:   productID,
+			ReasonID:    catalogReason.ID,
+			Code:        catalogReason.Code,
+			Title:       catalogReason.Title,
+			Field:       input.Field,
+			VariationID: input.VariationID,
+			ImageID:     input.ImageID,
+			Note:        input.Note,
+		})
+	}
+
+	return reasons, nil
+}
+
+func productVariationIDs(productID int) (map[int]bool, error) {
+	variations, err := db.GetProductVariations(productID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[int]bool, len(variations))
+	for _, v := range variations {
+		ids[v.ID] = true
+	}
+	return ids, nil
+}
+
+// rejectionSummary формирует текст комментария из причин, если модератор не написал свой
+func rejectionSummary(reasons []models.ProductRejectionReason) string {
+	parts := make([]string, 0, len(reasons))
+	for _, r := range reasons {
+		part := r.Title
+		if r.Field != "" {
+			part += " (" + r.Field + ")"
+		}
+		if r.Note != "" {
+			part += ": " + r.Note
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,62 @@
+// internal/services/sitemap_service.go
+
+package services
+
+import (
+	"encoding/xml"
+	"strings"
+
+	"github.com/WhyDias/Marketplace/internal/db"
+	"github.com/WhyDias/Marketplace/internal/utils"
+)
+
+// Адреса страниц сайта для категорий и продуктов
+const (
+	CategoryPagePrefix = "/catalog/"
+	ProductPagePrefix  = "/products/"
+)
+
+// SitemapService строит sitemap.xml по одобренным продуктам и непустым категориям
+type SitemapService struct{}
+
+func NewSitemapService() *SitemapService {
+	return &SitemapService{}
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc string `xml:"loc"`
+}
+
+// Sitemap возвращает sitemap.xml с адресами относительно baseURL (https://example.kg)
+func (s *SitemapService) Sitemap(baseURL string) ([]byte, error) {
+	baseURL = strings.TrimRight(baseURL, "/")
+
+	paths, err := db.GetSitemapCategoryPaths()
+	if err != nil {
+		return nil, err
+	}
+	slugs, err := db.GetSitemapProductSlugs()
+	if err != nil {
+		return nil, err
+	}
+
+	set := sitemapURLSet{Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	for _, path := range paths {
+		set.URLs = append(set.URLs, sitemapURL{Loc: baseURL + CategoryPagePrefix + utils.CategorySlugPath(path)})
+	}
+	for _, slug := range slugs {
+		set.URLs = append(set.URLs, sitemapURL{Loc: baseURL + ProductPagePrefix + slug})
+	}
+
+	data, err := xml.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
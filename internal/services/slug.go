@@ -0,0 +1,148 @@
+// internal/services/slug.go
+
+package services
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/WhyDias/Marketplace/internal/db"
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/WhyDias/Marketplace/internal/utils"
+)
+
+// uniqueProductSlug строит slug из названия продукта. Если slug занят другим продуктом,
+// добавляется числовой суффикс: futbolka, futbolka-2, futbolka-3.
+func uniqueProductSlug(name string, productID int) (string, error) {
+	base := utils.ProductSlug(name)
+	if base == "" {
+		base = "product"
+	}
+	slug := base
+	for n := 2; ; n++ {
+		exists, err := db.ProductSlugExists(slug, productID)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return slug, nil
+		}
+		slug = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+// maxProductSlugAttempts сколько раз подбирается slug, если его успел занять параллельный запрос
+const maxProductSlugAttempts = 5
+
+// saveProductSlug подбирает свободный slug и передаёт его в save. Проверка и запись не атомарны,
+// поэтому при конфликте с уникальным индексом slug подбирается заново.
+func saveProductSlug(name string, productID int, save func(slug string) error) error {
+	for attempt := 0; attempt < maxProductSlugAttempts; attempt++ {
+		slug, err := uniqueProductSlug(name, productID)
+		if err != nil {
+			return fmt.Errorf("не удалось построить slug продукта: %v", err)
+		}
+		if err := save(slug); !db.IsProductSlugConflict(err) {
+			return err
+		}
+	}
+	return fmt.Errorf("не удалось подобрать свободный slug продукта '%s'", name)
+}
+
+// uniqueCategoryPath дописывает метку к path родителя. Если path занят, к метке
+// добавляется числовой суффикс: odezhda, odezhda_2, odezhda_3.
+func uniqueCategoryPath(parent *models.Category, label string) (string, error) {
+	path := categoryPath(parent, label)
+	for n := 2; ; n++ {
+		exists, err := db.CategoryPathExists(path)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return path, nil
+		}
+		path = categoryPath(parent, fmt.Sprintf("%s_%d", label, n))
+	}
+}
+
+// BackfillProductSlugs заполняет slug продуктов, созданных до появления slug
+func (p *ProductService) BackfillProductSlugs() error {
+	products, err := db.GetProductsWithoutSlug()
+	if err != nil {
+		return err
+	}
+	for _, product := range products {
+		err := saveProductSlug(product.Name, product.ID, func(slug string) error {
+			return db.SetProductSlug(product.ID, slug)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if len(products) > 0 {
+		log.Printf("BackfillProductSlugs: заполнен slug у %d продуктов", len(products))
+	}
+	return nil
+}
+
+// GetPublishedProductBySlug возвращает одобренный продукт по slug. Если slug принадлежал
+// продукту раньше, возвращает текущий slug для переадресации.
+func (p *ProductService) GetPublishedProductBySlug(slug string) (*models.Product, string, error) {
+	productID, err := db.GetProductIDBySlug(slug)
+	if err != nil {
+		return nil, "", err
+	}
+	if productID == 0 {
+		redirectID, err := db.FindSlugRedirect(models.SlugEntityProduct, slug)
+		if err != nil {
+			return nil, "", err
+		}
+		if redirectID == 0 {
+			return nil, "", ErrProductNotFound
+		}
+		product, err := p.GetPublishedProduct(redirectID)
+		if err != nil {
+			return nil, "", err
+		}
+		return nil, product.Slug, nil
+	}
+
+	product, err := p.GetPublishedProduct(productID)
+	return product, "", err
+}
+
+// GetCategoryBySlug возвращает категорию по URL из path. Если URL принадлежал категории
+// раньше, возвращает её текущий URL для переадресации.
+func (s *CategoryService) GetCategoryBySlug(slug string) (*models.Category, string, error) {
+	path, ok := utils.CategoryPathFromSlug(slug)
+	if !ok {
+		return nil, "", ErrCategoryNotFound
+	}
+
+	categoryID, err := db.GetCategoryIDByPath(path)
+	if err != nil {
+		return nil, "", err
+	}
+	redirect := categoryID == 0
+	if redirect {
+		if categoryID, err = db.FindSlugRedirect(models.SlugEntityCategory, path); err != nil {
+			return nil, "", err
+		}
+		if categoryID == 0 {
+			return nil, "", ErrCategoryNotFound
+		}
+	}
+
+	category, err := db.GetCategoryByID(categoryID)
+	if err != nil {
+		return nil, "", err
+	}
+	if category == nil {
+		return nil, "", ErrCategoryNotFound
+	}
+	category.Slug = utils.CategorySlugPath(category.Path)
+	if redirect {
+		return nil, category.Slug, nil
+	}
+	return category, "", nil
+}
@@ -0,0 +1,110 @@
+// internal/services/supplier_dashboard.go
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/WhyDias/Marketplace/internal/db"
+	"github.com/WhyDias/Marketplace/internal/models"
+)
+
+const (
+	defaultDashboardPeriodDays = 30
+	maxDashboardPeriodDays     = 366
+
+	defaultLowStockThreshold = 5
+	maxLowStockVariations    = 50
+	dashboardTopProducts     = 5
+)
+
+var ErrInvalidDashboardPeriod = errors.New("некорректный период")
+
+// GetDashboard возвращает кабинет поставщика пользователя. По умолчанию период — последние 30 дней, включая сегодня.
+func (s *SupplierService) GetDashboard(userID int, filter models.SupplierDashboardFilter) (*models.SupplierDashboard, error) {
+	supplierID, err := supplierIDByUser(userID, models.SupplierRoleOwner, models.SupplierRoleManager)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.To.IsZero() {
+		now := time.Now()
+		filter.To = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	if filter.From.IsZero() {
+		filter.From = filter.To.AddDate(0, 0, -(defaultDashboardPeriodDays - 1))
+	}
+	if filter.From.After(filter.To) {
+		return nil, fmt.Errorf("%w: from позже to", ErrInvalidDashboardPeriod)
+	}
+	if filter.To.Sub(filter.From) >= maxDashboardPeriodDays*24*time.Hour {
+		return nil, fmt.Errorf("%w: период не длиннее %d дней", ErrInvalidDashboardPeriod, maxDashboardPeriodDays)
+	}
+	if filter.LowStockThreshold <= 0 {
+		filter.LowStockThreshold = defaultLowStockThreshold
+	}
+
+	dashboard := &models.SupplierDashboard{
+		From:              filter.From.Format("2006-01-02"),
+		To:                filter.To.Format("2006-01-02"),
+		LowStockThreshold: filter.LowStockThreshold,
+	}
+
+	counts, err := db.CountSupplierProductsByStatus(supplierID)
+	if err != nil {
+		return nil, err
+	}
+	for _, statusID := range []int{models.ProductStatusPending, models.ProductStatusApproved, models.ProductStatusRejected} {
+		dashboard.StatusCounts = append(dashboard.StatusCounts, models.ProductStatusCount{
+			StatusID: statusID,
+			Status:   models.ProductStatusNames[statusID],
+			Count:    counts[statusID],
+		})
+	}
+
+	if dashboard.LowStock, err = db.GetLowStockVariations(supplierID, filter.LowStockThreshold, maxLowStockVariations); err != nil {
+		return nil, err
+	}
+
+	if dashboard.Products, err = db.GetSupplierProductStats(supplierID, filter.From, filter.To); err != nil {
+		return nil, err
+	}
+	for _, stats := range dashboard.Products {
+		dashboard.Totals.Views += stats.Views
+		dashboard.Totals.Favorites += stats.Favorites
+		dashboard.Totals.ContactClicks += stats.ContactClicks
+	}
+	dashboard.TopProducts = topProducts(dashboard.Products, dashboardTopProducts)
+
+	if dashboard.StatsUpdatedAt, err = db.GetProductStatsAggregatedAt(); err != nil {
+		return nil, err
+	}
+	return dashboard, nil
+}
+
+// topProducts выбирает продукты с активностью за период: больше всего нажатий на контакты,
+// затем добавлений в избранное, затем просмотров
+func topProducts(products []models.ProductStats, limit int) []models.ProductStats {
+	top := []models.ProductStats{}
+	for _, stats := range products {
+		if stats.Views+stats.Favorites+stats.ContactClicks > 0 {
+			top = append(top, stats)
+		}
+	}
+	sort.SliceStable(top, func(i, j int) bool {
+		if top[i].ContactClicks != top[j].ContactClicks {
+			return top[i].ContactClicks > top[j].ContactClicks
+		}
+		if top[i].Favorites != top[j].Favorites {
+			return top[i].Favorites > top[j].Favorites
+		}
+		return top[i].Views > top[j].Views
+	})
+	if len(top) > limit {
+		top = top[:limit]
+	}
+	return top
+}
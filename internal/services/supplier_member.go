@@ -0,0 +1,269 @@
+// internal/services/supplier_member.go
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/WhyDias/Marketplace/internal/db"
+	"github.com/WhyDias/Marketplace/internal/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// SupplierInviteTTL срок действия приглашения в команду поставщика
+const SupplierInviteTTL = 7 * 24 * time.Hour
+
+var (
+	ErrSupplierForbidden      = errors.New("недостаточно прав в команде поставщика")
+	ErrSupplierMemberNotFound = errors.New("участник команды не найден")
+	ErrSupplierInviteNotFound = errors.New("приглашение не найдено")
+	ErrInvalidSupplierRole    = errors.New("роль должна быть manager или content_editor")
+	ErrAlreadySupplierMember  = errors.New("пользователь уже состоит в команде поставщика")
+	ErrSupplierOwnerRemoval   = errors.New("владельца нельзя исключить из команды или сменить ему роль")
+	ErrSupplierInvitePending  = errors.New("номер приглашён в команду поставщика, примите приглашение")
+	ErrInvalidInviteCode      = errors.New("неверный или истекший код подтверждения")
+	ErrInvitePasswordRequired = errors.New("задайте пароль, чтобы принять приглашение")
+)
+
+// supplierMembership возвращает участие пользователя в команде поставщика и проверяет,
+// что его роль входит в roles. Без roles подходит любая роль.
+func supplierMembership(userID int, roles ...string) (*models.SupplierMember, error) {
+	member, err := db.GetSupplierMember(userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, fmt.Errorf("%w: пользователь %d не состоит в команде поставщика", ErrSupplierNotFound, userID)
+	}
+	if len(roles) == 0 {
+		return member, nil
+	}
+	for _, role := range roles {
+		if member.Role == role {
+			return member, nil
+		}
+	}
+	return nil, ErrSupplierForbidden
+}
+
+// canManageMemberRole проверяет, может ли участник приглашать и исключать участников с ролью role:
+// владелец — менеджеров и контент-редакторов, менеджер — только контент-редакторов
+func canManageMemberRole(actor *models.SupplierMember, role string) bool {
+	switch actor.Role {
+	case models.SupplierRoleOwner:
+		return role != models.SupplierRoleOwner
+	case models.SupplierRoleManager:
+		return role == models.SupplierRoleContentEditor
+	}
+	return false
+}
+
+func validateInvitedRole(role string) error {
+	if role != models.SupplierRoleManager && role != models.SupplierRoleContentEditor {
+		return ErrInvalidSupplierRole
+	}
+	return nil
+}
+
+// GetTeam возвращает команду поставщика пользователя и ожидающие приглашения
+func (s *SupplierService) GetTeam(userID int) (*models.SupplierTeam, error) {
+	member, err := supplierMembership(userID, models.SupplierRoleOwner, models.SupplierRoleManager)
+	if err != nil {
+		return nil, err
+	}
+
+	team := &models.SupplierTeam{}
+	if team.Members, err = db.GetSupplierMembers(member.SupplierID); err != nil {
+		return nil, err
+	}
+	if team.Invites, err = db.GetPendingSupplierInvites(member.SupplierID); err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+// InviteMember приглашает в команду поставщика по номеру телефона. Пользователь с этим номером
+// создаётся без пароля при необходимости и получает в WhatsApp код, с которым принимает приглашение
+// в AcceptInvite.
+func (s *SupplierService) InviteMember(userID int, req models.InviteSupplierMemberRequest) (*models.SupplierInvite, error) {
+	if err := validateInvitedRole(req.Role); err != nil {
+		return nil, err
+	}
+	inviter, err := supplierMembership(userID, models.SupplierRoleOwner, models.SupplierRoleManager)
+	if err != nil {
+		return nil, err
+	}
+	if !canManageMemberRole(inviter, req.Role) {
+		return nil, ErrSupplierForbidden
+	}
+
+	invitee, err := db.GetUserByUsername(req.PhoneNumber)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить пользователя: %v", err)
+	}
+	if invitee == nil {
+		if invitee, err = NewUserService().RegisterPendingUser(req.PhoneNumber, []string{models.RoleSupplier}); err != nil {
+			return nil, err
+		}
+	}
+	existing, err := db.GetSupplierMember(invitee.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrAlreadySupplierMember
+	}
+
+	supplier, err := db.GetSupplierByID(inviter.SupplierID)
+	if err != nil {
+		return nil, err
+	}
+	if supplier == nil {
+		return nil, ErrSupplierNotFound
+	}
+
+	invite := &models.SupplierInvite{
+		SupplierID:  inviter.SupplierID,
+		UserID:      invitee.ID,
+		PhoneNumber: req.PhoneNumber,
+		Role:        req.Role,
+		InvitedBy:   userID,
+		ExpiresAt:   time.Now().Add(SupplierInviteTTL),
+	}
+	if err := db.CreateSupplierInvite(invite); err != nil {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("Вас пригласили в команду поставщика «%s». Чтобы принять приглашение, подтвердите номер кодом: ",
+		supplierDisplayName(supplier))
+	if err := sendVerificationCode(invitee.ID, req.PhoneNumber, message); err != nil {
+		return nil, err
+	}
+	return invite, nil
+}
+
+// AcceptInvite принимает приглашение в команду поставщика по коду из WhatsApp. Приглашённый без пароля
+// задаёт его здесь же, пароль сохраняется вместе с участием в команде. Возвращает пользователя для токена.
+func (s *SupplierService) AcceptInvite(req models.AcceptSupplierInviteRequest) (*models.User, error) {
+	user, err := db.GetUserByUsername(req.PhoneNumber)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить пользователя: %v", err)
+	}
+	if user == nil {
+		return nil, ErrSupplierInviteNotFound
+	}
+	if !s.ValidateVerificationCode(user.ID, req.Code) {
+		return nil, ErrInvalidInviteCode
+	}
+	existing, err := db.GetSupplierMember(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrAlreadySupplierMember
+	}
+
+	var passwordHash string
+	if req.Password != "" {
+		hashed, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при хешировании пароля: %v", err)
+		}
+		passwordHash = string(hashed)
+	} else if user.PasswordHash == "" {
+		return nil, ErrInvitePasswordRequired
+	}
+
+	supplierID, err := db.AcceptSupplierInvite(user.ID, passwordHash)
+	if err != nil {
+		return nil, err
+	}
+	if supplierID == 0 {
+		return nil, ErrSupplierInviteNotFound
+	}
+	log.Printf("AcceptInvite: пользователь %d вступил в команду поставщика %d", user.ID, supplierID)
+	return user, nil
+}
+
+// RevokeInvite отзывает приглашение в команду поставщика пользователя
+func (s *SupplierService) RevokeInvite(userID int, inviteID int) error {
+	actor, err := supplierMembership(userID, models.SupplierRoleOwner, models.SupplierRoleManager)
+	if err != nil {
+		return err
+	}
+	invite, err := db.GetSupplierInvite(inviteID)
+	if err != nil {
+		return err
+	}
+	if invite == nil || invite.SupplierID != actor.SupplierID || invite.Status != models.SupplierInvitePending {
+		return ErrSupplierInviteNotFound
+	}
+	if !canManageMemberRole(actor, invite.Role) {
+		return ErrSupplierForbidden
+	}
+	return db.RevokeSupplierInvite(inviteID)
+}
+
+// teamMember возвращает участника той же команды, что и actor
+func teamMember(actor *models.SupplierMember, memberUserID int) (*models.SupplierMember, error) {
+	member, err := db.GetSupplierMember(memberUserID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil || member.SupplierID != actor.SupplierID {
+		return nil, ErrSupplierMemberNotFound
+	}
+	return member, nil
+}
+
+// UpdateMemberRole меняет роль участника команды. Роли меняет только владелец.
+func (s *SupplierService) UpdateMemberRole(userID int, memberUserID int, role string) (*models.SupplierMember, error) {
+	if err := validateInvitedRole(role); err != nil {
+		return nil, err
+	}
+	owner, err := supplierMembership(userID, models.SupplierRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+	member, err := teamMember(owner, memberUserID)
+	if err != nil {
+		return nil, err
+	}
+	if member.Role == models.SupplierRoleOwner {
+		return nil, ErrSupplierOwnerRemoval
+	}
+
+	if err := db.UpdateSupplierMemberRole(owner.SupplierID, memberUserID, role); err != nil {
+		return nil, err
+	}
+	member.Role = role
+	return member, nil
+}
+
+// RemoveMember исключает участника из команды. Участник может выйти из команды сам,
+// владелец исключает любого участника, менеджер — контент-редакторов.
+func (s *SupplierService) RemoveMember(userID int, memberUserID int) error {
+	actor, err := supplierMembership(userID)
+	if err != nil {
+		return err
+	}
+	member, err := teamMember(actor, memberUserID)
+	if err != nil {
+		return err
+	}
+	if member.Role == models.SupplierRoleOwner {
+		return ErrSupplierOwnerRemoval
+	}
+	if memberUserID != userID && !canManageMemberRole(actor, member.Role) {
+		return ErrSupplierForbidden
+	}
+
+	if err := db.DeleteSupplierMember(actor.SupplierID, memberUserID); err != nil {
+		return err
+	}
+	log.Printf("RemoveMember: пользователь %d исключён из команды поставщика %d пользователем %d", memberUserID, actor.SupplierID, userID)
+	return nil
+}
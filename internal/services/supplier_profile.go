@@ -0,0 +1,222 @@
+// internal/services/supplier_profile.go
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/WhyDias/Marketplace/internal/db"
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/WhyDias/Marketplace/internal/utils"
+)
+
+const (
+	defaultStorefrontProductsLimit = 20
+	maxStorefrontProductsLimit     = 100
+)
+
+var (
+	ErrSupplierNotFound       = errors.New("поставщик не найден")
+	ErrInvalidSupplierProfile = errors.New("некорректный профиль поставщика")
+)
+
+var (
+	telegramUsernamePattern  = regexp.MustCompile(`^[A-Za-z0-9_]{5,32}$`)
+	instagramUsernamePattern = regexp.MustCompile(`^[A-Za-z0-9_.]{1,30}$`)
+)
+
+// supplierDisplayName название витрины. Поставщик, не указавший название, показывается по месту на рынке.
+func supplierDisplayName(supplier *models.Supplier) string {
+	if name := strings.TrimSpace(supplier.Name); name != "" {
+		return name
+	}
+	if supplier.RowName != "" && supplier.PlaceName != "" {
+		return fmt.Sprintf("Поставщик, ряд %s, место %s", supplier.RowName, supplier.PlaceName)
+	}
+	return fmt.Sprintf("Поставщик №%d", supplier.ID)
+}
+
+// supplierContacts способы связи на витрине. Телефон подтверждён через WhatsApp, поэтому
+// ссылка на WhatsApp строится из него же.
+func supplierContacts(phone string, profile *models.SupplierProfile) []models.SupplierContact {
+	contacts := []models.SupplierContact{}
+	if profile.ShowPhone && phone != "" {
+		digits := strings.TrimPrefix(phone, "+")
+		contacts = append(contacts,
+			models.SupplierContact{Type: models.SupplierContactPhone, Value: phone, URL: "tel:" + phone},
+			models.SupplierContact{Type: models.SupplierContactWhatsApp, Value: phone, URL: "https://wa.me/" + digits},
+		)
+	}
+	if profile.Telegram != "" {
+		contacts = append(contacts, models.SupplierContact{
+			Type: models.SupplierContactTelegram, Value: "@" + profile.Telegram, URL: "https://t.me/" + profile.Telegram,
+		})
+	}
+	if profile.Instagram != "" {
+		contacts = append(contacts, models.SupplierContact{
+			Type: models.SupplierContactInstagram, Value: "@" + profile.Instagram, URL: "https://instagram.com/" + profile.Instagram,
+		})
+	}
+	return contacts
+}
+
+// normalizeSocialUsername убирает @ и ссылку на профиль перед именем пользователя
+func normalizeSocialUsername(value string, pattern *regexp.Regexp, hosts ...string) (string, error) {
+	value = strings.TrimSpace(value)
+	for _, host := range hosts {
+		value = strings.TrimPrefix(value, "https://"+host+"/")
+		value = strings.TrimPrefix(value, host+"/")
+	}
+	value = strings.Trim(strings.TrimPrefix(value, "@"), "/")
+	if value == "" {
+		return "", nil
+	}
+	if !pattern.MatchString(value) {
+		return "", fmt.Errorf("%w: некорректное имя пользователя %q", ErrInvalidSupplierProfile, value)
+	}
+	return value, nil
+}
+
+// supplierIDByUser возвращает ID поставщика, в команде которого состоит пользователь,
+// если его роль входит в roles. Без roles подходит любая роль.
+func supplierIDByUser(userID int, roles ...string) (int, error) {
+	member, err := supplierMembership(userID, roles...)
+	if err != nil {
+		return 0, err
+	}
+	return member.SupplierID, nil
+}
+
+// GetStorefront возвращает витрину поставщика со страницей одобренных продуктов
+func (s *SupplierService) GetStorefront(supplierID, limit, offset int) (*models.SupplierStorefront, error) {
+	supplier, err := db.GetSupplierByID(supplierID)
+	if err != nil {
+		return nil, err
+	}
+	if supplier == nil {
+		return nil, ErrSupplierNotFound
+	}
+	profile, err := db.GetSupplierProfile(supplierID)
+	if err != nil {
+		return nil, err
+	}
+
+	storefront := &models.SupplierStorefront{
+		ID:             supplier.ID,
+		DisplayName:    supplierDisplayName(supplier),
+		IsVerified:     supplier.IsVerified,
+		VerifiedSeller: supplier.VerificationStatus == models.VerificationStatusApproved,
+		LogoURL:        profile.LogoURL,
+		BannerURL:      profile.BannerURL,
+		Description:    profile.Description,
+		RowID:          supplier.RowID,
+		StallID:        supplier.StallID,
+		PlaceName:      supplier.PlaceName,
+		RowName:        supplier.RowName,
+		Contacts:       supplierContacts(supplier.PhoneNumber, profile),
+	}
+	if supplier.MarketID != 0 {
+		if storefront.Market, err = db.GetMarketByID(supplier.MarketID); err != nil {
+			return nil, err
+		}
+	}
+	if storefront.Categories, err = db.GetCategoriesBySupplierID(supplierID); err != nil {
+		return nil, fmt.Errorf("не удалось получить категории поставщика: %v", err)
+	}
+	if storefront.Categories == nil {
+		storefront.Categories = []models.Category{}
+	}
+	schedule, err := supplierSchedule(supplierID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	storefront.Schedule = *schedule
+
+	if limit <= 0 {
+		limit = defaultStorefrontProductsLimit
+	} else if limit > maxStorefrontProductsLimit {
+		limit = maxStorefrontProductsLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	storefront.Products = models.ProductCardPage{Limit: limit, Offset: offset}
+	storefront.Products.Items, storefront.Products.Total, err = db.GetSupplierProductCards(supplierID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return storefront, nil
+}
+
+// GetProfile возвращает профиль витрины поставщика пользователя
+func (s *SupplierService) GetProfile(userID int) (*models.SupplierProfile, error) {
+	supplierID, err := supplierIDByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return db.GetSupplierProfile(supplierID)
+}
+
+// UpdateProfile меняет профиль витрины поставщика пользователя. Новые логотип и баннер
+// загружаются в Yandex Cloud Storage, nil оставляет прежнее изображение.
+func (s *SupplierService) UpdateProfile(userID int, req models.UpdateSupplierProfileRequest, logo, banner *multipart.FileHeader) (*models.SupplierProfile, error) {
+	supplierID, err := supplierIDByUser(userID, models.SupplierRoleOwner, models.SupplierRoleManager)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := db.GetSupplierProfile(supplierID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Description != nil {
+		profile.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Telegram != nil {
+		if profile.Telegram, err = normalizeSocialUsername(*req.Telegram, telegramUsernamePattern, "t.me"); err != nil {
+			return nil, err
+		}
+	}
+	if req.Instagram != nil {
+		if profile.Instagram, err = normalizeSocialUsername(*req.Instagram, instagramUsernamePattern, "instagram.com", "www.instagram.com"); err != nil {
+			return nil, err
+		}
+	}
+	if req.ShowPhone != nil {
+		profile.ShowPhone = *req.ShowPhone
+	}
+	if req.RemoveLogo {
+		profile.LogoURL = ""
+	}
+	if req.RemoveBanner {
+		profile.BannerURL = ""
+	}
+
+	// Изображения загружаются после проверки полей, чтобы не оставлять файлы от отклонённых запросов
+	folderPath := fmt.Sprintf("suppliers/%d", supplierID)
+	if logo != nil {
+		uploaded, err := utils.UploadImageToYandex(logo, folderPath+"/logo")
+		if err != nil {
+			return nil, fmt.Errorf("не удалось загрузить логотип: %w", err)
+		}
+		profile.LogoURL = uploaded.URL
+	}
+	if banner != nil {
+		uploaded, err := utils.UploadImageToYandex(banner, folderPath+"/banner")
+		if err != nil {
+			return nil, fmt.Errorf("не удалось загрузить баннер: %w", err)
+		}
+		profile.BannerURL = uploaded.URL
+	}
+
+	if err := db.UpdateSupplierProfile(supplierID, profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
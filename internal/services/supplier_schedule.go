@@ -0,0 +1,274 @@
+// internal/services/supplier_schedule.go
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/WhyDias/Marketplace/internal/db"
+	"github.com/WhyDias/Marketplace/internal/models"
+)
+
+// SupplierVacationSyncInterval период проверки границ отпусков поставщиков
+const SupplierVacationSyncInterval = time.Minute
+
+// MarketTimezone часовой пояс рынков: расписание и отпуска поставщиков считаются по местному времени.
+// В Кыргызстане нет перехода на летнее время.
+var MarketTimezone = time.FixedZone("Asia/Bishkek", 6*60*60)
+
+var (
+	ErrInvalidSchedule           = errors.New("некорректное расписание")
+	ErrScheduleExceptionNotFound = errors.New("исключение расписания не найдено")
+)
+
+// marketDate дата t по местному времени рынков
+func marketDate(t time.Time) time.Time {
+	t = t.In(MarketTimezone)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, MarketTimezone)
+}
+
+// parseScheduleDate разбирает дату ГГГГ-ММ-ДД по местному времени рынков
+func parseScheduleDate(value string) (time.Time, error) {
+	date, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(value), MarketTimezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: дата '%s', ожидается ГГГГ-ММ-ДД", ErrInvalidSchedule, value)
+	}
+	return date, nil
+}
+
+// normalizeOpeningHours проверяет часы работы: оба времени указаны и закрытие позже открытия
+func normalizeOpeningHours(opensAt, closesAt string) (string, string, error) {
+	opens, err := normalizeClockTime(opensAt, ErrInvalidSchedule)
+	if err != nil {
+		return "", "", err
+	}
+	closes, err := normalizeClockTime(closesAt, ErrInvalidSchedule)
+	if err != nil {
+		return "", "", err
+	}
+	if opens == "" || closes == "" {
+		return "", "", fmt.Errorf("%w: укажите время открытия и закрытия", ErrInvalidSchedule)
+	}
+	// ЧЧ:ММ сравниваются как строки
+	if closes <= opens {
+		return "", "", fmt.Errorf("%w: закрытие %s не позже открытия %s", ErrInvalidSchedule, closes, opens)
+	}
+	return opens, closes, nil
+}
+
+// isoWeekday день недели ISO: 1 — понедельник, 7 — воскресенье
+func isoWeekday(t time.Time) int {
+	if t.Weekday() == time.Sunday {
+		return 7
+	}
+	return int(t.Weekday())
+}
+
+// supplierSchedule собирает расписание поставщика на момент now
+func supplierSchedule(supplierID int, now time.Time) (*models.SupplierSchedule, error) {
+	today := marketDate(now)
+	schedule := &models.SupplierSchedule{}
+	var err error
+	if schedule.WorkingHours, err = db.GetSupplierWorkingHours(supplierID); err != nil {
+		return nil, err
+	}
+	if schedule.Exceptions, err = db.GetSupplierScheduleExceptions(supplierID, today); err != nil {
+		return nil, err
+	}
+	if schedule.Vacation, err = db.GetSupplierVacation(supplierID); err != nil {
+		return nil, err
+	}
+	schedule.OpenNow = isOpenAt(schedule, now.In(MarketTimezone))
+	return schedule, nil
+}
+
+// isOpenAt проверяет, работает ли поставщик в момент now по местному времени:
+// в отпуске закрыт, исключение на дату заменяет обычные часы дня недели
+func isOpenAt(schedule *models.SupplierSchedule, now time.Time) bool {
+	if schedule.Vacation != nil && schedule.Vacation.Active {
+		return false
+	}
+	clock := now.Format("15:04")
+	date := now.Format("2006-01-02")
+	for _, exception := range schedule.Exceptions {
+		if exception.Date == date {
+			return !exception.Closed && exception.OpensAt <= clock && clock < exception.ClosesAt
+		}
+	}
+	weekday := isoWeekday(now)
+	for _, day := range schedule.WorkingHours {
+		if day.Weekday == weekday {
+			return day.OpensAt <= clock && clock < day.ClosesAt
+		}
+	}
+	return false
+}
+
+// GetSchedule возвращает расписание поставщика пользователя
+func (s *SupplierService) GetSchedule(userID int) (*models.SupplierSchedule, error) {
+	supplierID, err := supplierIDByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return supplierSchedule(supplierID, time.Now())
+}
+
+// UpdateWorkingHours заменяет часы работы поставщика на неделю. Не указанные дни становятся выходными.
+func (s *SupplierService) UpdateWorkingHours(userID int, req models.UpdateWorkingHoursRequest) (*models.SupplierSchedule, error) {
+	supplierID, err := supplierIDByUser(userID, models.SupplierRoleOwner, models.SupplierRoleManager)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[int]bool{}
+	days := make([]models.SupplierWorkingDay, 0, len(req.Days))
+	for _, day := range req.Days {
+		if day.Weekday < 1 || day.Weekday > 7 {
+			return nil, fmt.Errorf("%w: день недели %d, ожидается от 1 до 7", ErrInvalidSchedule, day.Weekday)
+		}
+		if seen[day.Weekday] {
+			return nil, fmt.Errorf("%w: день недели %d указан дважды", ErrInvalidSchedule, day.Weekday)
+		}
+		seen[day.Weekday] = true
+		if day.OpensAt, day.ClosesAt, err = normalizeOpeningHours(day.OpensAt, day.ClosesAt); err != nil {
+			return nil, err
+		}
+		days = append(days, day)
+	}
+
+	if err := db.ReplaceSupplierWorkingHours(supplierID, days); err != nil {
+		return nil, err
+	}
+	return supplierSchedule(supplierID, time.Now())
+}
+
+// SetScheduleException задаёт часы работы поставщика на дату вместо обычных, например выходной в праздник
+func (s *SupplierService) SetScheduleException(userID int, date string, req models.ScheduleExceptionRequest) (*models.SupplierSchedule, error) {
+	supplierID, err := supplierIDByUser(userID, models.SupplierRoleOwner, models.SupplierRoleManager)
+	if err != nil {
+		return nil, err
+	}
+	day, err := parseScheduleDate(date)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if day.Before(marketDate(now)) {
+		return nil, fmt.Errorf("%w: дата %s уже прошла", ErrInvalidSchedule, date)
+	}
+
+	exception := models.SupplierScheduleException{
+		Date:   day.Format("2006-01-02"),
+		Closed: req.Closed,
+		Note:   strings.TrimSpace(req.Note),
+	}
+	if !req.Closed {
+		if exception.OpensAt, exception.ClosesAt, err = normalizeOpeningHours(req.OpensAt, req.ClosesAt); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := db.UpsertSupplierScheduleException(supplierID, exception); err != nil {
+		return nil, err
+	}
+	return supplierSchedule(supplierID, now)
+}
+
+// DeleteScheduleException убирает исключение расписания на дату
+func (s *SupplierService) DeleteScheduleException(userID int, date string) error {
+	supplierID, err := supplierIDByUser(userID, models.SupplierRoleOwner, models.SupplierRoleManager)
+	if err != nil {
+		return err
+	}
+	day, err := parseScheduleDate(date)
+	if err != nil {
+		return err
+	}
+	deleted, err := db.DeleteSupplierScheduleException(supplierID, day)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrScheduleExceptionNotFound
+	}
+	return nil
+}
+
+// SetVacation задаёт отпуск поставщика. Отпуск, включающий сегодняшний день, начинается сразу,
+// дальше его начало и конец переключает StartSupplierVacationScheduler.
+func (s *SupplierService) SetVacation(userID int, req models.VacationRequest) (*models.SupplierSchedule, error) {
+	supplierID, err := supplierIDByUser(userID, models.SupplierRoleOwner, models.SupplierRoleManager)
+	if err != nil {
+		return nil, err
+	}
+	startsOn, err := parseScheduleDate(req.StartsOn)
+	if err != nil {
+		return nil, err
+	}
+	endsOn, err := parseScheduleDate(req.EndsOn)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	today := marketDate(now)
+	if endsOn.Before(startsOn) {
+		return nil, fmt.Errorf("%w: отпуск заканчивается раньше, чем начинается", ErrInvalidSchedule)
+	}
+	if endsOn.Before(today) {
+		return nil, fmt.Errorf("%w: отпуск уже закончился", ErrInvalidSchedule)
+	}
+
+	mode := strings.TrimSpace(req.Mode)
+	switch mode {
+	case "":
+		mode = models.VacationModeUnavailable
+	case models.VacationModeUnavailable, models.VacationModeHidden:
+	default:
+		return nil, fmt.Errorf("%w: режим отпуска '%s', ожидается unavailable или hidden", ErrInvalidSchedule, mode)
+	}
+
+	if err := db.SetSupplierVacation(supplierID, startsOn, endsOn, mode, today); err != nil {
+		return nil, err
+	}
+	return supplierSchedule(supplierID, now)
+}
+
+// CancelVacation отменяет отпуск поставщика, продукты сразу снова доступны покупателям
+func (s *SupplierService) CancelVacation(userID int) error {
+	supplierID, err := supplierIDByUser(userID, models.SupplierRoleOwner, models.SupplierRoleManager)
+	if err != nil {
+		return err
+	}
+	return db.ClearSupplierVacation(supplierID)
+}
+
+// StartSupplierVacationScheduler запускает фоновое переключение отпусков поставщиков
+// в начале первого и после последнего дня отпуска
+func StartSupplierVacationScheduler(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			syncSupplierVacations()
+			<-ticker.C
+		}
+	}()
+}
+
+func syncSupplierVacations() {
+	started, ended, err := db.SyncSupplierVacations(marketDate(time.Now()))
+	if err != nil {
+		log.Printf("syncSupplierVacations: %v", err)
+		return
+	}
+	if len(started) > 0 {
+		log.Printf("syncSupplierVacations: поставщики ушли в отпуск: %v", started)
+	}
+	if len(ended) > 0 {
+		log.Printf("syncSupplierVacations: поставщики вернулись из отпуска: %v", ended)
+	}
+}
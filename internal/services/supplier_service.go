@@ -4,7 +4,6 @@ package services
 
 import (
 	"database/sql"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
@@ -32,6 +31,9 @@ func (s *SupplierService) CreateSupplier(supplier *models.Supplier) error {
 	if err != nil {
 		return fmt.Errorf("failed to create supplier: %v", err)
 	}
+	if supplier.UserID != 0 {
+		return db.SetSupplierOwner(supplier.ID, supplier.UserID)
+	}
 	return nil
 }
 
@@ -58,9 +60,33 @@ type UpdateSupplierRequest struct {
 	Category   string `json:"category" binding:"required"`
 }
 
-// MarkPhoneNumberAsVerified обновляет поле is_verified для поставщика
+// MarkPhoneNumberAsVerified обновляет поле is_verified для поставщика.
+// Участие в команде окончательно: участник чужой команды остаётся в ней, отдельный поставщик
+// для него не создаётся. Приглашённый номер принимает приглашение через AcceptInvite.
 func (s *SupplierService) MarkPhoneNumberAsVerified(phoneNumber string, userID int) error {
+	member, err := db.GetSupplierMember(userID)
+	if err != nil {
+		return err
+	}
+	if member == nil {
+		pending, err := db.HasPendingSupplierInvite(userID)
+		if err != nil {
+			return err
+		}
+		if pending {
+			return ErrSupplierInvitePending
+		}
+	}
+
 	supplier, err := s.GetSupplierByPhoneNumber(phoneNumber)
+	if err != nil && err.Error() != "supplier not found" {
+		return fmt.Errorf("Ошибка при получении поставщика: %v", err)
+	}
+	// Повторно подтверждается только номер собственного поставщика владельца
+	if member != nil && (supplier == nil || member.Role != models.SupplierRoleOwner || supplier.ID != member.SupplierID) {
+		log.Printf("MarkPhoneNumberAsVerified: пользователь %d уже состоит в команде поставщика %d", userID, member.SupplierID)
+		return nil
+	}
 	if err != nil {
 		if err.Error() == "supplier not found" {
 			// Если поставщик не найден, создаём новую запись
@@ -84,12 +110,17 @@ func (s *SupplierService) MarkPhoneNumberAsVerified(phoneNumber string, userID i
 		return fmt.Errorf("Не удалось обновить статус поставщика: %v", err)
 	}
 
-	return nil
+	return db.SetSupplierOwner(supplier.ID, userID)
 }
 
 func (s *SupplierService) SendVerificationCode(userID int, phoneNumber string) error {
+	return sendVerificationCode(userID, phoneNumber, "Ваш код подтверждения: ")
+}
+
+// sendVerificationCode отправляет в WhatsApp сообщение с новым кодом подтверждения в конце
+func sendVerificationCode(userID int, phoneNumber string, messagePrefix string) error {
 	code := utils.GenerateSixDigitCode()
-	message := fmt.Sprintf("Ваш код подтверждения: %s", code)
+	message := messagePrefix + code
 
 	// Отправляем сообщение через WhatsApp
 	err := utils.SendTextMessage(message, phoneNumber)
@@ -127,50 +158,42 @@ func (s *SupplierService) ValidateVerificationCode(userID int, code string) bool
 }
 
 func (s *SupplierService) LinkUserToSupplier(phoneNumber string, userID int) error {
-	query := `UPDATE supplier SET user_id = $1 WHERE phone_number = $2`
-	result, err := db.DB.Exec(query, userID, phoneNumber)
-	if err != nil {
-		return fmt.Errorf("Не удалось связать пользователя с поставщиком: %v", err)
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("Ошибка при получении количества затронутых строк: %v", err)
-	}
-
-	if rowsAffected == 0 {
+	query := `UPDATE supplier SET user_id = $1 WHERE phone_number = $2 RETURNING id`
+	var supplierID int
+	err := db.DB.QueryRow(query, userID, phoneNumber).Scan(&supplierID)
+	if err == sql.ErrNoRows {
 		return fmt.Errorf("Поставщик с номером телефона %s не найден", phoneNumber)
+	} else if err != nil {
+		return fmt.Errorf("Не удалось связать пользователя с поставщиком: %v", err)
 	}
 
-	return nil
+	return db.SetSupplierOwner(supplierID, userID)
 }
 
-func (s *SupplierService) UpdateSupplierDetailsByUserID(userID int, marketID int, place string, rowName string, categoryIDs []int) error {
-	err := db.UpdateSupplierDetailsByUserID(userID, marketID, place, rowName, categoryIDs)
+// UpdateSupplierDetailsByUserID закрепляет за поставщиком место на рынке и заменяет его категории.
+// Рынок, ряд и место поставщика берутся из выбранного места.
+func (s *SupplierService) UpdateSupplierDetailsByUserID(userID int, stallID int, categoryIDs []int) error {
+	if _, err := supplierMembership(userID, models.SupplierRoleOwner, models.SupplierRoleManager); err != nil {
+		return err
+	}
+	if _, err := getMarketStall(stallID); err != nil {
+		return err
+	}
+	occupiedBy, err := db.UpdateSupplierDetailsByUserID(userID, stallID, categoryIDs)
 	if err != nil {
 		return fmt.Errorf("could not update supplier details: %v", err)
 	}
+	if occupiedBy != 0 {
+		return fmt.Errorf("%w (поставщик %d)", ErrStallTaken, occupiedBy)
+	}
 	return nil
 }
 
 func (s *SupplierService) GetAllMarkets() ([]models.Market, error) {
-	query := `SELECT id, name FROM market`
-
-	rows, err := db.DB.Query(query)
+	markets, err := db.GetMarkets()
 	if err != nil {
 		return nil, fmt.Errorf("Ошибка при получении рынков: %v", err)
 	}
-	defer rows.Close()
-
-	var markets []models.Market
-	for rows.Next() {
-		var market models.Market
-		if err := rows.Scan(&market.ID, &market.Name); err != nil {
-			return nil, fmt.Errorf("Ошибка при сканировании рынка: %v", err)
-		}
-		markets = append(markets, market)
-	}
-
 	return markets, nil
 }
 
@@ -214,53 +237,14 @@ func (s *SupplierService) GetCategoryByPath(path string) (*models.Category, erro
 	return category, nil
 }
 
-func (s *SupplierService) AddCategory(name, path, imageURL string) (*models.Category, error) {
-	// Проверка уникальности path
-	existingCategory, err := db.GetCategoryByPath(path)
-	if err == nil && existingCategory != nil {
-		return nil, fmt.Errorf("категория с path '%s' уже существует", path)
-	} else if err != nil && err.Error() != "категория не найдена для path "+path {
-		log.Printf("AddCategory: ошибка при проверке существования категории: %v", err)
-		return nil, fmt.Errorf("ошибка при проверке существования категории: %v", err)
-	}
-
-	// Создание новой категории
-	category := &models.Category{
-		Name:     name,
-		Path:     path,
-		ImageURL: imageURL,
-	}
-
-	err = db.CreateCategory(category)
+// AddCategory создаёт категорию под родителем parentID, nil — корневую
+func (s *SupplierService) AddCategory(name string, parentID *int, imageURL string) (*models.Category, error) {
+	// Path строится из path родителя и транслитерации названия
+	category, err := createCategory(parentID, name, "", imageURL)
 	if err != nil {
 		log.Printf("AddCategory: ошибка при создании категории: %v", err)
-		return nil, fmt.Errorf("ошибка при создании категории: %v", err)
+		return nil, err
 	}
-
-	// Добавляем обязательный атрибут "Цвет"
-	mandatoryAttribute := models.CategoryAttribute{
-		CategoryID:   category.ID,
-		Name:         "Цвет",
-		Description:  StringPtr("Выберите цвет товара"),
-		TypeOfOption: StringPtr("dropdown"),
-	}
-
-	// Значения атрибута "Цвет"
-	colorValues := []string{"Красный", "Синий", "Зеленый"}
-	valueJSON, err := json.Marshal(colorValues)
-	if err != nil {
-		log.Printf("AddCategory: ошибка при сериализации значений цвета: %v", err)
-		return nil, fmt.Errorf("ошибка при сериализации значений цвета: %v", err)
-	}
-	mandatoryAttribute.Value = valueJSON
-
-	// Создание обязательного атрибута для новой категории
-	_, err = db.CreateCategoryAttribute(&mandatoryAttribute)
-	if err != nil {
-		log.Printf("AddCategory: ошибка при создании обязательного атрибута 'Цвет' для категории %d: %v", category.ID, err)
-		return nil, fmt.Errorf("ошибка при создании обязательного атрибута 'Цвет': %v", err)
-	}
-
 	return category, nil
 }
 
@@ -285,3 +269,8 @@ func (s *SupplierService) GetCategoriesBySupplierID(supplierID int) ([]models.Ca
 func (s *SupplierService) UpdateCategoryImageURL(categoryID int, imageURL string) error {
 	return db.UpdateCategoryImageURL(categoryID, imageURL)
 }
+
+// SaveCategoryImageHash сохраняет перцептивный хэш изображения категории
+func (s *SupplierService) SaveCategoryImageHash(categoryID int, uploaded *models.UploadedImage) error {
+	return recordImageHash(models.ImageOwnerCategory, categoryID, 0, uploaded)
+}
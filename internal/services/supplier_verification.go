@@ -0,0 +1,272 @@
+// internal/services/supplier_verification.go
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/WhyDias/Marketplace/internal/db"
+	"github.com/WhyDias/Marketplace/internal/models"
+	"github.com/WhyDias/Marketplace/internal/utils"
+)
+
+const (
+	// SupplierDocumentLinkTTL время действия ссылки на документ поставщика
+	SupplierDocumentLinkTTL = 15 * time.Minute
+
+	defaultVerificationQueueLimit = 50
+	maxVerificationQueueLimit     = 200
+)
+
+var (
+	ErrSupplierDocumentNotFound     = errors.New("документ не найден")
+	ErrInvalidSupplierDocument      = errors.New("некорректный документ")
+	ErrVerificationPending          = errors.New("документы уже на проверке")
+	ErrVerificationAlreadyApproved  = errors.New("поставщик уже прошёл проверку")
+	ErrVerificationNotPending       = errors.New("поставщик не ожидает проверки")
+	ErrVerificationDocumentsMissing = errors.New("для проверки нужны патент или свидетельство ИНН и договор аренды места")
+	ErrVerificationCommentRequired  = errors.New("укажите причину отказа")
+)
+
+var supplierDocumentTypes = map[string]bool{
+	models.SupplierDocumentPatent:         true,
+	models.SupplierDocumentINNCertificate: true,
+	models.SupplierDocumentStallLease:     true,
+}
+
+// GetVerification возвращает статус проверки поставщика пользователя с документами и историей
+func (s *SupplierService) GetVerification(userID int) (*models.SupplierVerification, error) {
+	supplierID, err := supplierIDByUser(userID, models.SupplierRoleOwner, models.SupplierRoleManager)
+	if err != nil {
+		return nil, err
+	}
+	return getSupplierVerification(supplierID)
+}
+
+// GetSupplierVerification возвращает проверку поставщика для администратора
+func (s *SupplierService) GetSupplierVerification(supplierID int) (*models.SupplierVerification, error) {
+	return getSupplierVerification(supplierID)
+}
+
+// getSupplierVerification собирает статус, документы со ссылками и историю проверки
+func getSupplierVerification(supplierID int) (*models.SupplierVerification, error) {
+	verification, err := db.GetSupplierVerification(supplierID)
+	if err != nil {
+		return nil, err
+	}
+	if verification == nil {
+		return nil, ErrSupplierNotFound
+	}
+
+	if verification.Documents, err = db.GetSupplierDocuments(supplierID); err != nil {
+		return nil, err
+	}
+	for i := range verification.Documents {
+		if err := signSupplierDocument(&verification.Documents[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	if verification.History, err = db.GetVerificationHistory(supplierID); err != nil {
+		return nil, err
+	}
+	return verification, nil
+}
+
+// signSupplierDocument заполняет временную ссылку на документ из закрытого хранилища
+func signSupplierDocument(doc *models.SupplierDocument) error {
+	url, err := utils.PresignPrivateObject(doc.ObjectKey, SupplierDocumentLinkTTL)
+	if err != nil {
+		return err
+	}
+	doc.URL = url
+	return nil
+}
+
+// UploadDocument загружает документ поставщика в закрытое хранилище.
+// Пока заявка на проверке, документы менять нельзя.
+func (s *SupplierService) UploadDocument(userID int, docType string, file *multipart.FileHeader) (*models.SupplierDocument, error) {
+	if !supplierDocumentTypes[docType] {
+		return nil, fmt.Errorf("%w: неизвестный тип документа %q", ErrInvalidSupplierDocument, docType)
+	}
+	supplierID, err := supplierIDByUser(userID, models.SupplierRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+	verification, err := db.GetSupplierVerification(supplierID)
+	if err != nil {
+		return nil, err
+	}
+	if verification == nil {
+		return nil, ErrSupplierNotFound
+	}
+	if verification.Status == models.VerificationStatusPending {
+		return nil, ErrVerificationPending
+	}
+
+	uploaded, err := utils.UploadPrivateDocument(file, fmt.Sprintf("suppliers/%d/documents", supplierID))
+	if err != nil {
+		return nil, fmt.Errorf("не удалось загрузить документ: %w", err)
+	}
+
+	doc := &models.SupplierDocument{
+		SupplierID:  supplierID,
+		DocType:     docType,
+		FileName:    truncateFileName(filepath.Base(file.Filename)),
+		ContentType: uploaded.ContentType,
+		Size:        uploaded.Size,
+		ObjectKey:   uploaded.Key,
+	}
+	if err := db.CreateSupplierDocument(doc); err != nil {
+		return nil, err
+	}
+	if err := signSupplierDocument(doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// truncateFileName обрезает имя файла до размера столбца file_name
+func truncateFileName(name string) string {
+	const maxLength = 255
+	runes := []rune(strings.TrimSpace(name))
+	if len(runes) > maxLength {
+		runes = runes[:maxLength]
+	}
+	return string(runes)
+}
+
+// DeleteDocument удаляет документ поставщика. Пока заявка на проверке, документы менять нельзя.
+func (s *SupplierService) DeleteDocument(userID int, documentID int) error {
+	supplierID, err := supplierIDByUser(userID, models.SupplierRoleOwner)
+	if err != nil {
+		return err
+	}
+	doc, err := db.GetSupplierDocument(documentID)
+	if err != nil {
+		return err
+	}
+	if doc == nil || doc.SupplierID != supplierID {
+		return ErrSupplierDocumentNotFound
+	}
+	verification, err := db.GetSupplierVerification(supplierID)
+	if err != nil {
+		return err
+	}
+	if verification.Status == models.VerificationStatusPending {
+		return ErrVerificationPending
+	}
+
+	if err := db.DeleteSupplierDocument(documentID); err != nil {
+		return err
+	}
+	// Запись уже удалена, поэтому оставшийся в хранилище файл не мешает работе
+	if err := utils.DeletePrivateObject(doc.ObjectKey); err != nil {
+		log.Printf("DeleteDocument: не удалось удалить файл документа %d: %v", documentID, err)
+	}
+	return nil
+}
+
+// SubmitVerification отправляет документы поставщика на проверку
+func (s *SupplierService) SubmitVerification(userID int) (*models.SupplierVerification, error) {
+	supplierID, err := supplierIDByUser(userID, models.SupplierRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	types, err := db.GetSupplierDocumentTypes(supplierID)
+	if err != nil {
+		return nil, err
+	}
+	uploaded := make(map[string]bool, len(types))
+	for _, docType := range types {
+		uploaded[docType] = true
+	}
+	hasTaxDocument := uploaded[models.SupplierDocumentPatent] || uploaded[models.SupplierDocumentINNCertificate]
+	if !hasTaxDocument || !uploaded[models.SupplierDocumentStallLease] {
+		return nil, ErrVerificationDocumentsMissing
+	}
+
+	from, changed, err := db.ChangeVerificationStatus(supplierID,
+		[]string{models.VerificationStatusNone, models.VerificationStatusRejected},
+		models.VerificationStatusPending, 0, "")
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		if from == models.VerificationStatusApproved {
+			return nil, ErrVerificationAlreadyApproved
+		}
+		return nil, ErrVerificationPending
+	}
+	return getSupplierVerification(supplierID)
+}
+
+// GetVerificationQueue возвращает поставщиков, ожидающих проверки, начиная с самых давних заявок
+func (s *SupplierService) GetVerificationQueue(limit, offset int) (*models.VerificationQueue, error) {
+	if limit <= 0 {
+		limit = defaultVerificationQueueLimit
+	} else if limit > maxVerificationQueueLimit {
+		limit = maxVerificationQueueLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	total, err := db.CountVerificationQueue()
+	if err != nil {
+		return nil, err
+	}
+	items, err := db.GetVerificationQueue(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.VerificationQueue{
+		Items:  items,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}, nil
+}
+
+// ApproveVerification подтверждает заявку поставщика, ожидающую проверки
+func (s *SupplierService) ApproveVerification(supplierID int, adminID int, comment string) (*models.SupplierVerification, error) {
+	return decideVerification(supplierID, adminID, models.VerificationStatusApproved, strings.TrimSpace(comment),
+		[]string{models.VerificationStatusPending})
+}
+
+// RejectVerification отклоняет заявку поставщика или снимает отметку о проверке. Причина обязательна.
+func (s *SupplierService) RejectVerification(supplierID int, adminID int, comment string) (*models.SupplierVerification, error) {
+	comment = strings.TrimSpace(comment)
+	if comment == "" {
+		return nil, ErrVerificationCommentRequired
+	}
+	return decideVerification(supplierID, adminID, models.VerificationStatusRejected, comment,
+		[]string{models.VerificationStatusPending, models.VerificationStatusApproved})
+}
+
+func decideVerification(supplierID int, adminID int, toStatus string, comment string, allowedFrom []string) (*models.SupplierVerification, error) {
+	supplier, err := db.GetSupplierByID(supplierID)
+	if err != nil {
+		return nil, err
+	}
+	if supplier == nil {
+		return nil, ErrSupplierNotFound
+	}
+
+	_, changed, err := db.ChangeVerificationStatus(supplierID, allowedFrom, toStatus, adminID, comment)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return nil, ErrVerificationNotPending
+	}
+	return getSupplierVerification(supplierID)
+}
@@ -0,0 +1,533 @@
+// internal/services/taxonomy_service.go
+
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/WhyDias/Marketplace/internal/db"
+	"github.com/WhyDias/Marketplace/internal/models"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	ErrInvalidTaxonomy           = errors.New("некорректный документ таксономии")
+	ErrUnsupportedTaxonomyFormat = errors.New("формат таксономии должен быть yaml или json")
+	ErrTaxonomyConflicts         = errors.New("таксономию нельзя импортировать: есть конфликты")
+)
+
+// TaxonomyService выгружает дерево категорий с атрибутами и загружает его в другое окружение
+type TaxonomyService struct{}
+
+func NewTaxonomyService() *TaxonomyService {
+	return &TaxonomyService{}
+}
+
+// Export выгружает все категории с собственными атрибутами и скрытыми унаследованными атрибутами
+func (s *TaxonomyService) Export() (*models.TaxonomyDocument, error) {
+	categories, err := db.GetAllCategories()
+	if err != nil {
+		return nil, err
+	}
+	attributes, err := db.GetAllCategoryAttributes()
+	if err != nil {
+		return nil, err
+	}
+	hidden, err := db.GetAllHiddenAttributes()
+	if err != nil {
+		return nil, err
+	}
+
+	byCategory := make(map[int][]models.TaxonomyAttribute)
+	for _, attr := range attributes {
+		byCategory[attr.CategoryID] = append(byCategory[attr.CategoryID], taxonomyAttribute(attr))
+	}
+
+	doc := &models.TaxonomyDocument{Version: models.TaxonomyVersion, Categories: []models.TaxonomyCategory{}}
+	for _, category := range categories {
+		doc.Categories = append(doc.Categories, models.TaxonomyCategory{
+			Path:             category.Path,
+			Name:             category.Name,
+			ImageURL:         category.ImageURL,
+			Attributes:       byCategory[category.ID],
+			HiddenAttributes: hidden[category.ID],
+		})
+	}
+	return doc, nil
+}
+
+func taxonomyAttribute(attr models.CategoryAttribute) models.TaxonomyAttribute {
+	result := models.TaxonomyAttribute{
+		Name:         attr.Name,
+		IsLinked:     attr.IsLinked,
+		Dimension:    attr.Dimension,
+		Unit:         attr.Unit,
+		IsRequired:   attr.IsRequired,
+		SortOrder:    attr.SortOrder,
+		IsFilterable: attr.IsFilterable,
+	}
+	if attr.Description != nil {
+		result.Description = *attr.Description
+	}
+	if attr.TypeOfOption != nil {
+		result.TypeOfOption = *attr.TypeOfOption
+	}
+	if len(attr.Value) > 0 {
+		if err := json.Unmarshal(attr.Value, &result.Value); err != nil {
+			log.Printf("Export: некорректное значение атрибута %d: %v", attr.ID, err)
+		}
+	}
+	return result
+}
+
+// MarshalTaxonomy кодирует документ таксономии в yaml или json
+func MarshalTaxonomy(doc *models.TaxonomyDocument, format string) ([]byte, error) {
+	switch format {
+	case models.TaxonomyFormatYAML:
+		return yaml.Marshal(doc)
+	case models.TaxonomyFormatJSON:
+		return json.MarshalIndent(doc, "", "  ")
+	default:
+		return nil, ErrUnsupportedTaxonomyFormat
+	}
+}
+
+// ParseTaxonomy разбирает документ таксономии в yaml или json. Неизвестные поля считаются ошибкой,
+// чтобы опечатка в названии поля не превращалась в молчаливое удаление значения.
+func ParseTaxonomy(data []byte, format string) (*models.TaxonomyDocument, error) {
+	var doc models.TaxonomyDocument
+	switch format {
+	case models.TaxonomyFormatYAML:
+		if err := yaml.UnmarshalStrict(data, &doc); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTaxonomy, err)
+		}
+		for i := range doc.Categories {
+			for j := range doc.Categories[i].Attributes {
+				attr := &doc.Categories[i].Attributes[j]
+				attr.Value = normalizeYAMLValue(attr.Value)
+			}
+		}
+	case models.TaxonomyFormatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTaxonomy, err)
+		}
+	default:
+		return nil, ErrUnsupportedTaxonomyFormat
+	}
+	return &doc, nil
+}
+
+// normalizeYAMLValue приводит числа из yaml к float64, как их декодирует encoding/json
+func normalizeYAMLValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case []interface{}:
+		for i := range v {
+			v[i] = normalizeYAMLValue(v[i])
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+// taxonomyCategoryPlan изменения одной категории документа
+type taxonomyCategoryPlan struct {
+	doc              models.TaxonomyCategory
+	existing         *models.Category
+	rename           bool
+	changeImage      bool
+	attributes       []attributeChange
+	hide             []string
+	unhide           []string
+	deleteAttributes []models.CategoryAttribute
+}
+
+// Import сравнивает документ с текущей таксономией по path категорий и названиям атрибутов
+// и, если dryRun не задан, применяет разницу. Категории, атрибуты и скрытия, которых нет
+// в документе, удаляются только с prune; категорию с продуктами или поставщиками и атрибут
+// со значениями у продуктов удалить нельзя. Конфликты отменяют импорт целиком, до записи.
+// Изменения записываются по очереди, поэтому после сбоя импорт можно просто повторить.
+func (s *TaxonomyService) Import(userID int, doc *models.TaxonomyDocument, dryRun, prune bool) (*models.TaxonomyDiff, error) {
+	if err := validateTaxonomy(doc); err != nil {
+		return nil, err
+	}
+
+	categories, err := db.GetAllCategories()
+	if err != nil {
+		return nil, err
+	}
+	attributes, err := db.GetAllCategoryAttributes()
+	if err != nil {
+		return nil, err
+	}
+	hidden, err := db.GetAllHiddenAttributes()
+	if err != nil {
+		return nil, err
+	}
+
+	existingByPath := make(map[string]*models.Category, len(categories))
+	for i := range categories {
+		existingByPath[categories[i].Path] = &categories[i]
+	}
+	attributesByCategory := make(map[int][]models.CategoryAttribute)
+	for _, attr := range attributes {
+		attributesByCategory[attr.CategoryID] = append(attributesByCategory[attr.CategoryID], attr)
+	}
+	docPaths := make(map[string]bool, len(doc.Categories))
+	for _, category := range doc.Categories {
+		docPaths[category.Path] = true
+	}
+
+	// Родители создаются раньше подкатегорий
+	ordered := append([]models.TaxonomyCategory(nil), doc.Categories...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return pathDepth(ordered[i].Path) < pathDepth(ordered[j].Path)
+	})
+
+	diff := &models.TaxonomyDiff{Changes: []models.TaxonomyChange{}}
+	plans := make([]*taxonomyCategoryPlan, 0, len(ordered))
+	for _, category := range ordered {
+		if parent := parentPath(category.Path); parent != "" && !docPaths[parent] && existingByPath[parent] == nil {
+			return nil, fmt.Errorf("%w: нет родительской категории %s для %s", ErrInvalidTaxonomy, parent, category.Path)
+		}
+		plan, err := planTaxonomyCategory(category, existingByPath[category.Path], attributesByCategory, hidden, prune, diff)
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, plan)
+	}
+
+	var deleteCategories []models.Category
+	if prune {
+		deleteCategories, err = planCategoryRemovals(categories, docPaths, diff)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(diff.Conflicts) > 0 {
+		if dryRun {
+			return diff, nil
+		}
+		return diff, ErrTaxonomyConflicts
+	}
+	if dryRun {
+		return diff, nil
+	}
+
+	if err := applyTaxonomy(userID, plans, deleteCategories); err != nil {
+		return nil, err
+	}
+	diff.Applied = true
+	log.Printf("Import: таксономия импортирована пользователем %d: создано %d, изменено %d, удалено %d",
+		userID, diff.Created, diff.Updated, diff.Deleted)
+	return diff, nil
+}
+
+// validateTaxonomy проверяет документ до сравнения с базой
+func validateTaxonomy(doc *models.TaxonomyDocument) error {
+	if doc.Version > models.TaxonomyVersion {
+		return fmt.Errorf("%w: неподдерживаемая версия %d", ErrInvalidTaxonomy, doc.Version)
+	}
+	if len(doc.Categories) == 0 {
+		return fmt.Errorf("%w: документ не содержит категорий", ErrInvalidTaxonomy)
+	}
+
+	paths := make(map[string]bool, len(doc.Categories))
+	for _, category := range doc.Categories {
+		for _, label := range strings.Split(category.Path, ".") {
+			if !categoryLabelPattern.MatchString(label) {
+				return fmt.Errorf("%w: path %q: %v", ErrInvalidTaxonomy, category.Path, ErrInvalidCategoryLabel)
+			}
+		}
+		if paths[category.Path] {
+			return fmt.Errorf("%w: категория %s указана дважды", ErrInvalidTaxonomy, category.Path)
+		}
+		paths[category.Path] = true
+		if strings.TrimSpace(category.Name) == "" {
+			return fmt.Errorf("%w: у категории %s нет названия", ErrInvalidTaxonomy, category.Path)
+		}
+
+		names := make(map[string]bool, len(category.Attributes))
+		for _, attr := range category.Attributes {
+			if strings.TrimSpace(attr.Name) == "" {
+				return fmt.Errorf("%w: у атрибута категории %s нет названия", ErrInvalidTaxonomy, category.Path)
+			}
+			if names[attr.Name] {
+				return fmt.Errorf("%w: атрибут '%s' указан в категории %s дважды", ErrInvalidTaxonomy, attr.Name, category.Path)
+			}
+			names[attr.Name] = true
+		}
+	}
+	return nil
+}
+
+// planTaxonomyCategory сравнивает категорию документа с существующей и дописывает изменения в diff
+func planTaxonomyCategory(category models.TaxonomyCategory, existing *models.Category,
+	attributesByCategory map[int][]models.CategoryAttribute, hidden map[int][]string, prune bool,
+	diff *models.TaxonomyDiff) (*taxonomyCategoryPlan, error) {
+
+	plan := &taxonomyCategoryPlan{doc: category, existing: existing}
+	categoryID := 0
+	if existing == nil {
+		addTaxonomyChange(diff, models.TaxonomyActionCreate, models.TaxonomyKindCategory, category.Path, "", category.Name)
+	} else {
+		categoryID = existing.ID
+		var details []string
+		if existing.Name != category.Name {
+			plan.rename = true
+			details = append(details, fmt.Sprintf("Название: %s → %s", existing.Name, category.Name))
+		}
+		if existing.ImageURL != category.ImageURL {
+			plan.changeImage = true
+			details = append(details, "Изменено изображение")
+		}
+		if len(details) > 0 {
+			addTaxonomyChange(diff, models.TaxonomyActionUpdate, models.TaxonomyKindCategory, category.Path, "", strings.Join(details, "; "))
+		}
+	}
+
+	docAttributes := make(map[string]bool, len(category.Attributes))
+	for _, attr := range category.Attributes {
+		docAttributes[attr.Name] = true
+		req := models.AttributeRequest{
+			Name:         attr.Name,
+			Description:  attr.Description,
+			TypeOfOption: attr.TypeOfOption,
+			Value:        attr.Value,
+			IsLinked:     attr.IsLinked,
+			Dimension:    attr.Dimension,
+			Unit:         attr.Unit,
+		}
+		changes, err := planCategoryAttributes(categoryID, []models.AttributeRequest{req})
+		if err != nil {
+			if isTaxonomyConflict(err) {
+				diff.Conflicts = append(diff.Conflicts, fmt.Sprintf("%s, атрибут '%s': %v", category.Path, attr.Name, err))
+				continue
+			}
+			return nil, fmt.Errorf("%w: %s, атрибут '%s': %v", ErrInvalidTaxonomy, category.Path, attr.Name, err)
+		}
+		change := changes[0]
+		flagDetails := planTaxonomyFlags(&change, attr)
+		switch {
+		case change.existing == nil:
+			addTaxonomyChange(diff, models.TaxonomyActionCreate, models.TaxonomyKindAttribute, category.Path, attr.Name, attr.TypeOfOption)
+		case change.summary != "" || len(flagDetails) > 0:
+			details := flagDetails
+			if change.summary != "" {
+				details = append([]string{change.summary}, flagDetails...)
+			}
+			addTaxonomyChange(diff, models.TaxonomyActionUpdate, models.TaxonomyKindAttribute, category.Path, attr.Name, strings.Join(details, "; "))
+		default:
+			continue
+		}
+		plan.attributes = append(plan.attributes, change)
+	}
+
+	var currentHidden []string
+	if existing != nil {
+		currentHidden = hidden[existing.ID]
+	}
+	hiddenSet, docHidden := stringSet(currentHidden), stringSet(category.HiddenAttributes)
+	for _, name := range category.HiddenAttributes {
+		if !hiddenSet[name] {
+			plan.hide = append(plan.hide, name)
+			addTaxonomyChange(diff, models.TaxonomyActionCreate, models.TaxonomyKindHiddenAttribute, category.Path, name, "")
+		}
+	}
+
+	if !prune || existing == nil {
+		return plan, nil
+	}
+
+	for _, name := range currentHidden {
+		if !docHidden[name] {
+			plan.unhide = append(plan.unhide, name)
+			addTaxonomyChange(diff, models.TaxonomyActionDelete, models.TaxonomyKindHiddenAttribute, category.Path, name, "")
+		}
+	}
+	for _, attr := range attributesByCategory[existing.ID] {
+		if docAttributes[attr.Name] {
+			continue
+		}
+		usage, err := db.CountAttributeUsage(attr.ID)
+		if err != nil {
+			return nil, err
+		}
+		if usage > 0 {
+			diff.Conflicts = append(diff.Conflicts,
+				fmt.Sprintf("%s, атрибут '%s': нельзя удалить, значения сохранены у продуктов (%d)", category.Path, attr.Name, usage))
+			continue
+		}
+		plan.deleteAttributes = append(plan.deleteAttributes, attr)
+		addTaxonomyChange(diff, models.TaxonomyActionDelete, models.TaxonomyKindAttribute, category.Path, attr.Name, "")
+	}
+	return plan, nil
+}
+
+// planTaxonomyFlags заполняет флаги атрибута, отличающиеся от текущих, и возвращает их описание
+func planTaxonomyFlags(change *attributeChange, attr models.TaxonomyAttribute) []string {
+	var current models.CategoryAttribute
+	if change.existing != nil {
+		current = *change.existing
+	}
+
+	var details []string
+	if attr.IsRequired != current.IsRequired {
+		change.flags.IsRequired = &attr.IsRequired
+		details = append(details, fmt.Sprintf("Обязательный: %s", yesNo(attr.IsRequired)))
+	}
+	if attr.SortOrder != current.SortOrder {
+		change.flags.SortOrder = &attr.SortOrder
+		details = append(details, fmt.Sprintf("Порядок: %d → %d", current.SortOrder, attr.SortOrder))
+	}
+	if attr.IsFilterable != current.IsFilterable {
+		change.flags.IsFilterable = &attr.IsFilterable
+		details = append(details, fmt.Sprintf("В фильтрах: %s", yesNo(attr.IsFilterable)))
+	}
+	return details
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "да"
+	}
+	return "нет"
+}
+
+// planCategoryRemovals отбирает категории, которых нет в документе, начиная с самых глубоких
+func planCategoryRemovals(categories []models.Category, docPaths map[string]bool, diff *models.TaxonomyDiff) ([]models.Category, error) {
+	var removals []models.Category
+	for _, category := range categories {
+		if docPaths[category.Path] {
+			continue
+		}
+		keptDescendant := ""
+		for path := range docPaths {
+			if isInSubtree(path, category.Path) {
+				keptDescendant = path
+				break
+			}
+		}
+		if keptDescendant != "" {
+			diff.Conflicts = append(diff.Conflicts,
+				fmt.Sprintf("%s: категории нет в документе, но её подкатегория %s есть", category.Path, keptDescendant))
+			continue
+		}
+
+		products, suppliers, err := db.CountCategoryUsage(category.ID)
+		if err != nil {
+			return nil, err
+		}
+		if products > 0 || suppliers > 0 {
+			diff.Conflicts = append(diff.Conflicts,
+				fmt.Sprintf("%s: нельзя удалить категорию с продуктами (%d) или поставщиками (%d)", category.Path, products, suppliers))
+			continue
+		}
+		removals = append(removals, category)
+	}
+
+	sort.SliceStable(removals, func(i, j int) bool {
+		return pathDepth(removals[i].Path) > pathDepth(removals[j].Path)
+	})
+	for _, category := range removals {
+		addTaxonomyChange(diff, models.TaxonomyActionDelete, models.TaxonomyKindCategory, category.Path, "", category.Name)
+	}
+	return removals, nil
+}
+
+// applyTaxonomy записывает спланированные изменения
+func applyTaxonomy(userID int, plans []*taxonomyCategoryPlan, deleteCategories []models.Category) error {
+	for _, plan := range plans {
+		category := plan.existing
+		if category == nil {
+			category = &models.Category{Name: plan.doc.Name, Path: plan.doc.Path, ImageURL: plan.doc.ImageURL}
+			if err := db.CreateCategory(category); err != nil {
+				return err
+			}
+		} else {
+			if plan.rename {
+				if _, err := db.RenameCategory(category.ID, plan.doc.Name); err != nil {
+					return err
+				}
+			}
+			if plan.changeImage {
+				if err := db.UpdateCategoryImageURL(category.ID, plan.doc.ImageURL); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := applyAttributeChanges(category.ID, userID, plan.attributes); err != nil {
+			return fmt.Errorf("категория %s: %w", category.Path, err)
+		}
+		for _, attr := range plan.deleteAttributes {
+			if err := db.DeleteAttribute(attr.ID); err != nil {
+				return fmt.Errorf("категория %s, атрибут '%s': %w", category.Path, attr.Name, err)
+			}
+		}
+		for _, name := range plan.hide {
+			if err := db.HideCategoryAttribute(category.ID, name); err != nil {
+				return err
+			}
+		}
+		for _, name := range plan.unhide {
+			if _, err := db.UnhideCategoryAttribute(category.ID, name); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, category := range deleteCategories {
+		if err := db.DeleteEmptyCategory(category.ID); err != nil {
+			return fmt.Errorf("категория %s: %w", category.Path, err)
+		}
+	}
+	return nil
+}
+
+// isTaxonomyConflict ошибки изменения атрибута, которые мешают импорту из-за данных продуктов
+func isTaxonomyConflict(err error) bool {
+	var optionsInUse *AttributeOptionsInUseError
+	return errors.As(err, &optionsInUse) ||
+		errors.Is(err, ErrAttributeTypeChangeInUse) ||
+		errors.Is(err, ErrAttributeUnitChangeInUse)
+}
+
+func addTaxonomyChange(diff *models.TaxonomyDiff, action, kind, path, name, details string) {
+	switch action {
+	case models.TaxonomyActionCreate:
+		diff.Created++
+	case models.TaxonomyActionUpdate:
+		diff.Updated++
+	case models.TaxonomyActionDelete:
+		diff.Deleted++
+	}
+	diff.Changes = append(diff.Changes, models.TaxonomyChange{Action: action, Kind: kind, Path: path, Name: name, Details: details})
+}
+
+func pathDepth(path string) int {
+	return strings.Count(path, ".") + 1
+}
+
+// parentPath возвращает path родителя, пустую строку для корневой категории
+func parentPath(path string) string {
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
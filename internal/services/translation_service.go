@@ -0,0 +1,487 @@
+// internal/services/translation_service.go
+
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/WhyDias/Marketplace/internal/db"
+	"github.com/WhyDias/Marketplace/internal/models"
+)
+
+var (
+	ErrUnsupportedLanguage       = errors.New("язык не поддерживается, доступны ky и en")
+	ErrUnknownTranslationEntity  = errors.New("неизвестный вид записи каталога")
+	ErrTranslationTargetNotFound = errors.New("запись каталога не найдена")
+	ErrInvalidTranslation        = errors.New("некорректный перевод")
+	ErrTranslationNotFound       = errors.New("перевод не найден")
+)
+
+// missingTranslationsLimit сколько записей без перевода возвращается по каждому виду записей
+const missingTranslationsLimit = 200
+
+type TranslationService struct{}
+
+func NewTranslationService() *TranslationService {
+	return &TranslationService{}
+}
+
+// ResolveLanguage выбирает язык ответа: параметр lang, затем заголовок Accept-Language
+// с учётом весов q. Если ни один язык не поддерживается, используется русский.
+func ResolveLanguage(lang, acceptLanguage string) string {
+	if l := supportedLanguage(lang); l != "" {
+		return l
+	}
+
+	type candidate struct {
+		lang string
+		q    float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if l := supportedLanguage(tag); l != "" && q > 0 {
+			candidates = append(candidates, candidate{l, q})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	if len(candidates) > 0 {
+		return candidates[0].lang
+	}
+	return models.DefaultLanguage
+}
+
+// supportedLanguage возвращает код языка каталога для тега вида "ky-KG", пустую строку для остальных
+func supportedLanguage(tag string) string {
+	primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+	primary, _, _ = strings.Cut(primary, "_")
+	switch primary {
+	case models.LangRussian, models.LangKyrgyz, models.LangEnglish:
+		return primary
+	}
+	return ""
+}
+
+// translationEntities виды записей каталога, которые можно переводить
+var translationEntities = []string{
+	models.TranslationCategory,
+	models.TranslationAttribute,
+	models.TranslationAttributeOption,
+	models.TranslationProduct,
+}
+
+func isTranslationEntity(entity string) bool {
+	for _, e := range translationEntities {
+		if e == entity {
+			return true
+		}
+	}
+	return false
+}
+
+func isTranslationLanguage(lang string) bool {
+	for _, l := range models.TranslationLanguages {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTranslationTarget проверяет, что запись каталога существует, и возвращает атрибут для значений атрибута
+func checkTranslationTarget(entity string, id int) (*models.CategoryAttribute, error) {
+	switch entity {
+	case models.TranslationCategory:
+		category, err := db.GetCategoryByID(id)
+		if err != nil {
+			return nil, err
+		}
+		if category == nil {
+			return nil, ErrTranslationTargetNotFound
+		}
+	case models.TranslationAttribute, models.TranslationAttributeOption:
+		attribute, err := db.GetCategoryAttributeByID(id)
+		if err != nil {
+			return nil, err
+		}
+		if attribute == nil {
+			return nil, ErrTranslationTargetNotFound
+		}
+		return attribute, nil
+	case models.TranslationProduct:
+		statusID, err := db.GetProductStatusID(id)
+		if err != nil {
+			return nil, err
+		}
+		if statusID == 0 {
+			return nil, ErrTranslationTargetNotFound
+		}
+	default:
+		return nil, ErrUnknownTranslationEntity
+	}
+	return nil, nil
+}
+
+// SaveTranslation создаёт или заменяет перевод записи каталога
+func (s *TranslationService) SaveTranslation(entity string, id int, lang string, req models.TranslationRequest) error {
+	if !isTranslationLanguage(lang) {
+		return ErrUnsupportedLanguage
+	}
+	attribute, err := checkTranslationTarget(entity, id)
+	if err != nil {
+		return err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	description := strings.TrimSpace(req.Description)
+
+	switch entity {
+	case models.TranslationCategory:
+		if name == "" {
+			return fmt.Errorf("%w: укажите name", ErrInvalidTranslation)
+		}
+		return db.UpsertCategoryTranslation(id, lang, name)
+
+	case models.TranslationAttribute:
+		if name == "" {
+			return fmt.Errorf("%w: укажите name", ErrInvalidTranslation)
+		}
+		return db.UpsertAttributeTranslation(id, lang, name, description)
+
+	case models.TranslationAttributeOption:
+		value := strings.TrimSpace(req.Value)
+		if req.Option == "" || value == "" {
+			return fmt.Errorf("%w: укажите option и value", ErrInvalidTranslation)
+		}
+		if attribute.TypeOfOption != nil && *attribute.TypeOfOption == "dropdown" {
+			var options []string
+			if err := json.Unmarshal(attribute.Value, &options); err == nil && !stringSet(options)[req.Option] {
+				return fmt.Errorf("%w: у атрибута '%s' нет опции '%s'", ErrInvalidTranslation, attribute.Name, req.Option)
+			}
+		}
+		return db.UpsertAttributeOptionTranslation(id, req.Option, lang, value)
+
+	default: // models.TranslationProduct
+		if name == "" {
+			return fmt.Errorf("%w: укажите name", ErrInvalidTranslation)
+		}
+		return db.UpsertProductTranslation(id, lang, name, description)
+	}
+}
+
+// GetTranslations возвращает все переводы записи каталога
+func (s *TranslationService) GetTranslations(entity string, id int) ([]models.Translation, error) {
+	if _, err := checkTranslationTarget(entity, id); err != nil {
+		return nil, err
+	}
+	return db.GetTranslations(entity, id)
+}
+
+// DeleteTranslation удаляет перевод записи каталога на язык
+func (s *TranslationService) DeleteTranslation(entity string, id int, lang, option string) error {
+	if !isTranslationLanguage(lang) {
+		return ErrUnsupportedLanguage
+	}
+	if !isTranslationEntity(entity) {
+		return ErrUnknownTranslationEntity
+	}
+	deleted, err := db.DeleteTranslation(entity, id, lang, option)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrTranslationNotFound
+	}
+	return nil
+}
+
+// GetMissingTranslations возвращает записи каталога без перевода на язык.
+// Пустой entity означает все виды записей.
+func (s *TranslationService) GetMissingTranslations(lang, entity string) (*models.MissingTranslationsReport, error) {
+	if !isTranslationLanguage(lang) {
+		return nil, ErrUnsupportedLanguage
+	}
+
+	entities := translationEntities
+	if entity != "" {
+		if !isTranslationEntity(entity) {
+			return nil, ErrUnknownTranslationEntity
+		}
+		entities = []string{entity}
+	}
+
+	report := &models.MissingTranslationsReport{
+		Lang:   lang,
+		Counts: make(map[string]int, len(entities)),
+		Items:  []models.MissingTranslation{},
+	}
+	for _, e := range entities {
+		items, total, err := db.GetMissingTranslations(e, lang, missingTranslationsLimit)
+		if err != nil {
+			return nil, err
+		}
+		report.Counts[e] = total
+		report.Items = append(report.Items, items...)
+	}
+	return report, nil
+}
+
+// LocalizeCategories подставляет переводы названий категорий. Без перевода остаётся русское название.
+func (s *TranslationService) LocalizeCategories(lang string, categories []models.Category) {
+	if lang == models.DefaultLanguage || len(categories) == 0 {
+		return
+	}
+	ids := make([]int, len(categories))
+	for i, c := range categories {
+		ids[i] = c.ID
+	}
+	names, err := db.GetCategoryTranslations(ids, lang)
+	if err != nil {
+		log.Printf("LocalizeCategories: %v", err)
+		return
+	}
+	for i := range categories {
+		if name, ok := names[categories[i].ID]; ok {
+			categories[i].Name = name
+		}
+	}
+}
+
+// LocalizeCategoryTree подставляет переводы названий во всё дерево категорий
+func (s *TranslationService) LocalizeCategoryTree(lang string, nodes []models.CategoryNode) {
+	if lang == models.DefaultLanguage || len(nodes) == 0 {
+		return
+	}
+	var ids []int
+	var collect func([]models.CategoryNode)
+	collect = func(list []models.CategoryNode) {
+		for _, n := range list {
+			ids = append(ids, n.ID)
+			collect(n.Children)
+		}
+	}
+	collect(nodes)
+
+	names, err := db.GetCategoryTranslations(ids, lang)
+	if err != nil {
+		log.Printf("LocalizeCategoryTree: %v", err)
+		return
+	}
+	var apply func([]models.CategoryNode)
+	apply = func(list []models.CategoryNode) {
+		for i := range list {
+			if name, ok := names[list[i].ID]; ok {
+				list[i].Name = name
+			}
+			apply(list[i].Children)
+		}
+	}
+	apply(nodes)
+}
+
+// attributeTranslations загружает переводы атрибутов и их значений
+func attributeTranslations(lang string, ids []int) (map[int]models.Translation, map[int]map[string]string, bool) {
+	attributes, err := db.GetAttributeTranslations(ids, lang)
+	if err != nil {
+		log.Printf("attributeTranslations: %v", err)
+		return nil, nil, false
+	}
+	options, err := db.GetAttributeOptionTranslations(ids, lang)
+	if err != nil {
+		log.Printf("attributeTranslations: %v", err)
+		return nil, nil, false
+	}
+	return attributes, options, true
+}
+
+// translateOptions переводит список опций dropdown, непереведённые опции остаются как есть
+func translateOptions(value json.RawMessage, translations map[string]string) json.RawMessage {
+	if len(translations) == 0 {
+		return value
+	}
+	var options []string
+	if err := json.Unmarshal(value, &options); err != nil {
+		return value
+	}
+	for i, option := range options {
+		if translated, ok := translations[option]; ok {
+			options[i] = translated
+		}
+	}
+	translated, err := json.Marshal(options)
+	if err != nil {
+		return value
+	}
+	return translated
+}
+
+// LocalizeAttributes подставляет переводы названий, описаний и опций атрибутов
+func (s *TranslationService) LocalizeAttributes(lang string, attributes []models.Attribute) {
+	if lang == models.DefaultLanguage || len(attributes) == 0 {
+		return
+	}
+	ids := make([]int, len(attributes))
+	for i, a := range attributes {
+		ids[i] = a.ID
+	}
+	names, options, ok := attributeTranslations(lang, ids)
+	if !ok {
+		return
+	}
+	for i := range attributes {
+		a := &attributes[i]
+		if t, ok := names[a.ID]; ok {
+			a.Name = t.Name
+			if t.Description != "" {
+				a.Description = t.Description
+			}
+		}
+		if a.TypeOfOption == "dropdown" {
+			a.Value = translateOptions(a.Value, options[a.ID])
+		}
+	}
+}
+
+// LocalizeCategoryAttributes подставляет переводы в атрибуты категории
+func (s *TranslationService) LocalizeCategoryAttributes(lang string, attributes []models.CategoryAttribute) {
+	if lang == models.DefaultLanguage || len(attributes) == 0 {
+		return
+	}
+	ids := make([]int, len(attributes))
+	for i, a := range attributes {
+		ids[i] = a.ID
+	}
+	names, options, ok := attributeTranslations(lang, ids)
+	if !ok {
+		return
+	}
+	for i := range attributes {
+		a := &attributes[i]
+		if t, ok := names[a.ID]; ok {
+			a.Name = t.Name
+			if t.Description != "" {
+				a.Description = StringPtr(t.Description)
+			}
+		}
+		if a.TypeOfOption != nil && *a.TypeOfOption == "dropdown" {
+			a.Value = translateOptions(a.Value, options[a.ID])
+		}
+	}
+}
+
+// LocalizeAttributeResponses подставляет переводы в атрибуты категории для формы поставщика
+func (s *TranslationService) LocalizeAttributeResponses(lang string, attributes []models.CategoryAttributeResponse) {
+	if lang == models.DefaultLanguage || len(attributes) == 0 {
+		return
+	}
+	ids := make([]int, len(attributes))
+	for i, a := range attributes {
+		ids[i] = a.ID
+	}
+	names, options, ok := attributeTranslations(lang, ids)
+	if !ok {
+		return
+	}
+	for i := range attributes {
+		a := &attributes[i]
+		if t, ok := names[a.ID]; ok {
+			a.Name = t.Name
+			if t.Description != "" {
+				a.Description = StringPtr(t.Description)
+			}
+		}
+		if values, ok := a.Value.([]string); ok && len(options[a.ID]) > 0 {
+			translated := make([]string, len(values))
+			for j, v := range values {
+				translated[j] = v
+				if t, ok := options[a.ID][v]; ok {
+					translated[j] = t
+				}
+			}
+			a.Value = translated
+		}
+	}
+}
+
+// LocalizeProductAttributeValues подставляет переводы названий атрибутов и текстовых значений продукта
+func (s *TranslationService) LocalizeProductAttributeValues(lang string, values []models.ProductAttributeValueView) {
+	if lang == models.DefaultLanguage || len(values) == 0 {
+		return
+	}
+	ids := make([]int, len(values))
+	for i, v := range values {
+		ids[i] = v.AttributeID
+	}
+	names, options, ok := attributeTranslations(lang, ids)
+	if !ok {
+		return
+	}
+	for i := range values {
+		v := &values[i]
+		if t, ok := names[v.AttributeID]; ok {
+			v.Name = t.Name
+		}
+		var text string
+		if json.Unmarshal(v.Value, &text) != nil {
+			continue
+		}
+		if translated, ok := options[v.AttributeID][text]; ok {
+			if raw, err := json.Marshal(translated); err == nil {
+				v.Value = raw
+			}
+		}
+	}
+}
+
+// LocalizeProduct подставляет перевод названия и описания продукта
+func (s *TranslationService) LocalizeProduct(lang string, product *models.Product) {
+	if lang == models.DefaultLanguage || product == nil {
+		return
+	}
+	translations, err := db.GetProductTranslations([]int{product.ID}, lang)
+	if err != nil {
+		log.Printf("LocalizeProduct: %v", err)
+		return
+	}
+	if t, ok := translations[product.ID]; ok {
+		product.Name = t.Name
+		if t.Description != "" {
+			product.Description = t.Description
+		}
+	}
+}
+
+// LocalizeProductCards подставляет переводы названий продуктов в списке
+func (s *TranslationService) LocalizeProductCards(lang string, cards []models.ProductCard) {
+	if lang == models.DefaultLanguage || len(cards) == 0 {
+		return
+	}
+	ids := make([]int, len(cards))
+	for i, card := range cards {
+		ids[i] = card.ID
+	}
+	translations, err := db.GetProductTranslations(ids, lang)
+	if err != nil {
+		log.Printf("LocalizeProductCards: %v", err)
+		return
+	}
+	for i := range cards {
+		if t, ok := translations[cards[i].ID]; ok {
+			cards[i].Name = t.Name
+		}
+	}
+}
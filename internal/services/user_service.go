@@ -72,6 +72,12 @@ func (s *UserService) RegisterUser(username, password string, roleNames []string
 	return user, nil
 }
 
+// RegisterPendingUser регистрирует пользователя без пароля. Войти по паролю он не может,
+// пока не задаст пароль после подтверждения номера кодом.
+func (s *UserService) RegisterPendingUser(username string, roleNames []string) (*models.User, error) {
+	return s.RegisterUser(username, "", roleNames)
+}
+
 // AuthenticateUser аутентифицирует пользователя по имени пользователя и паролю
 func (s *UserService) AuthenticateUser(username, password string) (*models.User, error) {
 	// Получаем пользователя из базы данных по имени пользователя
@@ -82,6 +88,9 @@ func (s *UserService) AuthenticateUser(username, password string) (*models.User,
 	if user == nil {
 		return nil, errors.New("пользователь не найден")
 	}
+	if user.PasswordHash == "" {
+		return nil, errors.New("пароль не задан")
+	}
 
 	// Сравниваем хеш пароля из базы данных с введённым паролем
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
@@ -0,0 +1,26 @@
+// internal/utils/color.go
+
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+var hexColorPattern = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+// NormalizeHexColor приводит цвет к виду #rrggbb в нижнем регистре. Допускается запись без #
+// и короткая запись #rgb. Возвращает false для некорректного цвета.
+func NormalizeHexColor(s string) (string, bool) {
+	hex := strings.ToLower(strings.TrimSpace(s))
+	if !strings.HasPrefix(hex, "#") {
+		hex = "#" + hex
+	}
+	if len(hex) == 4 {
+		hex = string([]byte{'#', hex[1], hex[1], hex[2], hex[2], hex[3], hex[3]})
+	}
+	if !hexColorPattern.MatchString(hex) {
+		return "", false
+	}
+	return hex, true
+}
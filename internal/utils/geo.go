@@ -0,0 +1,58 @@
+// internal/utils/geo.go
+
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// EarthRadiusKm средний радиус Земли для формулы гаверсинусов
+const EarthRadiusKm = 6371.0
+
+// kmPerDegree длина одного градуса широты
+const kmPerDegree = math.Pi * EarthRadiusKm / 180
+
+// BoundingBox прямоугольник координат, в который попадают все точки круга
+type BoundingBox struct {
+	MinLat, MaxLat float64
+	MinLng, MaxLng float64
+}
+
+// BoundingBoxAround возвращает прямоугольник вокруг точки, описанный около круга радиусом radiusKm.
+// Вблизи полюсов и у 180-го меридиана долгота не ограничивается.
+func BoundingBoxAround(lat, lng, radiusKm float64) BoundingBox {
+	dLat := radiusKm / kmPerDegree
+	box := BoundingBox{
+		MinLat: math.Max(lat-dLat, -90),
+		MaxLat: math.Min(lat+dLat, 90),
+		MinLng: -180,
+		MaxLng: 180,
+	}
+	if cos := math.Cos(lat * math.Pi / 180); box.MinLat > -90 && box.MaxLat < 90 && cos > 0 {
+		dLng := dLat / cos
+		if lng-dLng >= -180 && lng+dLng <= 180 {
+			box.MinLng, box.MaxLng = lng-dLng, lng+dLng
+		}
+	}
+	return box
+}
+
+// ParseLatLng разбирает координаты вида "42.87,74.59"
+func ParseLatLng(value string) (float64, float64, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("ожидаются координаты вида широта,долгота: %s", value)
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("некорректная широта: %s", parts[0])
+	}
+	lng, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("некорректная долгота: %s", parts[1])
+	}
+	return lat, lng, nil
+}
@@ -0,0 +1,89 @@
+// internal/utils/image_hash.go
+
+package utils
+
+import (
+	"image"
+	"image/color"
+	"math/bits"
+)
+
+// DHash вычисляет 64-битный разностный хэш (dHash) изображения.
+// Изображение усредняется до сетки 9x8 в оттенках серого, и каждый бит показывает,
+// ярче ли ячейка своего правого соседа. Хэш устойчив к масштабированию и пережатию.
+func DHash(img image.Image) uint64 {
+	grid := grayGrid(img, 9, 8)
+
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if grid[y][x] > grid[y][x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// maxSamplesPerCell ограничивает число пикселей, усредняемых в одной ячейке по каждой оси
+const maxSamplesPerCell = 32
+
+// grayGrid делит изображение на cols x rows ячеек и возвращает среднюю яркость каждой.
+// Усреднение (а не выборка отдельных пикселей) убирает высокочастотный шум,
+// который иначе по-разному попадает в сетку при разных размерах изображения.
+func grayGrid(img image.Image, cols, rows int) [][]float64 {
+	b := img.Bounds()
+	grid := make([][]float64, rows)
+	for row := 0; row < rows; row++ {
+		grid[row] = make([]float64, cols)
+		y0 := b.Min.Y + row*b.Dy()/rows
+		y1 := b.Min.Y + (row+1)*b.Dy()/rows
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for col := 0; col < cols; col++ {
+			x0 := b.Min.X + col*b.Dx()/cols
+			x1 := b.Min.X + (col+1)*b.Dx()/cols
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+			grid[row][col] = averageGray(img, x0, y0, x1, y1)
+		}
+	}
+	return grid
+}
+
+func averageGray(img image.Image, x0, y0, x1, y1 int) float64 {
+	stepX := (x1-x0)/maxSamplesPerCell + 1
+	stepY := (y1-y0)/maxSamplesPerCell + 1
+
+	var sum float64
+	var count int
+	for y := y0; y < y1; y += stepY {
+		for x := x0; x < x1; x += stepX {
+			sum += float64(color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y)
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return sum / float64(count)
+}
+
+// HammingDistance количество различающихся битов двух хэшей
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// HashBands разбивает хэш на 8 байтов с номером позиции: i*256 + байт.
+// Если расстояние Хэмминга между хэшами меньше 8, хотя бы один байт совпадает,
+// поэтому поиск кандидатов по пересечению массивов не пропускает близкие хэши.
+func HashBands(hash uint64) []int64 {
+	bands := make([]int64, 8)
+	for i := 0; i < 8; i++ {
+		bands[i] = int64(i*256) + int64((hash>>(uint(i)*8))&0xFF)
+	}
+	return bands
+}
@@ -0,0 +1,277 @@
+// internal/utils/image_processing.go
+
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // Регистрация декодера WebP
+)
+
+const (
+	// MaxImageFileSize максимальный размер загружаемого изображения в байтах
+	MaxImageFileSize = 10 << 20
+	// MaxImageDimension максимальная ширина или высота изображения в пикселях
+	MaxImageDimension = 8000
+	// MaxImagePixels максимальное количество пикселей (защита от "бомб" декомпрессии)
+	MaxImagePixels = 40_000_000
+
+	jpegQuality = 85
+)
+
+// ThumbnailWidths ширины, до которых масштабируется каждое изображение
+var ThumbnailWidths = []int{200, 600, 1200}
+
+var allowedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+}
+
+var (
+	ErrImageTooLarge       = fmt.Errorf("размер изображения превышает %d МБ", MaxImageFileSize>>20)
+	ErrImageTypeNotAllowed = errors.New("допустимы только изображения JPEG, PNG и WebP")
+	ErrImageDimensions     = fmt.Errorf("изображение больше %dx%d пикселей", MaxImageDimension, MaxImageDimension)
+)
+
+// ProcessedImage результат обработки: миниатюры и перцептивный хэш исходного изображения
+type ProcessedImage struct {
+	Renditions []ImageRendition
+	Hash       uint64
+}
+
+// ImageRendition одно перекодированное изображение заданной ширины
+type ImageRendition struct {
+	Width       int
+	Height      int
+	Data        []byte
+	ContentType string
+	Extension   string
+}
+
+// ProcessImage проверяет изображение, удаляет EXIF и перекодирует его в набор миниатюр.
+// Тип определяется по содержимому файла, а не по заголовку Content-Type клиента.
+func ProcessImage(file *multipart.FileHeader) (*ProcessedImage, error) {
+	if file.Size > MaxImageFileSize {
+		return nil, ErrImageTooLarge
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("Не удалось открыть файл: %v", err)
+	}
+	defer src.Close()
+
+	// Читаем на один байт больше лимита, чтобы не доверять file.Size
+	data, err := io.ReadAll(io.LimitReader(src, MaxImageFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("Ошибка при чтении файла: %v", err)
+	}
+	if len(data) > MaxImageFileSize {
+		return nil, ErrImageTooLarge
+	}
+
+	return ProcessImageBytes(data)
+}
+
+// ProcessImageBytes выполняет ту же обработку для уже прочитанного содержимого
+func ProcessImageBytes(data []byte) (*ProcessedImage, error) {
+	contentType := http.DetectContentType(data)
+	if !allowedImageTypes[contentType] {
+		return nil, ErrImageTypeNotAllowed
+	}
+
+	// Проверяем размеры до полного декодирования
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать изображение: %v", err)
+	}
+	if cfg.Width > MaxImageDimension || cfg.Height > MaxImageDimension || cfg.Width*cfg.Height > MaxImagePixels {
+		return nil, ErrImageDimensions
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("не удалось декодировать изображение: %v", err)
+	}
+
+	// Декодирование отбрасывает все метаданные, поэтому ориентацию из EXIF нужно применить заранее
+	if contentType == "image/jpeg" {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+
+	// Прозрачные изображения сохраняем в PNG, остальные в JPEG
+	keepAlpha := !isOpaque(img)
+
+	bounds := img.Bounds()
+	var renditions []ImageRendition
+	for _, width := range thumbnailWidthsFor(bounds.Dx()) {
+		height := bounds.Dy() * width / bounds.Dx()
+		if height < 1 {
+			height = 1
+		}
+
+		dst := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+
+		rendition := ImageRendition{Width: width, Height: height}
+		var buf bytes.Buffer
+		if keepAlpha {
+			err = png.Encode(&buf, dst)
+			rendition.ContentType = "image/png"
+			rendition.Extension = ".png"
+		} else {
+			err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: jpegQuality})
+			rendition.ContentType = "image/jpeg"
+			rendition.Extension = ".jpg"
+		}
+		if err != nil {
+			return nil, fmt.Errorf("не удалось перекодировать изображение: %v", err)
+		}
+		rendition.Data = buf.Bytes()
+		renditions = append(renditions, rendition)
+	}
+
+	return &ProcessedImage{Renditions: renditions, Hash: DHash(img)}, nil
+}
+
+// thumbnailWidthsFor возвращает ширины миниатюр, меньшие исходной ширины, и последней —
+// исходную ширину, ограниченную самой крупной миниатюрой. Последняя ширина становится основным URL,
+// поэтому изображение между порогами не уменьшается до ближайшей меньшей миниатюры.
+func thumbnailWidthsFor(originalWidth int) []int {
+	mainWidth := originalWidth
+	if largest := ThumbnailWidths[len(ThumbnailWidths)-1]; mainWidth > largest {
+		mainWidth = largest
+	}
+	var widths []int
+	for _, width := range ThumbnailWidths {
+		if width < mainWidth {
+			widths = append(widths, width)
+		}
+	}
+	return append(widths, mainWidth)
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
+
+// jpegOrientation возвращает значение тега Orientation (0x0112) из EXIF или 1, если его нет
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return 1
+		}
+		marker := data[pos+1]
+		segmentLength := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		// Начало данных изображения: дальше EXIF не бывает
+		if marker == 0xDA || segmentLength < 2 || pos+2+segmentLength > len(data) {
+			return 1
+		}
+
+		segment := data[pos+4 : pos+2+segmentLength]
+		if marker == 0xE1 && len(segment) > 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return exifOrientation(segment[6:])
+		}
+		pos += 2 + segmentLength
+	}
+
+	return 1
+}
+
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifdOffset := int(order.Uint32(tiff[4:8]))
+	if ifdOffset+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifdOffset : ifdOffset+2]))
+	for i := 0; i < entries; i++ {
+		entry := ifdOffset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:entry+2]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry+8 : entry+10]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+			return orientation
+		}
+	}
+
+	return 1
+}
+
+// applyOrientation поворачивает и отражает изображение согласно тегу Orientation
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	// Ориентации 5-8 меняют ширину и высоту местами
+	swap := orientation >= 5
+	dstW, dstH := w, h
+	if swap {
+		dstW, dstH = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // отражение по горизонтали
+				dx, dy = w-1-x, y
+			case 3: // поворот на 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // отражение по вертикали
+				dx, dy = x, h-1-y
+			case 5: // транспонирование
+				dx, dy = y, x
+			case 6: // поворот на 90° по часовой стрелке
+				dx, dy = h-1-y, x
+			case 7: // транспонирование с поворотом на 180°
+				dx, dy = h-1-y, w-1-x
+			case 8: // поворот на 90° против часовой стрелки
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,112 @@
+// internal/utils/private_storage.go
+
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/google/uuid"
+)
+
+// MaxDocumentFileSize максимальный размер документа поставщика в байтах
+const MaxDocumentFileSize = 10 << 20
+
+// privateFolder префикс объектов, закрытых от публичного доступа. Такие объекты
+// загружаются без public-read и открываются только по подписанной ссылке.
+const privateFolder = "private"
+
+var allowedDocumentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+}
+
+var (
+	ErrDocumentTooLarge       = fmt.Errorf("размер документа превышает %d МБ", MaxDocumentFileSize>>20)
+	ErrDocumentTypeNotAllowed = errors.New("допустимы только документы PDF, JPEG и PNG")
+)
+
+// PrivateObject загруженный закрытый объект
+type PrivateObject struct {
+	Key         string
+	ContentType string
+	Size        int64
+}
+
+// UploadPrivateDocument проверяет документ и загружает его в закрытую часть бакета
+func UploadPrivateDocument(file *multipart.FileHeader, folderPath string) (*PrivateObject, error) {
+	if file.Size > MaxDocumentFileSize {
+		return nil, ErrDocumentTooLarge
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("Не удалось открыть файл: %v", err)
+	}
+	defer src.Close()
+
+	buf := bytes.NewBuffer(nil)
+	if _, err := io.Copy(buf, io.LimitReader(src, MaxDocumentFileSize+1)); err != nil {
+		return nil, fmt.Errorf("Ошибка при чтении файла: %v", err)
+	}
+	if buf.Len() > MaxDocumentFileSize {
+		return nil, ErrDocumentTooLarge
+	}
+
+	// Тип содержимого определяем по самим данным, а не по заголовку клиента
+	contentType := http.DetectContentType(buf.Bytes())
+	if !allowedDocumentTypes[contentType] {
+		return nil, ErrDocumentTypeNotAllowed
+	}
+
+	objectKey := objectKeyFor(objectKeyFor(privateFolder, folderPath), uuid.New().String()+filepath.Ext(file.Filename))
+	if err := putObjectWithACL(objectKey, buf.Bytes(), contentType, "private"); err != nil {
+		return nil, err
+	}
+
+	return &PrivateObject{Key: objectKey, ContentType: contentType, Size: int64(buf.Len())}, nil
+}
+
+// PresignPrivateObject возвращает ссылку на закрытый объект, действующую ttl
+func PresignPrivateObject(objectKey string, ttl time.Duration) (string, error) {
+	svc, err := newYandexS3Client()
+	if err != nil {
+		return "", err
+	}
+
+	req, _ := svc.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(yandexBucketName),
+		Key:    aws.String(objectKey),
+	})
+	url, err := req.Presign(ttl)
+	if err != nil {
+		return "", fmt.Errorf("Не удалось подписать ссылку на файл: %v", err)
+	}
+	return url, nil
+}
+
+// DeletePrivateObject удаляет закрытый объект из бакета
+func DeletePrivateObject(objectKey string) error {
+	svc, err := newYandexS3Client()
+	if err != nil {
+		return err
+	}
+
+	_, err = svc.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(yandexBucketName),
+		Key:    aws.String(objectKey),
+	})
+	if err != nil {
+		return fmt.Errorf("Не удалось удалить файл из Yandex Cloud Storage: %v", err)
+	}
+	return nil
+}
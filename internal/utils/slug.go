@@ -0,0 +1,83 @@
+// internal/utils/slug.go
+
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// cyrillicToLatin транслитерация русских и киргизских букв
+var cyrillicToLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "h", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "sch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya", 'ң': "n", 'ө': "o", 'ү': "u",
+}
+
+// Slugify переводит строку в нижний регистр латиницей, заменяя остальные символы разделителем.
+// Повторяющиеся и крайние разделители убираются.
+func Slugify(s string, separator rune) string {
+	var b strings.Builder
+	pendingSeparator := false
+	for _, r := range strings.ToLower(s) {
+		var part string
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			part = string(r)
+		case cyrillicToLatin[r] != "":
+			part = cyrillicToLatin[r]
+		default:
+			if _, ok := cyrillicToLatin[r]; !ok {
+				pendingSeparator = true
+			}
+			continue
+		}
+		if pendingSeparator && b.Len() > 0 {
+			b.WriteRune(separator)
+		}
+		pendingSeparator = false
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+// ProductSlugMaxLength наибольшая длина slug продукта без числового суффикса
+const ProductSlugMaxLength = 80
+
+// ProductSlug строит slug продукта из названия: транслитерация через "-", не длиннее
+// ProductSlugMaxLength. Обрезается по границе слова.
+func ProductSlug(name string) string {
+	slug := Slugify(name, '-')
+	if len(slug) > ProductSlugMaxLength {
+		slug = slug[:ProductSlugMaxLength]
+		if i := strings.LastIndexByte(slug, '-'); i > 0 {
+			slug = slug[:i]
+		}
+	}
+	return slug
+}
+
+// CategorySlugPath URL категории из её path: метки через "/", "_" заменяется на "-"
+func CategorySlugPath(path string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(path, "_", "-"), ".", "/")
+}
+
+// CategoryPathFromSlug восстанавливает path категории из URL. Возвращает false, если
+// в URL есть что-то кроме латинских букв, цифр, "-" и "/".
+func CategoryPathFromSlug(slug string) (string, bool) {
+	labels := strings.Split(strings.Trim(slug, "/"), "/")
+	for i, label := range labels {
+		if label == "" {
+			return "", false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+				return "", false
+			}
+		}
+		labels[i] = strings.ReplaceAll(label, "-", "_")
+	}
+	return strings.Join(labels, "."), true
+}
@@ -0,0 +1,152 @@
+// internal/utils/units.go
+
+package utils
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Unit единица измерения. Factor переводит значение в базовую единицу измерения величины.
+type Unit struct {
+	Code      string  `json:"code"`
+	Name      string  `json:"name"`
+	Dimension string  `json:"dimension"`
+	Factor    float64 `json:"-"`
+}
+
+// Величины, для которых атрибуты могут объявлять единицу измерения
+const (
+	DimensionMass   = "mass"
+	DimensionLength = "length"
+	DimensionVolume = "volume"
+	DimensionPower  = "power"
+)
+
+var units = []Unit{
+	{Code: "mg", Name: "мг", Dimension: DimensionMass, Factor: 0.001},
+	{Code: "g", Name: "г", Dimension: DimensionMass, Factor: 1},
+	{Code: "kg", Name: "кг", Dimension: DimensionMass, Factor: 1000},
+	{Code: "t", Name: "т", Dimension: DimensionMass, Factor: 1000000},
+
+	{Code: "mm", Name: "мм", Dimension: DimensionLength, Factor: 0.001},
+	{Code: "cm", Name: "см", Dimension: DimensionLength, Factor: 0.01},
+	{Code: "m", Name: "м", Dimension: DimensionLength, Factor: 1},
+	{Code: "km", Name: "км", Dimension: DimensionLength, Factor: 1000},
+
+	{Code: "ml", Name: "мл", Dimension: DimensionVolume, Factor: 0.001},
+	{Code: "l", Name: "л", Dimension: DimensionVolume, Factor: 1},
+
+	{Code: "w", Name: "Вт", Dimension: DimensionPower, Factor: 1},
+	{Code: "kw", Name: "кВт", Dimension: DimensionPower, Factor: 1000},
+}
+
+// unitAliases написания единиц, которые встречаются у поставщиков
+var unitAliases = map[string]string{
+	"гр": "g", "грамм": "g", "кило": "kg", "килограмм": "kg", "тонна": "t",
+	"метр": "m", "литр": "l", "gr": "g", "lt": "l",
+}
+
+var unitsByName = func() map[string]Unit {
+	byName := make(map[string]Unit, len(units)*2+len(unitAliases))
+	for _, u := range units {
+		byName[u.Code] = u
+		byName[strings.ToLower(u.Name)] = u
+	}
+	for alias, code := range unitAliases {
+		byName[alias] = byName[code]
+	}
+	return byName
+}()
+
+// unitPrecision количество знаков после запятой, до которого округляется результат пересчёта
+const unitPrecision = 1e6
+
+// LookupUnit ищет единицу по коду или русскому обозначению без учёта регистра и точки в конце
+func LookupUnit(s string) (Unit, bool) {
+	u, ok := unitsByName[strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), ".")]
+	return u, ok
+}
+
+// IsDimension проверяет, что величина поддерживается
+func IsDimension(dimension string) bool {
+	for _, u := range units {
+		if u.Dimension == dimension {
+			return true
+		}
+	}
+	return false
+}
+
+// UnitsByDimension возвращает поддерживаемые единицы, сгруппированные по величинам
+func UnitsByDimension() map[string][]Unit {
+	result := make(map[string][]Unit)
+	for _, u := range units {
+		result[u.Dimension] = append(result[u.Dimension], u)
+	}
+	for _, list := range result {
+		sort.SliceStable(list, func(i, j int) bool { return list[i].Factor < list[j].Factor })
+	}
+	return result
+}
+
+// UnitFactor возвращает множитель перевода значения из единицы from в единицу to той же величины
+func UnitFactor(from, to string) (float64, error) {
+	fromUnit, ok := LookupUnit(from)
+	if !ok {
+		return 0, fmt.Errorf("неизвестная единица измерения: %s", from)
+	}
+	toUnit, ok := LookupUnit(to)
+	if !ok {
+		return 0, fmt.Errorf("неизвестная единица измерения: %s", to)
+	}
+	if fromUnit.Dimension != toUnit.Dimension {
+		return 0, fmt.Errorf("единицы %s и %s измеряют разные величины", fromUnit.Code, toUnit.Code)
+	}
+	if fromUnit.Code == toUnit.Code {
+		return 1, nil
+	}
+	return fromUnit.Factor / toUnit.Factor, nil
+}
+
+// ConvertUnit пересчитывает значение из единицы from в единицу to той же величины
+func ConvertUnit(value float64, from, to string) (float64, error) {
+	factor, err := UnitFactor(from, to)
+	if err != nil {
+		return 0, err
+	}
+	if factor == 1 {
+		return value, nil
+	}
+	return math.Round(value*factor*unitPrecision) / unitPrecision, nil
+}
+
+// ParseQuantity разбирает число с необязательной единицей измерения: "1,5 кг", "250g", "12".
+// Для числа без единицы возвращается пустая единица.
+func ParseQuantity(s string) (float64, string, error) {
+	s = strings.TrimSpace(s)
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return !(unicode.IsDigit(r) || r == '.' || r == ',' || r == '-' || r == '+')
+	})
+	number, unit := s, ""
+	if end >= 0 {
+		number, unit = s[:end], strings.TrimSpace(s[end:])
+	}
+
+	value, err := strconv.ParseFloat(strings.ReplaceAll(number, ",", "."), 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("'%s' не является числом", s)
+	}
+	if unit == "" {
+		return value, "", nil
+	}
+	u, ok := LookupUnit(unit)
+	if !ok {
+		return 0, "", fmt.Errorf("неизвестная единица измерения: %s", unit)
+	}
+	return value, u.Code, nil
+}
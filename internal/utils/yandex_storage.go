@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net/http"
 	"path/filepath"
 
+	"github.com/WhyDias/Marketplace/internal/models"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -14,6 +17,9 @@ import (
 	"github.com/google/uuid"
 )
 
+// MaxUploadFileSize максимальный размер любого загружаемого файла в байтах
+const MaxUploadFileSize = 20 << 20
+
 var (
 	yandexBucketName = "dias"
 	yandexRegion     = "ru-central1"
@@ -23,6 +29,10 @@ var (
 )
 
 func UploadFileToYandex(file *multipart.FileHeader, folderPath string) (string, error) {
+	if file.Size > MaxUploadFileSize {
+		return "", fmt.Errorf("размер файла превышает %d МБ", MaxUploadFileSize>>20)
+	}
+
 	// Открываем файл
 	src, err := file.Open()
 	if err != nil {
@@ -32,22 +42,69 @@ func UploadFileToYandex(file *multipart.FileHeader, folderPath string) (string,
 
 	// Читаем файл в буфер
 	buf := bytes.NewBuffer(nil)
-	if _, err := io.Copy(buf, src); err != nil {
+	if _, err := io.Copy(buf, io.LimitReader(src, MaxUploadFileSize+1)); err != nil {
 		return "", fmt.Errorf("Ошибка при чтении файла: %v", err)
 	}
+	if buf.Len() > MaxUploadFileSize {
+		return "", fmt.Errorf("размер файла превышает %d МБ", MaxUploadFileSize>>20)
+	}
 
 	// Генерируем уникальное имя файла
 	uniqueFileName := uuid.New().String() + filepath.Ext(file.Filename)
 
-	// Создаем полный путь внутри бакета
-	// Если folderPath пустой, файл сохраняется в корне бакета
-	var objectKey string
+	// Тип содержимого определяем по самим данным, а не по заголовку клиента
+	return putObjectToYandex(objectKeyFor(folderPath, uniqueFileName), buf.Bytes(), http.DetectContentType(buf.Bytes()))
+}
+
+// UploadImageToYandex проверяет изображение, удаляет из него EXIF и загружает набор миниатюр.
+// Все миниатюры хранятся под общим префиксом <folderPath>/<uuid>/<ширина>.<ext>.
+func UploadImageToYandex(file *multipart.FileHeader, folderPath string) (*models.UploadedImage, error) {
+	processed, err := ProcessImage(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return uploadProcessedImage(processed, folderPath)
+}
+
+func uploadProcessedImage(processed *ProcessedImage, folderPath string) (*models.UploadedImage, error) {
+	prefix := objectKeyFor(folderPath, uuid.New().String())
+
+	uploaded := &models.UploadedImage{Hash: processed.Hash}
+	for _, rendition := range processed.Renditions {
+		objectKey := fmt.Sprintf("%s/%d%s", prefix, rendition.Width, rendition.Extension)
+		fileURL, err := putObjectToYandex(objectKey, rendition.Data, rendition.ContentType)
+		if err != nil {
+			return nil, err
+		}
+
+		uploaded.Variants = append(uploaded.Variants, models.ImageVariant{
+			Width:  rendition.Width,
+			Height: rendition.Height,
+			URL:    fileURL,
+		})
+	}
+
+	// Основной URL указывает на самую крупную миниатюру
+	if len(uploaded.Variants) > 0 {
+		uploaded.URL = uploaded.Variants[len(uploaded.Variants)-1].URL
+	}
+	uploaded.SrcSet = models.BuildSrcSet(uploaded.Variants)
+
+	return uploaded, nil
+}
+
+// objectKeyFor формирует полный путь внутри бакета.
+// Если folderPath пустой, файл сохраняется в корне бакета.
+func objectKeyFor(folderPath string, name string) string {
 	if folderPath != "" {
-		objectKey = fmt.Sprintf("%s/%s", folderPath, uniqueFileName)
-	} else {
-		objectKey = uniqueFileName
+		return fmt.Sprintf("%s/%s", folderPath, name)
 	}
+	return name
+}
 
+// newYandexS3Client создаёт клиент S3 для бакета Yandex Cloud Storage
+func newYandexS3Client() (*s3.S3, error) {
 	// Создаем сессию AWS
 	sess, err := session.NewSession(&aws.Config{
 		Region:           aws.String(yandexRegion),
@@ -56,27 +113,43 @@ func UploadFileToYandex(file *multipart.FileHeader, folderPath string) (string,
 		S3ForcePathStyle: aws.Bool(true),
 	})
 	if err != nil {
-		return "", fmt.Errorf("Не удалось создать сессию: %v", err)
+		return nil, fmt.Errorf("Не удалось создать сессию: %v", err)
 	}
 
 	// Создаем клиент S3
-	svc := s3.New(sess)
+	return s3.New(sess), nil
+}
+
+// putObjectToYandex загружает данные в бакет и возвращает публичный URL объекта
+func putObjectToYandex(objectKey string, data []byte, contentType string) (string, error) {
+	if err := putObjectWithACL(objectKey, data, contentType, "public-read"); err != nil {
+		return "", err
+	}
+
+	// Формируем URL загруженного файла
+	fileURL := fmt.Sprintf("%s/%s/%s", yandexEndpoint, yandexBucketName, objectKey)
+
+	return fileURL, nil
+}
+
+// putObjectWithACL загружает данные в бакет с указанными правами доступа
+func putObjectWithACL(objectKey string, data []byte, contentType string, acl string) error {
+	svc, err := newYandexS3Client()
+	if err != nil {
+		return err
+	}
 
 	// Загружаем файл
 	_, err = svc.PutObject(&s3.PutObjectInput{
 		Bucket:        aws.String(yandexBucketName),
 		Key:           aws.String(objectKey),
-		Body:          bytes.NewReader(buf.Bytes()),
-		ContentLength: aws.Int64(file.Size),
-		ContentType:   aws.String(file.Header.Get("Content-Type")),
-		ACL:           aws.String("public-read"),
+		Body:          bytes.NewReader(data),
+		ContentLength: aws.Int64(int64(len(data))),
+		ContentType:   aws.String(contentType),
+		ACL:           aws.String(acl),
 	})
 	if err != nil {
-		return "", fmt.Errorf("Ошибка при загрузке файла в Yandex Cloud Storage: %v", err)
+		return fmt.Errorf("Ошибка при загрузке файла в Yandex Cloud Storage: %v", err)
 	}
-
-	// Формируем URL загруженного файла
-	fileURL := fmt.Sprintf("%s/%s/%s", yandexEndpoint, yandexBucketName, objectKey)
-
-	return fileURL, nil
+	return nil
 }
@@ -0,0 +1,70 @@
+-- migrations/001_product_images.sql
+-- Изображения продуктов и вариаций хранятся построчно: одна запись на одно изображение
+-- с позицией, alt-текстом и признаком главного изображения.
+
+BEGIN;
+
+ALTER TABLE product_variation_images
+    ADD COLUMN IF NOT EXISTS image_url  TEXT,
+    ADD COLUMN IF NOT EXISTS position   INT     NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS alt_text   TEXT    NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+ALTER TABLE product_images
+    ADD COLUMN IF NOT EXISTS id         SERIAL,
+    ADD COLUMN IF NOT EXISTS image_url  TEXT,
+    ADD COLUMN IF NOT EXISTS position   INT     NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS alt_text   TEXT    NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+-- Разбиваем JSON-массивы image_urls на отдельные строки
+INSERT INTO product_variation_images (product_variation_id, image_url, image_path, position, is_primary)
+SELECT pvi.product_variation_id,
+       t.url,
+       pvi.image_path,
+       ROW_NUMBER() OVER (PARTITION BY pvi.product_variation_id ORDER BY pvi.id, t.ord) - 1,
+       ROW_NUMBER() OVER (PARTITION BY pvi.product_variation_id ORDER BY pvi.id, t.ord) = 1
+FROM product_variation_images pvi,
+     jsonb_array_elements_text(pvi.image_urls::jsonb) WITH ORDINALITY AS t(url, ord)
+WHERE pvi.image_url IS NULL
+  AND pvi.image_urls IS NOT NULL
+  AND pvi.image_urls <> '';
+
+DELETE FROM product_variation_images WHERE image_url IS NULL;
+
+INSERT INTO product_images (product_id, image_url, image_path, position, is_primary)
+SELECT pi.product_id,
+       t.url,
+       pi.image_path,
+       ROW_NUMBER() OVER (PARTITION BY pi.product_id ORDER BY pi.id, t.ord) - 1,
+       ROW_NUMBER() OVER (PARTITION BY pi.product_id ORDER BY pi.id, t.ord) = 1
+FROM product_images pi,
+     jsonb_array_elements_text(pi.image_urls::jsonb) WITH ORDINALITY AS t(url, ord)
+WHERE pi.image_url IS NULL
+  AND pi.image_urls IS NOT NULL
+  AND pi.image_urls <> '';
+
+DELETE FROM product_images WHERE image_url IS NULL;
+
+ALTER TABLE product_variation_images
+    DROP COLUMN image_urls,
+    ALTER COLUMN image_url SET NOT NULL;
+
+ALTER TABLE product_images
+    DROP COLUMN image_urls,
+    ALTER COLUMN image_url SET NOT NULL;
+
+-- Не более одного главного изображения на продукт / вариацию
+CREATE UNIQUE INDEX IF NOT EXISTS product_variation_images_primary_idx
+    ON product_variation_images (product_variation_id) WHERE is_primary;
+CREATE UNIQUE INDEX IF NOT EXISTS product_images_primary_idx
+    ON product_images (product_id) WHERE is_primary;
+
+CREATE INDEX IF NOT EXISTS product_variation_images_position_idx
+    ON product_variation_images (product_variation_id, position);
+CREATE INDEX IF NOT EXISTS product_images_position_idx
+    ON product_images (product_id, position);
+
+COMMIT;
@@ -0,0 +1,13 @@
+-- migrations/002_image_variants.sql
+-- Миниатюры изображений (200, 600, 1200 px), сохранённые при загрузке.
+-- Хранятся как JSON-массив [{"width": 200, "height": 150, "url": "..."}].
+
+BEGIN;
+
+ALTER TABLE product_images
+    ADD COLUMN IF NOT EXISTS variants JSONB NOT NULL DEFAULT '[]';
+
+ALTER TABLE product_variation_images
+    ADD COLUMN IF NOT EXISTS variants JSONB NOT NULL DEFAULT '[]';
+
+COMMIT;
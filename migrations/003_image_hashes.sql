@@ -0,0 +1,37 @@
+-- migrations/003_image_hashes.sql
+-- Перцептивные хэши (dHash) загруженных изображений и найденные совпадения
+-- между продуктами разных поставщиков для очереди модерации.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS image_hashes (
+    id          SERIAL PRIMARY KEY,
+    owner_type  VARCHAR(20) NOT NULL,                 -- product, variation, category
+    owner_id    INTEGER NOT NULL,
+    image_id    INTEGER,                              -- id в product_images / product_variation_images
+    image_url   TEXT NOT NULL,
+    hash        BIGINT NOT NULL,
+    hash_bands  INTEGER[] NOT NULL,                   -- i*256 + i-й байт хэша, для поиска кандидатов
+    product_id  INTEGER REFERENCES product(id) ON DELETE CASCADE,
+    supplier_id INTEGER REFERENCES supplier(id) ON DELETE CASCADE,
+    created_at  TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_image_hashes_hash ON image_hashes (hash);
+CREATE INDEX IF NOT EXISTS idx_image_hashes_bands ON image_hashes USING GIN (hash_bands);
+CREATE INDEX IF NOT EXISTS idx_image_hashes_owner ON image_hashes (owner_type, owner_id);
+
+CREATE TABLE IF NOT EXISTS product_duplicate_images (
+    id                    SERIAL PRIMARY KEY,
+    product_id            INTEGER NOT NULL REFERENCES product(id) ON DELETE CASCADE,
+    image_hash_id         INTEGER NOT NULL REFERENCES image_hashes(id) ON DELETE CASCADE,
+    matched_product_id    INTEGER NOT NULL REFERENCES product(id) ON DELETE CASCADE,
+    matched_image_hash_id INTEGER NOT NULL REFERENCES image_hashes(id) ON DELETE CASCADE,
+    distance              SMALLINT NOT NULL,
+    created_at            TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (image_hash_id, matched_image_hash_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_duplicate_images_product ON product_duplicate_images (product_id);
+
+COMMIT;
@@ -0,0 +1,19 @@
+-- migrations/004_moderation_queue.sql
+-- Очередь модерации: время постановки продукта в очередь и захват продукта модератором.
+-- Для уже существующих продуктов время постановки в очередь отсчитывается с момента миграции.
+
+BEGIN;
+
+ALTER TABLE product
+    ADD COLUMN IF NOT EXISTS submitted_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+CREATE INDEX IF NOT EXISTS idx_product_status_submitted ON product (status_id, submitted_at);
+
+CREATE TABLE IF NOT EXISTS moderation_claims (
+    product_id   INTEGER PRIMARY KEY REFERENCES product(id) ON DELETE CASCADE,
+    moderator_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    claimed_at   TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at   TIMESTAMP NOT NULL
+);
+
+COMMIT;
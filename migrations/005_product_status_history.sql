@@ -0,0 +1,20 @@
+-- migrations/005_product_status_history.sql
+-- История смены статусов продукта. Комментарий модератора, оставленный вместе
+-- со сменой статуса (например, причина отклонения), связан через comment_id.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS product_status_history (
+    id             SERIAL PRIMARY KEY,
+    product_id     INTEGER NOT NULL REFERENCES product(id) ON DELETE CASCADE,
+    from_status_id INTEGER,                    -- NULL для создания продукта
+    to_status_id   INTEGER NOT NULL,
+    changed_by     INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    comment_id     INTEGER REFERENCES comments(id) ON DELETE SET NULL,
+    created_at     TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_status_history_product ON product_status_history (product_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_comments_product ON comments (product_id, created_at);
+
+COMMIT;
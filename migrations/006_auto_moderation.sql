@@ -0,0 +1,39 @@
+-- migrations/006_auto_moderation.sql
+-- Автомодерация: настраиваемые правила и результаты проверки продуктов.
+
+BEGIN;
+
+-- Комментарии автомодерации оставляются без пользователя
+ALTER TABLE comments
+    ALTER COLUMN user_id DROP NOT NULL;
+
+CREATE TABLE IF NOT EXISTS moderation_rules (
+    code       VARCHAR(50) PRIMARY KEY,
+    enabled    BOOLEAN NOT NULL DEFAULT TRUE,
+    action     VARCHAR(20) NOT NULL CHECK (action IN ('approve', 'reject', 'review')),
+    weight     INTEGER NOT NULL DEFAULT 0,
+    params     JSONB NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO moderation_rules (code, enabled, action, weight, params) VALUES
+    ('banned_words', TRUE, 'reject', 100, '{"words": []}'),
+    ('contact_details', TRUE, 'reject', 100, '{}'),
+    ('missing_images', TRUE, 'review', 30, '{}'),
+    ('price_outlier', TRUE, 'review', 20, '{"min_ratio": 0.2, "max_ratio": 5, "min_samples": 5}'),
+    ('missing_required_attributes', TRUE, 'review', 30, '{}'),
+    ('auto_approve', FALSE, 'approve', 0, '{}')
+ON CONFLICT (code) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS product_auto_moderation (
+    id          SERIAL PRIMARY KEY,
+    product_id  INTEGER NOT NULL REFERENCES product(id) ON DELETE CASCADE,
+    outcome     VARCHAR(20) NOT NULL,
+    score       INTEGER NOT NULL DEFAULT 0,
+    fired_rules JSONB NOT NULL DEFAULT '[]',
+    created_at  TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_auto_moderation_product ON product_auto_moderation (product_id, created_at);
+
+COMMIT;
@@ -0,0 +1,45 @@
+-- migrations/007_rejection_reasons.sql
+-- Справочник причин отклонения и причины, указанные при отклонении продукта.
+-- Причина может относиться к полю продукта, вариации или изображению.
+-- image_id указывает на product_variation_images, если задан variation_id, иначе на product_images.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS rejection_reasons (
+    id          SERIAL PRIMARY KEY,
+    code        VARCHAR(50) NOT NULL UNIQUE,
+    title       VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    is_active   BOOLEAN NOT NULL DEFAULT TRUE,
+    sort_order  INTEGER NOT NULL DEFAULT 0,
+    created_at  TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO rejection_reasons (code, title, description, sort_order) VALUES
+    ('bad_photos', 'Некачественные фотографии', 'Фото размытые, тёмные, с водяными знаками или не показывают товар', 10),
+    ('wrong_category', 'Неверная категория', 'Товар размещён не в той категории', 20),
+    ('prohibited_item', 'Запрещённый товар', 'Товар запрещён к продаже на площадке', 30),
+    ('incorrect_attribute', 'Неверный атрибут', 'Значение атрибута не соответствует товару или не заполнено', 40),
+    ('incorrect_price', 'Некорректная цена', 'Цена указана с ошибкой или не соответствует товару', 50),
+    ('incomplete_description', 'Неполное описание', 'Описание не позволяет понять, что продаётся', 60),
+    ('contact_details', 'Контактные данные', 'В названии, описании или на фото указаны контакты продавца', 70),
+    ('duplicate', 'Чужие фотографии', 'Фотографии совпадают с товаром другого поставщика', 80)
+ON CONFLICT (code) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS product_rejection_reasons (
+    id           SERIAL PRIMARY KEY,
+    product_id   INTEGER NOT NULL REFERENCES product(id) ON DELETE CASCADE,
+    comment_id   INTEGER NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    reason_id    INTEGER NOT NULL REFERENCES rejection_reasons(id),
+    field        VARCHAR(100),
+    variation_id INTEGER REFERENCES product_variation(id) ON DELETE SET NULL,
+    image_id     INTEGER,
+    note         TEXT NOT NULL DEFAULT '',
+    created_at   TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_rejection_reasons_comment ON product_rejection_reasons (comment_id);
+CREATE INDEX IF NOT EXISTS idx_product_rejection_reasons_reason ON product_rejection_reasons (reason_id, created_at);
+
+COMMIT;
@@ -0,0 +1,21 @@
+-- migrations/008_category_tree.sql
+-- Управление деревом категорий: parent_id заполняется по path,
+-- path уникален и индексирован для выборки поддеревьев.
+
+BEGIN;
+
+CREATE EXTENSION IF NOT EXISTS ltree;
+
+-- Категории, созданные без parent_id, получают родителя по path
+UPDATE categories c
+SET parent_id = p.id
+FROM categories p
+WHERE c.parent_id IS NULL
+  AND nlevel(c.path) > 1
+  AND p.path = subpath(c.path, 0, nlevel(c.path) - 1);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_path_unique ON categories (path);
+CREATE INDEX IF NOT EXISTS idx_categories_path_gist ON categories USING GIST (path);
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories (parent_id);
+
+COMMIT;
@@ -0,0 +1,17 @@
+-- migrations/009_category_hidden_attributes.sql
+-- Наследование атрибутов по дереву категорий: категория получает атрибуты всех предков,
+-- одноимённый атрибут подкатегории переопределяет унаследованный, а скрытие убирает
+-- унаследованный атрибут у категории и её подкатегорий.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS category_hidden_attributes (
+    category_id    INTEGER NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    attribute_name VARCHAR(255) NOT NULL,
+    created_at     TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (category_id, attribute_name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_attributes_category_name ON attributes (category_id, name);
+
+COMMIT;
@@ -0,0 +1,48 @@
+-- migrations/010_attribute_versions.sql
+-- Версии определений атрибутов и задания на смену типа атрибута
+-- с конвертацией сохранённых значений.
+
+BEGIN;
+
+ALTER TABLE attributes
+    ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS attribute_versions (
+    id             SERIAL PRIMARY KEY,
+    attribute_id   INTEGER NOT NULL REFERENCES attributes(id) ON DELETE CASCADE,
+    version        INTEGER NOT NULL,
+    name           VARCHAR(255) NOT NULL,
+    description    TEXT,
+    type_of_option VARCHAR(50),
+    value          JSONB,
+    changed_by     INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    change_summary TEXT NOT NULL DEFAULT '',
+    created_at     TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (attribute_id, version)
+);
+
+-- Текущие определения становятся первой версией
+INSERT INTO attribute_versions (attribute_id, version, name, description, type_of_option, value, change_summary)
+SELECT id, 1, name, description, type_of_option, value::jsonb, 'Исходная версия'
+FROM attributes
+ON CONFLICT (attribute_id, version) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS attribute_conversion_jobs (
+    id           SERIAL PRIMARY KEY,
+    attribute_id INTEGER NOT NULL REFERENCES attributes(id) ON DELETE CASCADE,
+    from_type    VARCHAR(50) NOT NULL,
+    to_type      VARCHAR(50) NOT NULL,
+    value        JSONB,                                -- новый список опций или значение по умолчанию
+    mapping      JSONB NOT NULL DEFAULT '{}',          -- старое значение -> новое
+    status       VARCHAR(20) NOT NULL DEFAULT 'pending'
+                 CHECK (status IN ('pending', 'running', 'done', 'failed')),
+    converted    INTEGER NOT NULL DEFAULT 0,
+    error        TEXT NOT NULL DEFAULT '',
+    created_by   INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at   TIMESTAMP NOT NULL DEFAULT NOW(),
+    finished_at  TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_attribute_conversion_jobs_attribute ON attribute_conversion_jobs (attribute_id);
+
+COMMIT;
@@ -0,0 +1,29 @@
+-- migrations/011_attribute_units.sql
+-- Единицы измерения числовых атрибутов. Атрибут объявляет величину и каноническую единицу,
+-- значения продуктов хранятся в канонической единице в value_numeric для фильтрации и сортировки.
+
+BEGIN;
+
+ALTER TABLE attributes
+    ADD COLUMN IF NOT EXISTS dimension VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS unit      VARCHAR(20);
+
+ALTER TABLE attribute_versions
+    ADD COLUMN IF NOT EXISTS dimension VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS unit      VARCHAR(20);
+
+ALTER TABLE attribute_value
+    ADD COLUMN IF NOT EXISTS value_numeric NUMERIC;
+
+-- Уже сохранённые числовые значения
+UPDATE attribute_value av
+SET value_numeric = (av.value_json::jsonb #>> '{}')::numeric
+FROM attributes a
+WHERE a.id = av.attribute_id
+  AND a.type_of_option IN ('numeric', 'range')
+  AND jsonb_typeof(av.value_json::jsonb) = 'number';
+
+CREATE INDEX IF NOT EXISTS idx_attribute_value_numeric ON attribute_value (attribute_id, value_numeric)
+    WHERE value_numeric IS NOT NULL;
+
+COMMIT;
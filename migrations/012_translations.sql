@@ -0,0 +1,43 @@
+-- migrations/012_translations.sql
+-- Переводы каталога на кыргызский и английский. Исходные тексты хранятся на русском
+-- в самих таблицах каталога, здесь только переводы.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS category_translations (
+    category_id INTEGER NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    lang        VARCHAR(5) NOT NULL CHECK (lang IN ('ky', 'en')),
+    name        VARCHAR(255) NOT NULL,
+    updated_at  TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (category_id, lang)
+);
+
+CREATE TABLE IF NOT EXISTS attribute_translations (
+    attribute_id INTEGER NOT NULL REFERENCES attributes(id) ON DELETE CASCADE,
+    lang         VARCHAR(5) NOT NULL CHECK (lang IN ('ky', 'en')),
+    name         VARCHAR(255) NOT NULL,
+    description  TEXT NOT NULL DEFAULT '',
+    updated_at   TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (attribute_id, lang)
+);
+
+-- Переводы значений атрибута по исходному тексту, в первую очередь опций dropdown
+CREATE TABLE IF NOT EXISTS attribute_option_translations (
+    attribute_id INTEGER NOT NULL REFERENCES attributes(id) ON DELETE CASCADE,
+    option       VARCHAR(255) NOT NULL,
+    lang         VARCHAR(5) NOT NULL CHECK (lang IN ('ky', 'en')),
+    value        VARCHAR(255) NOT NULL,
+    updated_at   TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (attribute_id, option, lang)
+);
+
+CREATE TABLE IF NOT EXISTS product_translations (
+    product_id  INTEGER NOT NULL REFERENCES product(id) ON DELETE CASCADE,
+    lang        VARCHAR(5) NOT NULL CHECK (lang IN ('ky', 'en')),
+    name        VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    updated_at  TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (product_id, lang)
+);
+
+COMMIT;
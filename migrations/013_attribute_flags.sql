@@ -0,0 +1,12 @@
+-- migrations/013_attribute_flags.sql
+-- Флаги атрибутов, которыми управляет администратор: обязательность,
+-- порядок вывода и участие в фильтрах каталога.
+
+BEGIN;
+
+ALTER TABLE attributes
+    ADD COLUMN IF NOT EXISTS is_required   BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS sort_order    INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS is_filterable BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMIT;
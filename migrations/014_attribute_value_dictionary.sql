@@ -0,0 +1,34 @@
+-- migrations/014_attribute_value_dictionary.sql
+-- Справочник значений атрибутов: порядок вывода, образец цвета и одна запись изображений
+-- на значение.
+
+BEGIN;
+
+ALTER TABLE attribute_value
+    ADD COLUMN IF NOT EXISTS sort_order INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS swatch_hex VARCHAR(7) CHECK (swatch_hex ~ '^#[0-9a-f]{6}$');
+
+-- Изображения значения собираются в первую запись, остальные записи удаляются
+UPDATE attribute_value_image first
+SET image_url = merged.urls
+FROM (
+    SELECT i.attribute_value_id, array_agg(t.u ORDER BY i.id, t.o) AS urls
+    FROM attribute_value_image i
+    CROSS JOIN LATERAL unnest(i.image_url) WITH ORDINALITY AS t(u, o)
+    GROUP BY i.attribute_value_id
+) merged
+WHERE first.attribute_value_id = merged.attribute_value_id
+  AND first.id = (SELECT MIN(id) FROM attribute_value_image WHERE attribute_value_id = first.attribute_value_id)
+  AND EXISTS (
+      SELECT 1 FROM attribute_value_image other
+      WHERE other.attribute_value_id = first.attribute_value_id AND other.id <> first.id
+  );
+
+DELETE FROM attribute_value_image i
+USING attribute_value_image first
+WHERE first.attribute_value_id = i.attribute_value_id AND first.id < i.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_attribute_value_image_value ON attribute_value_image (attribute_value_id);
+CREATE INDEX IF NOT EXISTS idx_attribute_value_order ON attribute_value (attribute_id, sort_order, id);
+
+COMMIT;
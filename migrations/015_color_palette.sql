@@ -0,0 +1,92 @@
+-- migrations/015_color_palette.sql
+-- Палитра цветов вариаций: семейства цветов для фильтров каталога, коды #rrggbb,
+-- уникальные названия. Повторяющиеся по названию цвета объединяются, вариации
+-- переходят к цвету с наименьшим id.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS colors (
+    id   SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    code VARCHAR(7)
+);
+
+CREATE TABLE IF NOT EXISTS variation_colors (
+    variation_id INTEGER NOT NULL REFERENCES product_variation(id) ON DELETE CASCADE,
+    color_id     INTEGER NOT NULL REFERENCES colors(id)
+);
+
+CREATE TABLE IF NOT EXISTS color_families (
+    code       VARCHAR(32) PRIMARY KEY,
+    name       VARCHAR(100) NOT NULL,
+    swatch_hex VARCHAR(7) CHECK (swatch_hex ~ '^#[0-9a-f]{6}$'),
+    sort_order INTEGER NOT NULL DEFAULT 0
+);
+
+INSERT INTO color_families (code, name, swatch_hex, sort_order) VALUES
+    ('red',    'Красный',      '#e53935', 10),
+    ('pink',   'Розовый',      '#ec407a', 20),
+    ('orange', 'Оранжевый',    '#fb8c00', 30),
+    ('yellow', 'Жёлтый',       '#fdd835', 40),
+    ('green',  'Зелёный',      '#43a047', 50),
+    ('blue',   'Синий',        '#1e88e5', 60),
+    ('purple', 'Фиолетовый',   '#8e24aa', 70),
+    ('brown',  'Коричневый',   '#6d4c41', 80),
+    ('beige',  'Бежевый',      '#d7c4a3', 90),
+    ('white',  'Белый',        '#ffffff', 100),
+    ('gray',   'Серый',        '#9e9e9e', 110),
+    ('black',  'Чёрный',       '#000000', 120),
+    ('multi',  'Разноцветный', NULL,      130)
+ON CONFLICT (code) DO NOTHING;
+
+-- Коды приводятся к #rrggbb, некорректные очищаются
+ALTER TABLE colors ALTER COLUMN code DROP NOT NULL;
+UPDATE colors SET name = btrim(name), code = lower(ltrim(btrim(code), '#'));
+UPDATE colors
+SET code = substr(code, 1, 1) || substr(code, 1, 1) || substr(code, 2, 1) || substr(code, 2, 1)
+        || substr(code, 3, 1) || substr(code, 3, 1)
+WHERE code ~ '^[0-9a-f]{3}$';
+UPDATE colors SET code = CASE WHEN code ~ '^[0-9a-f]{6}$' THEN '#' || code END;
+
+-- Объединение цветов с одинаковым названием
+CREATE TEMP TABLE color_merge ON COMMIT DROP AS
+SELECT c.id AS source_id, k.target_id
+FROM colors c
+JOIN (SELECT lower(name) AS key, MIN(id) AS target_id FROM colors GROUP BY lower(name)) k
+  ON k.key = lower(c.name)
+WHERE c.id <> k.target_id;
+
+UPDATE colors t
+SET code = s.code
+FROM color_merge m
+JOIN colors s ON s.id = m.source_id
+WHERE t.id = m.target_id AND t.code IS NULL AND s.code IS NOT NULL;
+
+UPDATE variation_colors vc SET color_id = m.target_id FROM color_merge m WHERE vc.color_id = m.source_id;
+DELETE FROM colors c USING color_merge m WHERE c.id = m.source_id;
+
+DELETE FROM variation_colors a
+USING variation_colors b
+WHERE a.variation_id = b.variation_id AND a.color_id = b.color_id AND a.ctid > b.ctid;
+
+ALTER TABLE colors
+    ADD COLUMN IF NOT EXISTS family     VARCHAR(32) REFERENCES color_families(code),
+    ADD COLUMN IF NOT EXISTS sort_order INTEGER NOT NULL DEFAULT 0;
+
+-- ADD CONSTRAINT без IF NOT EXISTS: проверка, чтобы миграцию можно было выполнить повторно
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conname = 'colors_code_hex' AND conrelid = 'colors'::regclass
+    ) THEN
+        ALTER TABLE colors ADD CONSTRAINT colors_code_hex CHECK (code ~ '^#[0-9a-f]{6}$');
+    END IF;
+END $$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_colors_name ON colors (lower(name));
+CREATE INDEX IF NOT EXISTS idx_colors_family ON colors (family);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_variation_colors_link ON variation_colors (variation_id, color_id);
+CREATE INDEX IF NOT EXISTS idx_variation_colors_color ON variation_colors (color_id);
+
+COMMIT;
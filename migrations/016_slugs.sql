@@ -0,0 +1,21 @@
+-- migrations/016_slugs.sql
+-- Slug продуктов для SEO URL и переадресации со старых slug продуктов и path категорий.
+-- URL категории строится из её path, поэтому отдельного slug у категорий нет.
+-- Slug существующих продуктов заполняется при запуске сервера.
+
+BEGIN;
+
+ALTER TABLE product ADD COLUMN IF NOT EXISTS slug VARCHAR(120);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_slug ON product (slug);
+
+CREATE TABLE IF NOT EXISTS slug_redirects (
+    entity_type VARCHAR(16) NOT NULL, -- product или category
+    old_slug    TEXT NOT NULL,        -- Прежний slug продукта или path категории
+    entity_id   INTEGER NOT NULL,
+    created_at  TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (entity_type, old_slug)
+);
+
+CREATE INDEX IF NOT EXISTS idx_slug_redirects_entity ON slug_redirects (entity_type, entity_id);
+
+COMMIT;
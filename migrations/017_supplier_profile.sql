@@ -0,0 +1,15 @@
+-- migrations/017_supplier_profile.sql
+-- Профиль витрины поставщика: логотип, баннер, описание и способы связи.
+-- Телефон поставщика уже хранится в supplier.phone_number, show_phone скрывает его с витрины.
+
+BEGIN;
+
+ALTER TABLE supplier
+    ADD COLUMN IF NOT EXISTS logo_url    TEXT,
+    ADD COLUMN IF NOT EXISTS banner_url  TEXT,
+    ADD COLUMN IF NOT EXISTS description TEXT,
+    ADD COLUMN IF NOT EXISTS telegram    VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS instagram   VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS show_phone  BOOLEAN NOT NULL DEFAULT TRUE;
+
+COMMIT;
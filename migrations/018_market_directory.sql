@@ -0,0 +1,69 @@
+-- migrations/018_market_directory.sql
+-- Справочник рынков: адрес, координаты, часы работы и выходные дни, ряды и места.
+-- Поставщик выбирает место (stall_id), place_name и row_name остаются копией названий
+-- места и ряда. Существующие ряды и места создаются из текстовых полей поставщиков;
+-- если одно место указали несколько поставщиков, оно достаётся поставщику с наименьшим id.
+
+BEGIN;
+
+ALTER TABLE market
+    ADD COLUMN IF NOT EXISTS address   TEXT,
+    ADD COLUMN IF NOT EXISTS latitude  DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS opens_at  TIME,
+    ADD COLUMN IF NOT EXISTS closes_at TIME,
+    ADD COLUMN IF NOT EXISTS days_off  SMALLINT[] NOT NULL DEFAULT '{}'; -- Дни недели ISO: 1 — понедельник, 7 — воскресенье
+
+ALTER TABLE market DROP CONSTRAINT IF EXISTS market_coordinates_check;
+ALTER TABLE market ADD CONSTRAINT market_coordinates_check CHECK (
+    (latitude IS NULL AND longitude IS NULL)
+    OR (latitude BETWEEN -90 AND 90 AND longitude BETWEEN -180 AND 180)
+);
+
+CREATE TABLE IF NOT EXISTS market_rows (
+    id         SERIAL PRIMARY KEY,
+    market_id  INTEGER     NOT NULL REFERENCES market(id) ON DELETE CASCADE,
+    name       VARCHAR(50) NOT NULL,
+    sort_order INTEGER     NOT NULL DEFAULT 0,
+    UNIQUE (market_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS market_stalls (
+    id     SERIAL PRIMARY KEY,
+    row_id INTEGER     NOT NULL REFERENCES market_rows(id) ON DELETE CASCADE,
+    name   VARCHAR(50) NOT NULL,
+    UNIQUE (row_id, name)
+);
+
+ALTER TABLE supplier ADD COLUMN IF NOT EXISTS stall_id INTEGER REFERENCES market_stalls(id);
+
+-- Одно место занимает один поставщик
+CREATE UNIQUE INDEX IF NOT EXISTS idx_supplier_stall ON supplier (stall_id) WHERE stall_id IS NOT NULL;
+
+INSERT INTO market_rows (market_id, name)
+SELECT DISTINCT s.market_id, TRIM(s.row_name)
+FROM supplier s
+JOIN market m ON m.id = s.market_id
+WHERE TRIM(COALESCE(s.row_name, '')) <> '' AND TRIM(COALESCE(s.place_name, '')) <> ''
+ON CONFLICT (market_id, name) DO NOTHING;
+
+INSERT INTO market_stalls (row_id, name)
+SELECT DISTINCT r.id, TRIM(s.place_name)
+FROM supplier s
+JOIN market_rows r ON r.market_id = s.market_id AND r.name = TRIM(s.row_name)
+WHERE TRIM(COALESCE(s.place_name, '')) <> ''
+ON CONFLICT (row_id, name) DO NOTHING;
+
+UPDATE supplier s
+SET stall_id = claim.stall_id
+FROM (
+    SELECT DISTINCT ON (st.id) st.id AS stall_id, s2.id AS supplier_id
+    FROM supplier s2
+    JOIN market_rows r ON r.market_id = s2.market_id AND r.name = TRIM(s2.row_name)
+    JOIN market_stalls st ON st.row_id = r.id AND st.name = TRIM(s2.place_name)
+    WHERE NOT EXISTS (SELECT 1 FROM supplier o WHERE o.stall_id = st.id)
+    ORDER BY st.id, s2.id
+) claim
+WHERE s.id = claim.supplier_id AND s.stall_id IS NULL;
+
+COMMIT;
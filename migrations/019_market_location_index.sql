@@ -0,0 +1,10 @@
+-- migrations/019_market_location_index.sql
+-- Индекс для поиска рынков рядом: прямоугольник координат вокруг точки отбирается
+-- по индексу, расстояние по формуле гаверсинусов считается только для попавших в него рынков.
+
+BEGIN;
+
+CREATE INDEX IF NOT EXISTS idx_market_location ON market (latitude, longitude)
+    WHERE latitude IS NOT NULL AND longitude IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,54 @@
+-- migrations/020_supplier_verification.sql
+-- Проверка поставщика по документам (патент или свидетельство ИНН, договор аренды места).
+-- supplier.is_verified по-прежнему означает только подтверждённый через WhatsApp телефон,
+-- бейдж «проверенный продавец» выдаётся по verification_status = 'approved'.
+
+BEGIN;
+
+ALTER TABLE supplier
+    ADD COLUMN IF NOT EXISTS verification_status       VARCHAR(20) NOT NULL DEFAULT 'none',
+    ADD COLUMN IF NOT EXISTS verification_submitted_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS verified_at               TIMESTAMP;
+
+ALTER TABLE supplier DROP CONSTRAINT IF EXISTS supplier_verification_status_check;
+ALTER TABLE supplier
+    ADD CONSTRAINT supplier_verification_status_check
+        CHECK (verification_status IN ('none', 'pending', 'approved', 'rejected'));
+
+CREATE INDEX IF NOT EXISTS idx_supplier_verification_pending
+    ON supplier (verification_submitted_at) WHERE verification_status = 'pending';
+
+-- Документы хранятся в закрытой части бакета, object_key не является публичной ссылкой
+CREATE TABLE IF NOT EXISTS supplier_documents (
+    id           SERIAL PRIMARY KEY,
+    supplier_id  INTEGER NOT NULL REFERENCES supplier(id) ON DELETE CASCADE,
+    doc_type     VARCHAR(30) NOT NULL CHECK (doc_type IN ('patent', 'inn_certificate', 'stall_lease')),
+    object_key   TEXT NOT NULL,
+    file_name    VARCHAR(255) NOT NULL DEFAULT '',
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes   BIGINT NOT NULL,
+    uploaded_at  TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_supplier_documents_supplier ON supplier_documents (supplier_id, uploaded_at);
+
+CREATE TABLE IF NOT EXISTS supplier_verification_history (
+    id          SERIAL PRIMARY KEY,
+    supplier_id INTEGER NOT NULL REFERENCES supplier(id) ON DELETE CASCADE,
+    from_status VARCHAR(20) NOT NULL,
+    to_status   VARCHAR(20) NOT NULL,
+    changed_by  INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    comment     TEXT NOT NULL DEFAULT '',
+    created_at  TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_supplier_verification_history_supplier
+    ON supplier_verification_history (supplier_id, created_at);
+
+-- Правило автомодерации и одновременно требование для ручного подтверждения:
+-- пока оно включено, продукты непроверенных поставщиков не подтверждаются
+INSERT INTO moderation_rules (code, enabled, action, weight, params) VALUES
+    ('unverified_supplier', FALSE, 'review', 30, '{}')
+ON CONFLICT (code) DO NOTHING;
+
+COMMIT;
@@ -0,0 +1,45 @@
+-- migrations/021_supplier_members.sql
+-- Команда поставщика: владелец, менеджеры и контент-редакторы. Пользователь состоит
+-- не больше чем в одной команде, поэтому поставщик пользователя определяется однозначно.
+-- supplier.user_id остаётся ссылкой на владельца.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS supplier_members (
+    id          SERIAL PRIMARY KEY,
+    supplier_id INTEGER NOT NULL REFERENCES supplier(id) ON DELETE CASCADE,
+    user_id     INTEGER NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    role        VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'manager', 'content_editor')),
+    invited_by  INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at  TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_supplier_members_owner ON supplier_members (supplier_id) WHERE role = 'owner';
+
+-- Приглашение по номеру телефона принимается подтверждением номера кодом из WhatsApp
+CREATE TABLE IF NOT EXISTS supplier_invites (
+    id           SERIAL PRIMARY KEY,
+    supplier_id  INTEGER NOT NULL REFERENCES supplier(id) ON DELETE CASCADE,
+    user_id      INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    phone_number VARCHAR(20) NOT NULL,
+    role         VARCHAR(20) NOT NULL CHECK (role IN ('manager', 'content_editor')),
+    status       VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'revoked')),
+    invited_by   INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    expires_at   TIMESTAMP NOT NULL,
+    created_at   TIMESTAMP NOT NULL DEFAULT NOW(),
+    accepted_at  TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_supplier_invites_pending ON supplier_invites (user_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_supplier_invites_supplier ON supplier_invites (supplier_id, created_at);
+
+-- Владельцы существующих поставщиков. Если пользователь привязан к нескольким
+-- поставщикам, владельцем он становится у поставщика с меньшим id.
+INSERT INTO supplier_members (supplier_id, user_id, role)
+SELECT DISTINCT ON (user_id) id, user_id, 'owner'
+FROM supplier
+WHERE user_id IS NOT NULL
+ORDER BY user_id, id
+ON CONFLICT DO NOTHING;
+
+COMMIT;
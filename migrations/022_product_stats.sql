@@ -0,0 +1,45 @@
+-- migrations/022_product_stats.sql
+-- Статистика продуктов для кабинета поставщика. Просмотры, добавления в избранное
+-- и нажатия на контакты пишутся в product_events, фоновый агрегатор переносит их
+-- в дневные итоги product_daily_stats, из которых строится кабинет.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS product_events (
+    id         BIGSERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES product(id) ON DELETE CASCADE,
+    event_type VARCHAR(20) NOT NULL CHECK (event_type IN ('view', 'favorite', 'contact_click')),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_events_created ON product_events (created_at);
+
+CREATE TABLE IF NOT EXISTS product_favorites (
+    user_id    INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES product(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, product_id)
+);
+
+CREATE TABLE IF NOT EXISTS product_daily_stats (
+    product_id     INTEGER NOT NULL REFERENCES product(id) ON DELETE CASCADE,
+    day            DATE NOT NULL,
+    views          INTEGER NOT NULL DEFAULT 0,
+    favorites      INTEGER NOT NULL DEFAULT 0,
+    contact_clicks INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (product_id, day)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_daily_stats_day ON product_daily_stats (day);
+
+-- Последнее событие, учтённое в дневных итогах. Строка одна, её блокировка
+-- не даёт двум экземплярам приложения учесть события дважды.
+CREATE TABLE IF NOT EXISTS product_stats_state (
+    id            SMALLINT PRIMARY KEY CHECK (id = 1),
+    last_event_id BIGINT NOT NULL DEFAULT 0,
+    aggregated_at TIMESTAMP
+);
+
+INSERT INTO product_stats_state (id) VALUES (1) ON CONFLICT (id) DO NOTHING;
+
+COMMIT;
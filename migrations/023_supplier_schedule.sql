@@ -0,0 +1,37 @@
+-- migrations/023_supplier_schedule.sql
+-- Часы работы поставщика по дням недели, исключения на отдельные даты и отпуск.
+-- День недели без строки в supplier_working_hours — выходной. На время отпуска продукты
+-- поставщика остаются в каталоге с отметкой «нет в наличии» (unavailable) или скрываются (hidden).
+-- on_vacation переключает фоновая задача на границах отпуска, каталог фильтрует по нему.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS supplier_working_hours (
+    supplier_id INT      NOT NULL REFERENCES supplier(id) ON DELETE CASCADE,
+    weekday     SMALLINT NOT NULL CHECK (weekday BETWEEN 1 AND 7), -- ISO: 1 — понедельник, 7 — воскресенье
+    opens_at    TIME     NOT NULL,
+    closes_at   TIME     NOT NULL CHECK (closes_at > opens_at),
+    PRIMARY KEY (supplier_id, weekday)
+);
+
+CREATE TABLE IF NOT EXISTS supplier_schedule_exceptions (
+    supplier_id INT          NOT NULL REFERENCES supplier(id) ON DELETE CASCADE,
+    day         DATE         NOT NULL,
+    closed      BOOLEAN      NOT NULL,
+    opens_at    TIME,
+    closes_at   TIME,
+    note        VARCHAR(255) NOT NULL DEFAULT '',
+    PRIMARY KEY (supplier_id, day),
+    CHECK (closed OR (opens_at IS NOT NULL AND closes_at > opens_at))
+);
+
+ALTER TABLE supplier
+    ADD COLUMN IF NOT EXISTS vacation_starts_on DATE,
+    ADD COLUMN IF NOT EXISTS vacation_ends_on   DATE,
+    ADD COLUMN IF NOT EXISTS vacation_mode      VARCHAR(20) NOT NULL DEFAULT 'unavailable'
+        CHECK (vacation_mode IN ('unavailable', 'hidden')),
+    ADD COLUMN IF NOT EXISTS on_vacation        BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_supplier_on_vacation ON supplier (id) WHERE on_vacation;
+
+COMMIT;